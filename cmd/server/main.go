@@ -4,6 +4,7 @@ import (
 	"gold_portal/config"
 	"gold_portal/internal/api/route"
 	"gold_portal/internal/infrastructure/database"
+	"gold_portal/internal/pkg/crypto"
 
 	_ "gold_portal/docs"
 
@@ -21,6 +22,21 @@ func main() {
 		log.Fatalf("Ошибка загрузки конфигурации: %v", err)
 	}
 
+	// Хэшер нужен до InitDB: при старте создаётся администратор по умолчанию
+	hasher, err := crypto.NewPasswordHasher(crypto.PasswordHasherConfig{
+		Algorithm:         cfg.PasswordHash.Algorithm,
+		BcryptCost:        cfg.PasswordHash.BcryptCost,
+		Argon2Memory:      uint32(cfg.PasswordHash.Argon2Memory),
+		Argon2Iterations:  uint32(cfg.PasswordHash.Argon2Iterations),
+		Argon2Parallelism: uint8(cfg.PasswordHash.Argon2Parallelism),
+		Argon2SaltLength:  uint32(cfg.PasswordHash.Argon2SaltLength),
+		Argon2KeyLength:   uint32(cfg.PasswordHash.Argon2KeyLength),
+	})
+	if err != nil {
+		log.Fatalf("Ошибка настройки хэширования паролей: %v", err)
+	}
+	crypto.SetPasswordHasher(hasher)
+
 	db, err := database.InitDB(cfg)
 	if err != nil {
 		log.Fatalf("Ошибка подключения к базе данных: %v", err)
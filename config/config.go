@@ -4,17 +4,28 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	JWT      JWTConfig
-	Redis    RedisConfig
-	Minio    MinioConfig
+	Server    ServerConfig
+	Database  DatabaseConfig
+	JWT       JWTConfig
+	Redis     RedisConfig
+	Minio     MinioConfig
+	MFA       MFAConfig
+	WebAuthn  WebAuthnConfig
+	SMS       SMSConfig
+	OTP       OTPConfig
+	Lockout   LockoutConfig
+	RateLimit RateLimitConfig
+	Password  PasswordPolicyConfig
+	// Алгоритм хэширования паролей
+	PasswordHash PasswordHashConfig
+	APIKey       APIKeyConfig
 }
 
 type ServerConfig struct {
@@ -36,16 +47,33 @@ type DatabaseConfig struct {
 }
 
 type JWTConfig struct {
-	Secret        string
 	Expiry        time.Duration
 	RefreshExpiry time.Duration
+	ClientExpiry  time.Duration
+	Algorithm     string
+	KeysDir       string
+	KeyRotation   time.Duration
+	KeyOverlap    time.Duration
+	Issuer        string
 }
 
 type RedisConfig struct {
+	// redis или memory (хранилище в памяти процесса, только для разработки и тестов)
+	Driver   string
 	Host     string
 	Port     string
 	Password string
 	DB       int
+	// Размер пула соединений и число простаивающих соединений
+	PoolSize     int
+	MinIdleConns int
+	// Повторы команды при сетевой ошибке
+	MaxRetries   int
+	DialTimeout  time.Duration
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	// Попытки подключиться при старте, пока Redis не ответит на PING
+	ConnectAttempts int
 }
 
 type MinioConfig struct {
@@ -58,6 +86,130 @@ type MinioConfig struct {
 	MinioPublicEndpoint string
 }
 
+type MFAConfig struct {
+	// Роли, которым второй фактор обязателен для доступа к панели управления
+	RequiredRoles []string
+	// Название сервиса в приложении-аутентификаторе
+	Issuer string
+	// Время на ввод кода после пароля
+	ChallengeExpiry time.Duration
+	// Число попыток ввода кода на один вход
+	MaxAttempts int
+	// Ключ шифрования секретов TOTP в базе. Отдельный от OTP_HASH_KEY,
+	// чтобы утечка одного ключа не раскрывала второй фактор
+	EncryptionKey string
+}
+
+type WebAuthnConfig struct {
+	// Домен, к которому привязываются ключи доступа
+	RPID          string
+	RPDisplayName string
+	// Адреса фронтенда, с которых разрешены церемонии WebAuthn
+	RPOrigins []string
+	// Время на ответ аутентификатора
+	ChallengeExpiry time.Duration
+}
+
+type SMSConfig struct {
+	// console, file (коды видны в журнале или файле, только для разработки) или http.
+	// Значения по умолчанию нет: драйвер нужно выбрать явно
+	Driver      string
+	FilePath    string
+	HTTPURL     string
+	HTTPToken   string
+	HTTPTimeout time.Duration
+	// Имя отправителя у провайдера
+	From string
+}
+
+type OTPConfig struct {
+	Length int
+	Expiry time.Duration
+	// Число попыток ввода одного кода
+	MaxAttempts int
+	// Минимальный интервал между отправками кода на один номер
+	ResendInterval time.Duration
+	// Ключ HMAC для хэшей кодов в кэше
+	HashKey string
+}
+
+type LockoutConfig struct {
+	// Неудачных попыток до блокировки номера и IP
+	PhoneMaxAttempts int
+	IPMaxAttempts    int
+	// Попыток без задержки; дальше задержка удваивается с каждой ошибкой
+	FreeAttempts int
+	BaseDelay    time.Duration
+	MaxDelay     time.Duration
+	// Срок хранения счётчика неудачных попыток
+	Window time.Duration
+	// Длительность блокировки
+	Duration time.Duration
+}
+
+// Способы различать клиентов в ограничении частоты запросов
+const (
+	RateLimitKeyIP    = "ip"
+	RateLimitKeyUser  = "user"
+	RateLimitKeyPhone = "phone"
+)
+
+type RateLimitPolicy struct {
+	// Запросов за окно; 0 отключает ограничение
+	Limit  int
+	Window time.Duration
+	// По каким признакам считаются запросы: ip, user, phone. Лимит действует для каждого отдельно
+	KeyBy []string
+}
+
+type RateLimitConfig struct {
+	Enabled bool
+	// Вход по паролю, по коду из SMS, по ключу доступа и второй шаг 2FA
+	Login    RateLimitPolicy
+	Register RateLimitPolicy
+	Refresh  RateLimitPolicy
+	// Отправка и проверка кодов из SMS, сброс пароля
+	OTP       RateLimitPolicy
+	Dashboard RateLimitPolicy
+}
+
+type PasswordPolicyConfig struct {
+	MinLength int
+	// bcrypt учитывает только первые 72 байта пароля; с argon2id ограничение можно поднять
+	MaxLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	// Сколько последних паролей нельзя использовать повторно; 0 отключает проверку
+	HistorySize int
+	// Каталог утёкших паролей в формате k-anonymity: файлы с именем из первых 5 символов
+	// SHA-1 пароля, строки SUFFIX:COUNT. Пустое значение отключает проверку
+	BreachedDir string
+}
+
+// PasswordHashConfig задаёт, как хэшируются новые пароли. Хэши bcrypt и argon2id
+// проверяются всегда, устаревшие пересчитываются при входе
+type PasswordHashConfig struct {
+	// argon2id или bcrypt
+	Algorithm  string
+	BcryptCost int
+	// Память в КиБ
+	Argon2Memory      int
+	Argon2Iterations  int
+	Argon2Parallelism int
+	Argon2SaltLength  int
+	Argon2KeyLength   int
+}
+
+type APIKeyConfig struct {
+	// Срок действия ключа, если пользователь его не указал, и наибольший допустимый срок
+	DefaultLifetime time.Duration
+	MaxLifetime     time.Duration
+	// Сколько действующих ключей может быть у пользователя
+	MaxPerUser int
+}
+
 func LoadConfig() (*Config, error) {
 	_ = godotenv.Load() // Игнорируем ошибку, если .env файл не найден
 
@@ -79,15 +231,28 @@ func LoadConfig() (*Config, error) {
 			ConnMaxLifetime: time.Hour * time.Duration(getEnvAsInt("DB_CONN_MAX_LIFETIME_HOURS", 1)),
 		},
 		JWT: JWTConfig{
-			Secret:        getEnv("SECRET_KEY", ""),
 			Expiry:        time.Hour * time.Duration(getEnvAsInt("JWT_EXPIRY_HOURS", 24)),
 			RefreshExpiry: time.Hour * time.Duration(getEnvAsInt("JWT_REFRESH_EXPIRY_HOURS", 168)),
+			ClientExpiry:  time.Minute * time.Duration(getEnvAsInt("JWT_CLIENT_EXPIRY_MINUTES", 15)),
+			Algorithm:     getEnv("JWT_ALGORITHM", "RS256"),
+			KeysDir:       getEnv("JWT_KEYS_DIR", "keys"),
+			KeyRotation:   time.Hour * time.Duration(getEnvAsInt("JWT_KEY_ROTATION_HOURS", 720)),
+			KeyOverlap:    time.Hour * time.Duration(getEnvAsInt("JWT_KEY_OVERLAP_HOURS", 168)),
+			Issuer:        strings.TrimSuffix(getEnv("JWT_ISSUER", "http://localhost:8080"), "/"),
 		},
 		Redis: RedisConfig{
-			Host:     getEnv("REDIS_HOST", ""),
-			Port:     getEnv("REDIS_PORT", "6379"),
-			Password: getEnv("REDIS_PASSWORD", ""),
-			DB:       getEnvAsInt("REDIS_DB", 0),
+			Driver:          getEnv("REDIS_DRIVER", "redis"),
+			Host:            getEnv("REDIS_HOST", ""),
+			Port:            getEnv("REDIS_PORT", "6379"),
+			Password:        getEnv("REDIS_PASSWORD", ""),
+			DB:              getEnvAsInt("REDIS_DB", 0),
+			PoolSize:        getEnvAsInt("REDIS_POOL_SIZE", 20),
+			MinIdleConns:    getEnvAsInt("REDIS_MIN_IDLE_CONNS", 2),
+			MaxRetries:      getEnvAsInt("REDIS_MAX_RETRIES", 3),
+			DialTimeout:     time.Second * time.Duration(getEnvAsInt("REDIS_DIAL_TIMEOUT_SECONDS", 5)),
+			ReadTimeout:     time.Second * time.Duration(getEnvAsInt("REDIS_READ_TIMEOUT_SECONDS", 3)),
+			WriteTimeout:    time.Second * time.Duration(getEnvAsInt("REDIS_WRITE_TIMEOUT_SECONDS", 3)),
+			ConnectAttempts: getEnvAsInt("REDIS_CONNECT_ATTEMPTS", 5),
 		},
 		Minio: MinioConfig{
 			MinioHost:           getEnv("MINIO_HOST", ""),
@@ -98,6 +263,75 @@ func LoadConfig() (*Config, error) {
 			MinioSSL:            getEnvAsBool("MINIO_SSL", false),
 			MinioPublicEndpoint: getEnv("MINIO_PUBLIC_ENDPOINT", ""),
 		},
+		MFA: MFAConfig{
+			RequiredRoles:   getEnvAsSlice("MFA_REQUIRED_ROLES", []string{"admin", "superuser"}),
+			Issuer:          getEnv("MFA_ISSUER", "Gold Portal"),
+			ChallengeExpiry: time.Minute * time.Duration(getEnvAsInt("MFA_CHALLENGE_EXPIRY_MINUTES", 5)),
+			MaxAttempts:     getEnvAsInt("MFA_MAX_ATTEMPTS", 5),
+			EncryptionKey:   getEnv("MFA_ENCRYPTION_KEY", ""),
+		},
+		WebAuthn: WebAuthnConfig{
+			RPID:            getEnv("WEBAUTHN_RP_ID", "localhost"),
+			RPDisplayName:   getEnv("WEBAUTHN_RP_DISPLAY_NAME", "Gold Portal"),
+			RPOrigins:       getEnvAsSlice("WEBAUTHN_RP_ORIGINS", []string{"http://localhost:5173", "http://localhost:3000"}),
+			ChallengeExpiry: time.Minute * time.Duration(getEnvAsInt("WEBAUTHN_CHALLENGE_EXPIRY_MINUTES", 5)),
+		},
+		SMS: SMSConfig{
+			Driver:      getEnv("SMS_DRIVER", ""),
+			FilePath:    getEnv("SMS_FILE_PATH", ""),
+			HTTPURL:     getEnv("SMS_HTTP_URL", ""),
+			HTTPToken:   getEnv("SMS_HTTP_TOKEN", ""),
+			HTTPTimeout: time.Second * time.Duration(getEnvAsInt("SMS_HTTP_TIMEOUT_SECONDS", 10)),
+			From:        getEnv("SMS_FROM", "GoldPortal"),
+		},
+		OTP: OTPConfig{
+			Length:         getEnvAsInt("OTP_LENGTH", 6),
+			Expiry:         time.Minute * time.Duration(getEnvAsInt("OTP_EXPIRY_MINUTES", 5)),
+			MaxAttempts:    getEnvAsInt("OTP_MAX_ATTEMPTS", 5),
+			ResendInterval: time.Second * time.Duration(getEnvAsInt("OTP_RESEND_INTERVAL_SECONDS", 60)),
+			HashKey:        getEnv("OTP_HASH_KEY", ""),
+		},
+		Lockout: LockoutConfig{
+			PhoneMaxAttempts: getEnvAsInt("LOCKOUT_PHONE_MAX_ATTEMPTS", 10),
+			IPMaxAttempts:    getEnvAsInt("LOCKOUT_IP_MAX_ATTEMPTS", 50),
+			FreeAttempts:     getEnvAsInt("LOCKOUT_FREE_ATTEMPTS", 3),
+			BaseDelay:        time.Second * time.Duration(getEnvAsInt("LOCKOUT_BASE_DELAY_SECONDS", 1)),
+			MaxDelay:         time.Second * time.Duration(getEnvAsInt("LOCKOUT_MAX_DELAY_SECONDS", 60)),
+			Window:           time.Minute * time.Duration(getEnvAsInt("LOCKOUT_WINDOW_MINUTES", 15)),
+			Duration:         time.Minute * time.Duration(getEnvAsInt("LOCKOUT_DURATION_MINUTES", 15)),
+		},
+		RateLimit: RateLimitConfig{
+			Enabled:   getEnvAsBool("RATE_LIMIT_ENABLED", true),
+			Login:     getRateLimitPolicy("LOGIN", 10, time.Minute, RateLimitKeyIP, RateLimitKeyPhone),
+			Register:  getRateLimitPolicy("REGISTER", 5, time.Hour, RateLimitKeyIP),
+			Refresh:   getRateLimitPolicy("REFRESH", 30, time.Minute, RateLimitKeyIP),
+			OTP:       getRateLimitPolicy("OTP", 5, 10*time.Minute, RateLimitKeyIP, RateLimitKeyPhone),
+			Dashboard: getRateLimitPolicy("DASHBOARD", 300, time.Minute, RateLimitKeyUser),
+		},
+		Password: PasswordPolicyConfig{
+			MinLength:     getEnvAsInt("PASSWORD_MIN_LENGTH", 8),
+			MaxLength:     getEnvAsInt("PASSWORD_MAX_LENGTH", 72),
+			RequireUpper:  getEnvAsBool("PASSWORD_REQUIRE_UPPER", true),
+			RequireLower:  getEnvAsBool("PASSWORD_REQUIRE_LOWER", true),
+			RequireDigit:  getEnvAsBool("PASSWORD_REQUIRE_DIGIT", true),
+			RequireSymbol: getEnvAsBool("PASSWORD_REQUIRE_SYMBOL", false),
+			HistorySize:   getEnvAsInt("PASSWORD_HISTORY_SIZE", 5),
+			BreachedDir:   getEnv("PASSWORD_BREACHED_DIR", ""),
+		},
+		PasswordHash: PasswordHashConfig{
+			Algorithm:         getEnv("PASSWORD_HASH_ALGORITHM", "argon2id"),
+			BcryptCost:        getEnvAsInt("PASSWORD_BCRYPT_COST", 12),
+			Argon2Memory:      getEnvAsInt("PASSWORD_ARGON2_MEMORY_KB", 64*1024),
+			Argon2Iterations:  getEnvAsInt("PASSWORD_ARGON2_ITERATIONS", 3),
+			Argon2Parallelism: getEnvAsInt("PASSWORD_ARGON2_PARALLELISM", 2),
+			Argon2SaltLength:  getEnvAsInt("PASSWORD_ARGON2_SALT_LENGTH", 16),
+			Argon2KeyLength:   getEnvAsInt("PASSWORD_ARGON2_KEY_LENGTH", 32),
+		},
+		APIKey: APIKeyConfig{
+			DefaultLifetime: 24 * time.Hour * time.Duration(getEnvAsInt("API_KEY_DEFAULT_LIFETIME_DAYS", 90)),
+			MaxLifetime:     24 * time.Hour * time.Duration(getEnvAsInt("API_KEY_MAX_LIFETIME_DAYS", 365)),
+			MaxPerUser:      getEnvAsInt("API_KEY_MAX_PER_USER", 20),
+		},
 	}
 
 	// Валидация конфигурации
@@ -122,8 +356,83 @@ func (c *Config) validate() error {
 	if c.Database.Name == "" {
 		return fmt.Errorf("DB_NAME is required")
 	}
-	if c.JWT.Secret == "" {
-		return fmt.Errorf("SECRET_KEY is required")
+	if c.JWT.Algorithm != "RS256" && c.JWT.Algorithm != "EdDSA" {
+		return fmt.Errorf("JWT_ALGORITHM must be RS256 or EdDSA")
+	}
+	// Вытесненный ключ должен проверять подписи, пока живут выданные им токены
+	if c.JWT.KeyOverlap < c.JWT.RefreshExpiry {
+		return fmt.Errorf("JWT_KEY_OVERLAP_HOURS must not be less than JWT_REFRESH_EXPIRY_HOURS")
+	}
+	switch c.Redis.Driver {
+	case "redis":
+		if c.Redis.Host == "" {
+			return fmt.Errorf("REDIS_HOST is required")
+		}
+	case "memory":
+	default:
+		return fmt.Errorf("REDIS_DRIVER must be redis or memory")
+	}
+	if c.MFA.EncryptionKey == "" {
+		return fmt.Errorf("MFA_ENCRYPTION_KEY is required")
+	}
+	if c.OTP.HashKey == "" {
+		return fmt.Errorf("OTP_HASH_KEY is required")
+	}
+	// Утечка одного ключа не должна раскрывать то, что защищено другим
+	if c.OTP.HashKey == c.MFA.EncryptionKey {
+		return fmt.Errorf("OTP_HASH_KEY must differ from MFA_ENCRYPTION_KEY")
+	}
+	// Без явного выбора коды входа и сброса пароля не должны молча уходить в консоль
+	switch c.SMS.Driver {
+	case "console", "file", "http":
+	case "":
+		return fmt.Errorf("SMS_DRIVER is required")
+	default:
+		return fmt.Errorf("SMS_DRIVER must be console, file or http")
+	}
+	if c.OTP.Length < 4 || c.OTP.Length > 10 {
+		return fmt.Errorf("OTP_LENGTH must be between 4 and 10")
+	}
+	for name, policy := range map[string]RateLimitPolicy{
+		"LOGIN":     c.RateLimit.Login,
+		"REGISTER":  c.RateLimit.Register,
+		"REFRESH":   c.RateLimit.Refresh,
+		"OTP":       c.RateLimit.OTP,
+		"DASHBOARD": c.RateLimit.Dashboard,
+	} {
+		if policy.Limit > 0 && policy.Window <= 0 {
+			return fmt.Errorf("RATE_LIMIT_%s_WINDOW_SECONDS must be positive", name)
+		}
+		for _, key := range policy.KeyBy {
+			if key != RateLimitKeyIP && key != RateLimitKeyUser && key != RateLimitKeyPhone {
+				return fmt.Errorf("RATE_LIMIT_%s_KEY_BY: unknown key %q", name, key)
+			}
+		}
+	}
+	if c.Password.MinLength < 1 || c.Password.MaxLength < c.Password.MinLength {
+		return fmt.Errorf("PASSWORD_MIN_LENGTH must be positive and not greater than PASSWORD_MAX_LENGTH")
+	}
+	switch c.PasswordHash.Algorithm {
+	case "argon2id":
+		if c.PasswordHash.Argon2Memory < 8*c.PasswordHash.Argon2Parallelism || c.PasswordHash.Argon2Iterations < 1 ||
+			c.PasswordHash.Argon2Parallelism < 1 || c.PasswordHash.Argon2Parallelism > 255 {
+			return fmt.Errorf("PASSWORD_ARGON2_MEMORY_KB, PASSWORD_ARGON2_ITERATIONS and PASSWORD_ARGON2_PARALLELISM must be positive")
+		}
+		if c.PasswordHash.Argon2SaltLength < 8 || c.PasswordHash.Argon2KeyLength < 16 {
+			return fmt.Errorf("PASSWORD_ARGON2_SALT_LENGTH must be at least 8 and PASSWORD_ARGON2_KEY_LENGTH at least 16")
+		}
+	case "bcrypt":
+		if c.PasswordHash.BcryptCost < 4 || c.PasswordHash.BcryptCost > 31 {
+			return fmt.Errorf("PASSWORD_BCRYPT_COST must be between 4 and 31")
+		}
+	default:
+		return fmt.Errorf("PASSWORD_HASH_ALGORITHM must be argon2id or bcrypt")
+	}
+	if c.APIKey.DefaultLifetime <= 0 || c.APIKey.MaxLifetime < c.APIKey.DefaultLifetime {
+		return fmt.Errorf("API_KEY_DEFAULT_LIFETIME_DAYS must be positive and not greater than API_KEY_MAX_LIFETIME_DAYS")
+	}
+	if c.APIKey.MaxPerUser < 1 {
+		return fmt.Errorf("API_KEY_MAX_PER_USER must be positive")
 	}
 	if c.Minio.MinioAccessKey == "" {
 		return fmt.Errorf("MINIO_ACCESS_KEY is required")
@@ -158,3 +467,26 @@ func getEnvAsBool(key string, fallback bool) bool {
 	}
 	return fallback
 }
+
+func getEnvAsSlice(key string, fallback []string) []string {
+	if value, exists := os.LookupEnv(key); exists {
+		var values []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				values = append(values, item)
+			}
+		}
+		return values
+	}
+	return fallback
+}
+
+// getRateLimitPolicy читает RATE_LIMIT_<NAME>_LIMIT, RATE_LIMIT_<NAME>_WINDOW_SECONDS и RATE_LIMIT_<NAME>_KEY_BY
+func getRateLimitPolicy(name string, limit int, window time.Duration, keyBy ...string) RateLimitPolicy {
+	prefix := "RATE_LIMIT_" + name
+	return RateLimitPolicy{
+		Limit:  getEnvAsInt(prefix+"_LIMIT", limit),
+		Window: time.Second * time.Duration(getEnvAsInt(prefix+"_WINDOW_SECONDS", int(window.Seconds()))),
+		KeyBy:  getEnvAsSlice(prefix+"_KEY_BY", keyBy),
+	}
+}
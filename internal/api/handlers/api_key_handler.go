@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	stdErrors "errors"
+	"gold_portal/internal/domain/dto"
+	"gold_portal/internal/domain/services"
+	"gold_portal/internal/errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type APIKeyHandler struct {
+	apiKeyService services.APIKeyService
+}
+
+func NewAPIKeyHandler(apiKeyService services.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeyService,
+	}
+}
+
+// CreateMyAPIKey godoc
+// @Summary Создать API-ключ
+// @Description Создаёт именованный ключ для скриптов и интеграций. Ключ передаётся в заголовке X-API-Key
+// @Description или Authorization: Bearer и действует с ролью владельца в пределах scopes:
+// @Description profile:read, users:read, users:write, audit:read. Ключ возвращается только в этом ответе
+// @Tags auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.APIKeyCreateRequestDTO true "Название, scopes и срок действия"
+// @Success 201 {object} dto.APIKeyCreateResponseDTO
+// @Router /api/v1/auth/api-keys [post]
+func (h *APIKeyHandler) CreateMyAPIKey(c *gin.Context) {
+	id, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "User is not authorized"})
+		return
+	}
+	organizationID, ok := activeOrganization(c)
+	if !ok {
+		return
+	}
+
+	var request dto.APIKeyCreateRequestDTO
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	request.UserAgent = c.GetHeader("User-Agent")
+	request.ClientIP = c.ClientIP()
+
+	ctx := c.Request.Context()
+	key, err := h.apiKeyService.Create(ctx, id.(uuid.UUID), organizationID, request)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, key)
+}
+
+// GetMyAPIKeys godoc
+// @Summary API-ключи
+// @Description Возвращает действующие API-ключи текущего пользователя без секретной части
+// @Tags auth
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} dto.APIKeyResponseDTO
+// @Router /api/v1/auth/api-keys [get]
+func (h *APIKeyHandler) GetMyAPIKeys(c *gin.Context) {
+	id, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "User is not authorized"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	keys, err := h.apiKeyService.GetByUser(ctx, id.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, keys)
+}
+
+// RevokeMyAPIKey godoc
+// @Summary Отозвать API-ключ
+// @Description Отзывает API-ключ текущего пользователя; запросы с ним сразу перестают приниматься
+// @Tags auth
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "ID ключа"
+// @Router /api/v1/auth/api-keys/{id} [delete]
+func (h *APIKeyHandler) RevokeMyAPIKey(c *gin.Context) {
+	id, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "User is not authorized"})
+		return
+	}
+
+	keyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Некорректный ID ключа"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := h.apiKeyService.Revoke(ctx, id.(uuid.UUID), keyID, c.ClientIP(), c.GetHeader("User-Agent")); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "API-ключ отозван"})
+}
+
+func (h *APIKeyHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case stdErrors.Is(err, errors.ErrAPIKeyNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"message": "API-ключ не найден"})
+	case stdErrors.Is(err, errors.ErrAPIKeyInvalidScope):
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+	case stdErrors.Is(err, errors.ErrAPIKeyInvalidExpiry):
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Срок действия ключа должен быть в будущем и не дальше допустимого"})
+	case stdErrors.Is(err, errors.ErrAPIKeyLimitReached):
+		c.JSON(http.StatusConflict, gin.H{"message": "Достигнуто максимальное число API-ключей"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+	}
+}
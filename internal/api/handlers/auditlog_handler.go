@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"gold_portal/internal/domain/dto"
+	"gold_portal/internal/domain/entities"
 	"gold_portal/internal/domain/services"
 	"net/http"
 
@@ -18,14 +19,24 @@ func NewAuditHandler(auditService services.AuditService) *AuditHandler {
 
 // GetAllLogs godoc
 // @Summary Получить все действия пользователей
-// @Description Возвращает список действий пользователей
+// @Description Возвращает список действий пользователей текущей организации.
+// @Description Записи вне организаций (вход, действия на платформе) видит только superuser
 // @Tags audit
 // @Security BearerAuth
 // @Produce json
 // @Success 200 {array} dto.AuditLogResponse
 // @Router /api/v1/audit [get]
 func (h *AuditHandler) GetAllLogs(c *gin.Context) {
-	logs, err := h.auditService.GetAll()
+	organizationID, ok := activeOrganization(c)
+	if !ok {
+		return
+	}
+
+	// Записи без организации видит только superuser платформы
+	roleValue, _ := c.Get("role")
+	role, _ := roleValue.(entities.Role)
+	includePlatform := role == entities.RoleSuperUser
+	logs, err := h.auditService.GetAll(organizationID, includePlatform)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -35,16 +46,18 @@ func (h *AuditHandler) GetAllLogs(c *gin.Context) {
 	var responseLogs []dto.AuditLogResponse
 	for _, log := range logs {
 		responseLog := dto.AuditLogResponse{
-			ID:        log.ID,
-			UserID:    log.UserID,
-			Action:    log.Action,
-			Entity:    log.Entity,
-			EntityID:  log.EntityID,
-			Data:      log.Data,
-			Status:    log.Status,
-			ClientIP:  log.ClientIP,
-			UserAgent: log.UserAgent,
-			CreatedAt: log.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+			ID:             log.ID,
+			UserID:         log.UserID,
+			APIKeyID:       log.APIKeyID,
+			OrganizationID: log.OrganizationID,
+			Action:         log.Action,
+			Entity:         log.Entity,
+			EntityID:       log.EntityID,
+			Data:           log.Data,
+			Status:         log.Status,
+			ClientIP:       log.ClientIP,
+			UserAgent:      log.UserAgent,
+			CreatedAt:      log.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		}
 		responseLogs = append(responseLogs, responseLog)
 	}
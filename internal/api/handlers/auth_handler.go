@@ -1,11 +1,14 @@
 package handlers
 
 import (
+	stdErrors "errors"
 	"gold_portal/internal/domain/dto"
 	"gold_portal/internal/domain/entities"
 	"gold_portal/internal/domain/services"
+	"gold_portal/internal/errors"
 	"mime/multipart"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -13,11 +16,13 @@ import (
 
 type AuthHandler struct {
 	authService services.AuthService
+	roleService services.RoleService
 }
 
-func NewAuthHandler(authService services.AuthService) *AuthHandler {
+func NewAuthHandler(authService services.AuthService, roleService services.RoleService) *AuthHandler {
 	return &AuthHandler{
 		authService: authService,
+		roleService: roleService,
 	}
 }
 
@@ -53,6 +58,9 @@ func (h *AuthHandler) UserRegister(c *gin.Context) {
 	ctx := c.Request.Context()
 	user, err := h.authService.UserRegister(ctx, request, photoFile)
 	if err != nil {
+		if respondPasswordPolicy(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
 		return
 	}
@@ -64,7 +72,7 @@ func (h *AuthHandler) UserRegister(c *gin.Context) {
 
 // Register godoc
 // @Summary Регистрация нового пользователя
-// @Description Регистрирует нового пользователя в системе
+// @Description Регистрирует нового пользователя участником текущей организации с ролью из формы
 // @Tags dashboard
 // @Security BearerAuth
 // @Accept multipart/form-data
@@ -86,6 +94,13 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	request.Password = c.PostForm("password")
 	request.Phone = c.PostForm("phone")
 	request.Role = entities.Role(c.PostForm("role"))
+	if request.Role != "" && !respondRoleAssignment(c, h.roleService, request.Role) {
+		return
+	}
+	organizationID, ok := activeOrganization(c)
+	if !ok {
+		return
+	}
 
 	var photoFile *multipart.FileHeader
 	if file, err := c.FormFile("photo"); err == nil && file != nil {
@@ -93,8 +108,11 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	}
 
 	ctx := c.Request.Context()
-	user, err := h.authService.Register(ctx, request, photoFile)
+	user, err := h.authService.Register(ctx, organizationID, request, photoFile)
 	if err != nil {
+		if respondPasswordPolicy(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
 		return
 	}
@@ -106,7 +124,8 @@ func (h *AuthHandler) Register(c *gin.Context) {
 
 // Login godoc
 // @Summary Вход в систему
-// @Description Аутентифицирует пользователя и возвращает JWT токен
+// @Description Аутентифицирует пользователя и возвращает JWT токен.
+// @Description Если у пользователя включена 2FA, вместо токена возвращается mfa_token для /auth/2fa/verify
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -118,33 +137,67 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
 		return
 	}
+	request.UserAgent = c.GetHeader("User-Agent")
+	request.ClientIP = c.ClientIP()
 
 	ctx := c.Request.Context()
 	tokenResponse, err := h.authService.Login(ctx, request)
 	if err != nil {
+		if respondLockout(c, err) {
+			return
+		}
 		c.JSON(http.StatusUnauthorized, gin.H{"message": err.Error()})
 		return
 	}
 
-	http.SetCookie(c.Writer, &http.Cookie{
-		Name:     "access_token",
-		Value:    tokenResponse.AccessToken,
-		Path:     "/",
-		HttpOnly: true,
-		Secure:   false,
-		SameSite: http.SameSiteLaxMode,
-		MaxAge:   int(h.authService.GetAccessTokenExpiry().Seconds()),
-	})
+	// Пароль верный, но токены выдаются только после ввода кода 2FA
+	if tokenResponse.MFARequired {
+		c.JSON(http.StatusOK, gin.H{
+			"mfa_required": true,
+			"mfa_token":    tokenResponse.MFAToken,
+			"message":      tokenResponse.Message,
+		})
+		return
+	}
 
-	http.SetCookie(c.Writer, &http.Cookie{
-		Name:     "refresh_token",
-		Value:    tokenResponse.RefreshToken,
-		Path:     "/",
-		HttpOnly: true,
-		Secure:   false, // true в production с HTTPS
-		SameSite: http.SameSiteStrictMode,
-		MaxAge:   int(h.authService.GetRefreshTokenExpiry().Seconds()),
+	setAuthCookies(c, h.authService, tokenResponse.AccessToken, tokenResponse.RefreshToken)
+
+	c.JSON(http.StatusOK, gin.H{
+		"access_token": tokenResponse.AccessToken,
+		"message":      "Success authorization",
 	})
+}
+
+// VerifyMFA godoc
+// @Summary Второй шаг входа
+// @Description Проверяет код из приложения-аутентификатора или код восстановления по mfa_token, полученному при входе, и выдаёт токены
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.MFAVerifyRequestDTO true "mfa_token и код"
+// @Router /api/v1/auth/2fa/verify [post]
+func (h *AuthHandler) VerifyMFA(c *gin.Context) {
+	var request dto.MFAVerifyRequestDTO
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	tokenResponse, err := h.authService.VerifyMFA(ctx, request)
+	if err != nil {
+		if stdErrors.Is(err, errors.ErrMFAChallengeInvalid) {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"message": err.Error(),
+				"code":    "AUTH_MFA_CHALLENGE_INVALID",
+			})
+			return
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"message": err.Error()})
+		return
+	}
+
+	setAuthCookies(c, h.authService, tokenResponse.AccessToken, tokenResponse.RefreshToken)
 
 	c.JSON(http.StatusOK, gin.H{
 		"access_token": tokenResponse.AccessToken,
@@ -154,24 +207,44 @@ func (h *AuthHandler) Login(c *gin.Context) {
 
 // Logout godoc
 // @Summary Выход из системы
-// @Description Завершает сессию пользователя. На сервере токен не сохраняется, поэтому выход обрабатывается на клиенте (удаление токена).
+// @Description Завершает сессию: access токен попадает в черный список, refresh токен и сессия отзываются.
+// @Description Токены берутся из cookie, заголовка Authorization и тела запроса; cookie очищаются
 // @Tags auth
 // @Security BearerAuth
+// @Accept json
 // @Produce json
+// @Param request body dto.RefreshTokenRequest false "Refresh token, если он хранится не в cookie"
 // @Router /api/v1/auth/logout [post]
 func (h *AuthHandler) Logout(c *gin.Context) {
-	ctx := c.Request.Context()
-	token, err := c.Cookie("access_token")
-	if err != nil || token == "" {
+	var req dto.RefreshTokenRequest
+	_ = c.ShouldBindJSON(&req)
+
+	accessToken, _ := c.Cookie("access_token")
+	if accessToken == "" {
+		authHeader := c.GetHeader("Authorization")
+		if strings.HasPrefix(authHeader, "Bearer ") {
+			accessToken = strings.TrimPrefix(authHeader, "Bearer ")
+		}
+	}
+	refreshToken := req.RefreshToken
+	if refreshToken == "" {
+		refreshToken, _ = c.Cookie("refresh_token")
+	}
+
+	// Cookie очищаем в любом случае, даже если токены уже недействительны
+	clearAuthCookies(c)
+
+	if accessToken == "" && refreshToken == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"message": "Токен не предоставлен"})
 		return
 	}
-	err = h.authService.Logout(ctx, token)
-	if err != nil {
+
+	ctx := c.Request.Context()
+	err := h.authService.Logout(ctx, accessToken, refreshToken)
+	if err != nil && !stdErrors.Is(err, errors.ErrInvalidToken) {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
 		return
 	}
-	c.SetCookie("access_token", "", -1, "/", "", false, true)
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Success logout",
 	})
@@ -179,15 +252,21 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 
 // Refresh godoc
 // @Summary Обновление access токена по refresh token
-// @Description Получить новый access токен, отправив refresh token
+// @Description Получить новую пару токенов, отправив refresh token в теле запроса или в cookie. Refresh token одноразовый: повторное использование отзывает все токены сессии.
 // @Tags auth
 // @Accept json
 // @Produce json
-// @Param request body dto.RefreshTokenRequest true "Тело запроса с refresh token"
+// @Param request body dto.RefreshTokenRequest false "Тело запроса с refresh token"
 // @Router /api/v1/auth/refresh [post]
 func (h *AuthHandler) Refresh(c *gin.Context) {
 	var req dto.RefreshTokenRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	_ = c.ShouldBindJSON(&req)
+	if req.RefreshToken == "" {
+		if cookieToken, err := c.Cookie("refresh_token"); err == nil {
+			req.RefreshToken = cookieToken
+		}
+	}
+	if req.RefreshToken == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"message": "Refresh token required"})
 		return
 	}
@@ -195,19 +274,30 @@ func (h *AuthHandler) Refresh(c *gin.Context) {
 	ctx := c.Request.Context()
 	tokenResponse, err := h.authService.RefreshToken(ctx, req.RefreshToken)
 	if err != nil {
+		if stdErrors.Is(err, errors.ErrRefreshTokenReused) {
+			clearAuthCookies(c)
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"message": err.Error(),
+				"code":    "AUTH_REFRESH_TOKEN_REUSED",
+			})
+			return
+		}
 		c.JSON(http.StatusUnauthorized, gin.H{"message": err.Error()})
 		return
 	}
 
+	setAuthCookies(c, h.authService, tokenResponse.AccessToken, tokenResponse.RefreshToken)
+
 	c.JSON(http.StatusOK, gin.H{
-		"access_token": tokenResponse.AccessToken,
-		"message":      tokenResponse.Message,
+		"access_token":  tokenResponse.AccessToken,
+		"refresh_token": tokenResponse.RefreshToken,
+		"message":       tokenResponse.Message,
 	})
 }
 
 // UserMe
 // @Summary Данные профиля
-// @Description Данные авторизованного пользователя
+// @Description Данные авторизованного пользователя с ролью в текущей организации
 // @Tags auth
 // @Security BearerAuth
 // @Produce json
@@ -217,11 +307,44 @@ func (h *AuthHandler) UserMe(c *gin.Context) {
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"message": "User is not authorized"})
 	}
+	organizationID, ok := activeOrganization(c)
+	if !ok {
+		return
+	}
 	ctx := c.Request.Context()
-	profile, err := h.authService.UserMe(ctx, id.(uuid.UUID))
+	profile, err := h.authService.UserMe(ctx, id.(uuid.UUID), organizationID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, profile)
 }
+
+// setAuthCookies выставляет cookie с access и refresh токенами
+func setAuthCookies(c *gin.Context, authService services.AuthService, accessToken, refreshToken string) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     "access_token",
+		Value:    accessToken,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   false,
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   int(authService.GetAccessTokenExpiry().Seconds()),
+	})
+
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     "refresh_token",
+		Value:    refreshToken,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   false, // true в production с HTTPS
+		SameSite: http.SameSiteStrictMode,
+		MaxAge:   int(authService.GetRefreshTokenExpiry().Seconds()),
+	})
+}
+
+// clearAuthCookies удаляет cookie с токенами
+func clearAuthCookies(c *gin.Context) {
+	c.SetCookie("access_token", "", -1, "/", "", false, true)
+	c.SetCookie("refresh_token", "", -1, "/", "", false, true)
+}
@@ -0,0 +1,288 @@
+package handlers
+
+import (
+	stdErrors "errors"
+	"gold_portal/internal/domain/dto"
+	"gold_portal/internal/domain/services"
+	"gold_portal/internal/errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type GroupHandler struct {
+	groupService services.GroupService
+}
+
+func NewGroupHandler(groupService services.GroupService) *GroupHandler {
+	return &GroupHandler{
+		groupService: groupService,
+	}
+}
+
+// GetGroups godoc
+// @Summary Группы
+// @Description Возвращает группы текущей организации с ролями, правами и участниками
+// @Tags dashboard
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} dto.GroupResponseDTO
+// @Router /api/v1/dashboard/groups [get]
+func (h *GroupHandler) GetGroups(c *gin.Context) {
+	organizationID, ok := activeOrganization(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	groups, err := h.groupService.GetAll(ctx, organizationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, groups)
+}
+
+// GetGroup godoc
+// @Summary Группа
+// @Description Возвращает группу текущей организации с ролями, правами и участниками
+// @Tags dashboard
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "ID группы"
+// @Success 200 {object} dto.GroupResponseDTO
+// @Router /api/v1/dashboard/groups/{id} [get]
+func (h *GroupHandler) GetGroup(c *gin.Context) {
+	groupID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Некорректный ID группы"})
+		return
+	}
+	organizationID, ok := activeOrganization(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	group, err := h.groupService.Get(ctx, organizationID, groupID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, group)
+}
+
+// CreateGroup godoc
+// @Summary Создание группы
+// @Description Создаёт группу в текущей организации. Участники группы получают её роли и права в дополнение
+// @Description к своей роли. Роли и права группы не могут быть шире, чем у текущего пользователя
+// @Tags dashboard
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param group body dto.GroupRequestDTO true "Группа"
+// @Success 201 {object} dto.GroupResponseDTO
+// @Router /api/v1/dashboard/groups [post]
+func (h *GroupHandler) CreateGroup(c *gin.Context) {
+	actorID, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "User is not authorized"})
+		return
+	}
+	organizationID, ok := activeOrganization(c)
+	if !ok {
+		return
+	}
+
+	var request dto.GroupRequestDTO
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	request.UserAgent = c.GetHeader("User-Agent")
+	request.ClientIP = c.ClientIP()
+
+	ctx := c.Request.Context()
+	group, err := h.groupService.Create(ctx, actorID.(uuid.UUID), organizationID, request)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, group)
+}
+
+// UpdateGroup godoc
+// @Summary Изменение группы
+// @Description Переименовывает группу, меняет описание, роли или права. Изменения прав действуют сразу
+// @Tags dashboard
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "ID группы"
+// @Param group body dto.GroupUpdateDTO true "Изменения"
+// @Success 200 {object} dto.GroupResponseDTO
+// @Router /api/v1/dashboard/groups/{id} [patch]
+func (h *GroupHandler) UpdateGroup(c *gin.Context) {
+	actorID, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "User is not authorized"})
+		return
+	}
+	groupID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Некорректный ID группы"})
+		return
+	}
+	organizationID, ok := activeOrganization(c)
+	if !ok {
+		return
+	}
+
+	var request dto.GroupUpdateDTO
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	request.UserAgent = c.GetHeader("User-Agent")
+	request.ClientIP = c.ClientIP()
+
+	ctx := c.Request.Context()
+	group, err := h.groupService.Update(ctx, actorID.(uuid.UUID), organizationID, groupID, request)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, group)
+}
+
+// DeleteGroup godoc
+// @Summary Удаление группы
+// @Description Удаляет группу; её участники теряют выданные группой роли и права
+// @Tags dashboard
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "ID группы"
+// @Router /api/v1/dashboard/groups/{id} [delete]
+func (h *GroupHandler) DeleteGroup(c *gin.Context) {
+	actorID, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "User is not authorized"})
+		return
+	}
+	groupID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Некорректный ID группы"})
+		return
+	}
+	organizationID, ok := activeOrganization(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := h.groupService.Delete(ctx, actorID.(uuid.UUID), organizationID, groupID, c.ClientIP(), c.GetHeader("User-Agent")); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Группа удалена"})
+}
+
+// AddMember godoc
+// @Summary Добавление в группу
+// @Description Добавляет участника текущей организации в группу
+// @Tags dashboard
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "ID группы"
+// @Param member body dto.GroupMemberRequestDTO true "Пользователь"
+// @Router /api/v1/dashboard/groups/{id}/members [post]
+func (h *GroupHandler) AddMember(c *gin.Context) {
+	actorID, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "User is not authorized"})
+		return
+	}
+	groupID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Некорректный ID группы"})
+		return
+	}
+	organizationID, ok := activeOrganization(c)
+	if !ok {
+		return
+	}
+
+	var request dto.GroupMemberRequestDTO
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	request.UserAgent = c.GetHeader("User-Agent")
+	request.ClientIP = c.ClientIP()
+
+	ctx := c.Request.Context()
+	if err := h.groupService.AddMember(ctx, actorID.(uuid.UUID), organizationID, groupID, request); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Пользователь добавлен в группу"})
+}
+
+// RemoveMember godoc
+// @Summary Исключение из группы
+// @Description Исключает пользователя из группы
+// @Tags dashboard
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "ID группы"
+// @Param user_id path string true "ID пользователя"
+// @Router /api/v1/dashboard/groups/{id}/members/{user_id} [delete]
+func (h *GroupHandler) RemoveMember(c *gin.Context) {
+	actorID, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "User is not authorized"})
+		return
+	}
+	groupID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Некорректный ID группы"})
+		return
+	}
+	userID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Некорректный ID пользователя"})
+		return
+	}
+	organizationID, ok := activeOrganization(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := h.groupService.RemoveMember(ctx, actorID.(uuid.UUID), organizationID, groupID, userID, c.ClientIP(), c.GetHeader("User-Agent")); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Пользователь исключён из группы"})
+}
+
+func (h *GroupHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case stdErrors.Is(err, errors.ErrGroupNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"message": "Группа не найдена"})
+	case stdErrors.Is(err, errors.ErrGroupExists):
+		c.JSON(http.StatusConflict, gin.H{"message": "Группа с таким названием уже есть"})
+	case stdErrors.Is(err, errors.ErrNotOrganizationMember):
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Пользователь не состоит в организации"})
+	case stdErrors.Is(err, errors.ErrInvalidUserRole):
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Неизвестная роль"})
+	case stdErrors.Is(err, errors.ErrUnknownPermission):
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+	case stdErrors.Is(err, errors.ErrRoleRankTooHigh), stdErrors.Is(err, errors.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"message": "Нельзя выдать группе роли или права шире собственных"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+	}
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	stdErrors "errors"
+	"gold_portal/internal/domain/services"
+	"gold_portal/internal/errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type LockoutHandler struct {
+	lockoutService services.LockoutService
+}
+
+func NewLockoutHandler(lockoutService services.LockoutService) *LockoutHandler {
+	return &LockoutHandler{
+		lockoutService: lockoutService,
+	}
+}
+
+// UnlockUser godoc
+// @Summary Снять блокировку входа
+// @Description Сбрасывает неудачные попытки входа и блокировку аккаунта пользователя
+// @Tags dashboard
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Failure 403 {object} map[string]string "Права пользователя шире собственных"
+// @Router /api/v1/dashboard/users/{id}/unlock [post]
+func (h *LockoutHandler) UnlockUser(c *gin.Context) {
+	actorID, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "User is not authorized"})
+		return
+	}
+
+	organizationID, ok := activeOrganization(c)
+	if !ok {
+		return
+	}
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Некорректный ID пользователя"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	err = h.lockoutService.Unlock(ctx, actorID.(uuid.UUID), organizationID, userID, c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		if stdErrors.Is(err, errors.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"message": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Блокировка входа снята"})
+}
+
+// respondLockout отвечает 429 с Retry-After, если вход временно запрещён
+func respondLockout(c *gin.Context, err error) bool {
+	lockoutErr := &errors.LockoutError{}
+	if !stdErrors.As(err, &lockoutErr) {
+		return false
+	}
+
+	retryAfter := int(math.Ceil(lockoutErr.RetryAfter.Seconds()))
+	code := "AUTH_LOGIN_THROTTLED"
+	if lockoutErr.Locked {
+		code = "AUTH_LOGIN_LOCKED"
+	}
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"message":     err.Error(),
+		"code":        code,
+		"scope":       lockoutErr.Scope,
+		"retry_after": retryAfter,
+	})
+	return true
+}
@@ -0,0 +1,429 @@
+package handlers
+
+import (
+	stdErrors "errors"
+	"fmt"
+	"gold_portal/internal/domain/dto"
+	"gold_portal/internal/domain/entities"
+	"gold_portal/internal/domain/services"
+	"gold_portal/internal/errors"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type OAuthHandler struct {
+	oauthService services.OAuthService
+}
+
+func NewOAuthHandler(oauthService services.OAuthService) *OAuthHandler {
+	return &OAuthHandler{
+		oauthService: oauthService,
+	}
+}
+
+// authorizePage данные страницы входа и согласия
+type authorizePage struct {
+	ClientName string
+	Scopes     []string
+	Request    dto.AuthorizeRequestDTO
+	Phone      string
+	Error      string
+	// Токен второго шага: страница запрашивает код 2FA вместо пароля
+	MFAToken string
+}
+
+// Authorize godoc
+// @Summary Запрос авторизации OAuth 2.0
+// @Description Показывает страницу входа и согласия для authorization code flow с PKCE
+// @Tags oauth
+// @Produce html
+// @Param response_type query string true "code"
+// @Param client_id query string true "Идентификатор клиента"
+// @Param redirect_uri query string true "Зарегистрированный redirect_uri"
+// @Param scope query string false "Запрашиваемые scope через пробел"
+// @Param state query string false "Значение state"
+// @Param code_challenge query string true "PKCE code_challenge"
+// @Param code_challenge_method query string true "S256"
+// @Router /oauth/authorize [get]
+func (h *OAuthHandler) Authorize(c *gin.Context) {
+	var request dto.AuthorizeRequestDTO
+	_ = c.ShouldBindQuery(&request)
+
+	ctx := c.Request.Context()
+	client, err := h.oauthService.ValidateAuthorizeRequest(ctx, request)
+	if err != nil {
+		h.authorizeError(c, client, request, err)
+		return
+	}
+
+	h.renderAuthorizePage(c, http.StatusOK, authorizePage{
+		ClientName: client.Name,
+		Scopes:     strings.Fields(request.Scope),
+		Request:    request,
+	})
+}
+
+// AuthorizeSubmit godoc
+// @Summary Подтверждение авторизации OAuth 2.0
+// @Description Проверяет учётные данные и перенаправляет на redirect_uri с кодом авторизации.
+// @Description Если у пользователя включена 2FA, страница запрашивает код и повторно отправляется с mfa_token
+// @Tags oauth
+// @Accept x-www-form-urlencoded
+// @Produce html
+// @Router /oauth/authorize [post]
+func (h *OAuthHandler) AuthorizeSubmit(c *gin.Context) {
+	var request dto.AuthorizeRequestDTO
+	_ = c.ShouldBind(&request)
+
+	ctx := c.Request.Context()
+	client, err := h.oauthService.ValidateAuthorizeRequest(ctx, request)
+	if err != nil {
+		h.authorizeError(c, client, request, err)
+		return
+	}
+
+	if c.PostForm("action") != "approve" {
+		denied := errors.NewOAuthError(errors.OAuthAccessDenied, "the user denied the request")
+		c.Redirect(http.StatusFound, h.oauthService.AuthorizeErrorRedirect(request, denied))
+		return
+	}
+
+	page := authorizePage{
+		ClientName: client.Name,
+		Scopes:     strings.Fields(request.Scope),
+		Request:    request,
+		Phone:      c.PostForm("phone"),
+	}
+
+	var redirectURI string
+	if mfaToken := c.PostForm("mfa_token"); mfaToken != "" {
+		// Второй шаг: пароль уже проверен, ждём код 2FA
+		redirectURI, err = h.oauthService.AuthorizeMFA(ctx, request, dto.MFAVerifyRequestDTO{
+			MFAToken:  mfaToken,
+			Code:      c.PostForm("code"),
+			UserAgent: c.GetHeader("User-Agent"),
+			ClientIP:  c.ClientIP(),
+		})
+		if stdErrors.Is(err, errors.ErrInvalidTwoFactorCode) {
+			page.MFAToken = mfaToken
+			page.Error = "Неверный код подтверждения"
+			h.renderAuthorizePage(c, http.StatusUnauthorized, page)
+			return
+		}
+		if stdErrors.Is(err, errors.ErrMFAChallengeInvalid) {
+			page.Error = "Время на ввод кода истекло, войдите заново"
+			h.renderAuthorizePage(c, http.StatusUnauthorized, page)
+			return
+		}
+	} else {
+		login := dto.LoginRequestDTO{
+			Phone:     c.PostForm("phone"),
+			Password:  c.PostForm("password"),
+			UserAgent: c.GetHeader("User-Agent"),
+			ClientIP:  c.ClientIP(),
+		}
+
+		var mfaToken string
+		redirectURI, mfaToken, err = h.oauthService.Authorize(ctx, request, login)
+		if err == nil && mfaToken != "" {
+			page.MFAToken = mfaToken
+			h.renderAuthorizePage(c, http.StatusOK, page)
+			return
+		}
+	}
+
+	if err != nil {
+		oauthErr := &errors.OAuthError{}
+		if stdErrors.As(err, &oauthErr) {
+			h.authorizeError(c, client, request, err)
+			return
+		}
+		lockoutErr := &errors.LockoutError{}
+		if stdErrors.As(err, &lockoutErr) {
+			seconds := int(math.Ceil(lockoutErr.RetryAfter.Seconds()))
+			page.Error = fmt.Sprintf("Слишком много неудачных попыток, повторите через %d с", seconds)
+			c.Header("Retry-After", strconv.Itoa(seconds))
+			h.renderAuthorizePage(c, http.StatusTooManyRequests, page)
+			return
+		}
+		page.Error = "Неверный телефон или пароль"
+		if stdErrors.Is(err, errors.ErrAccountBlocked) {
+			page.Error = "Аккаунт заблокирован"
+		}
+		h.renderAuthorizePage(c, http.StatusUnauthorized, page)
+		return
+	}
+
+	c.Redirect(http.StatusFound, redirectURI)
+}
+
+// Token godoc
+// @Summary Token endpoint OAuth 2.0
+// @Description Обменивает код авторизации (с code_verifier) или refresh token на токены,
+// @Description выдаёт сервисным клиентам токены по client_credentials.
+// @Description Конфиденциальный клиент передаёт секрет через HTTP Basic или client_secret
+// @Tags oauth
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param grant_type formData string true "authorization_code, refresh_token или client_credentials"
+// @Param code formData string false "Код авторизации"
+// @Param redirect_uri formData string false "redirect_uri из запроса авторизации"
+// @Param client_id formData string false "Идентификатор клиента"
+// @Param client_secret formData string false "Секрет конфиденциального клиента"
+// @Param code_verifier formData string false "PKCE code_verifier"
+// @Param refresh_token formData string false "Refresh token"
+// @Param scope formData string false "Запрашиваемые scope для client_credentials"
+// @Success 200 {object} dto.OAuthTokenResponseDTO
+// @Failure 400 {object} dto.OAuthErrorResponseDTO
+// @Router /oauth/token [post]
+func (h *OAuthHandler) Token(c *gin.Context) {
+	// Ответы token endpoint не должны кэшироваться (RFC 6749, раздел 5.1)
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+
+	var request dto.TokenRequestDTO
+	_ = c.ShouldBind(&request)
+
+	request.ClientID, request.ClientSecret = clientCredentials(c, request.ClientID, request.ClientSecret)
+
+	ctx := c.Request.Context()
+	response, err := h.oauthService.Token(ctx, request)
+	if err != nil {
+		h.tokenError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// Revoke godoc
+// @Summary Отзыв токена
+// @Description Отзывает access или refresh токен, выданный клиенту (RFC 7009).
+// @Description Отзыв refresh токена завершает сессию. Для неизвестного токена также возвращается 200
+// @Tags oauth
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param token formData string true "Отзываемый токен"
+// @Param token_type_hint formData string false "access_token или refresh_token"
+// @Param client_id formData string false "Идентификатор клиента"
+// @Param client_secret formData string false "Секрет конфиденциального клиента"
+// @Success 200
+// @Failure 401 {object} dto.OAuthErrorResponseDTO
+// @Router /oauth/revoke [post]
+func (h *OAuthHandler) Revoke(c *gin.Context) {
+	var request dto.RevokeRequestDTO
+	_ = c.ShouldBind(&request)
+	request.ClientID, request.ClientSecret = clientCredentials(c, request.ClientID, request.ClientSecret)
+
+	ctx := c.Request.Context()
+	if err := h.oauthService.Revoke(ctx, request); err != nil {
+		h.tokenError(c, err)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+// Introspect godoc
+// @Summary Интроспекция токена
+// @Description Сообщает, действителен ли access или refresh токен (RFC 7662).
+// @Description Доступна конфиденциальным клиентам, секрет передаётся через HTTP Basic или client_secret
+// @Tags oauth
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param token formData string true "Проверяемый токен"
+// @Param token_type_hint formData string false "access_token или refresh_token"
+// @Param client_id formData string false "Идентификатор клиента"
+// @Param client_secret formData string false "Секрет клиента"
+// @Success 200 {object} dto.IntrospectResponseDTO
+// @Failure 401 {object} dto.OAuthErrorResponseDTO
+// @Router /oauth/introspect [post]
+func (h *OAuthHandler) Introspect(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+
+	var request dto.IntrospectRequestDTO
+	_ = c.ShouldBind(&request)
+	request.ClientID, request.ClientSecret = clientCredentials(c, request.ClientID, request.ClientSecret)
+
+	ctx := c.Request.Context()
+	response, err := h.oauthService.Introspect(ctx, request)
+	if err != nil {
+		h.tokenError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// UserInfo godoc
+// @Summary UserInfo OpenID Connect
+// @Description Возвращает claims пользователя по access токену, выданному со scope openid
+// @Tags oauth
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} dto.UserInfoDTO
+// @Router /oauth/userinfo [get]
+func (h *OAuthHandler) UserInfo(c *gin.Context) {
+	scope := c.GetString("scope")
+	if !strings.Contains(" "+scope+" ", " openid ") {
+		c.Header("WWW-Authenticate", `Bearer error="insufficient_scope", scope="openid"`)
+		c.JSON(http.StatusForbidden, dto.OAuthErrorResponseDTO{
+			Error:            "insufficient_scope",
+			ErrorDescription: "access token was not issued with the openid scope",
+		})
+		return
+	}
+
+	id, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "User is not authorized"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	userInfo, err := h.oauthService.UserInfo(ctx, id.(uuid.UUID), scope)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, userInfo)
+}
+
+// CreateClient godoc
+// @Summary Регистрация клиента OAuth
+// @Description Регистрирует приложение для входа через /oauth/authorize или сервисного клиента client_credentials.
+// @Description Секрет конфиденциального клиента возвращается только в этом ответе
+// @Tags dashboard
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param client body dto.OAuthClientRequestDTO true "Данные клиента"
+// @Success 201 {object} dto.OAuthClientResponseDTO
+// @Failure 403 {object} map[string]string "Роль клиента выше роли текущего пользователя"
+// @Router /api/v1/dashboard/oauth/clients [post]
+func (h *OAuthHandler) CreateClient(c *gin.Context) {
+	var request dto.OAuthClientRequestDTO
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	roleValue, _ := c.Get("role")
+	actorRole, _ := roleValue.(entities.Role)
+
+	ctx := c.Request.Context()
+	client, err := h.oauthService.RegisterClient(ctx, actorRole, request)
+	if err != nil {
+		oauthErr := &errors.OAuthError{}
+		if stdErrors.As(err, &oauthErr) {
+			status := http.StatusBadRequest
+			if oauthErr.Code == errors.OAuthAccessDenied {
+				status = http.StatusForbidden
+			}
+			c.JSON(status, gin.H{"message": oauthErr.Description})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, client)
+}
+
+// GetClients godoc
+// @Summary Клиенты OAuth
+// @Description Возвращает зарегистрированные приложения
+// @Tags dashboard
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} dto.OAuthClientResponseDTO
+// @Router /api/v1/dashboard/oauth/clients [get]
+func (h *OAuthHandler) GetClients(c *gin.Context) {
+	ctx := c.Request.Context()
+	clients, err := h.oauthService.GetClients(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, clients)
+}
+
+// DeleteClient godoc
+// @Summary Удаление клиента OAuth
+// @Description Удаляет зарегистрированное приложение
+// @Tags dashboard
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "ID клиента"
+// @Router /api/v1/dashboard/oauth/clients/{id} [delete]
+func (h *OAuthHandler) DeleteClient(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Некорректный ID клиента"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := h.oauthService.DeleteClient(ctx, id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Клиент удалён"})
+}
+
+// authorizeError возвращает ошибку авторизации на redirect_uri, а если клиент
+// или redirect_uri не прошли проверку — показывает её пользователю
+func (h *OAuthHandler) authorizeError(c *gin.Context, client *entities.OAuthClient, request dto.AuthorizeRequestDTO, err error) {
+	if client == nil {
+		message := "Некорректный запрос авторизации"
+		oauthErr := &errors.OAuthError{}
+		if stdErrors.As(err, &oauthErr) {
+			message = oauthErr.Error()
+		}
+		c.Status(http.StatusBadRequest)
+		c.Header("Content-Type", "text/html; charset=utf-8")
+		_ = authorizeErrorTemplate.Execute(c.Writer, message)
+		return
+	}
+	c.Redirect(http.StatusFound, h.oauthService.AuthorizeErrorRedirect(request, err))
+}
+
+func (h *OAuthHandler) renderAuthorizePage(c *gin.Context, status int, page authorizePage) {
+	c.Status(status)
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	// Страницу с вводом пароля нельзя встраивать в чужие сайты
+	c.Header("X-Frame-Options", "DENY")
+	_ = authorizePageTemplate.Execute(c.Writer, page)
+}
+
+// clientCredentials возвращает учётные данные клиента из HTTP Basic, если они переданы,
+// иначе — из тела запроса. Значения в заголовке закодированы как form-urlencoded (RFC 6749, раздел 2.3.1)
+func clientCredentials(c *gin.Context, clientID, clientSecret string) (string, string) {
+	basicID, basicSecret, ok := c.Request.BasicAuth()
+	if !ok {
+		return clientID, clientSecret
+	}
+	clientID, _ = url.QueryUnescape(basicID)
+	clientSecret, _ = url.QueryUnescape(basicSecret)
+	return clientID, clientSecret
+}
+
+func (h *OAuthHandler) tokenError(c *gin.Context, err error) {
+	oauthErr := &errors.OAuthError{}
+	if !stdErrors.As(err, &oauthErr) {
+		c.JSON(http.StatusInternalServerError, dto.OAuthErrorResponseDTO{Error: errors.OAuthServerError})
+		return
+	}
+
+	status := http.StatusBadRequest
+	if oauthErr.Code == errors.OAuthInvalidClient {
+		status = http.StatusUnauthorized
+		c.Header("WWW-Authenticate", `Basic realm="oauth"`)
+	}
+	c.JSON(status, dto.OAuthErrorResponseDTO{
+		Error:            oauthErr.Code,
+		ErrorDescription: oauthErr.Description,
+	})
+}
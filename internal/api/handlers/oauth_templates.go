@@ -0,0 +1,55 @@
+package handlers
+
+import "html/template"
+
+// authorizePageTemplate страница входа и согласия для /oauth/authorize
+var authorizePageTemplate = template.Must(template.New("authorize").Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Вход — {{.ClientName}}</title>
+<style>
+body{font-family:sans-serif;background:#f5f5f5;display:flex;justify-content:center;padding-top:10vh}
+form{background:#fff;padding:24px;border-radius:8px;width:320px;box-shadow:0 1px 4px rgba(0,0,0,.1)}
+input[type=text],input[type=password]{width:100%;box-sizing:border-box;margin:6px 0 12px;padding:8px}
+.error{color:#b00020}
+.actions{display:flex;gap:8px}
+button{flex:1;padding:8px}
+</style>
+</head>
+<body>
+<form method="post" action="/oauth/authorize">
+<h3>{{.ClientName}} запрашивает доступ к вашему аккаунту</h3>
+{{if .Scopes}}<p>Разрешения:</p><ul>{{range .Scopes}}<li>{{.}}</li>{{end}}</ul>{{end}}
+{{if .Error}}<p class="error">{{.Error}}</p>{{end}}
+<input type="hidden" name="response_type" value="{{.Request.ResponseType}}">
+<input type="hidden" name="client_id" value="{{.Request.ClientID}}">
+<input type="hidden" name="redirect_uri" value="{{.Request.RedirectURI}}">
+<input type="hidden" name="scope" value="{{.Request.Scope}}">
+<input type="hidden" name="state" value="{{.Request.State}}">
+<input type="hidden" name="code_challenge" value="{{.Request.CodeChallenge}}">
+<input type="hidden" name="code_challenge_method" value="{{.Request.CodeChallengeMethod}}">
+<input type="hidden" name="nonce" value="{{.Request.Nonce}}">
+{{if .MFAToken}}<input type="hidden" name="mfa_token" value="{{.MFAToken}}">
+<label>Код из приложения-аутентификатора или код восстановления<input type="text" name="code" inputmode="numeric" autocomplete="one-time-code" autofocus></label>
+{{else}}<label>Телефон<input type="text" name="phone" value="{{.Phone}}" autocomplete="username"></label>
+<label>Пароль<input type="password" name="password" autocomplete="current-password"></label>
+{{end}}
+<div class="actions">
+<button type="submit" name="action" value="deny">Отклонить</button>
+<button type="submit" name="action" value="approve">Разрешить</button>
+</div>
+</form>
+</body>
+</html>`))
+
+// authorizeErrorTemplate страница ошибки, когда нельзя вернуть пользователя на redirect_uri
+var authorizeErrorTemplate = template.Must(template.New("authorize_error").Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head><meta charset="utf-8"><title>Ошибка авторизации</title></head>
+<body>
+<h3>Ошибка авторизации</h3>
+<p>{{.}}</p>
+</body>
+</html>`))
@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	stdErrors "errors"
+	"gold_portal/internal/domain/dto"
+	"gold_portal/internal/domain/services"
+	"gold_portal/internal/errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type OrganizationHandler struct {
+	organizationService services.OrganizationService
+	authService         services.AuthService
+	roleService         services.RoleService
+}
+
+func NewOrganizationHandler(organizationService services.OrganizationService, authService services.AuthService, roleService services.RoleService) *OrganizationHandler {
+	return &OrganizationHandler{
+		organizationService: organizationService,
+		authService:         authService,
+		roleService:         roleService,
+	}
+}
+
+// GetOrganizations godoc
+// @Summary Организации
+// @Description Возвращает все организации
+// @Tags dashboard
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} dto.OrganizationResponseDTO
+// @Router /api/v1/dashboard/organizations [get]
+func (h *OrganizationHandler) GetOrganizations(c *gin.Context) {
+	ctx := c.Request.Context()
+	organizations, err := h.organizationService.GetAll(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, organizations)
+}
+
+// CreateOrganization godoc
+// @Summary Создание организации
+// @Description Создаёт организацию. Slug: строчные латинские буквы, цифры и -, от 2 до 63 символов
+// @Tags dashboard
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param organization body dto.OrganizationRequestDTO true "Организация"
+// @Success 201 {object} dto.OrganizationResponseDTO
+// @Router /api/v1/dashboard/organizations [post]
+func (h *OrganizationHandler) CreateOrganization(c *gin.Context) {
+	actorID, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "User is not authorized"})
+		return
+	}
+
+	var request dto.OrganizationRequestDTO
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	request.UserAgent = c.GetHeader("User-Agent")
+	request.ClientIP = c.ClientIP()
+
+	ctx := c.Request.Context()
+	organization, err := h.organizationService.Create(ctx, actorID.(uuid.UUID), request)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, organization)
+}
+
+// SaveMember godoc
+// @Summary Участник организации
+// @Description Добавляет пользователя в организацию с ролью или меняет его роль в ней.
+// @Description Смена роли выводит пользователя со всех устройств
+// @Tags dashboard
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "ID организации"
+// @Param member body dto.OrganizationMemberRequestDTO true "Пользователь и роль"
+// @Router /api/v1/dashboard/organizations/{id}/members [post]
+func (h *OrganizationHandler) SaveMember(c *gin.Context) {
+	actorID, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "User is not authorized"})
+		return
+	}
+
+	organizationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Некорректный ID организации"})
+		return
+	}
+
+	var request dto.OrganizationMemberRequestDTO
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	if !respondRoleAssignment(c, h.roleService, request.Role) {
+		return
+	}
+	request.UserAgent = c.GetHeader("User-Agent")
+	request.ClientIP = c.ClientIP()
+
+	ctx := c.Request.Context()
+	if err := h.organizationService.SaveMember(ctx, actorID.(uuid.UUID), organizationID, request); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Участник организации сохранён"})
+}
+
+// RemoveMember godoc
+// @Summary Исключение из организации
+// @Description Исключает пользователя из организации и выводит его со всех устройств
+// @Tags dashboard
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "ID организации"
+// @Param user_id path string true "ID пользователя"
+// @Router /api/v1/dashboard/organizations/{id}/members/{user_id} [delete]
+func (h *OrganizationHandler) RemoveMember(c *gin.Context) {
+	actorID, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "User is not authorized"})
+		return
+	}
+
+	organizationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Некорректный ID организации"})
+		return
+	}
+	userID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Некорректный ID пользователя"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := h.organizationService.RemoveMember(ctx, actorID.(uuid.UUID), organizationID, userID, c.ClientIP(), c.GetHeader("User-Agent")); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Пользователь исключён из организации"})
+}
+
+// GetMyOrganizations godoc
+// @Summary Мои организации
+// @Description Организации текущего пользователя с ролью в каждой; active — организация текущей сессии
+// @Tags auth
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} dto.OrganizationMembershipDTO
+// @Router /api/v1/auth/me/organizations [get]
+func (h *OrganizationHandler) GetMyOrganizations(c *gin.Context) {
+	id, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "User is not authorized"})
+		return
+	}
+	organizationID, ok := activeOrganization(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	memberships, err := h.organizationService.GetMemberships(ctx, id.(uuid.UUID), organizationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, memberships)
+}
+
+// SwitchOrganization godoc
+// @Summary Смена организации
+// @Description Делает организацию активной в текущей сессии и выдаёт новую пару токенов с ролью в ней.
+// @Description Текущий access токен попадает в черный список
+// @Tags auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.OrganizationSwitchRequestDTO true "ID организации"
+// @Router /api/v1/auth/organizations/switch [post]
+func (h *OrganizationHandler) SwitchOrganization(c *gin.Context) {
+	id, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "User is not authorized"})
+		return
+	}
+	sessionID, err := uuid.Parse(c.GetString("session_id"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "Сессия не найдена"})
+		return
+	}
+
+	var request dto.OrganizationSwitchRequestDTO
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	tokenResponse, err := h.authService.SwitchOrganization(ctx, id.(uuid.UUID), sessionID, request.OrganizationID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	// Прежний access токен несёт роль в другой организации
+	if accessToken := c.GetString("token"); accessToken != "" {
+		_ = h.authService.RevokeToken(ctx, accessToken)
+	}
+
+	setAuthCookies(c, h.authService, tokenResponse.AccessToken, tokenResponse.RefreshToken)
+
+	c.JSON(http.StatusOK, gin.H{
+		"access_token":  tokenResponse.AccessToken,
+		"refresh_token": tokenResponse.RefreshToken,
+		"message":       tokenResponse.Message,
+	})
+}
+
+func (h *OrganizationHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case stdErrors.Is(err, errors.ErrOrganizationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"message": "Организация не найдена"})
+	case stdErrors.Is(err, errors.ErrOrganizationExists):
+		c.JSON(http.StatusConflict, gin.H{"message": "Организация с таким slug уже есть"})
+	case stdErrors.Is(err, errors.ErrInvalidOrganizationSlug):
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Slug: строчные латинские буквы, цифры и -, от 2 до 63 символов"})
+	case stdErrors.Is(err, errors.ErrNotOrganizationMember):
+		c.JSON(http.StatusForbidden, gin.H{"message": "Пользователь не состоит в организации"})
+	case stdErrors.Is(err, errors.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"message": "Пользователь не найден"})
+	case stdErrors.Is(err, errors.ErrSessionNotFound):
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "Сессия не найдена"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+	}
+}
+
+// activeOrganization возвращает организацию, к которой привязан запрос. Сервисные клиенты
+// не состоят в организациях: для них пишет 403 и возвращает false
+func activeOrganization(c *gin.Context) (uuid.UUID, bool) {
+	value, exists := c.Get("organization_id")
+	organizationID, _ := value.(uuid.UUID)
+	if !exists || organizationID == uuid.Nil {
+		c.JSON(http.StatusForbidden, gin.H{"message": "Запрос не привязан к организации"})
+		return uuid.Nil, false
+	}
+	return organizationID, true
+}
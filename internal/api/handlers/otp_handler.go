@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	stdErrors "errors"
+	"gold_portal/internal/domain/dto"
+	"gold_portal/internal/domain/services"
+	"gold_portal/internal/errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type OTPHandler struct {
+	authService services.AuthService
+}
+
+func NewOTPHandler(authService services.AuthService) *OTPHandler {
+	return &OTPHandler{
+		authService: authService,
+	}
+}
+
+// SendLoginCode godoc
+// @Summary Код входа по SMS
+// @Description Отправляет одноразовый код входа на номер. Ответ не зависит от того, зарегистрирован ли номер
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.OTPSendRequestDTO true "Номер телефона"
+// @Router /api/v1/auth/otp/send [post]
+func (h *OTPHandler) SendLoginCode(c *gin.Context) {
+	var request dto.OTPSendRequestDTO
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := h.authService.SendLoginOTP(ctx, request.Phone); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Если номер зарегистрирован, на него отправлен код"})
+}
+
+// Login godoc
+// @Summary Вход по коду из SMS
+// @Description Проверяет код из SMS и выдаёт токены. Если у пользователя включена 2FA, вместо токена возвращается mfa_token
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.OTPLoginRequestDTO true "Номер телефона и код"
+// @Router /api/v1/auth/otp/login [post]
+func (h *OTPHandler) Login(c *gin.Context) {
+	var request dto.OTPLoginRequestDTO
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	request.UserAgent = c.GetHeader("User-Agent")
+	request.ClientIP = c.ClientIP()
+
+	ctx := c.Request.Context()
+	tokenResponse, err := h.authService.LoginOTP(ctx, request)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	if tokenResponse.MFARequired {
+		c.JSON(http.StatusOK, gin.H{
+			"mfa_required": true,
+			"mfa_token":    tokenResponse.MFAToken,
+			"message":      tokenResponse.Message,
+		})
+		return
+	}
+
+	setAuthCookies(c, h.authService, tokenResponse.AccessToken, tokenResponse.RefreshToken)
+
+	c.JSON(http.StatusOK, gin.H{
+		"access_token": tokenResponse.AccessToken,
+		"message":      "Success authorization",
+	})
+}
+
+// SendPhoneVerificationCode godoc
+// @Summary Код подтверждения номера
+// @Description Отправляет код подтверждения на номер текущего пользователя
+// @Tags auth
+// @Security BearerAuth
+// @Produce json
+// @Router /api/v1/auth/phone/send-code [post]
+func (h *OTPHandler) SendPhoneVerificationCode(c *gin.Context) {
+	id, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "User is not authorized"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := h.authService.SendPhoneVerificationCode(ctx, id.(uuid.UUID)); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Код отправлен"})
+}
+
+// VerifyPhone godoc
+// @Summary Подтверждение номера
+// @Description Проверяет код из SMS и отмечает номер текущего пользователя подтверждённым
+// @Tags auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.PhoneVerifyRequestDTO true "Код из SMS"
+// @Router /api/v1/auth/phone/verify [post]
+func (h *OTPHandler) VerifyPhone(c *gin.Context) {
+	id, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "User is not authorized"})
+		return
+	}
+
+	var request dto.PhoneVerifyRequestDTO
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := h.authService.VerifyPhone(ctx, id.(uuid.UUID), request.Code); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Номер подтверждён"})
+}
+
+// ForgotPassword godoc
+// @Summary Забыли пароль
+// @Description Отправляет код сброса пароля на номер. Ответ не зависит от того, зарегистрирован ли номер
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.PasswordForgotRequestDTO true "Номер телефона"
+// @Router /api/v1/auth/password/forgot [post]
+func (h *OTPHandler) ForgotPassword(c *gin.Context) {
+	var request dto.PasswordForgotRequestDTO
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := h.authService.ForgotPassword(ctx, request.Phone); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Если номер зарегистрирован, на него отправлен код"})
+}
+
+// ResetPassword godoc
+// @Summary Сброс пароля
+// @Description Устанавливает новый пароль по коду из SMS. Все сессии пользователя завершаются
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.PasswordResetRequestDTO true "Номер, код и новый пароль"
+// @Router /api/v1/auth/password/reset [post]
+func (h *OTPHandler) ResetPassword(c *gin.Context) {
+	var request dto.PasswordResetRequestDTO
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := h.authService.ResetPassword(ctx, request); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	// Текущие cookie тоже больше недействительны
+	clearAuthCookies(c)
+	c.JSON(http.StatusOK, gin.H{"message": "Пароль изменён"})
+}
+
+func (h *OTPHandler) handleError(c *gin.Context, err error) {
+	if respondPasswordPolicy(c, err) {
+		return
+	}
+	switch {
+	case stdErrors.Is(err, errors.ErrOTPTooManyRequests):
+		c.JSON(http.StatusTooManyRequests, gin.H{"message": err.Error(), "code": "OTP_TOO_MANY_REQUESTS"})
+	case stdErrors.Is(err, errors.ErrInvalidOTP),
+		stdErrors.Is(err, errors.ErrInvalidCredentials),
+		stdErrors.Is(err, errors.ErrAccountBlocked):
+		c.JSON(http.StatusUnauthorized, gin.H{"message": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+	}
+}
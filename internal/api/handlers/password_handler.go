@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	stdErrors "errors"
+	"gold_portal/internal/domain/dto"
+	"gold_portal/internal/domain/services"
+	"gold_portal/internal/errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type PasswordHandler struct {
+	passwordService services.PasswordService
+}
+
+func NewPasswordHandler(passwordService services.PasswordService) *PasswordHandler {
+	return &PasswordHandler{
+		passwordService: passwordService,
+	}
+}
+
+// ChangeMyPassword godoc
+// @Summary Смена пароля
+// @Description Меняет пароль текущего пользователя после проверки старого. Все сессии, кроме текущей, завершаются
+// @Tags auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.ChangePasswordDTO true "Старый и новый пароль"
+// @Router /api/v1/auth/me/password [post]
+func (h *PasswordHandler) ChangeMyPassword(c *gin.Context) {
+	id, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "User is not authorized"})
+		return
+	}
+	sessionID, err := uuid.Parse(c.GetString("session_id"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "Сессия не найдена"})
+		return
+	}
+
+	var request dto.ChangePasswordDTO
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	request.UserAgent = c.GetHeader("User-Agent")
+	request.ClientIP = c.ClientIP()
+
+	ctx := c.Request.Context()
+	if err := h.passwordService.ChangePassword(ctx, id.(uuid.UUID), sessionID, request); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Пароль изменён, остальные сессии завершены"})
+}
+
+// SetUserPassword godoc
+// @Summary Смена пароля пользователя
+// @Description Устанавливает новый пароль пользователю. Все сессии пользователя завершаются
+// @Tags dashboard
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Param request body dto.ChangePasswordDashboardDTO true "Новый пароль"
+// @Router /api/v1/dashboard/users/{id}/password [post]
+func (h *PasswordHandler) SetUserPassword(c *gin.Context) {
+	actorID, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "User is not authorized"})
+		return
+	}
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Некорректный ID пользователя"})
+		return
+	}
+	organizationID, ok := activeOrganization(c)
+	if !ok {
+		return
+	}
+
+	var request dto.ChangePasswordDashboardDTO
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	request.UserAgent = c.GetHeader("User-Agent")
+	request.ClientIP = c.ClientIP()
+
+	ctx := c.Request.Context()
+	if err := h.passwordService.SetPassword(ctx, actorID.(uuid.UUID), organizationID, userID, request); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Пароль изменён"})
+}
+
+func (h *PasswordHandler) handleError(c *gin.Context, err error) {
+	if respondPasswordPolicy(c, err) {
+		return
+	}
+	switch {
+	case stdErrors.Is(err, errors.ErrInvalidPassword):
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+	case stdErrors.Is(err, errors.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"message": err.Error()})
+	case stdErrors.Is(err, errors.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"message": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+	}
+}
+
+// respondPasswordPolicy отвечает 400 со списком нарушений, если пароль не прошёл политику
+func respondPasswordPolicy(c *gin.Context, err error) bool {
+	policyErr := &errors.PasswordPolicyError{}
+	if !stdErrors.As(err, &policyErr) {
+		return false
+	}
+	c.JSON(http.StatusBadRequest, gin.H{
+		"message":    "Пароль не соответствует требованиям",
+		"code":       "PASSWORD_POLICY_VIOLATION",
+		"violations": policyErr.Violations,
+	})
+	return true
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"gold_portal/internal/domain/entities"
+	"gold_portal/internal/domain/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type PermissionHandler struct {
+	permissionService services.PermissionService
+}
+
+func NewPermissionHandler(permissionService services.PermissionService) *PermissionHandler {
+	return &PermissionHandler{
+		permissionService: permissionService,
+	}
+}
+
+// GetMyPermissions godoc
+// @Summary Права текущего пользователя
+// @Description Возвращает роль текущего пользователя и его действующие права с учётом групп, например чтобы
+// @Description показать доступные разделы панели
+// @Tags auth
+// @Security BearerAuth
+// @Produce json
+// @Router /api/v1/auth/me/permissions [get]
+func (h *PermissionHandler) GetMyPermissions(c *gin.Context) {
+	roleValue, exists := c.Get("role")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "User is not authorized"})
+		return
+	}
+	role, _ := roleValue.(entities.Role)
+
+	ctx := c.Request.Context()
+	var permissions []string
+	var err error
+	if _, ok := c.Get("service_client"); ok {
+		permissions, err = h.permissionService.GetRolePermissions(ctx, role)
+	} else {
+		organizationID, ok := activeOrganization(c)
+		if !ok {
+			return
+		}
+		id, _ := c.Get("id")
+		permissions, err = h.permissionService.GetUserPermissions(ctx, role, organizationID, id.(uuid.UUID))
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"role":        role,
+		"permissions": permissions,
+	})
+}
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	stdErrors "errors"
+	"gold_portal/internal/domain/dto"
+	"gold_portal/internal/domain/entities"
+	"gold_portal/internal/domain/services"
+	"gold_portal/internal/errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type RoleHandler struct {
+	roleService services.RoleService
+}
+
+func NewRoleHandler(roleService services.RoleService) *RoleHandler {
+	return &RoleHandler{
+		roleService: roleService,
+	}
+}
+
+// GetRoles godoc
+// @Summary Роли
+// @Description Возвращает роли с их правами и рангом, начиная со старших
+// @Tags dashboard
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} dto.RoleResponseDTO
+// @Router /api/v1/dashboard/roles [get]
+func (h *RoleHandler) GetRoles(c *gin.Context) {
+	ctx := c.Request.Context()
+	roles, err := h.roleService.GetAll(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, roles)
+}
+
+// GetPermissions godoc
+// @Summary Права
+// @Description Возвращает все права, которые можно выдать роли
+// @Tags dashboard
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} dto.PermissionResponseDTO
+// @Router /api/v1/dashboard/permissions [get]
+func (h *RoleHandler) GetPermissions(c *gin.Context) {
+	ctx := c.Request.Context()
+	permissions, err := h.roleService.GetPermissions(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, permissions)
+}
+
+// CreateRole godoc
+// @Summary Создание роли
+// @Description Создаёт роль с набором прав и рангом. Права и ранг не могут быть шире, чем у текущего пользователя
+// @Tags dashboard
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param role body dto.RoleRequestDTO true "Роль"
+// @Success 201 {object} dto.RoleResponseDTO
+// @Router /api/v1/dashboard/roles [post]
+func (h *RoleHandler) CreateRole(c *gin.Context) {
+	actorID, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "User is not authorized"})
+		return
+	}
+
+	var request dto.RoleRequestDTO
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	request.UserAgent = c.GetHeader("User-Agent")
+	request.ClientIP = c.ClientIP()
+
+	ctx := c.Request.Context()
+	role, err := h.roleService.Create(ctx, actorID.(uuid.UUID), request)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, role)
+}
+
+// UpdateRole godoc
+// @Summary Изменение роли
+// @Description Переименовывает роль, меняет описание, ранг или набор прав. Пользователи переименованной роли
+// @Description сохраняют её. Встроенные роли переименовать нельзя
+// @Tags dashboard
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param name path string true "Название роли"
+// @Param role body dto.RoleUpdateDTO true "Изменения"
+// @Success 200 {object} dto.RoleResponseDTO
+// @Router /api/v1/dashboard/roles/{name} [patch]
+func (h *RoleHandler) UpdateRole(c *gin.Context) {
+	actorID, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "User is not authorized"})
+		return
+	}
+
+	var request dto.RoleUpdateDTO
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	request.UserAgent = c.GetHeader("User-Agent")
+	request.ClientIP = c.ClientIP()
+
+	ctx := c.Request.Context()
+	role, err := h.roleService.Update(ctx, actorID.(uuid.UUID), entities.Role(c.Param("name")), request)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, role)
+}
+
+// DeleteRole godoc
+// @Summary Удаление роли
+// @Description Удаляет роль, которая не назначена ни пользователям, ни группам, ни клиентам OAuth. Встроенные роли удалить нельзя
+// @Tags dashboard
+// @Security BearerAuth
+// @Produce json
+// @Param name path string true "Название роли"
+// @Router /api/v1/dashboard/roles/{name} [delete]
+func (h *RoleHandler) DeleteRole(c *gin.Context) {
+	actorID, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "User is not authorized"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := h.roleService.Delete(ctx, actorID.(uuid.UUID), entities.Role(c.Param("name")), c.ClientIP(), c.GetHeader("User-Agent")); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Роль удалена"})
+}
+
+func (h *RoleHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case stdErrors.Is(err, errors.ErrRoleNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"message": "Роль не найдена"})
+	case stdErrors.Is(err, errors.ErrRoleExists):
+		c.JSON(http.StatusConflict, gin.H{"message": "Роль с таким названием уже есть"})
+	case stdErrors.Is(err, errors.ErrRoleInUse):
+		c.JSON(http.StatusConflict, gin.H{"message": "Роль назначена пользователям, группам или клиентам OAuth"})
+	case stdErrors.Is(err, errors.ErrRoleBuiltIn):
+		c.JSON(http.StatusConflict, gin.H{"message": "Встроенную роль нельзя переименовать или удалить"})
+	case stdErrors.Is(err, errors.ErrInvalidRoleName):
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Название роли: строчные латинские буквы, цифры, _ и -, от 2 до 50 символов"})
+	case stdErrors.Is(err, errors.ErrUnknownPermission):
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+	case stdErrors.Is(err, errors.ErrSuperUserLockedOut):
+		c.JSON(http.StatusBadRequest, gin.H{"message": "У роли superuser должно остаться право roles:manage"})
+	case stdErrors.Is(err, errors.ErrRoleRankTooHigh), stdErrors.Is(err, errors.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"message": "Нельзя выдать роли права или ранг шире собственных"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+	}
+}
+
+// respondRoleAssignment проверяет роль из формы по ролям в базе и рангу текущего пользователя.
+// Возвращает false и пишет ответ, если назначить роль нельзя
+func respondRoleAssignment(c *gin.Context, roleService services.RoleService, role entities.Role) bool {
+	actorRole, _ := c.Get("role")
+	actor, _ := actorRole.(entities.Role)
+
+	err := roleService.CheckAssignable(c.Request.Context(), actor, role)
+	switch {
+	case err == nil:
+		return true
+	case stdErrors.Is(err, errors.ErrInvalidUserRole):
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Неизвестная роль: " + string(role)})
+	case stdErrors.Is(err, errors.ErrRoleRankTooHigh):
+		c.JSON(http.StatusForbidden, gin.H{"message": "Нельзя назначить роль с рангом выше собственного"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+	}
+	return false
+}
@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	stdErrors "errors"
+	"gold_portal/internal/domain/services"
+	"gold_portal/internal/errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type SessionHandler struct {
+	sessionService services.SessionService
+}
+
+func NewSessionHandler(sessionService services.SessionService) *SessionHandler {
+	return &SessionHandler{
+		sessionService: sessionService,
+	}
+}
+
+// GetMySessions godoc
+// @Summary Активные сессии
+// @Description Возвращает список устройств, на которых выполнен вход текущим пользователем
+// @Tags auth
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} dto.SessionResponseDTO
+// @Router /api/v1/auth/sessions [get]
+func (h *SessionHandler) GetMySessions(c *gin.Context) {
+	id, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "User is not authorized"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	sessions, err := h.sessionService.GetByUser(ctx, id.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	currentSessionID := c.GetString("session_id")
+	for _, session := range sessions {
+		session.Current = session.ID.String() == currentSessionID
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+// RevokeMySession godoc
+// @Summary Завершить сессию
+// @Description Завершает одну из сессий текущего пользователя
+// @Tags auth
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "ID сессии"
+// @Router /api/v1/auth/sessions/{id} [delete]
+func (h *SessionHandler) RevokeMySession(c *gin.Context) {
+	id, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "User is not authorized"})
+		return
+	}
+
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Некорректный ID сессии"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := h.sessionService.Revoke(ctx, id.(uuid.UUID), sessionID); err != nil {
+		h.handleRevokeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Сессия завершена"})
+}
+
+// LogoutEverywhere godoc
+// @Summary Выйти со всех устройств
+// @Description Завершает все сессии текущего пользователя и отзывает все выданные ему токены, включая текущий, и API-ключи
+// @Tags auth
+// @Security BearerAuth
+// @Produce json
+// @Router /api/v1/auth/logout-all [post]
+func (h *SessionHandler) LogoutEverywhere(c *gin.Context) {
+	id, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "User is not authorized"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := h.sessionService.LogoutEverywhere(ctx, id.(uuid.UUID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	clearAuthCookies(c)
+	c.JSON(http.StatusOK, gin.H{"message": "Выполнен выход со всех устройств"})
+}
+
+// GetUserSessions godoc
+// @Summary Сессии пользователя
+// @Description Возвращает активные сессии указанного пользователя
+// @Tags dashboard
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Success 200 {array} dto.SessionResponseDTO
+// @Failure 403 {object} map[string]string "Права пользователя шире собственных"
+// @Router /api/v1/dashboard/users/{id}/sessions [get]
+func (h *SessionHandler) GetUserSessions(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Некорректный ID пользователя"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	sessions, err := h.sessionService.GetByUser(ctx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, sessions)
+}
+
+// RevokeUserSession godoc
+// @Summary Завершить сессию пользователя
+// @Description Завершает сессию указанного пользователя
+// @Tags dashboard
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Param session_id path string true "ID сессии"
+// @Failure 403 {object} map[string]string "Права пользователя шире собственных"
+// @Router /api/v1/dashboard/users/{id}/sessions/{session_id} [delete]
+func (h *SessionHandler) RevokeUserSession(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Некорректный ID пользователя"})
+		return
+	}
+
+	sessionID, err := uuid.Parse(c.Param("session_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Некорректный ID сессии"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := h.sessionService.Revoke(ctx, userID, sessionID); err != nil {
+		h.handleRevokeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Сессия завершена"})
+}
+
+// LogoutUserEverywhere godoc
+// @Summary Вывести пользователя со всех устройств
+// @Description Завершает все сессии указанного пользователя и отзывает все выданные ему токены и API-ключи
+// @Tags dashboard
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Failure 403 {object} map[string]string "Права пользователя шире собственных"
+// @Router /api/v1/dashboard/users/{id}/logout-all [post]
+func (h *SessionHandler) LogoutUserEverywhere(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Некорректный ID пользователя"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := h.sessionService.LogoutEverywhere(ctx, userID); err != nil {
+		if stdErrors.Is(err, errors.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"message": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Пользователь выведен со всех устройств"})
+}
+
+func (h *SessionHandler) handleRevokeError(c *gin.Context, err error) {
+	if stdErrors.Is(err, errors.ErrSessionNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Сессия не найдена"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	stdErrors "errors"
+	"gold_portal/internal/domain/dto"
+	"gold_portal/internal/domain/services"
+	"gold_portal/internal/errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type TwoFactorHandler struct {
+	twoFactorService services.TwoFactorService
+}
+
+func NewTwoFactorHandler(twoFactorService services.TwoFactorService) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		twoFactorService: twoFactorService,
+	}
+}
+
+// Enroll godoc
+// @Summary Подключение 2FA
+// @Description Создаёт секрет TOTP и возвращает otpauth URI и QR код для приложения-аутентификатора.
+// @Description 2FA включается только после подтверждения кода
+// @Tags 2fa
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} dto.TwoFactorEnrollResponseDTO
+// @Router /api/v1/auth/2fa/enroll [post]
+func (h *TwoFactorHandler) Enroll(c *gin.Context) {
+	id, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "User is not authorized"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	response, err := h.twoFactorService.Enroll(ctx, id.(uuid.UUID))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// Confirm godoc
+// @Summary Подтверждение 2FA
+// @Description Проверяет первый код из приложения, включает 2FA и возвращает коды восстановления
+// @Tags 2fa
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.TwoFactorCodeRequestDTO true "Код из приложения-аутентификатора"
+// @Success 200 {object} dto.RecoveryCodesResponseDTO
+// @Router /api/v1/auth/2fa/confirm [post]
+func (h *TwoFactorHandler) Confirm(c *gin.Context) {
+	id, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "User is not authorized"})
+		return
+	}
+
+	var request dto.TwoFactorCodeRequestDTO
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	response, err := h.twoFactorService.Confirm(ctx, id.(uuid.UUID), request.Code)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// Disable godoc
+// @Summary Отключение 2FA
+// @Description Отключает 2FA по коду из приложения или коду восстановления. Для ролей с обязательной 2FA недоступно
+// @Tags 2fa
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.TwoFactorCodeRequestDTO true "Код из приложения или код восстановления"
+// @Router /api/v1/auth/2fa/disable [post]
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	id, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "User is not authorized"})
+		return
+	}
+
+	var request dto.TwoFactorCodeRequestDTO
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := h.twoFactorService.Disable(ctx, id.(uuid.UUID), request.Code); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Двухфакторная аутентификация отключена"})
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary Новые коды восстановления
+// @Description Выпускает новые коды восстановления; старые коды перестают действовать
+// @Tags 2fa
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.TwoFactorCodeRequestDTO true "Код из приложения или код восстановления"
+// @Success 200 {object} dto.RecoveryCodesResponseDTO
+// @Router /api/v1/auth/2fa/recovery-codes [post]
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	id, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "User is not authorized"})
+		return
+	}
+
+	var request dto.TwoFactorCodeRequestDTO
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	response, err := h.twoFactorService.RegenerateRecoveryCodes(ctx, id.(uuid.UUID), request.Code)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *TwoFactorHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case stdErrors.Is(err, errors.ErrInvalidTwoFactorCode):
+		c.JSON(http.StatusUnauthorized, gin.H{"message": err.Error(), "code": "AUTH_2FA_INVALID_CODE"})
+	case stdErrors.Is(err, errors.ErrTwoFactorRequired):
+		c.JSON(http.StatusForbidden, gin.H{"message": err.Error(), "code": "AUTH_2FA_REQUIRED"})
+	case stdErrors.Is(err, errors.ErrTwoFactorAlreadyEnabled),
+		stdErrors.Is(err, errors.ErrTwoFactorNotEnabled),
+		stdErrors.Is(err, errors.ErrTwoFactorNotEnrolled):
+		c.JSON(http.StatusConflict, gin.H{"message": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+	}
+}
@@ -1,9 +1,11 @@
 package handlers
 
 import (
+	stdErrors "errors"
 	"gold_portal/internal/domain/dto"
 	"gold_portal/internal/domain/entities"
 	"gold_portal/internal/domain/services"
+	"gold_portal/internal/errors"
 	"mime/multipart"
 	"net/http"
 
@@ -13,17 +15,19 @@ import (
 
 type UserHandler struct {
 	userService services.UsersService
+	roleService services.RoleService
 }
 
-func NewUserHandler(userService services.UsersService) *UserHandler {
+func NewUserHandler(userService services.UsersService, roleService services.RoleService) *UserHandler {
 	return &UserHandler{
 		userService: userService,
+		roleService: roleService,
 	}
 }
 
 // GetAll godoc
 // @Summary Получение всех пользователей
-// @Description Возвращает список всех зарегистрированных пользователей (только для авторизованных пользователей)
+// @Description Возвращает список пользователей текущей организации с ролью в ней (только для авторизованных пользователей)
 // @Tags dashboard
 // @Security BearerAuth
 // @Produce json
@@ -32,8 +36,12 @@ func NewUserHandler(userService services.UsersService) *UserHandler {
 // @Failure 500 {object} map[string]string "Внутренняя ошибка сервера"
 // @Router /api/v1/dashboard [get]
 func (h *UserHandler) GetAll(c *gin.Context) {
+	organizationID, ok := activeOrganization(c)
+	if !ok {
+		return
+	}
 	ctx := c.Request.Context()
-	users, err := h.userService.GetAll(ctx)
+	users, err := h.userService.GetAll(ctx, organizationID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
 		return
@@ -56,8 +64,12 @@ func (h *UserHandler) GetByID(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"message": "Некорректный ID пользователя"})
 		return
 	}
+	organizationID, ok := activeOrganization(c)
+	if !ok {
+		return
+	}
 	ctx := c.Request.Context()
-	user, err := h.userService.UserID(ctx, userID)
+	user, err := h.userService.UserID(ctx, organizationID, userID)
 	if err != nil {
 		if err.Error() == "record not found" {
 			c.JSON(http.StatusNotFound, gin.H{"message": "Пользователь не найден"})
@@ -79,8 +91,12 @@ func (h *UserHandler) GetByID(c *gin.Context) {
 // @Router  /api/v1/dashboard/phone/{phone} [get]
 func (h *UserHandler) GetByPhone(c *gin.Context) {
 	phone := c.Param("phone")
+	organizationID, ok := activeOrganization(c)
+	if !ok {
+		return
+	}
 	ctx := c.Request.Context()
-	user, err := h.userService.GetByPhone(ctx, phone)
+	user, err := h.userService.GetByPhone(ctx, organizationID, phone)
 	if err != nil {
 		if err.Error() == "record not found" {
 			c.JSON(http.StatusNotFound, gin.H{"message": "Пользователь не найден"})
@@ -95,6 +111,7 @@ func (h *UserHandler) GetByPhone(c *gin.Context) {
 // Patch godoc
 // @Summary Обновление пользователя
 // @Description Обновляет информацию о пользователе с возможностью загрузки фото. Можно передавать только те поля, которые нужно изменить (PATCH).
+// @Description Смена роли или блокировка выводят пользователя со всех устройств: в ответе sessions_revoked = true
 // @Tags dashboard
 // @Security BearerAuth
 // @Accept multipart/form-data
@@ -107,14 +124,25 @@ func (h *UserHandler) GetByPhone(c *gin.Context) {
 // @Param role formData string false "Роль"
 // @Param is_active formData bool false "Активен"
 // @Param photo formData file false "Фото профиля"
+// @Success 200 {object} dto.UserPatchResponseDTO
 // @Router /api/v1/dashboard/patch/{id} [patch]
 func (h *UserHandler) Patch(c *gin.Context) {
+	actorID, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "User is not authorized"})
+		return
+	}
+
 	userParam := c.Param("id")
 	userID, err := uuid.Parse(userParam)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"message": "Некорректный ID пользователя"})
 		return
 	}
+	organizationID, ok := activeOrganization(c)
+	if !ok {
+		return
+	}
 
 	// Получаем данные формы
 	var request dto.UserUpdateDTO
@@ -133,6 +161,9 @@ func (h *UserHandler) Patch(c *gin.Context) {
 	}
 	if role := c.PostForm("role"); role != "" {
 		userRole := entities.Role(role)
+		if !respondRoleAssignment(c, h.roleService, userRole) {
+			return
+		}
 		request.Role = &userRole
 	}
 	if isActiveStr := c.PostForm("is_active"); isActiveStr != "" {
@@ -147,10 +178,15 @@ func (h *UserHandler) Patch(c *gin.Context) {
 	}
 
 	ctx := c.Request.Context()
-	user, err := h.userService.Patch(ctx, userID, request, photoFile)
+	request.UserAgent = c.GetHeader("User-Agent")
+	request.ClientIP = c.ClientIP()
+
+	user, err := h.userService.Patch(ctx, actorID.(uuid.UUID), organizationID, userID, request, photoFile)
 	if err != nil {
-		if err.Error() == "record not found" {
+		if stdErrors.Is(err, errors.ErrUserNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"message": "Пользователь не найден"})
+		} else if stdErrors.Is(err, errors.ErrForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"message": "Нельзя изменить пользователя с правами шире собственных"})
 		} else {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		}
@@ -161,7 +197,8 @@ func (h *UserHandler) Patch(c *gin.Context) {
 
 // Delete godoc
 // @Summary Удаление пользователя
-// @Description Удаляет пользователя по указанному ID
+// @Description Исключает пользователя из текущей организации. Учётная запись удаляется, если других организаций у него нет
+// @Description Учётную запись superuser удалить нельзя
 // @Tags dashboard
 // @Accept json
 // @Produce json
@@ -170,15 +207,37 @@ func (h *UserHandler) Patch(c *gin.Context) {
 // @Success 204 "Пользователь успешно удален"
 // @Router /api/v1/dashboard/delete/{id} [delete]
 func (h *UserHandler) Delete(c *gin.Context) {
+	actorID, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "User is not authorized"})
+		return
+	}
+
 	userParam := c.Param("id")
 	userID, err := uuid.Parse(userParam)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"message": "Некорректный ID пользователя"})
 		return
 	}
+	organizationID, ok := activeOrganization(c)
+	if !ok {
+		return
+	}
 	ctx := c.Request.Context()
-	err = h.userService.Delete(ctx, userID)
+	err = h.userService.Delete(ctx, actorID.(uuid.UUID), organizationID, userID)
 	if err != nil {
+		if stdErrors.Is(err, errors.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"message": "Пользователь не найден"})
+			return
+		}
+		if stdErrors.Is(err, errors.ErrForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"message": "Нельзя удалить пользователя с правами шире собственных"})
+			return
+		}
+		if stdErrors.Is(err, errors.ErrSuperUserDelete) {
+			c.JSON(http.StatusConflict, gin.H{"message": "Учётную запись superuser нельзя удалить, сначала снимите роль"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	stdErrors "errors"
+	"gold_portal/internal/domain/dto"
+	"gold_portal/internal/domain/services"
+	"gold_portal/internal/errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type WebAuthnHandler struct {
+	webAuthnService services.WebAuthnService
+	authService     services.AuthService
+}
+
+func NewWebAuthnHandler(webAuthnService services.WebAuthnService, authService services.AuthService) *WebAuthnHandler {
+	return &WebAuthnHandler{
+		webAuthnService: webAuthnService,
+		authService:     authService,
+	}
+}
+
+// RegisterBegin godoc
+// @Summary Начало регистрации ключа доступа
+// @Description Возвращает параметры для navigator.credentials.create()
+// @Tags webauthn
+// @Security BearerAuth
+// @Produce json
+// @Router /api/v1/auth/webauthn/register/begin [post]
+func (h *WebAuthnHandler) RegisterBegin(c *gin.Context) {
+	id, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "User is not authorized"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	options, err := h.webAuthnService.BeginRegistration(ctx, id.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, options)
+}
+
+// RegisterFinish godoc
+// @Summary Завершение регистрации ключа доступа
+// @Description Проверяет ответ аутентификатора и сохраняет ключ доступа
+// @Tags webauthn
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.WebAuthnRegisterFinishRequestDTO true "Ответ navigator.credentials.create()"
+// @Success 201 {object} dto.WebAuthnCredentialResponseDTO
+// @Router /api/v1/auth/webauthn/register/finish [post]
+func (h *WebAuthnHandler) RegisterFinish(c *gin.Context) {
+	id, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "User is not authorized"})
+		return
+	}
+
+	var request dto.WebAuthnRegisterFinishRequestDTO
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	credential, err := h.webAuthnService.FinishRegistration(ctx, id.(uuid.UUID), request)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, credential)
+}
+
+// LoginBegin godoc
+// @Summary Начало входа по ключу доступа
+// @Description Возвращает challenge_id и параметры для navigator.credentials.get()
+// @Tags webauthn
+// @Produce json
+// @Success 200 {object} dto.WebAuthnLoginBeginResponseDTO
+// @Router /api/v1/auth/webauthn/login/begin [post]
+func (h *WebAuthnHandler) LoginBegin(c *gin.Context) {
+	ctx := c.Request.Context()
+	response, err := h.webAuthnService.BeginLogin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// LoginFinish godoc
+// @Summary Вход по ключу доступа
+// @Description Проверяет подпись ключа доступа и выдаёт токены так же, как вход по паролю.
+// @Description Вход ключом доступа считается двухфакторным
+// @Tags webauthn
+// @Accept json
+// @Produce json
+// @Param request body dto.WebAuthnLoginFinishRequestDTO true "challenge_id и ответ navigator.credentials.get()"
+// @Router /api/v1/auth/webauthn/login/finish [post]
+func (h *WebAuthnHandler) LoginFinish(c *gin.Context) {
+	var request dto.WebAuthnLoginFinishRequestDTO
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	request.UserAgent = c.GetHeader("User-Agent")
+	request.ClientIP = c.ClientIP()
+
+	ctx := c.Request.Context()
+	tokenResponse, err := h.authService.LoginWebAuthn(ctx, request)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	setAuthCookies(c, h.authService, tokenResponse.AccessToken, tokenResponse.RefreshToken)
+
+	c.JSON(http.StatusOK, gin.H{
+		"access_token": tokenResponse.AccessToken,
+		"message":      "Success authorization",
+	})
+}
+
+// GetCredentials godoc
+// @Summary Ключи доступа
+// @Description Возвращает ключи доступа текущего пользователя
+// @Tags webauthn
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} dto.WebAuthnCredentialResponseDTO
+// @Router /api/v1/auth/webauthn/credentials [get]
+func (h *WebAuthnHandler) GetCredentials(c *gin.Context) {
+	id, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "User is not authorized"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	credentials, err := h.webAuthnService.GetCredentials(ctx, id.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, credentials)
+}
+
+// DeleteCredential godoc
+// @Summary Удалить ключ доступа
+// @Description Удаляет ключ доступа текущего пользователя
+// @Tags webauthn
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "ID ключа доступа"
+// @Router /api/v1/auth/webauthn/credentials/{id} [delete]
+func (h *WebAuthnHandler) DeleteCredential(c *gin.Context) {
+	id, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "User is not authorized"})
+		return
+	}
+
+	credentialID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Некорректный ID ключа"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := h.webAuthnService.DeleteCredential(ctx, id.(uuid.UUID), credentialID); err != nil {
+		h.handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Ключ доступа удалён"})
+}
+
+func (h *WebAuthnHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case stdErrors.Is(err, errors.ErrWebAuthnCredentialNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"message": err.Error()})
+	case stdErrors.Is(err, errors.ErrWebAuthnChallengeInvalid),
+		stdErrors.Is(err, errors.ErrWebAuthnVerificationFailed),
+		stdErrors.Is(err, errors.ErrInvalidCredentials),
+		stdErrors.Is(err, errors.ErrAccountBlocked):
+		c.JSON(http.StatusUnauthorized, gin.H{"message": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+	}
+}
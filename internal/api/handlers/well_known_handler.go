@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"gold_portal/internal/domain/services"
+	"gold_portal/internal/pkg/jwt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WellKnownHandler struct {
+	jwtService   jwt.JWTService
+	oauthService services.OAuthService
+}
+
+func NewWellKnownHandler(jwtService jwt.JWTService, oauthService services.OAuthService) *WellKnownHandler {
+	return &WellKnownHandler{
+		jwtService:   jwtService,
+		oauthService: oauthService,
+	}
+}
+
+// JWKS godoc
+// @Summary Открытые ключи подписи
+// @Description Возвращает JWKS с открытыми ключами, которыми можно проверить подпись выданных токенов
+// @Tags well-known
+// @Produce json
+// @Success 200 {object} jwt.JWKS
+// @Router /.well-known/jwks.json [get]
+func (h *WellKnownHandler) JWKS(c *gin.Context) {
+	// Ключи меняются не чаще раза в минуту, даём потребителям кэшировать ответ
+	c.Header("Cache-Control", "public, max-age=60")
+	c.JSON(http.StatusOK, h.jwtService.JWKS())
+}
+
+// OpenIDConfiguration godoc
+// @Summary Метаданные OpenID Connect
+// @Description Возвращает документ OIDC Discovery с адресами endpoint'ов и поддерживаемыми возможностями
+// @Tags well-known
+// @Produce json
+// @Success 200 {object} dto.OpenIDConfigurationDTO
+// @Router /.well-known/openid-configuration [get]
+func (h *WellKnownHandler) OpenIDConfiguration(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.JSON(http.StatusOK, h.oauthService.OpenIDConfiguration())
+}
@@ -1,13 +1,16 @@
 package middleware
 
 import (
+	stdErrors "errors"
 	"fmt"
+	"gold_portal/internal/domain/entities"
 	"gold_portal/internal/domain/services"
+	"gold_portal/internal/errors"
 	"net/http"
 	"strings"
-	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
 )
 
@@ -15,11 +18,23 @@ const (
 	AuthorizationHeaderKey = "Authorization"
 	BearerSchema           = "Bearer "
 	AccessTokenCookieName  = "access_token"
+	APIKeyHeaderKey        = "X-API-Key"
 )
 
-// AuthMiddleware middleware для проверки JWT токена
-func AuthMiddleware(authService services.AuthService) gin.HandlerFunc {
+// AuthMiddleware middleware для проверки JWT токена или API-ключа
+func AuthMiddleware(authService services.AuthService, apiKeyService services.APIKeyService) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		// API-ключ передаётся в X-API-Key или в Authorization вместо JWT
+		apiKey := c.GetHeader(APIKeyHeaderKey)
+		if authHeader := c.GetHeader(AuthorizationHeaderKey); apiKey == "" && strings.HasPrefix(authHeader, BearerSchema) &&
+			services.IsAPIKey(strings.TrimPrefix(authHeader, BearerSchema)) {
+			apiKey = strings.TrimPrefix(authHeader, BearerSchema)
+		}
+		if apiKey != "" {
+			authenticateAPIKey(c, apiKeyService, apiKey)
+			return
+		}
+
 		var tokenString string
 
 		// Попробовать получить токен из cookie
@@ -54,8 +69,54 @@ func AuthMiddleware(authService services.AuthService) gin.HandlerFunc {
 			return
 		}
 
+		// Сохраняем предъявленный токен для TokenBlacklistMiddleware
+		c.Set("token", tokenString)
+
+		// Сервисный клиент действует от своего имени: пользователя в базе нет
+		if client, ok := authService.GetClientFromToken(token); ok {
+			c.Set("id", client.ID)
+			c.Set("role", client.Role)
+			c.Set("client_id", client.ClientID)
+			c.Set("scope", client.Scope)
+			c.Set("service_client", client)
+			c.Next()
+			return
+		}
+
 		// Получение пользователя из токена
 		userDTO, err := authService.GetUserFromToken(c.Request.Context(), token)
+		if stdErrors.Is(err, errors.ErrAccountBlocked) {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Аккаунт заблокирован",
+				"code":  "AUTH_ACCOUNT_BLOCKED",
+			})
+			c.Abort()
+			return
+		}
+		if stdErrors.Is(err, errors.ErrTokenRevoked) {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Выполнен выход со всех устройств",
+				"code":  "AUTH_TOKEN_REVOKED",
+			})
+			c.Abort()
+			return
+		}
+		if stdErrors.Is(err, errors.ErrSessionRevoked) {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Сессия завершена",
+				"code":  "AUTH_SESSION_REVOKED",
+			})
+			c.Abort()
+			return
+		}
+		if stdErrors.Is(err, errors.ErrNotOrganizationMember) || stdErrors.Is(err, errors.ErrOrganizationNotFound) {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Нет доступа к организации токена",
+				"code":  "AUTH_ORGANIZATION_ACCESS_REVOKED",
+			})
+			c.Abort()
+			return
+		}
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error":   "Ошибка получения пользователя из токена",
@@ -70,6 +131,117 @@ func AuthMiddleware(authService services.AuthService) gin.HandlerFunc {
 		c.Set("id", userDTO.ID)
 		c.Set("user", userDTO)
 		c.Set("role", userDTO.Role)
+		c.Set("organization_id", *userDTO.OrganizationID)
+		if claims, ok := token.Claims.(jwt.MapClaims); ok {
+			if sessionID, ok := claims["sid"].(string); ok {
+				c.Set("session_id", sessionID)
+			}
+			// Пользовательский токен с client_id выдан стороннему приложению; его scope проверяет ScopeMiddleware
+			if clientID, ok := claims["client_id"].(string); ok {
+				c.Set("client_id", clientID)
+				c.Set("oauth_client_id", clientID)
+			}
+			if scope, ok := claims["scope"].(string); ok {
+				c.Set("scope", scope)
+			}
+			// Способы аутентификации нужны RequireTwoFactorMiddleware
+			var authMethods []string
+			if amr, ok := claims["amr"].([]interface{}); ok {
+				for _, method := range amr {
+					if str, ok := method.(string); ok {
+						authMethods = append(authMethods, str)
+					}
+				}
+			}
+			c.Set("amr", authMethods)
+		}
+
+		c.Next()
+	}
+}
+
+func authenticateAPIKey(c *gin.Context, apiKeyService services.APIKeyService, rawKey string) {
+	key, userDTO, err := apiKeyService.Authenticate(c.Request.Context(), rawKey, c.ClientIP())
+	if stdErrors.Is(err, errors.ErrAccountBlocked) {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Аккаунт заблокирован",
+			"code":  "AUTH_ACCOUNT_BLOCKED",
+		})
+		c.Abort()
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Недействительный API-ключ",
+			"code":  "AUTH_API_KEY_INVALID",
+		})
+		c.Abort()
+		return
+	}
+
+	// Ключ действует с текущей ролью владельца в организации ключа; scope проверяет ScopeMiddleware
+	c.Set("id", userDTO.ID)
+	c.Set("user", userDTO)
+	c.Set("role", userDTO.Role)
+	c.Set("organization_id", *userDTO.OrganizationID)
+	c.Set("api_key", key)
+	c.Next()
+}
+
+// ScopeMiddleware ограничивает запросы по API-ключу и по токену, выданному стороннему
+// приложению через /oauth/authorize: чтение (GET, HEAD) требует readScope, остальные
+// методы — writeScope. Пустой scope закрывает доступ таким учётным данным.
+// Запросы по токену сессии и сервисного клиента не затрагиваются
+func ScopeMiddleware(readScope, writeScope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		required := writeScope
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			required = readScope
+		}
+
+		if value, exists := c.Get("api_key"); exists {
+			key, _ := value.(*entities.APIKey)
+			if required == "" {
+				c.JSON(http.StatusForbidden, gin.H{
+					"error": "Операция недоступна по API-ключу",
+					"code":  "AUTH_API_KEY_NOT_ALLOWED",
+				})
+				c.Abort()
+				return
+			}
+			if key == nil || !key.HasScope(required) {
+				c.JSON(http.StatusForbidden, gin.H{
+					"error":          "Недостаточно прав API-ключа",
+					"code":           "AUTH_INSUFFICIENT_SCOPE",
+					"required_scope": required,
+				})
+				c.Abort()
+				return
+			}
+			c.Next()
+			return
+		}
+
+		// Токен стороннего приложения действует только в пределах выданных ему scope
+		if _, exists := c.Get("oauth_client_id"); exists {
+			if required == "" {
+				c.JSON(http.StatusForbidden, gin.H{
+					"error": "Операция недоступна по токену приложения",
+					"code":  "AUTH_OAUTH_TOKEN_NOT_ALLOWED",
+				})
+				c.Abort()
+				return
+			}
+			if !strings.Contains(" "+c.GetString("scope")+" ", " "+required+" ") {
+				c.JSON(http.StatusForbidden, gin.H{
+					"error":          "Недостаточно прав токена приложения",
+					"code":           "AUTH_INSUFFICIENT_SCOPE",
+					"required_scope": required,
+				})
+				c.Abort()
+				return
+			}
+		}
 
 		c.Next()
 	}
@@ -113,7 +285,9 @@ func AuditMiddleware(auditService services.AuditService) gin.HandlerFunc {
 		}
 
 		// Определяем тип объекта по пути
-		if strings.Contains(path, "/products") {
+		if strings.HasPrefix(path, "/oauth") {
+			entityType = "OAuth"
+		} else if strings.Contains(path, "/products") {
 			entityType = "Product"
 		} else if strings.Contains(path, "/users") || strings.Contains(path, "/dashboard") {
 			entityType = "User"
@@ -143,95 +317,24 @@ func AuditMiddleware(auditService services.AuditService) gin.HandlerFunc {
 		// Формируем данные для логирования
 		logData := fmt.Sprintf("Действие: %s %s | Пользователь: %s", actionDescription, entityType, userName)
 
-		// Логируем действие
-		_ = auditService.Log(userID, entityID, method, path, status, clientIP, userAgent, logData)
-	}
-}
-
-// AdminRoleMiddleware проверяет, что пользователь имеет роль "admin"
-func AdminRoleMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		roleValue, exists := c.Get("role")
-		if !exists {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-				"error": "Роль пользователя не определена",
-				"code":  "AUTH_ROLE_MISSING",
-			})
-			return
-		}
-
-		roleStr := fmt.Sprintf("%v", roleValue)
-
-		if roleStr != "admin" {
-			c.JSON(http.StatusForbidden, gin.H{
-				"error":         "Недостаточно прав для выполнения операции",
-				"code":          "AUTH_INSUFFICIENT_PRIVILEGES",
-				"required_role": "admin",
-				"user_role":     roleStr,
-			})
-			c.Abort()
-			return
-		}
-
-		c.Next()
-	}
-}
-
-func SuperUserRoleMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		roleValue, exists := c.Get("role")
-		if !exists {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-				"error": "Роль пользователя не определена",
-				"code":  "AUTH_ROLE_MISSING",
-			})
-			return
-		}
-
-		roleStr := fmt.Sprintf("%v", roleValue)
-
-		if roleStr != "superuser" {
-			c.JSON(http.StatusForbidden, gin.H{
-				"error":         "Недостаточно прав для выполнения операции",
-				"code":          "AUTH_INSUFFICIENT_PRIVILEGES",
-				"required_role": "superuser",
-				"user_role":     roleStr,
-			})
-			c.Abort()
-			return
-		}
-
-		c.Next()
-	}
-}
-
-// ManagerRoleMiddleware проверяет, что пользователь имеет роль "manager" или выше
-func ManagerRoleMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		roleValue, exists := c.Get("role")
-		if !exists {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-				"error": "Роль пользователя не определена",
-				"code":  "AUTH_ROLE_MISSING",
-			})
-			return
+		// Запись попадает в журнал организации, от имени которой выполнен запрос
+		if value, exists := c.Get("organization_id"); exists {
+			if organizationID, ok := value.(uuid.UUID); ok {
+				auditService = auditService.ForOrganization(organizationID)
+			}
 		}
 
-		roleStr := fmt.Sprintf("%v", roleValue)
-
-		// Проверяем, что роль manager или выше (admin, superuser)
-		if roleStr != "manager" && roleStr != "admin" && roleStr != "superuser" {
-			c.JSON(http.StatusForbidden, gin.H{
-				"error":         "Недостаточно прав для выполнения операции",
-				"code":          "AUTH_INSUFFICIENT_PRIVILEGES",
-				"required_role": "manager",
-				"user_role":     roleStr,
-			})
-			c.Abort()
-			return
+		// Запросы по API-ключу записываем с ключом, чтобы отличать их от действий самого пользователя
+		if value, exists := c.Get("api_key"); exists {
+			if key, ok := value.(*entities.APIKey); ok {
+				logData = fmt.Sprintf("Действие: %s %s | API-ключ: %s", actionDescription, entityType, key.Prefix)
+				_ = auditService.LogAPIKey(key.ID, userID, entityID, method, path, status, clientIP, userAgent, logData)
+				return
+			}
 		}
 
-		c.Next()
+		// Логируем действие
+		_ = auditService.Log(userID, entityID, method, path, status, clientIP, userAgent, logData)
 	}
 }
 
@@ -266,87 +369,3 @@ func RequireRoleMiddleware(roles ...string) gin.HandlerFunc {
 		c.Abort()
 	}
 }
-
-// RequireRoleLevelMiddleware проверяет, что пользователь имеет роль с уровнем не ниже указанного
-func RequireRoleLevelMiddleware(minLevel int) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		roleValue, exists := c.Get("role")
-		if !exists {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-				"error": "Роль пользователя не определена",
-				"code":  "AUTH_ROLE_MISSING",
-			})
-			return
-		}
-
-		roleStr := fmt.Sprintf("%v", roleValue)
-
-		// Определяем уровень роли пользователя
-		var userLevel int
-		switch roleStr {
-		case "superuser":
-			userLevel = 4
-		case "admin":
-			userLevel = 3
-		case "manager":
-			userLevel = 2
-		case "user":
-			userLevel = 1
-		default:
-			userLevel = 0
-		}
-
-		if userLevel < minLevel {
-			c.JSON(http.StatusForbidden, gin.H{
-				"error":          "Недостаточно прав для выполнения операции",
-				"code":           "AUTH_INSUFFICIENT_PRIVILEGES",
-				"required_level": minLevel,
-				"user_level":     userLevel,
-				"user_role":      roleStr,
-			})
-			c.Abort()
-			return
-		}
-
-		c.Next()
-	}
-}
-
-// RateLimitMiddleware базовый middleware для ограничения запросов
-func RateLimitMiddleware(requestsPerMinute int) gin.HandlerFunc {
-	// Простая реализация rate limiting с использованием map
-	// В продакшене лучше использовать Redis или специализированные библиотеки
-	clients := make(map[string][]time.Time)
-
-	return func(c *gin.Context) {
-		clientIP := c.ClientIP()
-		now := time.Now()
-
-		// Очистка старых записей
-		if times, exists := clients[clientIP]; exists {
-			var validTimes []time.Time
-			for _, t := range times {
-				if now.Sub(t) < time.Minute {
-					validTimes = append(validTimes, t)
-				}
-			}
-			clients[clientIP] = validTimes
-		}
-
-		// Проверка лимита
-		if len(clients[clientIP]) >= requestsPerMinute {
-			c.JSON(http.StatusTooManyRequests, gin.H{
-				"error":       "Превышен лимит запросов",
-				"code":        "RATE_LIMIT_EXCEEDED",
-				"retry_after": "60 seconds",
-			})
-			c.Abort()
-			return
-		}
-
-		// Добавление текущего запроса
-		clients[clientIP] = append(clients[clientIP], now)
-
-		c.Next()
-	}
-}
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	stdErrors "errors"
+	"gold_portal/internal/domain/services"
+	"gold_portal/internal/errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// OrganizationMemberMiddleware пропускает запрос к пользователю из параметра :id, только если
+// он состоит в организации запроса. Чужие пользователи выглядят несуществующими
+func OrganizationMemberMiddleware(organizationService services.OrganizationService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, _ := c.Get("organization_id")
+		organizationID, _ := value.(uuid.UUID)
+		if organizationID == uuid.Nil {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error": "Запрос не привязан к организации",
+				"code":  "AUTH_ORGANIZATION_MISSING",
+			})
+			return
+		}
+
+		userID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "Некорректный ID пользователя"})
+			return
+		}
+
+		member, err := organizationService.IsMember(c.Request.Context(), organizationID, userID)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"error": "Не удалось проверить членство в организации",
+			})
+			return
+		}
+		if !member {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "Пользователь не найден"})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// TargetUserMiddleware пропускает запрос к пользователю из параметра :id, только если его права
+// в организации не шире прав текущего пользователя. Ставится после OrganizationMemberMiddleware
+func TargetUserMiddleware(usersService services.UsersService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		actorValue, _ := c.Get("id")
+		actorID, _ := actorValue.(uuid.UUID)
+		organizationValue, _ := c.Get("organization_id")
+		organizationID, _ := organizationValue.(uuid.UUID)
+
+		userID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "Некорректный ID пользователя"})
+			return
+		}
+
+		err = usersService.CheckTarget(c.Request.Context(), actorID, organizationID, userID)
+		switch {
+		case err == nil:
+			c.Next()
+		case stdErrors.Is(err, errors.ErrForbidden):
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"message": "Нельзя управлять пользователем с правами шире собственных"})
+		case stdErrors.Is(err, errors.ErrUserNotFound), stdErrors.Is(err, errors.ErrNotOrganizationMember):
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "Пользователь не найден"})
+		default:
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		}
+	}
+}
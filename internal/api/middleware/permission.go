@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"gold_portal/internal/domain/entities"
+	"gold_portal/internal/domain/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RequirePermissionMiddleware пропускает запрос, если у пользователя есть все перечисленные
+// права: через роль в организации или через группы. Сервисному клиенту права выдаются
+// только ролью. Права хранятся в базе
+func RequirePermissionMiddleware(permissionService services.PermissionService, permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		roleValue, exists := c.Get("role")
+		if !exists {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "Роль пользователя не определена",
+				"code":  "AUTH_ROLE_MISSING",
+			})
+			return
+		}
+		role, _ := roleValue.(entities.Role)
+
+		granted, err := grantedPermissions(c, permissionService, role)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"error": "Не удалось проверить права",
+			})
+			return
+		}
+		for _, permission := range permissions {
+			if !granted[permission] {
+				c.JSON(http.StatusForbidden, gin.H{
+					"error":               "Недостаточно прав для выполнения операции",
+					"code":                "AUTH_INSUFFICIENT_PRIVILEGES",
+					"required_permission": permission,
+					"user_role":           role,
+				})
+				c.Abort()
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// grantedPermissions права текущего запроса: для пользователя — действующие права
+// в организации запроса, для сервисного клиента — права его роли
+func grantedPermissions(c *gin.Context, permissionService services.PermissionService, role entities.Role) (map[string]bool, error) {
+	ctx := c.Request.Context()
+
+	var permissions []string
+	var err error
+	if _, ok := c.Get("user"); ok {
+		userID, _ := c.Get("id")
+		organizationID, _ := c.Get("organization_id")
+		id, _ := userID.(uuid.UUID)
+		organization, _ := organizationID.(uuid.UUID)
+		permissions, err = permissionService.GetUserPermissions(ctx, role, organization, id)
+	} else {
+		permissions, err = permissionService.GetRolePermissions(ctx, role)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	granted := make(map[string]bool, len(permissions))
+	for _, permission := range permissions {
+		granted[permission] = true
+	}
+	return granted, nil
+}
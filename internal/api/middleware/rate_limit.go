@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"gold_portal/config"
+	"gold_portal/internal/domain/services"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Больше тела запроса для поиска номера телефона не читаем
+const rateLimitMaxBodySize = 64 << 10
+
+// RateLimitMiddleware ограничивает частоту запросов по правилу policy.
+// name разделяет счётчики разных правил. Запросы считаются отдельно по каждому
+// признаку из policy.KeyBy; запрос отклоняется, если превышен любой из лимитов
+func RateLimitMiddleware(limiter services.RateLimiter, name string, policy config.RateLimitPolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if policy.Limit <= 0 {
+			c.Next()
+			return
+		}
+
+		var strictest *services.RateLimitResult
+		for _, keyBy := range policy.KeyBy {
+			subject := rateLimitSubject(c, keyBy)
+			if subject == "" {
+				continue
+			}
+
+			key := fmt.Sprintf("%s:%s:%s", name, keyBy, subject)
+			result, err := limiter.Allow(c.Request.Context(), key, policy.Limit, policy.Window)
+			if err != nil {
+				// Недоступный кеш не должен останавливать вход и работу панели
+				log.Printf("rate limit %s: %v", name, err)
+				continue
+			}
+			if strictest == nil || stricter(result, strictest) {
+				strictest = result
+			}
+		}
+		if strictest == nil {
+			c.Next()
+			return
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(strictest.Limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(strictest.Remaining))
+		c.Header("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(strictest.ResetAfter.Seconds())))
+
+		if !strictest.Allowed {
+			retryAfter := ceilSeconds(strictest.RetryAfter.Seconds())
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"error":       "Превышен лимит запросов",
+				"code":        "RATE_LIMIT_EXCEEDED",
+				"retry_after": retryAfter,
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// stricter сравнивает результаты разных лимитов: в ответе показываем тот, что ограничивает сильнее
+func stricter(a, b *services.RateLimitResult) bool {
+	if a.Allowed != b.Allowed {
+		return !a.Allowed
+	}
+	if !a.Allowed {
+		return a.RetryAfter > b.RetryAfter
+	}
+	return a.Remaining < b.Remaining
+}
+
+func rateLimitSubject(c *gin.Context, keyBy string) string {
+	switch keyBy {
+	case config.RateLimitKeyIP:
+		return c.ClientIP()
+	case config.RateLimitKeyUser:
+		// Доступно только после AuthMiddleware
+		if id, exists := c.Get("id"); exists {
+			return fmt.Sprint(id)
+		}
+		return ""
+	case config.RateLimitKeyPhone:
+		return requestPhone(c)
+	default:
+		return ""
+	}
+}
+
+// requestPhone достаёт номер телефона из JSON или формы, не расходуя тело запроса
+func requestPhone(c *gin.Context) string {
+	if c.Request.Body == nil {
+		return ""
+	}
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, rateLimitMaxBodySize))
+	if err != nil {
+		return ""
+	}
+	c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), c.Request.Body))
+
+	if strings.HasPrefix(c.ContentType(), "application/json") {
+		var payload struct {
+			Phone string `json:"phone"`
+		}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return ""
+		}
+		return strings.TrimSpace(payload.Phone)
+	}
+	if c.ContentType() == "application/x-www-form-urlencoded" {
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(values.Get("phone"))
+	}
+	return ""
+}
+
+func ceilSeconds(seconds float64) int {
+	return int(math.Max(1, math.Ceil(seconds)))
+}
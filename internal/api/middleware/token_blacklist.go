@@ -11,22 +11,24 @@ import (
 // TokenBlacklistMiddleware проверяет, не находится ли токен в черном списке
 func TokenBlacklistMiddleware(tokenService services.TokenService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Получаем токен из заголовка Authorization
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
+		// API-ключи отзываются в базе, а не через черный список
+		if _, ok := c.Get("api_key"); ok {
 			c.Next()
 			return
 		}
 
-		// Проверяем формат заголовка
-		tokenParts := strings.Split(authHeader, " ")
-		if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
-			c.Next()
-			return
+		// Проверяем тот же токен, что принял AuthMiddleware (из cookie или заголовка)
+		tokenString := c.GetString("token")
+		if tokenString == "" {
+			// Получаем токен из заголовка Authorization
+			tokenParts := strings.Split(c.GetHeader("Authorization"), " ")
+			if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
+				c.Next()
+				return
+			}
+			tokenString = tokenParts[1]
 		}
 
-		tokenString := tokenParts[1]
-
 		// Проверяем, не в черном ли списке токен
 		isBlacklisted, err := tokenService.IsTokenBlacklisted(c.Request.Context(), tokenString)
 		if err != nil {
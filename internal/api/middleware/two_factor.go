@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"gold_portal/internal/domain/entities"
+	"gold_portal/internal/domain/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RequireTwoFactorMiddleware пропускает пользователей с обязательной 2FA только
+// если токен выдан после второго фактора: кода 2FA или ключа доступа. 2FA обязательна,
+// если она требуется для роли в организации или для роли любой из групп пользователя
+func RequireTwoFactorMiddleware(twoFactorService services.TwoFactorService, permissionService services.PermissionService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Сервисные клиенты входят по секрету, второго фактора у них нет
+		if _, ok := c.Get("service_client"); ok {
+			c.Next()
+			return
+		}
+		// API-ключ создаётся только в сессии, прошедшей эту же проверку
+		if _, ok := c.Get("api_key"); ok {
+			c.Next()
+			return
+		}
+
+		roleValue, exists := c.Get("role")
+		if !exists {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "Роль пользователя не определена",
+				"code":  "AUTH_ROLE_MISSING",
+			})
+			return
+		}
+
+		role, _ := roleValue.(entities.Role)
+		userID, _ := c.Get("id")
+		organizationID, _ := c.Get("organization_id")
+		id, _ := userID.(uuid.UUID)
+		organization, _ := organizationID.(uuid.UUID)
+		roles, err := permissionService.GetUserRoles(c.Request.Context(), role, organization, id)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"error": "Не удалось проверить права",
+			})
+			return
+		}
+		required := false
+		for _, granted := range roles {
+			if twoFactorService.IsRequired(granted) {
+				required = true
+				break
+			}
+		}
+		if !required {
+			c.Next()
+			return
+		}
+
+		authMethods, _ := c.Get("amr")
+		methods, _ := authMethods.([]string)
+		if services.HasSecondFactor(methods) {
+			c.Next()
+			return
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Для этой роли обязательна двухфакторная аутентификация",
+			"code":  "AUTH_2FA_REQUIRED",
+		})
+		c.Abort()
+	}
+}
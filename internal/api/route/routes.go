@@ -4,9 +4,11 @@ import (
 	"gold_portal/config"
 	"gold_portal/internal/api/handlers"
 	"gold_portal/internal/api/middleware"
+	"gold_portal/internal/domain/entities"
 	"gold_portal/internal/domain/repositories"
 	"gold_portal/internal/domain/services"
 	"gold_portal/internal/infrastructure/cache"
+	"gold_portal/internal/infrastructure/sms"
 	"gold_portal/internal/pkg/jwt"
 	"net/http"
 
@@ -48,6 +50,16 @@ func SetupRoutes(db *gorm.DB, cfg *config.Config) *gin.Engine {
 
 	// Repositories
 	userRepository := repositories.NewUserRepository(db)
+	sessionRepository := repositories.NewSessionRepository(db)
+	oauthClientRepository := repositories.NewOAuthClientRepository(db)
+	recoveryCodeRepository := repositories.NewRecoveryCodeRepository(db)
+	webAuthnCredentialRepository := repositories.NewWebAuthnCredentialRepository(db)
+	passwordHistoryRepository := repositories.NewPasswordHistoryRepository(db)
+	apiKeyRepository := repositories.NewAPIKeyRepository(db)
+	permissionRepository := repositories.NewPermissionRepository(db)
+	roleRepository := repositories.NewRoleRepository(db)
+	organizationRepository := repositories.NewOrganizationRepository(db)
+	groupRepository := repositories.NewGroupRepository(db)
 
 	// Cache (Redis)
 	redisCache, err := cache.NewRedisCache(cfg)
@@ -56,7 +68,22 @@ func SetupRoutes(db *gorm.DB, cfg *config.Config) *gin.Engine {
 	}
 
 	// JWT Service
-	jwtService := jwt.NewJWTService(cfg.JWT.Secret)
+	keyring, err := jwt.NewKeyring(jwt.KeyringConfig{
+		Algorithm:        cfg.JWT.Algorithm,
+		Dir:              cfg.JWT.KeysDir,
+		RotationInterval: cfg.JWT.KeyRotation,
+		Overlap:          cfg.JWT.KeyOverlap,
+	})
+	if err != nil {
+		panic("Failed to initialize signing keys: " + err.Error())
+	}
+	jwtService := jwt.NewJWTService(keyring)
+
+	// SMS gateway
+	smsSender, err := sms.NewSMSSender(cfg)
+	if err != nil {
+		panic("Failed to initialize SMS sender: " + err.Error())
+	}
 
 	// Token Service
 	tokenService := services.NewTokenService(redisCache, jwtService)
@@ -69,19 +96,89 @@ func SetupRoutes(db *gorm.DB, cfg *config.Config) *gin.Engine {
 
 	// Services
 	auditService := services.NewAuditService(db)
-	authService := services.NewAuthService(userRepository, tokenService, fileService, cfg)
-	userService := services.NewUserService(userRepository, fileService)
+	sessionService := services.NewSessionService(sessionRepository, userRepository, apiKeyRepository, tokenService, cfg)
+	organizationService := services.NewOrganizationService(organizationRepository, userRepository, sessionService, auditService)
+	twoFactorService := services.NewTwoFactorService(userRepository, recoveryCodeRepository, organizationRepository, groupRepository, redisCache, cfg)
+	webAuthnService, err := services.NewWebAuthnService(userRepository, webAuthnCredentialRepository, redisCache, cfg)
+	if err != nil {
+		panic("Failed to initialize WebAuthn: " + err.Error())
+	}
+	otpService := services.NewOTPService(smsSender, redisCache, cfg)
+	passwordPolicyService := services.NewPasswordPolicyService(userRepository, passwordHistoryRepository, cfg)
+	lockoutService := services.NewLockoutService(userRepository, auditService, redisCache, cfg)
+	authService := services.NewAuthService(userRepository, tokenService, sessionService, twoFactorService, webAuthnService, otpService, lockoutService, passwordPolicyService, organizationService, groupRepository, fileService, jwtService, cfg)
+	permissionService := services.NewPermissionService(permissionRepository, groupRepository, redisCache)
+	userService := services.NewUserService(userRepository, organizationRepository, organizationService, permissionService, sessionService, auditService, fileService)
+	roleService := services.NewRoleService(roleRepository, permissionRepository, userRepository, permissionService, auditService)
+	passwordService := services.NewPasswordService(userRepository, sessionService, auditService, passwordPolicyService, userService)
+	apiKeyService := services.NewAPIKeyService(apiKeyRepository, userRepository, organizationService, auditService, cfg)
+	groupService := services.NewGroupService(groupRepository, permissionRepository, userRepository, organizationService, roleService, permissionService, auditService)
+	oauthService := services.NewOAuthService(oauthClientRepository, userRepository, roleService, authService, sessionService, tokenService, jwtService, redisCache, cfg)
 
 	// Initialize middleware
-	authMiddleware := middleware.AuthMiddleware(authService)
+	authMiddleware := middleware.AuthMiddleware(authService, apiKeyService)
 	auditMiddleware := middleware.AuditMiddleware(auditService)
 	tokenBlacklistMiddleware := middleware.TokenBlacklistMiddleware(tokenService)
-	adminMiddleware := middleware.RequireRoleLevelMiddleware(2)
+	twoFactorMiddleware := middleware.RequireTwoFactorMiddleware(twoFactorService, permissionService)
+	// Администратор организации видит только её участников
+	organizationMember := middleware.OrganizationMemberMiddleware(organizationService)
+	// Пользователя с правами шире собственных нельзя выводить из сессий, разблокировать и т. п.
+	targetUser := middleware.TargetUserMiddleware(userService)
+	requirePermission := func(permissions ...string) gin.HandlerFunc {
+		return middleware.RequirePermissionMiddleware(permissionService, permissions...)
+	}
+	// Маршруты без scope недоступны по API-ключу и по токену стороннего приложения
+	noScopeMiddleware := middleware.ScopeMiddleware("", "")
+	// /userinfo доступен приложениям со scope openid; API-ключам такой scope не выдаётся
+	userInfoScopeMiddleware := middleware.ScopeMiddleware("openid", "openid")
+
+	// Rate limiting
+	rateLimiter := services.NewRateLimiter(redisCache)
+	rateLimit := func(name string, policy config.RateLimitPolicy) gin.HandlerFunc {
+		if !cfg.RateLimit.Enabled {
+			policy.Limit = 0
+		}
+		return middleware.RateLimitMiddleware(rateLimiter, name, policy)
+	}
+	loginRateLimit := rateLimit("login", cfg.RateLimit.Login)
+	registerRateLimit := rateLimit("register", cfg.RateLimit.Register)
+	refreshRateLimit := rateLimit("refresh", cfg.RateLimit.Refresh)
+	otpRateLimit := rateLimit("otp", cfg.RateLimit.OTP)
+	dashboardRateLimit := rateLimit("dashboard", cfg.RateLimit.Dashboard)
 
 	// Initialize handlers
-	authHandler := handlers.NewAuthHandler(authService)
-	userHandler := handlers.NewUserHandler(userService)
+	authHandler := handlers.NewAuthHandler(authService, roleService)
+	userHandler := handlers.NewUserHandler(userService, roleService)
 	auditHandler := handlers.NewAuditHandler(auditService)
+	sessionHandler := handlers.NewSessionHandler(sessionService)
+	twoFactorHandler := handlers.NewTwoFactorHandler(twoFactorService)
+	webAuthnHandler := handlers.NewWebAuthnHandler(webAuthnService, authService)
+	otpHandler := handlers.NewOTPHandler(authService)
+	passwordHandler := handlers.NewPasswordHandler(passwordService)
+	lockoutHandler := handlers.NewLockoutHandler(lockoutService)
+	wellKnownHandler := handlers.NewWellKnownHandler(jwtService, oauthService)
+	oauthHandler := handlers.NewOAuthHandler(oauthService)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService)
+	permissionHandler := handlers.NewPermissionHandler(permissionService)
+	roleHandler := handlers.NewRoleHandler(roleService)
+	organizationHandler := handlers.NewOrganizationHandler(organizationService, authService, roleService)
+	groupHandler := handlers.NewGroupHandler(groupService)
+
+	router.GET("/.well-known/jwks.json", wellKnownHandler.JWKS)
+	router.GET("/.well-known/openid-configuration", wellKnownHandler.OpenIDConfiguration)
+
+	// OAuth 2.0 authorization server
+	oauth := router.Group("/oauth")
+	oauth.Use(auditMiddleware)
+	{
+		oauth.GET("/authorize", oauthHandler.Authorize)
+		oauth.POST("/authorize", loginRateLimit, oauthHandler.AuthorizeSubmit)
+		oauth.POST("/token", refreshRateLimit, oauthHandler.Token)
+		oauth.POST("/introspect", oauthHandler.Introspect)
+		oauth.POST("/revoke", oauthHandler.Revoke)
+		oauth.GET("/userinfo", authMiddleware, tokenBlacklistMiddleware, userInfoScopeMiddleware, oauthHandler.UserInfo)
+		oauth.POST("/userinfo", authMiddleware, tokenBlacklistMiddleware, userInfoScopeMiddleware, oauthHandler.UserInfo)
+	}
 
 	//API routes
 	api := router.Group("/api/v1")
@@ -90,36 +187,117 @@ func SetupRoutes(db *gorm.DB, cfg *config.Config) *gin.Engine {
 		auth := api.Group("/auth")
 		auth.Use(auditMiddleware)
 		{
-			auth.POST("/web-register", authHandler.UserRegister)
-			auth.POST("/login", authHandler.Login)
+			auth.POST("/web-register", registerRateLimit, authHandler.UserRegister)
+			auth.POST("/login", loginRateLimit, authHandler.Login)
 			auth.POST("/logout", authHandler.Logout)
-			auth.POST("/refresh", authHandler.Refresh)
+			auth.POST("/refresh", refreshRateLimit, authHandler.Refresh)
+			auth.POST("/2fa/verify", loginRateLimit, authHandler.VerifyMFA)
+			auth.POST("/webauthn/login/begin", webAuthnHandler.LoginBegin)
+			auth.POST("/webauthn/login/finish", loginRateLimit, webAuthnHandler.LoginFinish)
+			auth.POST("/otp/send", otpRateLimit, otpHandler.SendLoginCode)
+			auth.POST("/otp/login", loginRateLimit, otpHandler.Login)
+			auth.POST("/password/forgot", otpRateLimit, otpHandler.ForgotPassword)
+			auth.POST("/password/reset", otpRateLimit, otpHandler.ResetPassword)
 		}
 		authAuth := auth.Group("/")
 		authAuth.Use(authMiddleware, auditMiddleware, tokenBlacklistMiddleware)
 		{
-			authAuth.GET("/me", authHandler.UserMe)
+			authAuth.GET("/me", middleware.ScopeMiddleware(entities.APIKeyScopeProfileRead, ""), authHandler.UserMe)
+			authAuth.GET("/me/permissions", middleware.ScopeMiddleware(entities.APIKeyScopeProfileRead, ""), permissionHandler.GetMyPermissions)
+			authAuth.GET("/me/organizations", middleware.ScopeMiddleware(entities.APIKeyScopeProfileRead, ""), organizationHandler.GetMyOrganizations)
+
+			// Управление учётной записью только из сессии: ключ не должен выпускать
+			// новые ключи или менять пароль и второй фактор
+			account := authAuth.Group("/")
+			account.Use(noScopeMiddleware)
+			{
+				account.POST("/me/password", passwordHandler.ChangeMyPassword)
+				account.GET("/sessions", sessionHandler.GetMySessions)
+				account.DELETE("/sessions/:id", sessionHandler.RevokeMySession)
+				account.POST("/logout-all", sessionHandler.LogoutEverywhere)
+				account.POST("/organizations/switch", organizationHandler.SwitchOrganization)
+				account.POST("/2fa/enroll", twoFactorHandler.Enroll)
+				account.POST("/2fa/confirm", twoFactorHandler.Confirm)
+				account.POST("/2fa/disable", twoFactorHandler.Disable)
+				account.POST("/2fa/recovery-codes", twoFactorHandler.RegenerateRecoveryCodes)
+				account.POST("/webauthn/register/begin", webAuthnHandler.RegisterBegin)
+				account.POST("/webauthn/register/finish", webAuthnHandler.RegisterFinish)
+				account.GET("/webauthn/credentials", webAuthnHandler.GetCredentials)
+				account.DELETE("/webauthn/credentials/:id", webAuthnHandler.DeleteCredential)
+				account.POST("/phone/send-code", otpRateLimit, otpHandler.SendPhoneVerificationCode)
+				account.POST("/phone/verify", otpRateLimit, otpHandler.VerifyPhone)
+				// Ключ с правами администратора создаётся только после второго фактора
+				account.POST("/api-keys", twoFactorMiddleware, apiKeyHandler.CreateMyAPIKey)
+				account.GET("/api-keys", apiKeyHandler.GetMyAPIKeys)
+				account.DELETE("/api-keys/:id", apiKeyHandler.RevokeMyAPIKey)
+			}
 		}
 
 		protected := api.Group("/")
 		protected.Use(authMiddleware, tokenBlacklistMiddleware)
 		{
 			dashboard := protected.Group("/dashboard")
-			dashboard.Use(adminMiddleware, auditMiddleware)
+			dashboard.Use(dashboardRateLimit, twoFactorMiddleware, auditMiddleware,
+				middleware.ScopeMiddleware(entities.APIKeyScopeUsersRead, entities.APIKeyScopeUsersWrite))
 			{
-				dashboard.GET("", userHandler.GetAll)
-				dashboard.POST("register", authHandler.Register)
-				dashboard.GET("/id/:id", userHandler.GetByID)
-				dashboard.GET("/phone/:phone", userHandler.GetByPhone)
-				dashboard.PATCH("/patch/:id", userHandler.Patch)
-				dashboard.DELETE("/delete/:id", userHandler.Delete)
+				dashboard.GET("", requirePermission(entities.PermissionUsersRead), userHandler.GetAll)
+				dashboard.POST("register", requirePermission(entities.PermissionUsersCreate), authHandler.Register)
+				dashboard.GET("/id/:id", requirePermission(entities.PermissionUsersRead), userHandler.GetByID)
+				dashboard.GET("/phone/:phone", requirePermission(entities.PermissionUsersRead), userHandler.GetByPhone)
+				dashboard.PATCH("/patch/:id", requirePermission(entities.PermissionUsersUpdate), userHandler.Patch)
+				dashboard.DELETE("/delete/:id", requirePermission(entities.PermissionUsersDelete), userHandler.Delete)
+				dashboard.GET("/users/:id/sessions", requirePermission(entities.PermissionUsersRead), organizationMember, targetUser, sessionHandler.GetUserSessions)
+				dashboard.DELETE("/users/:id/sessions/:session_id", requirePermission(entities.PermissionUsersSessions), organizationMember, targetUser, sessionHandler.RevokeUserSession)
+				dashboard.POST("/users/:id/logout-all", requirePermission(entities.PermissionUsersSessions), organizationMember, targetUser, sessionHandler.LogoutUserEverywhere)
+				dashboard.POST("/users/:id/password", requirePermission(entities.PermissionUsersPassword), organizationMember, passwordHandler.SetUserPassword)
+				dashboard.POST("/users/:id/unlock", requirePermission(entities.PermissionUsersUnlock), organizationMember, targetUser, lockoutHandler.UnlockUser)
+
+				oauthClients := dashboard.Group("/oauth/clients")
+				oauthClients.Use(requirePermission(entities.PermissionOAuthClientsManage), noScopeMiddleware)
+				{
+					oauthClients.POST("", oauthHandler.CreateClient)
+					oauthClients.GET("", oauthHandler.GetClients)
+					oauthClients.DELETE("/:id", oauthHandler.DeleteClient)
+				}
+
+				roles := dashboard.Group("")
+				roles.Use(requirePermission(entities.PermissionRolesManage), noScopeMiddleware)
+				{
+					roles.GET("/roles", roleHandler.GetRoles)
+					roles.POST("/roles", roleHandler.CreateRole)
+					roles.PATCH("/roles/:name", roleHandler.UpdateRole)
+					roles.DELETE("/roles/:name", roleHandler.DeleteRole)
+					roles.GET("/permissions", roleHandler.GetPermissions)
+				}
+
+				organizations := dashboard.Group("/organizations")
+				organizations.Use(requirePermission(entities.PermissionOrganizationsManage), noScopeMiddleware)
+				{
+					organizations.GET("", organizationHandler.GetOrganizations)
+					organizations.POST("", organizationHandler.CreateOrganization)
+					organizations.POST("/:id/members", organizationHandler.SaveMember)
+					organizations.DELETE("/:id/members/:user_id", organizationHandler.RemoveMember)
+				}
+
+				groups := dashboard.Group("/groups")
+				groups.Use(requirePermission(entities.PermissionGroupsManage), noScopeMiddleware)
+				{
+					groups.GET("", groupHandler.GetGroups)
+					groups.POST("", groupHandler.CreateGroup)
+					groups.GET("/:id", groupHandler.GetGroup)
+					groups.PATCH("/:id", groupHandler.UpdateGroup)
+					groups.DELETE("/:id", groupHandler.DeleteGroup)
+					groups.POST("/:id/members", groupHandler.AddMember)
+					groups.DELETE("/:id/members/:user_id", groupHandler.RemoveMember)
+				}
 
 			}
 		}
 
 	}
 	audit := api.Group("/audit")
-	audit.Use(authMiddleware, adminMiddleware, tokenBlacklistMiddleware) // только авторизованные админы
+	audit.Use(authMiddleware, tokenBlacklistMiddleware, twoFactorMiddleware,
+		middleware.ScopeMiddleware(entities.APIKeyScopeAuditRead, ""), requirePermission(entities.PermissionAuditRead))
 	{
 		audit.GET("", auditHandler.GetAllLogs)
 	}
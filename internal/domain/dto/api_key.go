@@ -0,0 +1,44 @@
+package dto
+
+import (
+	"gold_portal/internal/domain/entities"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type APIKeyCreateRequestDTO struct {
+	Name   string   `json:"name" binding:"required,max=255" example:"CI deploy"`
+	Scopes []string `json:"scopes" binding:"required,min=1" example:"users:read"`
+	// Без срока действия ключ живёт срок по умолчанию из настроек
+	ExpiresAt *time.Time `json:"expires_at"`
+	UserAgent string     `json:"-"`
+	ClientIP  string     `json:"-"`
+}
+
+type APIKeyResponseDTO struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP string     `json:"last_used_ip,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type APIKeyCreateResponseDTO struct {
+	APIKeyResponseDTO
+	Key string `json:"key"` // Показывается только при создании
+}
+
+func (dto *APIKeyResponseDTO) FromModel(key *entities.APIKey) {
+	dto.ID = key.ID
+	dto.Name = key.Name
+	dto.Prefix = key.Prefix
+	dto.Scopes = key.Scopes
+	dto.ExpiresAt = key.ExpiresAt
+	dto.LastUsedAt = key.LastUsedAt
+	dto.LastUsedIP = key.LastUsedIP
+	dto.CreatedAt = key.CreatedAt
+}
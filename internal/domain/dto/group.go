@@ -0,0 +1,67 @@
+package dto
+
+import (
+	"gold_portal/internal/domain/entities"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type GroupRequestDTO struct {
+	Name        string          `json:"name" binding:"required,max=100" example:"support"`
+	Description string          `json:"description" example:"Служба поддержки"`
+	Roles       []entities.Role `json:"roles" example:"manager"`
+	Permissions []string        `json:"permissions" example:"audit:read"`
+	UserAgent   string          `json:"-"`
+	ClientIP    string          `json:"-"`
+}
+
+// GroupUpdateDTO изменения группы; отсутствующие поля не меняются
+type GroupUpdateDTO struct {
+	Name        *string `json:"name" binding:"omitempty,max=100"`
+	Description *string `json:"description"`
+	// Новый набор ролей и прав целиком
+	Roles       *[]entities.Role `json:"roles"`
+	Permissions *[]string        `json:"permissions"`
+	UserAgent   string           `json:"-"`
+	ClientIP    string           `json:"-"`
+}
+
+type GroupMemberRequestDTO struct {
+	UserID    uuid.UUID `json:"user_id" binding:"required"`
+	UserAgent string    `json:"-"`
+	ClientIP  string    `json:"-"`
+}
+
+type GroupResponseDTO struct {
+	ID          uuid.UUID       `json:"id"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Roles       []entities.Role `json:"roles"`
+	Permissions []string        `json:"permissions"`
+	Members     []uuid.UUID     `json:"members"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// GroupSummaryDTO группа в профиле пользователя
+type GroupSummaryDTO struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+}
+
+func (dto *GroupResponseDTO) FromModel(group *entities.Group, roles []entities.Role, permissions []string, members []uuid.UUID) {
+	dto.ID = group.ID
+	dto.Name = group.Name
+	dto.Description = group.Description
+	dto.Roles = roles
+	dto.Permissions = permissions
+	dto.Members = members
+	dto.CreatedAt = group.CreatedAt
+	dto.UpdatedAt = group.UpdatedAt
+}
+
+func (dto *GroupSummaryDTO) FromModel(group *entities.Group) {
+	dto.ID = group.ID
+	dto.Name = group.Name
+}
@@ -0,0 +1,140 @@
+package dto
+
+import (
+	"gold_portal/internal/domain/entities"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type OAuthClientRequestDTO struct {
+	Name         string        `json:"name" binding:"required" example:"Mobile App"`
+	RedirectURIs []string      `json:"redirect_uris" example:"com.example.app:/oauth/callback"`
+	Scopes       []string      `json:"scopes"`
+	GrantTypes   []string      `json:"grant_types" example:"authorization_code,refresh_token"`
+	Confidential bool          `json:"confidential"`
+	Role         entities.Role `json:"role" example:"manager"`
+}
+
+type OAuthClientResponseDTO struct {
+	ID           uuid.UUID     `json:"id"`
+	ClientID     string        `json:"client_id"`
+	ClientSecret string        `json:"client_secret,omitempty"` // Показывается только при создании
+	Name         string        `json:"name"`
+	RedirectURIs []string      `json:"redirect_uris"`
+	Scopes       []string      `json:"scopes"`
+	GrantTypes   []string      `json:"grant_types"`
+	Confidential bool          `json:"confidential"`
+	Role         entities.Role `json:"role,omitempty"`
+	CreatedAt    time.Time     `json:"created_at"`
+}
+
+// ServiceClientDTO сервисный клиент, от имени которого выполняется запрос по client_credentials
+type ServiceClientDTO struct {
+	ID       uuid.UUID     `json:"id"`
+	ClientID string        `json:"client_id"`
+	Role     entities.Role `json:"role"`
+	Scope    string        `json:"scope"`
+}
+
+// AuthorizeRequestDTO параметры запроса авторизации (RFC 6749, раздел 4.1.1; RFC 7636)
+type AuthorizeRequestDTO struct {
+	ResponseType        string `form:"response_type"`
+	ClientID            string `form:"client_id"`
+	RedirectURI         string `form:"redirect_uri"`
+	Scope               string `form:"scope"`
+	State               string `form:"state"`
+	CodeChallenge       string `form:"code_challenge"`
+	CodeChallengeMethod string `form:"code_challenge_method"`
+	Nonce               string `form:"nonce"`
+}
+
+// TokenRequestDTO параметры запроса к token endpoint (RFC 6749, раздел 4.1.3 и 6)
+type TokenRequestDTO struct {
+	GrantType    string `form:"grant_type"`
+	Code         string `form:"code"`
+	RedirectURI  string `form:"redirect_uri"`
+	ClientID     string `form:"client_id"`
+	ClientSecret string `form:"client_secret"`
+	CodeVerifier string `form:"code_verifier"`
+	RefreshToken string `form:"refresh_token"`
+	Scope        string `form:"scope"`
+}
+
+// OAuthTokenResponseDTO успешный ответ token endpoint (RFC 6749, раздел 5.1)
+type OAuthTokenResponseDTO struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+	IDToken      string `json:"id_token,omitempty"`
+}
+
+// OAuthErrorResponseDTO ответ с ошибкой (RFC 6749, раздел 5.2)
+// IntrospectRequestDTO запрос интроспекции токена (RFC 7662, раздел 2.1)
+type IntrospectRequestDTO struct {
+	Token         string `form:"token"`
+	TokenTypeHint string `form:"token_type_hint"`
+	ClientID      string `form:"client_id"`
+	ClientSecret  string `form:"client_secret"`
+}
+
+// RevokeRequestDTO запрос отзыва токена (RFC 7009, раздел 2.1)
+type RevokeRequestDTO struct {
+	Token         string `form:"token"`
+	TokenTypeHint string `form:"token_type_hint"`
+	ClientID      string `form:"client_id"`
+	ClientSecret  string `form:"client_secret"`
+}
+
+// IntrospectResponseDTO ответ интроспекции (RFC 7662, раздел 2.2).
+// Для недействительного токена возвращается только active=false
+type IntrospectResponseDTO struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	Exp       int64  `json:"exp,omitempty"`
+	Iat       int64  `json:"iat,omitempty"`
+	Sub       string `json:"sub,omitempty"`
+	Iss       string `json:"iss,omitempty"`
+	Jti       string `json:"jti,omitempty"`
+	Role      string `json:"role,omitempty"`
+	SessionID string `json:"sid,omitempty"`
+	// Активная организация, в которой действует role
+	Organization string `json:"org,omitempty"`
+}
+
+type OAuthErrorResponseDTO struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+func (dto *OAuthClientRequestDTO) ToModel(clientID string) *entities.OAuthClient {
+	client := &entities.OAuthClient{
+		ClientID:     clientID,
+		Name:         dto.Name,
+		RedirectURIs: dto.RedirectURIs,
+		Scopes:       dto.Scopes,
+		GrantTypes:   dto.GrantTypes,
+		Confidential: dto.Confidential,
+		Role:         dto.Role,
+	}
+	if len(client.GrantTypes) == 0 {
+		client.GrantTypes = []string{"authorization_code", "refresh_token"}
+	}
+	return client
+}
+
+func (dto *OAuthClientResponseDTO) FromModel(client *entities.OAuthClient) {
+	dto.ID = client.ID
+	dto.ClientID = client.ClientID
+	dto.Name = client.Name
+	dto.RedirectURIs = client.RedirectURIs
+	dto.Scopes = client.Scopes
+	dto.GrantTypes = client.GrantTypes
+	dto.Confidential = client.Confidential
+	dto.Role = client.Role
+	dto.CreatedAt = client.CreatedAt
+}
@@ -0,0 +1,58 @@
+package dto
+
+import (
+	"strings"
+)
+
+// UserInfoDTO стандартные claims пользователя OpenID Connect (OIDC Core, раздел 5.1)
+type UserInfoDTO struct {
+	Sub                 string `json:"sub"`
+	Name                string `json:"name,omitempty"`
+	GivenName           string `json:"given_name,omitempty"`
+	FamilyName          string `json:"family_name,omitempty"`
+	MiddleName          string `json:"middle_name,omitempty"`
+	Picture             string `json:"picture,omitempty"`
+	UpdatedAt           int64  `json:"updated_at,omitempty"`
+	PhoneNumber         string `json:"phone_number,omitempty"`
+	PhoneNumberVerified *bool  `json:"phone_number_verified,omitempty"`
+}
+
+// OpenIDConfigurationDTO метаданные провайдера (OIDC Discovery, раздел 3)
+type OpenIDConfigurationDTO struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	IntrospectionEndpoint             string   `json:"introspection_endpoint"`
+	RevocationEndpoint                string   `json:"revocation_endpoint"`
+	UserinfoEndpoint                  string   `json:"userinfo_endpoint"`
+	JwksURI                           string   `json:"jwks_uri"`
+	ScopesSupported                   []string `json:"scopes_supported"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+	ClaimsSupported                   []string `json:"claims_supported"`
+}
+
+// FromUser заполняет claims по профилю пользователя с учётом выданных scope
+func (dto *UserInfoDTO) FromUser(user *UserResponseDTO, scopes []string) {
+	dto.Sub = user.ID.String()
+
+	for _, scope := range scopes {
+		switch scope {
+		case "profile":
+			dto.GivenName = user.FirstName
+			dto.FamilyName = user.LastName
+			dto.MiddleName = user.MiddleName
+			dto.Name = strings.Join(strings.Fields(user.FirstName+" "+user.MiddleName+" "+user.LastName), " ")
+			dto.Picture = user.Photo
+			dto.UpdatedAt = user.UpdatedAt.Unix()
+		case "phone":
+			verified := user.PhoneVerified
+			dto.PhoneNumber = user.Phone
+			dto.PhoneNumberVerified = &verified
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package dto
+
+import (
+	"gold_portal/internal/domain/entities"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type OrganizationRequestDTO struct {
+	Slug      string `json:"slug" binding:"required" example:"acme"`
+	Name      string `json:"name" binding:"required,max=255" example:"ACME"`
+	UserAgent string `json:"-"`
+	ClientIP  string `json:"-"`
+}
+
+type OrganizationResponseDTO struct {
+	ID        uuid.UUID `json:"id"`
+	Slug      string    `json:"slug"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// OrganizationMemberRequestDTO добавление пользователя в организацию или смена его роли в ней
+type OrganizationMemberRequestDTO struct {
+	UserID    uuid.UUID     `json:"user_id" binding:"required"`
+	Role      entities.Role `json:"role" binding:"required" example:"manager"`
+	UserAgent string        `json:"-"`
+	ClientIP  string        `json:"-"`
+}
+
+// OrganizationMembershipDTO организация пользователя и его роль в ней
+type OrganizationMembershipDTO struct {
+	OrganizationResponseDTO
+	Role   entities.Role `json:"role"`
+	Active bool          `json:"active"` // Активная организация текущей сессии
+}
+
+type OrganizationSwitchRequestDTO struct {
+	OrganizationID uuid.UUID `json:"organization_id" binding:"required"`
+}
+
+func (dto *OrganizationResponseDTO) FromModel(organization *entities.Organization) {
+	dto.ID = organization.ID
+	dto.Slug = organization.Slug
+	dto.Name = organization.Name
+	dto.CreatedAt = organization.CreatedAt
+}
@@ -0,0 +1,60 @@
+package dto
+
+import (
+	"gold_portal/internal/domain/entities"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RoleRequestDTO struct {
+	Name        entities.Role `json:"name" binding:"required" example:"support"`
+	Description string        `json:"description" example:"Служба поддержки"`
+	Rank        int           `json:"rank" binding:"min=0" example:"150"`
+	Permissions []string      `json:"permissions" example:"users:read"`
+	UserAgent   string        `json:"-"`
+	ClientIP    string        `json:"-"`
+}
+
+// RoleUpdateDTO изменения роли; отсутствующие поля не меняются
+type RoleUpdateDTO struct {
+	Name        *entities.Role `json:"name"`
+	Description *string        `json:"description"`
+	Rank        *int           `json:"rank" binding:"omitempty,min=0"`
+	// Новый набор прав целиком
+	Permissions *[]string `json:"permissions"`
+	UserAgent   string    `json:"-"`
+	ClientIP    string    `json:"-"`
+}
+
+type RoleResponseDTO struct {
+	ID          uuid.UUID     `json:"id"`
+	Name        entities.Role `json:"name"`
+	Description string        `json:"description"`
+	Rank        int           `json:"rank"`
+	BuiltIn     bool          `json:"built_in"`
+	Permissions []string      `json:"permissions"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+}
+
+type PermissionResponseDTO struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+func (dto *RoleResponseDTO) FromModel(role *entities.RoleDefinition, permissions []string) {
+	dto.ID = role.ID
+	dto.Name = role.Name
+	dto.Description = role.Description
+	dto.Rank = role.Rank
+	dto.BuiltIn = role.BuiltIn
+	dto.Permissions = permissions
+	dto.CreatedAt = role.CreatedAt
+	dto.UpdatedAt = role.UpdatedAt
+}
+
+func (dto *PermissionResponseDTO) FromModel(permission *entities.Permission) {
+	dto.Name = permission.Name
+	dto.Description = permission.Description
+}
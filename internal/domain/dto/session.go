@@ -0,0 +1,31 @@
+package dto
+
+import (
+	"gold_portal/internal/domain/entities"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SessionResponseDTO struct {
+	ID         uuid.UUID `json:"id"`
+	UserID     uuid.UUID `json:"user_id"`
+	UserAgent  string    `json:"user_agent"`
+	ClientIP   string    `json:"client_ip"`
+	ClientID   string    `json:"client_id,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+}
+
+func (dto *SessionResponseDTO) FromModel(session *entities.Session) {
+	dto.ID = session.ID
+	dto.UserID = session.UserID
+	dto.UserAgent = session.UserAgent
+	dto.ClientIP = session.ClientIP
+	dto.ClientID = session.ClientID
+	dto.CreatedAt = session.CreatedAt
+	dto.LastSeenAt = session.LastSeenAt
+	dto.ExpiresAt = session.ExpiresAt
+}
@@ -0,0 +1,30 @@
+package dto
+
+// TwoFactorEnrollResponseDTO данные для добавления аккаунта в приложение-аутентификатор
+type TwoFactorEnrollResponseDTO struct {
+	Secret string `json:"secret" example:"JBSWY3DPEHPK3PXP"`
+	URI    string `json:"otpauth_uri" example:"otpauth://totp/Gold%20Portal:+996500500500?secret=JBSWY3DPEHPK3PXP&issuer=Gold%20Portal"`
+	// QR код с otpauth URI в формате PNG (data URI)
+	QRCode string `json:"qr_code"`
+}
+
+// TwoFactorCodeRequestDTO код из приложения-аутентификатора или код восстановления
+type TwoFactorCodeRequestDTO struct {
+	Code string `json:"code" binding:"required" example:"123456"`
+}
+
+// RecoveryCodesResponseDTO коды восстановления показываются только один раз
+type RecoveryCodesResponseDTO struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// MFAVerifyRequestDTO второй шаг входа
+type MFAVerifyRequestDTO struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required" example:"123456"`
+
+	// Приложение OAuth, для которого начат вход, и данные устройства заполняются обработчиком
+	ClientID  string `json:"-"`
+	UserAgent string `json:"-"`
+	ClientIP  string `json:"-"`
+}
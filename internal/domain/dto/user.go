@@ -40,17 +40,24 @@ type UserDashboardDTO struct {
 }
 
 type UserResponseDTO struct {
-	ID         uuid.UUID     `json:"id"`
-	FirstName  string        `json:"first_name"`
-	LastName   string        `json:"last_name"`
-	MiddleName string        `json:"middle_name"`
-	Phone      string        `json:"phone"`
-	Role       entities.Role `json:"role"`
-	Photo      string        `json:"photo"`
-	IsActive   bool          `json:"is_active"`
-	CreatedAt  time.Time     `json:"created_at"`
-	UpdatedAt  time.Time     `json:"updated_at"`
-	DeletedAt  *time.Time    `json:"deleted_at"`
+	ID            uuid.UUID     `json:"id"`
+	FirstName     string        `json:"first_name"`
+	LastName      string        `json:"last_name"`
+	MiddleName    string        `json:"middle_name"`
+	Phone         string        `json:"phone"`
+	PhoneVerified bool          `json:"phone_verified"`
+	Role          entities.Role `json:"role"`
+	Photo         string        `json:"photo"`
+	IsActive      bool          `json:"is_active"`
+	TOTPEnabled   bool          `json:"two_factor_enabled"`
+	CreatedAt     time.Time     `json:"created_at"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+	DeletedAt     *time.Time    `json:"deleted_at"`
+
+	// Активная организация текущего пользователя; role — роль в ней
+	OrganizationID *uuid.UUID `json:"organization_id,omitempty"`
+	// Группы пользователя в активной организации; заполняются только в профиле
+	Groups []GroupSummaryDTO `json:"groups,omitempty"`
 }
 
 type UserUpdateDTO struct {
@@ -61,24 +68,85 @@ type UserUpdateDTO struct {
 	Role       *entities.Role `json:"role" default:"user"`
 	Photo      *string        `json:"photo"`
 	IsActive   *bool          `json:"is_active"`
+
+	// Данные администратора для аудита, заполняются обработчиком
+	UserAgent string `json:"-"`
+	ClientIP  string `json:"-"`
+}
+
+// UserPatchResponseDTO пользователь после изменения. SessionsRevoked = true, если смена роли
+// или блокировка вывели пользователя со всех устройств
+type UserPatchResponseDTO struct {
+	UserResponseDTO
+	SessionsRevoked bool `json:"sessions_revoked"`
 }
 
 type LoginRequestDTO struct {
 	Phone    string `json:"phone" validate:"required,e164" example:"+996500500500"`
 	Password string `json:"password" validate:"required,min=8" example:"Password123"`
+
+	// Данные устройства для сессии, заполняются обработчиком
+	UserAgent string `json:"-"`
+	ClientIP  string `json:"-"`
+	// Приложение OAuth и запрошенные scope при делегированном входе
+	ClientID string `json:"-"`
+	Scope    string `json:"-"`
+}
+
+// OTPSendRequestDTO запрос кода из SMS для входа
+type OTPSendRequestDTO struct {
+	Phone string `json:"phone" binding:"required" validate:"required,e164" example:"+996500500500"`
+}
+
+// OTPLoginRequestDTO вход по коду из SMS без пароля
+type OTPLoginRequestDTO struct {
+	Phone string `json:"phone" binding:"required" validate:"required,e164" example:"+996500500500"`
+	Code  string `json:"code" binding:"required" example:"123456"`
+
+	// Данные устройства для сессии, заполняются обработчиком
+	UserAgent string `json:"-"`
+	ClientIP  string `json:"-"`
+}
+
+// PhoneVerifyRequestDTO код подтверждения номера из SMS
+type PhoneVerifyRequestDTO struct {
+	Code string `json:"code" binding:"required" example:"123456"`
+}
+
+// PasswordForgotRequestDTO запрос кода для сброса пароля
+type PasswordForgotRequestDTO struct {
+	Phone string `json:"phone" binding:"required" validate:"required,e164" example:"+996500500500"`
+}
+
+// PasswordResetRequestDTO новый пароль по коду из SMS
+type PasswordResetRequestDTO struct {
+	Phone           string `json:"phone" binding:"required" validate:"required,e164" example:"+996500500500"`
+	Code            string `json:"code" binding:"required" example:"123456"`
+	NewPassword     string `json:"new_password" binding:"required" validate:"required"`
+	ConfirmPassword string `json:"confirm_password" binding:"required,eqfield=NewPassword" validate:"required,eqfield=NewPassword"`
 }
 
 type LoginResponseDTO struct {
 	AccessToken  string `json:"access_token"`
 	RefreshToken string `json:"refresh_token"`
 	Message      string `json:"message"`
+
+	// Если включена 2FA, токены не выдаются до ввода кода по MFAToken
+	MFARequired bool   `json:"mfa_required,omitempty"`
+	MFAToken    string `json:"mfa_token,omitempty"`
+
+	UserID    uuid.UUID `json:"-"`
+	SessionID uuid.UUID `json:"-"`
+	// Пройденные способы аутентификации, если токены выдаются позже (вход через OAuth)
+	AuthMethods []string `json:"-"`
 }
 
 type TokenResponseDTO struct {
-	AccessToken string          `json:"access_token"`
-	User        UserResponseDTO `json:"user"`
-	ExpiresAt   time.Time       `json:"expires_at"`
-	Message     string          `json:"message,omitempty"`
+	AccessToken  string          `json:"access_token"`
+	RefreshToken string          `json:"refresh_token"`
+	User         UserResponseDTO `json:"user"`
+	ExpiresAt    time.Time       `json:"expires_at"`
+	Message      string          `json:"message,omitempty"`
 }
 
 type RefreshTokenRequest struct {
@@ -86,14 +154,22 @@ type RefreshTokenRequest struct {
 }
 
 type ChangePasswordDashboardDTO struct {
-	NewPassword     string `json:"new_password" validate:"required,min=8"`
-	ConfirmPassword string `json:"confirm_password" validate:"required,eqfield=NewPassword"`
+	NewPassword     string `json:"new_password" binding:"required" validate:"required"`
+	ConfirmPassword string `json:"confirm_password" binding:"required,eqfield=NewPassword" validate:"required,eqfield=NewPassword"`
+
+	// Данные запроса для аудита, заполняются обработчиком
+	UserAgent string `json:"-"`
+	ClientIP  string `json:"-"`
 }
 
 type ChangePasswordDTO struct {
-	OldPassword     string `json:"old_password" validate:"required"`
-	NewPassword     string `json:"new_password" validate:"required,min=8"`
-	ConfirmPassword string `json:"confirm_password" validate:"required,eqfield=NewPassword"`
+	OldPassword     string `json:"old_password" binding:"required" validate:"required"`
+	NewPassword     string `json:"new_password" binding:"required" validate:"required"`
+	ConfirmPassword string `json:"confirm_password" binding:"required,eqfield=NewPassword" validate:"required,eqfield=NewPassword"`
+
+	// Данные запроса для аудита, заполняются обработчиком
+	UserAgent string `json:"-"`
+	ClientIP  string `json:"-"`
 }
 
 func (dto *UserResponseDTO) FromModel(user *entities.User) {
@@ -102,9 +178,11 @@ func (dto *UserResponseDTO) FromModel(user *entities.User) {
 	dto.LastName = user.LastName
 	dto.MiddleName = user.MiddleName
 	dto.Phone = user.Phone
+	dto.PhoneVerified = user.PhoneVerified
 	dto.Role = user.Role
 	dto.Photo = user.Photo
 	dto.IsActive = user.IsActive
+	dto.TOTPEnabled = user.TOTPEnabled
 	dto.CreatedAt = user.CreatedAt
 	dto.UpdatedAt = user.UpdatedAt
 	if user.DeletedAt.Valid {
@@ -121,8 +199,10 @@ func (dto *UserResponseDTO) FromModelUser(user *entities.User) {
 	dto.LastName = user.LastName
 	dto.MiddleName = user.MiddleName
 	dto.Phone = user.Phone
+	dto.PhoneVerified = user.PhoneVerified
 	dto.Photo = user.Photo
 	dto.IsActive = user.IsActive
+	dto.TOTPEnabled = user.TOTPEnabled
 	dto.CreatedAt = user.CreatedAt
 	dto.UpdatedAt = user.UpdatedAt
 	if user.DeletedAt.Valid {
@@ -138,7 +218,7 @@ func (dto *UserDashboardDTO) ToModel(hashedPassword string) *entities.User {
 		FirstName:  dto.FirstName,
 		LastName:   dto.LastName,
 		MiddleName: dto.MiddleName,
-		Password:   dto.Password,
+		Password:   hashedPassword,
 		Phone:      dto.Phone,
 		Role:       dto.Role,
 		Photo:      dto.Photo,
@@ -156,7 +236,7 @@ func (dto *UserRequestDTO) ToModelUser(hashedPassword string) *entities.User {
 		FirstName:  dto.FirstName,
 		LastName:   dto.LastName,
 		MiddleName: dto.MiddleName,
-		Password:   dto.Password,
+		Password:   hashedPassword,
 		Phone:      dto.Phone,
 		Photo:      dto.Photo,
 		IsActive:   true,
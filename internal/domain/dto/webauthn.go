@@ -0,0 +1,45 @@
+package dto
+
+import (
+	"encoding/json"
+	"gold_portal/internal/domain/entities"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WebAuthnRegisterFinishRequestDTO ответ аутентификатора на navigator.credentials.create()
+type WebAuthnRegisterFinishRequestDTO struct {
+	Name       string          `json:"name" example:"MacBook"`
+	Credential json.RawMessage `json:"credential" binding:"required" swaggertype:"object"`
+}
+
+// WebAuthnLoginBeginResponseDTO параметры для navigator.credentials.get()
+type WebAuthnLoginBeginResponseDTO struct {
+	// Идентификатор церемонии, его нужно вернуть в login/finish
+	ChallengeID string      `json:"challenge_id"`
+	Options     interface{} `json:"options" swaggertype:"object"`
+}
+
+// WebAuthnLoginFinishRequestDTO ответ аутентификатора на navigator.credentials.get()
+type WebAuthnLoginFinishRequestDTO struct {
+	ChallengeID string          `json:"challenge_id" binding:"required"`
+	Credential  json.RawMessage `json:"credential" binding:"required" swaggertype:"object"`
+
+	UserAgent string `json:"-"`
+	ClientIP  string `json:"-"`
+}
+
+type WebAuthnCredentialResponseDTO struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
+
+func (dto *WebAuthnCredentialResponseDTO) FromModel(credential *entities.WebAuthnCredential) {
+	dto.ID = credential.ID
+	dto.Name = credential.Name
+	dto.CreatedAt = credential.CreatedAt
+	dto.LastUsedAt = credential.LastUsedAt
+}
@@ -0,0 +1,75 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Префикс всех API-ключей: по нему сканеры секретов находят утёкшие ключи
+const APIKeyPrefix = "gpk_"
+
+// Права API-ключа. Ключ действует с ролью владельца, scope лишь сужают доступ
+const (
+	APIKeyScopeProfileRead = "profile:read"
+	APIKeyScopeUsersRead   = "users:read"
+	APIKeyScopeUsersWrite  = "users:write"
+	APIKeyScopeAuditRead   = "audit:read"
+)
+
+var APIKeyScopes = []string{
+	APIKeyScopeProfileRead,
+	APIKeyScopeUsersRead,
+	APIKeyScopeUsersWrite,
+	APIKeyScopeAuditRead,
+}
+
+// APIKey долгоживущий ключ пользователя для скриптов и интеграций. Сам ключ
+// показывается один раз при создании, хранится только его хэш
+type APIKey struct {
+	ID     uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserID uuid.UUID `gorm:"type:uuid;index;not null"`
+	Name   string    `gorm:"type:varchar(255);not null"`
+	// Организация, в которой создан ключ: ключ действует с ролью владельца в ней
+	OrganizationID uuid.UUID `gorm:"type:uuid"`
+	// Открытое начало ключа (gpk_ и идентификатор): по нему ключ ищется и узнаётся в списке
+	Prefix  string   `gorm:"uniqueIndex;not null"`
+	KeyHash string   `gorm:"not null"`
+	Scopes  []string `gorm:"serializer:json"`
+	// Версия токенов владельца при создании: после выхода со всех устройств ключ перестаёт действовать
+	TokenVersion int `gorm:"not null;default:0"`
+
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	LastUsedIP string
+	CreatedAt  time.Time
+	RevokedAt  *time.Time
+}
+
+// IsActive проверяет, что ключ не отозван и не истёк
+func (k *APIKey) IsActive() bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || time.Now().Before(*k.ExpiresAt)
+}
+
+// HasScope проверяет, что ключу выдано право scope
+func (k *APIKey) HasScope(scope string) bool {
+	for _, keyScope := range k.Scopes {
+		if keyScope == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAPIKeyScope проверяет, что scope из списка известных прав API-ключей
+func IsAPIKeyScope(scope string) bool {
+	for _, known := range APIKeyScopes {
+		if known == scope {
+			return true
+		}
+	}
+	return false
+}
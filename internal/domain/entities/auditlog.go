@@ -7,14 +7,18 @@ import (
 )
 
 type AuditLog struct {
-	ID        uint      `gorm:"primaryKey"`
-	UserID    uuid.UUID `gorm:"type:uuid"`
-	Action    string
-	Entity    string
-	EntityID  uuid.UUID `gorm:"type:uuid"`
-	ClientIP  string
-	UserAgent string
-	Data      string
-	Status    int
-	CreatedAt time.Time
+	ID     uint      `gorm:"primaryKey"`
+	UserID uuid.UUID `gorm:"type:uuid"`
+	// API-ключ, которым аутентифицирован запрос; nil — вход по токену
+	APIKeyID *uuid.UUID `gorm:"type:uuid;index"`
+	// Организация, в которой выполнено действие; nil — действие вне организации
+	OrganizationID *uuid.UUID `gorm:"type:uuid;index"`
+	Action         string
+	Entity         string
+	EntityID       uuid.UUID `gorm:"type:uuid"`
+	ClientIP       string
+	UserAgent      string
+	Data           string
+	Status         int
+	CreatedAt      time.Time
 }
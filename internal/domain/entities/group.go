@@ -0,0 +1,39 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Group группа пользователей организации. Роли (GroupRole) и права (GroupPermission),
+// выданные группе, действуют у всех её участников в дополнение к роли в организации
+type Group struct {
+	ID             uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	OrganizationID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_groups_organization_name"`
+	Name           string    `gorm:"type:varchar(100);not null;uniqueIndex:idx_groups_organization_name"`
+	Description    string
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// GroupMember участник группы. Состоять в группе может только участник её организации
+type GroupMember struct {
+	GroupID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	UserID  uuid.UUID `gorm:"type:uuid;primaryKey;index"`
+
+	CreatedAt time.Time
+}
+
+// GroupRole роль, выданная группе: участники получают все права роли
+type GroupRole struct {
+	GroupID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Role    Role      `gorm:"type:varchar(50);primaryKey"`
+}
+
+// GroupPermission право, выданное группе напрямую
+type GroupPermission struct {
+	GroupID    uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Permission string    `gorm:"type:varchar(100);primaryKey"`
+}
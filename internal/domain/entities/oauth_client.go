@@ -0,0 +1,68 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OAuthClient зарегистрированное приложение, которому разрешён делегированный вход
+type OAuthClient struct {
+	ID           uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	ClientID     string    `gorm:"uniqueIndex;not null"`
+	Name         string    `gorm:"type:varchar(255);not null"`
+	RedirectURIs []string  `gorm:"serializer:json"`
+	Scopes       []string  `gorm:"serializer:json"`
+	GrantTypes   []string  `gorm:"serializer:json"`
+
+	// Конфиденциальный клиент аутентифицируется секретом, хранится только его хэш
+	Confidential bool
+	SecretHash   string
+	// Роль, с которой сервисный клиент вызывает API по client_credentials
+	Role Role `gorm:"type:varchar(50)"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// HasRedirectURI проверяет точное совпадение redirect_uri с зарегистрированным
+func (c *OAuthClient) HasRedirectURI(redirectURI string) bool {
+	for _, uri := range c.RedirectURIs {
+		if uri == redirectURI {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsScopes проверяет, что все запрошенные scope разрешены клиенту
+func (c *OAuthClient) AllowsScopes(scopes []string) bool {
+	for _, scope := range scopes {
+		allowed := false
+		for _, clientScope := range c.Scopes {
+			if scope == clientScope {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return false
+		}
+	}
+	return true
+}
+
+// AllowsGrantType проверяет, что клиенту разрешён указанный grant_type.
+// Клиенты, зарегистрированные до появления grant_types, считаются клиентами authorization_code
+func (c *OAuthClient) AllowsGrantType(grantType string) bool {
+	grantTypes := c.GrantTypes
+	if len(grantTypes) == 0 {
+		grantTypes = []string{"authorization_code", "refresh_token"}
+	}
+	for _, allowed := range grantTypes {
+		if allowed == grantType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,34 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultOrganizationSlug организация, которая создаётся при первом запуске. В неё попадают
+// пользователи, зарегистрированные до появления организаций, и самостоятельная регистрация
+const DefaultOrganizationSlug = "default"
+
+// Organization клиентский портал. Учётная запись пользователя одна на все организации
+// (телефон уникален глобально), а доступ и роль задаются членством (OrganizationMember)
+type Organization struct {
+	ID   uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	Slug string    `gorm:"type:varchar(63);uniqueIndex;not null"`
+	Name string    `gorm:"type:varchar(255);not null"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// OrganizationMember членство пользователя в организации с ролью в ней. Роль superuser
+// в организации совпадает с ролью пользователя (User.Role) и даёт доступ ко всем организациям
+type OrganizationMember struct {
+	OrganizationID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	UserID         uuid.UUID `gorm:"type:uuid;primaryKey;index"`
+	Role           Role      `gorm:"type:varchar(50);not null"`
+
+	Organization Organization `gorm:"foreignKey:OrganizationID;constraint:OnDelete:CASCADE"`
+
+	CreatedAt time.Time
+}
@@ -0,0 +1,16 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PasswordHistory хэш одного из прежних паролей пользователя: не даёт вернуться к недавним паролям
+type PasswordHistory struct {
+	ID           uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserID       uuid.UUID `gorm:"type:uuid;index;not null"`
+	PasswordHash string    `gorm:"not null"`
+
+	CreatedAt time.Time
+}
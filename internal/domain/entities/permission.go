@@ -0,0 +1,71 @@
+package entities
+
+import "time"
+
+// Права на действия в API. Набор прав роли хранится в базе (RolePermission)
+const (
+	PermissionUsersRead           = "users:read"
+	PermissionUsersCreate         = "users:create"
+	PermissionUsersUpdate         = "users:update"
+	PermissionUsersDelete         = "users:delete"
+	PermissionUsersSessions       = "users:sessions"
+	PermissionUsersPassword       = "users:password"
+	PermissionUsersUnlock         = "users:unlock"
+	PermissionAuditRead           = "audit:read"
+	PermissionOAuthClientsManage  = "oauth_clients:manage"
+	PermissionRolesManage         = "roles:manage"
+	PermissionOrganizationsManage = "organizations:manage"
+	PermissionGroupsManage        = "groups:manage"
+)
+
+// Permission право, которое можно выдать роли
+type Permission struct {
+	Name        string `gorm:"type:varchar(100);primaryKey"`
+	Description string
+
+	CreatedAt time.Time
+}
+
+// RolePermission право, выданное роли
+type RolePermission struct {
+	Role       Role   `gorm:"type:varchar(50);primaryKey"`
+	Permission string `gorm:"type:varchar(100);primaryKey"`
+
+	CreatedAt time.Time
+}
+
+// DefaultPermissions права, которые создаются при первом запуске
+var DefaultPermissions = []Permission{
+	{Name: PermissionUsersRead, Description: "Просмотр пользователей и их сессий"},
+	{Name: PermissionUsersCreate, Description: "Регистрация пользователей"},
+	{Name: PermissionUsersUpdate, Description: "Изменение данных и роли пользователей"},
+	{Name: PermissionUsersDelete, Description: "Удаление пользователей"},
+	{Name: PermissionUsersSessions, Description: "Завершение сессий пользователей"},
+	{Name: PermissionUsersPassword, Description: "Смена пароля пользователям"},
+	{Name: PermissionUsersUnlock, Description: "Снятие блокировки входа"},
+	{Name: PermissionAuditRead, Description: "Просмотр журнала аудита"},
+	{Name: PermissionOAuthClientsManage, Description: "Управление клиентами OAuth"},
+	{Name: PermissionRolesManage, Description: "Управление ролями и их правами"},
+	{Name: PermissionOrganizationsManage, Description: "Управление организациями и их участниками"},
+	{Name: PermissionGroupsManage, Description: "Управление группами, их участниками и правами"},
+}
+
+// DefaultRolePermissions права встроенных ролей. Право выдаётся, когда оно впервые
+// появляется в базе; дальше права ролей меняются через API
+var DefaultRolePermissions = map[Role][]string{
+	RoleSuperUser: {
+		PermissionUsersRead, PermissionUsersCreate, PermissionUsersUpdate, PermissionUsersDelete,
+		PermissionUsersSessions, PermissionUsersPassword, PermissionUsersUnlock,
+		PermissionAuditRead, PermissionOAuthClientsManage, PermissionRolesManage,
+		PermissionOrganizationsManage, PermissionGroupsManage,
+	},
+	RoleAdmin: {
+		PermissionUsersRead, PermissionUsersCreate, PermissionUsersUpdate, PermissionUsersDelete,
+		PermissionUsersSessions, PermissionUsersPassword, PermissionUsersUnlock,
+		PermissionAuditRead, PermissionGroupsManage,
+	},
+	RoleManager: {
+		PermissionUsersRead, PermissionUsersUnlock,
+	},
+	RoleUser: {},
+}
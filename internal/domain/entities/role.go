@@ -1,5 +1,14 @@
 package entities
 
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Role название роли пользователя. Роли хранятся в базе (RoleDefinition);
+// встроенные создаются при первом запуске и не переименовываются и не удаляются
 type Role string
 
 const (
@@ -9,32 +18,41 @@ const (
 	RoleUser      Role = "user"
 )
 
-func (r Role) IsValid() bool {
-	switch r {
-	case RoleSuperUser, RoleAdmin, RoleUser, RoleManager:
-		return true
-	}
-	return false
-}
-
 func (r Role) String() string {
 	return string(r)
 }
 
-func (r Role) Level() int {
-	switch r {
-	case RoleSuperUser:
-		return 4
-	case RoleAdmin:
-		return 3
-	case RoleManager:
-		return 2
-	case RoleUser:
-		return 1
-	}
-	return 0
+// RoleDefinition роль с набором прав (RolePermission) и рангом. Назначить роль можно
+// только пользователю с рангом не ниже её ранга
+type RoleDefinition struct {
+	ID          uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	Name        Role      `gorm:"type:varchar(50);uniqueIndex;not null"`
+	Description string
+	Rank        int  `gorm:"not null;default:0"`
+	BuiltIn     bool `gorm:"not null;default:false"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (RoleDefinition) TableName() string {
+	return "roles"
+}
+
+// BuiltInRoles роли, которые создаются при первом запуске
+var BuiltInRoles = []RoleDefinition{
+	{Name: RoleSuperUser, Description: "Суперпользователь", Rank: 400, BuiltIn: true},
+	{Name: RoleAdmin, Description: "Администратор", Rank: 300, BuiltIn: true},
+	{Name: RoleManager, Description: "Менеджер", Rank: 200, BuiltIn: true},
+	{Name: RoleUser, Description: "Пользователь", Rank: 100, BuiltIn: true},
 }
 
-func (r Role) HasLevel(minLevel int) bool {
-	return r.Level() >= minLevel
+// RoleExists проверяет, что роль есть в базе. Используется хуками GORM,
+// поэтому принимает соединение текущей операции
+func RoleExists(tx *gorm.DB, role Role) (bool, error) {
+	var count int64
+	err := tx.Session(&gorm.Session{NewDB: true}).Model(&RoleDefinition{}).
+		Where("name = ?", role).
+		Count(&count).Error
+	return count > 0, err
 }
@@ -0,0 +1,33 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Session серверная запись о входе пользователя с конкретного устройства
+type Session struct {
+	ID              uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserID          uuid.UUID `gorm:"type:uuid;index;not null"`
+	UserAgent       string
+	ClientIP        string
+	RefreshFamilyID string `gorm:"index;not null"`
+	// Приложение OAuth, через которое выполнен вход, и выданные ему scope
+	ClientID string `gorm:"index"`
+	Scope    string
+	// Активная организация: попадает в claim org и меняется переключением организации
+	OrganizationID uuid.UUID `gorm:"type:uuid;index"`
+	// Пройденные способы аутентификации (amr, RFC 8176): pwd, otp
+	AuthMethods []string `gorm:"serializer:json"`
+
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+	ExpiresAt  time.Time
+	RevokedAt  *time.Time
+}
+
+// IsActive проверяет, что сессия не отозвана и не истекла
+func (s *Session) IsActive() bool {
+	return s.RevokedAt == nil && time.Now().Before(s.ExpiresAt)
+}
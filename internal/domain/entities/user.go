@@ -15,62 +15,63 @@ type User struct {
 	LastName   string    `gorm:"type:varchar(255);omitempty"`
 	MiddleName string    `gorm:"type:varchar(255);omitempty"`
 	Phone      string    `gorm:"unique;not null" validate:"required,e164"`
-	Password   string    `gorm:"not null" validate:"required,min=8"`
-	Role       Role      `gorm:"default:user" validate:"required"`
-	Photo      string    `validate:"omitempty,url"`
+	// Номер подтверждён кодом из SMS; сбрасывается при смене номера
+	PhoneVerified bool   `gorm:"default:false"`
+	Password      string `gorm:"not null" validate:"required,min=8"`
+	// Роль на уровне платформы: superuser действует во всех организациях, у остальных — user.
+	// Роль в организации хранится в OrganizationMember
+	Role  Role   `gorm:"default:user" validate:"required"`
+	Photo string `validate:"omitempty,url"`
 
 	IsActive bool `gorm:"default:true"`
 
+	// Секрет TOTP хранится зашифрованным; до подтверждения кода TOTPEnabled = false
+	TOTPSecret  string
+	TOTPEnabled bool `gorm:"default:false"`
+
+	// Версия токенов: попадает в claim ver и увеличивается при выходе со всех устройств,
+	// после чего все ранее выданные токены отклоняются
+	TokenVersion int `gorm:"not null;default:0"`
+
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	DeletedAt gorm.DeletedAt `gorm:"index"`
 }
 
-// BeforeCreate хук GORM - автоматически hash пароль перед созданием
-func (u *User) BeforeCreate(*gorm.DB) error {
+// BeforeCreate хук GORM. Пароль хэширует сервисный слой: в модель попадает только хэш
+func (u *User) BeforeCreate(tx *gorm.DB) error {
 	// Генерируем UUID если он не установлен
 	if u.ID == uuid.Nil {
 		u.ID = uuid.New()
 	}
 
-	// Hash пароль если он не пустой
-	if u.Password != "" {
-		hashedPassword, err := crypto.HashPassword(u.Password)
-		if err != nil {
-			return err
-		}
-		u.Password = hashedPassword
-	}
-
 	// Устанавливаем роль по умолчанию
 	if u.Role == "" {
 		u.Role = RoleUser
 	}
 
-	// Проверяем валидность роли
-	if !u.Role.IsValid() {
-		return errors.ErrInvalidUserRole
+	// Проверяем, что роль есть в базе
+	return u.checkRole(tx)
+}
+
+// BeforeUpdate хук GORM - проверяет роль, если она изменилась
+func (u *User) BeforeUpdate(tx *gorm.DB) error {
+	// Проверяем роль при изменении
+	if tx.Statement.Changed("role") {
+		return u.checkRole(tx)
 	}
 
 	return nil
 }
 
-// BeforeUpdate хук GORM - hash пароль если он изменился
-func (u *User) BeforeUpdate(tx *gorm.DB) error {
-	// Если пароль изменился, hash его
-	if tx.Statement.Changed("Password") && u.Password != "" {
-		hashedPassword, err := crypto.HashPassword(u.Password)
-		if err != nil {
-			return err
-		}
-		u.Password = hashedPassword
+func (u *User) checkRole(tx *gorm.DB) error {
+	exists, err := RoleExists(tx, u.Role)
+	if err != nil {
+		return err
 	}
-
-	// Проверяем валидность роли при изменении
-	if tx.Statement.Changed("role") && !u.Role.IsValid() {
+	if !exists {
 		return errors.ErrInvalidUserRole
 	}
-
 	return nil
 }
 
@@ -0,0 +1,22 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/google/uuid"
+)
+
+// WebAuthnCredential ключ доступа (passkey), зарегистрированный пользователем
+type WebAuthnCredential struct {
+	ID           uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserID       uuid.UUID `gorm:"type:uuid;index;not null"`
+	CredentialID []byte    `gorm:"uniqueIndex;not null"`
+	// Название, которое пользователь дал ключу, например «MacBook»
+	Name string `gorm:"type:varchar(255)"`
+	// Публичный ключ, флаги и счётчик подписей аутентификатора
+	Credential webauthn.Credential `gorm:"serializer:json"`
+
+	CreatedAt  time.Time
+	LastUsedAt *time.Time
+}
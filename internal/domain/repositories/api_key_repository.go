@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"context"
+	stdErrors "errors"
+	"gold_portal/internal/domain/entities"
+	"gold_portal/internal/errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *entities.APIKey) error
+	GetID(ctx context.Context, id uuid.UUID) (*entities.APIKey, error)
+	FindByPrefix(ctx context.Context, prefix string) (*entities.APIKey, error)
+	// Возвращает неотозванные и неистёкшие ключи пользователя
+	GetActiveByUser(ctx context.Context, userID uuid.UUID) ([]*entities.APIKey, error)
+	Touch(ctx context.Context, id uuid.UUID, usedAt time.Time, clientIP string) error
+	Revoke(ctx context.Context, id uuid.UUID) error
+	// Отзывает все ключи пользователя
+	RevokeByUser(ctx context.Context, userID uuid.UUID) error
+}
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+func (repository *apiKeyRepository) Create(ctx context.Context, key *entities.APIKey) error {
+	return repository.db.WithContext(ctx).Create(key).Error
+}
+
+func (repository *apiKeyRepository) GetID(ctx context.Context, id uuid.UUID) (*entities.APIKey, error) {
+	var key entities.APIKey
+	err := repository.db.WithContext(ctx).First(&key, "id = ?", id).Error
+	if err != nil {
+		if stdErrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (repository *apiKeyRepository) FindByPrefix(ctx context.Context, prefix string) (*entities.APIKey, error) {
+	var key entities.APIKey
+	err := repository.db.WithContext(ctx).First(&key, "prefix = ?", prefix).Error
+	if err != nil {
+		if stdErrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (repository *apiKeyRepository) GetActiveByUser(ctx context.Context, userID uuid.UUID) ([]*entities.APIKey, error) {
+	var keys []*entities.APIKey
+	err := repository.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", userID, time.Now()).
+		Order("created_at desc").
+		Find(&keys).Error
+	return keys, err
+}
+
+func (repository *apiKeyRepository) Touch(ctx context.Context, id uuid.UUID, usedAt time.Time, clientIP string) error {
+	return repository.db.WithContext(ctx).Model(&entities.APIKey{}).
+		Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{
+			"last_used_at": usedAt,
+			"last_used_ip": clientIP,
+		}).Error
+}
+
+func (repository *apiKeyRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	return repository.db.WithContext(ctx).Model(&entities.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (repository *apiKeyRepository) RevokeByUser(ctx context.Context, userID uuid.UUID) error {
+	return repository.db.WithContext(ctx).Model(&entities.APIKey{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
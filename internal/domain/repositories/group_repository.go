@@ -0,0 +1,249 @@
+package repositories
+
+import (
+	"context"
+	stdErrors "errors"
+	"gold_portal/internal/domain/entities"
+	"gold_portal/internal/errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GroupRepository interface {
+	GetAll(ctx context.Context, organizationID uuid.UUID) ([]*entities.Group, error)
+	// Группа организации или ErrGroupNotFound, в том числе если группа из другой организации
+	GetID(ctx context.Context, organizationID, id uuid.UUID) (*entities.Group, error)
+	GetByName(ctx context.Context, organizationID uuid.UUID, name string) (*entities.Group, error)
+	// Создаёт группу вместе с её ролями и правами
+	Create(ctx context.Context, group *entities.Group, roles []entities.Role, permissions []string) error
+	// Сохраняет группу; roles и permissions, равные nil, остаются как есть
+	Update(ctx context.Context, group *entities.Group, roles []entities.Role, permissions []string) error
+	// Удаляет группу с участниками, ролями и правами
+	Delete(ctx context.Context, group *entities.Group) error
+
+	GetRoles(ctx context.Context, groupID uuid.UUID) ([]entities.Role, error)
+	GetPermissions(ctx context.Context, groupID uuid.UUID) ([]string, error)
+	GetMembers(ctx context.Context, groupID uuid.UUID) ([]uuid.UUID, error)
+	AddMember(ctx context.Context, groupID, userID uuid.UUID) error
+	RemoveMember(ctx context.Context, groupID, userID uuid.UUID) error
+
+	// Группы пользователя в организации
+	GetByUser(ctx context.Context, organizationID, userID uuid.UUID) ([]*entities.Group, error)
+	// Роли и права, выданные пользователю через группы организации
+	GetUserGrants(ctx context.Context, organizationID, userID uuid.UUID) ([]entities.Role, []string, error)
+	// Роли, выданные пользователю через группы во всех организациях
+	GetUserRoles(ctx context.Context, userID uuid.UUID) ([]entities.Role, error)
+}
+
+type groupRepository struct {
+	db *gorm.DB
+}
+
+func NewGroupRepository(db *gorm.DB) GroupRepository {
+	return &groupRepository{db: db}
+}
+
+func (repository *groupRepository) GetAll(ctx context.Context, organizationID uuid.UUID) ([]*entities.Group, error) {
+	var groups []*entities.Group
+	err := repository.db.WithContext(ctx).
+		Where("organization_id = ?", organizationID).
+		Order("name").
+		Find(&groups).Error
+	return groups, err
+}
+
+func (repository *groupRepository) GetID(ctx context.Context, organizationID, id uuid.UUID) (*entities.Group, error) {
+	var group entities.Group
+	err := repository.db.WithContext(ctx).First(&group, "organization_id = ? AND id = ?", organizationID, id).Error
+	if err != nil {
+		if stdErrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrGroupNotFound
+		}
+		return nil, err
+	}
+	return &group, nil
+}
+
+func (repository *groupRepository) GetByName(ctx context.Context, organizationID uuid.UUID, name string) (*entities.Group, error) {
+	var group entities.Group
+	err := repository.db.WithContext(ctx).First(&group, "organization_id = ? AND name = ?", organizationID, name).Error
+	if err != nil {
+		if stdErrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrGroupNotFound
+		}
+		return nil, err
+	}
+	return &group, nil
+}
+
+func (repository *groupRepository) Create(ctx context.Context, group *entities.Group, roles []entities.Role, permissions []string) error {
+	return repository.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(group).Error; err != nil {
+			return err
+		}
+		if err := replaceGroupRoles(tx, group.ID, roles); err != nil {
+			return err
+		}
+		return replaceGroupPermissions(tx, group.ID, permissions)
+	})
+}
+
+func (repository *groupRepository) Update(ctx context.Context, group *entities.Group, roles []entities.Role, permissions []string) error {
+	return repository.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&entities.Group{}).
+			Where("id = ?", group.ID).
+			Updates(map[string]interface{}{
+				"name":        group.Name,
+				"description": group.Description,
+				"updated_at":  group.UpdatedAt,
+			}).Error; err != nil {
+			return err
+		}
+		if roles != nil {
+			if err := replaceGroupRoles(tx, group.ID, roles); err != nil {
+				return err
+			}
+		}
+		if permissions != nil {
+			return replaceGroupPermissions(tx, group.ID, permissions)
+		}
+		return nil
+	})
+}
+
+func (repository *groupRepository) Delete(ctx context.Context, group *entities.Group) error {
+	return repository.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("group_id = ?", group.ID).Delete(&entities.GroupMember{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("group_id = ?", group.ID).Delete(&entities.GroupRole{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("group_id = ?", group.ID).Delete(&entities.GroupPermission{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&entities.Group{}, "id = ?", group.ID).Error
+	})
+}
+
+func (repository *groupRepository) GetRoles(ctx context.Context, groupID uuid.UUID) ([]entities.Role, error) {
+	var roles []entities.Role
+	err := repository.db.WithContext(ctx).Model(&entities.GroupRole{}).
+		Where("group_id = ?", groupID).
+		Order("role").
+		Pluck("role", &roles).Error
+	return roles, err
+}
+
+func (repository *groupRepository) GetPermissions(ctx context.Context, groupID uuid.UUID) ([]string, error) {
+	var permissions []string
+	err := repository.db.WithContext(ctx).Model(&entities.GroupPermission{}).
+		Where("group_id = ?", groupID).
+		Order("permission").
+		Pluck("permission", &permissions).Error
+	return permissions, err
+}
+
+func (repository *groupRepository) GetMembers(ctx context.Context, groupID uuid.UUID) ([]uuid.UUID, error) {
+	var members []uuid.UUID
+	err := repository.db.WithContext(ctx).Model(&entities.GroupMember{}).
+		Where("group_id = ?", groupID).
+		Order("created_at").
+		Pluck("user_id", &members).Error
+	return members, err
+}
+
+func (repository *groupRepository) AddMember(ctx context.Context, groupID, userID uuid.UUID) error {
+	member := &entities.GroupMember{GroupID: groupID, UserID: userID, CreatedAt: time.Now()}
+	return repository.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(member).Error
+}
+
+func (repository *groupRepository) RemoveMember(ctx context.Context, groupID, userID uuid.UUID) error {
+	return repository.db.WithContext(ctx).
+		Where("group_id = ? AND user_id = ?", groupID, userID).
+		Delete(&entities.GroupMember{}).Error
+}
+
+func (repository *groupRepository) GetByUser(ctx context.Context, organizationID, userID uuid.UUID) ([]*entities.Group, error) {
+	var groups []*entities.Group
+	err := repository.db.WithContext(ctx).
+		Where("organization_id = ?", organizationID).
+		Where("id IN (?)", repository.userGroups(userID)).
+		Order("name").
+		Find(&groups).Error
+	return groups, err
+}
+
+func (repository *groupRepository) GetUserGrants(ctx context.Context, organizationID, userID uuid.UUID) ([]entities.Role, []string, error) {
+	groups := repository.db.Model(&entities.Group{}).
+		Select("id").
+		Where("organization_id = ?", organizationID).
+		Where("id IN (?)", repository.userGroups(userID))
+
+	var roles []entities.Role
+	if err := repository.db.WithContext(ctx).Model(&entities.GroupRole{}).
+		Distinct("role").
+		Where("group_id IN (?)", groups).
+		Pluck("role", &roles).Error; err != nil {
+		return nil, nil, err
+	}
+	var permissions []string
+	if err := repository.db.WithContext(ctx).Model(&entities.GroupPermission{}).
+		Distinct("permission").
+		Where("group_id IN (?)", groups).
+		Pluck("permission", &permissions).Error; err != nil {
+		return nil, nil, err
+	}
+	return roles, permissions, nil
+}
+
+func (repository *groupRepository) GetUserRoles(ctx context.Context, userID uuid.UUID) ([]entities.Role, error) {
+	var roles []entities.Role
+	err := repository.db.WithContext(ctx).Model(&entities.GroupRole{}).
+		Distinct("role").
+		Where("group_id IN (?)", repository.userGroups(userID)).
+		Pluck("role", &roles).Error
+	return roles, err
+}
+
+// userGroups подзапрос с идентификаторами групп пользователя
+func (repository *groupRepository) userGroups(userID uuid.UUID) *gorm.DB {
+	return repository.db.Model(&entities.GroupMember{}).
+		Select("group_id").
+		Where("user_id = ?", userID)
+}
+
+func replaceGroupRoles(tx *gorm.DB, groupID uuid.UUID, roles []entities.Role) error {
+	if err := tx.Where("group_id = ?", groupID).Delete(&entities.GroupRole{}).Error; err != nil {
+		return err
+	}
+	if len(roles) == 0 {
+		return nil
+	}
+
+	grants := make([]entities.GroupRole, 0, len(roles))
+	for _, role := range roles {
+		grants = append(grants, entities.GroupRole{GroupID: groupID, Role: role})
+	}
+	return tx.Create(&grants).Error
+}
+
+func replaceGroupPermissions(tx *gorm.DB, groupID uuid.UUID, permissions []string) error {
+	if err := tx.Where("group_id = ?", groupID).Delete(&entities.GroupPermission{}).Error; err != nil {
+		return err
+	}
+	if len(permissions) == 0 {
+		return nil
+	}
+
+	grants := make([]entities.GroupPermission, 0, len(permissions))
+	for _, permission := range permissions {
+		grants = append(grants, entities.GroupPermission{GroupID: groupID, Permission: permission})
+	}
+	return tx.Create(&grants).Error
+}
@@ -0,0 +1,52 @@
+package repositories
+
+import (
+	"context"
+	stdErrors "errors"
+	"gold_portal/internal/domain/entities"
+	"gold_portal/internal/errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type OAuthClientRepository interface {
+	Create(ctx context.Context, client *entities.OAuthClient) error
+	Get(ctx context.Context) ([]*entities.OAuthClient, error)
+	FindByClientID(ctx context.Context, clientID string) (*entities.OAuthClient, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type oauthClientRepository struct {
+	db *gorm.DB
+}
+
+func NewOAuthClientRepository(db *gorm.DB) OAuthClientRepository {
+	return &oauthClientRepository{db: db}
+}
+
+func (repository *oauthClientRepository) Create(ctx context.Context, client *entities.OAuthClient) error {
+	return repository.db.WithContext(ctx).Create(client).Error
+}
+
+func (repository *oauthClientRepository) Get(ctx context.Context) ([]*entities.OAuthClient, error) {
+	var clients []*entities.OAuthClient
+	err := repository.db.WithContext(ctx).Order("created_at desc").Find(&clients).Error
+	return clients, err
+}
+
+func (repository *oauthClientRepository) FindByClientID(ctx context.Context, clientID string) (*entities.OAuthClient, error) {
+	var client entities.OAuthClient
+	err := repository.db.WithContext(ctx).First(&client, "client_id = ?", clientID).Error
+	if err != nil {
+		if stdErrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrOAuthClientNotFound
+		}
+		return nil, err
+	}
+	return &client, nil
+}
+
+func (repository *oauthClientRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return repository.db.WithContext(ctx).Delete(&entities.OAuthClient{}, "id = ?", id).Error
+}
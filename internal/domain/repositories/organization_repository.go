@@ -0,0 +1,119 @@
+package repositories
+
+import (
+	"context"
+	stdErrors "errors"
+	"gold_portal/internal/domain/entities"
+	"gold_portal/internal/errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OrganizationRepository interface {
+	Create(ctx context.Context, organization *entities.Organization) error
+	GetAll(ctx context.Context) ([]*entities.Organization, error)
+	GetID(ctx context.Context, id uuid.UUID) (*entities.Organization, error)
+	GetBySlug(ctx context.Context, slug string) (*entities.Organization, error)
+
+	// Возвращает членство или ErrNotOrganizationMember
+	GetMember(ctx context.Context, organizationID, userID uuid.UUID) (*entities.OrganizationMember, error)
+	// Членства пользователя вместе с организациями, начиная с самого раннего
+	GetMemberships(ctx context.Context, userID uuid.UUID) ([]*entities.OrganizationMember, error)
+	// Добавляет пользователя в организацию; если он уже в ней состоит, меняет роль
+	SaveMember(ctx context.Context, member *entities.OrganizationMember) error
+	// Исключает пользователя из организации и из всех её групп
+	RemoveMember(ctx context.Context, organizationID, userID uuid.UUID) error
+}
+
+type organizationRepository struct {
+	db *gorm.DB
+}
+
+func NewOrganizationRepository(db *gorm.DB) OrganizationRepository {
+	return &organizationRepository{db: db}
+}
+
+func (repository *organizationRepository) Create(ctx context.Context, organization *entities.Organization) error {
+	return repository.db.WithContext(ctx).Create(organization).Error
+}
+
+func (repository *organizationRepository) GetAll(ctx context.Context) ([]*entities.Organization, error) {
+	var organizations []*entities.Organization
+	err := repository.db.WithContext(ctx).Order("name").Find(&organizations).Error
+	return organizations, err
+}
+
+func (repository *organizationRepository) GetID(ctx context.Context, id uuid.UUID) (*entities.Organization, error) {
+	var organization entities.Organization
+	err := repository.db.WithContext(ctx).First(&organization, "id = ?", id).Error
+	if err != nil {
+		if stdErrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrOrganizationNotFound
+		}
+		return nil, err
+	}
+	return &organization, nil
+}
+
+func (repository *organizationRepository) GetBySlug(ctx context.Context, slug string) (*entities.Organization, error) {
+	var organization entities.Organization
+	err := repository.db.WithContext(ctx).First(&organization, "slug = ?", slug).Error
+	if err != nil {
+		if stdErrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrOrganizationNotFound
+		}
+		return nil, err
+	}
+	return &organization, nil
+}
+
+func (repository *organizationRepository) GetMember(ctx context.Context, organizationID, userID uuid.UUID) (*entities.OrganizationMember, error) {
+	var member entities.OrganizationMember
+	err := repository.db.WithContext(ctx).
+		First(&member, "organization_id = ? AND user_id = ?", organizationID, userID).Error
+	if err != nil {
+		if stdErrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrNotOrganizationMember
+		}
+		return nil, err
+	}
+	return &member, nil
+}
+
+func (repository *organizationRepository) GetMemberships(ctx context.Context, userID uuid.UUID) ([]*entities.OrganizationMember, error) {
+	var members []*entities.OrganizationMember
+	err := repository.db.WithContext(ctx).
+		Preload("Organization").
+		Where("user_id = ?", userID).
+		Order("created_at").
+		Find(&members).Error
+	return members, err
+}
+
+func (repository *organizationRepository) SaveMember(ctx context.Context, member *entities.OrganizationMember) error {
+	if member.CreatedAt.IsZero() {
+		member.CreatedAt = time.Now()
+	}
+	return repository.db.WithContext(ctx).
+		Omit("Organization").
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "organization_id"}, {Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"role"}),
+		}).
+		Create(member).Error
+}
+
+func (repository *organizationRepository) RemoveMember(ctx context.Context, organizationID, userID uuid.UUID) error {
+	return repository.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).
+			Where("group_id IN (?)", tx.Model(&entities.Group{}).Select("id").Where("organization_id = ?", organizationID)).
+			Delete(&entities.GroupMember{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("organization_id = ? AND user_id = ?", organizationID, userID).
+			Delete(&entities.OrganizationMember{}).Error
+	})
+}
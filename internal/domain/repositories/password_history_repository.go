@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"context"
+	"gold_portal/internal/domain/entities"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type PasswordHistoryRepository interface {
+	// Добавляет хэш пароля и оставляет в истории только keep последних записей
+	Add(ctx context.Context, entry *entities.PasswordHistory, keep int) error
+	// Возвращает limit последних хэшей паролей пользователя, начиная с новых
+	GetRecent(ctx context.Context, userID uuid.UUID, limit int) ([]*entities.PasswordHistory, error)
+}
+
+type passwordHistoryRepository struct {
+	db *gorm.DB
+}
+
+func NewPasswordHistoryRepository(db *gorm.DB) PasswordHistoryRepository {
+	return &passwordHistoryRepository{db: db}
+}
+
+func (repository *passwordHistoryRepository) Add(ctx context.Context, entry *entities.PasswordHistory, keep int) error {
+	return repository.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(entry).Error; err != nil {
+			return err
+		}
+		recent := tx.Model(&entities.PasswordHistory{}).Select("id").
+			Where("user_id = ?", entry.UserID).
+			Order("created_at DESC").Limit(keep)
+		return tx.Where("user_id = ? AND id NOT IN (?)", entry.UserID, recent).
+			Delete(&entities.PasswordHistory{}).Error
+	})
+}
+
+func (repository *passwordHistoryRepository) GetRecent(ctx context.Context, userID uuid.UUID, limit int) ([]*entities.PasswordHistory, error) {
+	var entries []*entities.PasswordHistory
+	err := repository.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").Limit(limit).
+		Find(&entries).Error
+	return entries, err
+}
@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"context"
+	"gold_portal/internal/domain/entities"
+
+	"gorm.io/gorm"
+)
+
+type PermissionRepository interface {
+	GetAll(ctx context.Context) ([]*entities.Permission, error)
+	// Возвращает названия прав, выданных роли
+	GetByRole(ctx context.Context, role entities.Role) ([]string, error)
+}
+
+type permissionRepository struct {
+	db *gorm.DB
+}
+
+func NewPermissionRepository(db *gorm.DB) PermissionRepository {
+	return &permissionRepository{db: db}
+}
+
+func (repository *permissionRepository) GetAll(ctx context.Context) ([]*entities.Permission, error) {
+	var permissions []*entities.Permission
+	err := repository.db.WithContext(ctx).Order("name").Find(&permissions).Error
+	return permissions, err
+}
+
+func (repository *permissionRepository) GetByRole(ctx context.Context, role entities.Role) ([]string, error) {
+	var names []string
+	err := repository.db.WithContext(ctx).Model(&entities.RolePermission{}).
+		Where("role = ?", role).
+		Order("permission").
+		Pluck("permission", &names).Error
+	return names, err
+}
@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"context"
+	"gold_portal/internal/domain/entities"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type RecoveryCodeRepository interface {
+	// Заменяет все коды пользователя новыми
+	Replace(ctx context.Context, userID uuid.UUID, codes []*entities.RecoveryCode) error
+	// Погашает неиспользованный код; false, если такого кода нет
+	Use(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error)
+	DeleteByUser(ctx context.Context, userID uuid.UUID) error
+}
+
+type recoveryCodeRepository struct {
+	db *gorm.DB
+}
+
+func NewRecoveryCodeRepository(db *gorm.DB) RecoveryCodeRepository {
+	return &recoveryCodeRepository{db: db}
+}
+
+func (repository *recoveryCodeRepository) Replace(ctx context.Context, userID uuid.UUID, codes []*entities.RecoveryCode) error {
+	return repository.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&entities.RecoveryCode{}, "user_id = ?", userID).Error; err != nil {
+			return err
+		}
+		return tx.Create(codes).Error
+	})
+}
+
+func (repository *recoveryCodeRepository) Use(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error) {
+	// Условие used_at IS NULL не даёт погасить один код дважды параллельными запросами
+	result := repository.db.WithContext(ctx).Model(&entities.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (repository *recoveryCodeRepository) DeleteByUser(ctx context.Context, userID uuid.UUID) error {
+	return repository.db.WithContext(ctx).Delete(&entities.RecoveryCode{}, "user_id = ?", userID).Error
+}
@@ -0,0 +1,157 @@
+package repositories
+
+import (
+	"context"
+	stdErrors "errors"
+	"gold_portal/internal/domain/entities"
+	"gold_portal/internal/errors"
+
+	"gorm.io/gorm"
+)
+
+type RoleRepository interface {
+	GetAll(ctx context.Context) ([]*entities.RoleDefinition, error)
+	GetByName(ctx context.Context, name entities.Role) (*entities.RoleDefinition, error)
+	// Создаёт роль вместе с её правами
+	Create(ctx context.Context, role *entities.RoleDefinition, permissions []string) error
+	// Сохраняет роль под именем role.Name. Если имя изменилось, роль переименовывается
+	// и у пользователей, участников организаций, групп, клиентов OAuth и прав. permissions == nil оставляет права как есть
+	Update(ctx context.Context, previousName entities.Role, role *entities.RoleDefinition, permissions []string) error
+	// Удаляет роль и её права
+	Delete(ctx context.Context, role *entities.RoleDefinition) error
+	// Сколько пользователей, участников организаций, групп и клиентов OAuth получили роль
+	CountAssignments(ctx context.Context, name entities.Role) (int64, error)
+}
+
+type roleRepository struct {
+	db *gorm.DB
+}
+
+func NewRoleRepository(db *gorm.DB) RoleRepository {
+	return &roleRepository{db: db}
+}
+
+func (repository *roleRepository) GetAll(ctx context.Context) ([]*entities.RoleDefinition, error) {
+	var roles []*entities.RoleDefinition
+	err := repository.db.WithContext(ctx).Order("rank desc, name").Find(&roles).Error
+	return roles, err
+}
+
+func (repository *roleRepository) GetByName(ctx context.Context, name entities.Role) (*entities.RoleDefinition, error) {
+	var role entities.RoleDefinition
+	err := repository.db.WithContext(ctx).First(&role, "name = ?", name).Error
+	if err != nil {
+		if stdErrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrRoleNotFound
+		}
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (repository *roleRepository) Create(ctx context.Context, role *entities.RoleDefinition, permissions []string) error {
+	return repository.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(role).Error; err != nil {
+			return err
+		}
+		return replaceRolePermissions(tx, role.Name, permissions)
+	})
+}
+
+func (repository *roleRepository) Update(ctx context.Context, previousName entities.Role, role *entities.RoleDefinition, permissions []string) error {
+	return repository.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&entities.RoleDefinition{}).
+			Where("id = ?", role.ID).
+			Updates(map[string]interface{}{
+				"name":        role.Name,
+				"description": role.Description,
+				"rank":        role.Rank,
+				"updated_at":  role.UpdatedAt,
+			}).Error; err != nil {
+			return err
+		}
+
+		if role.Name != previousName {
+			// UpdateColumn не вызывает хуки User: новой роли в этой транзакции хук бы уже не нашёл
+			if err := tx.Unscoped().Model(&entities.User{}).
+				Where("role = ?", previousName).
+				UpdateColumn("role", role.Name).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&entities.OAuthClient{}).
+				Where("role = ?", previousName).
+				UpdateColumn("role", role.Name).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&entities.OrganizationMember{}).
+				Where("role = ?", previousName).
+				UpdateColumn("role", role.Name).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&entities.GroupRole{}).
+				Where("role = ?", previousName).
+				UpdateColumn("role", role.Name).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&entities.RolePermission{}).
+				Where("role = ?", previousName).
+				UpdateColumn("role", role.Name).Error; err != nil {
+				return err
+			}
+		}
+
+		if permissions == nil {
+			return nil
+		}
+		return replaceRolePermissions(tx, role.Name, permissions)
+	})
+}
+
+func (repository *roleRepository) Delete(ctx context.Context, role *entities.RoleDefinition) error {
+	return repository.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("role = ?", role.Name).Delete(&entities.RolePermission{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&entities.RoleDefinition{}, "id = ?", role.ID).Error
+	})
+}
+
+func (repository *roleRepository) CountAssignments(ctx context.Context, name entities.Role) (int64, error) {
+	var users, clients, members, groups int64
+	if err := repository.db.WithContext(ctx).Model(&entities.User{}).
+		Where("role = ?", name).
+		Count(&users).Error; err != nil {
+		return 0, err
+	}
+	if err := repository.db.WithContext(ctx).Model(&entities.OAuthClient{}).
+		Where("role = ?", name).
+		Count(&clients).Error; err != nil {
+		return 0, err
+	}
+	if err := repository.db.WithContext(ctx).Model(&entities.OrganizationMember{}).
+		Where("role = ?", name).
+		Count(&members).Error; err != nil {
+		return 0, err
+	}
+	if err := repository.db.WithContext(ctx).Model(&entities.GroupRole{}).
+		Where("role = ?", name).
+		Count(&groups).Error; err != nil {
+		return 0, err
+	}
+	return users + clients + members + groups, nil
+}
+
+func replaceRolePermissions(tx *gorm.DB, role entities.Role, permissions []string) error {
+	if err := tx.Where("role = ?", role).Delete(&entities.RolePermission{}).Error; err != nil {
+		return err
+	}
+	if len(permissions) == 0 {
+		return nil
+	}
+
+	grants := make([]entities.RolePermission, 0, len(permissions))
+	for _, permission := range permissions {
+		grants = append(grants, entities.RolePermission{Role: role, Permission: permission})
+	}
+	return tx.Create(&grants).Error
+}
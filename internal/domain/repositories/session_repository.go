@@ -0,0 +1,75 @@
+package repositories
+
+import (
+	"context"
+	stdErrors "errors"
+	"gold_portal/internal/domain/entities"
+	"gold_portal/internal/errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type SessionRepository interface {
+	Create(ctx context.Context, session *entities.Session) error
+	GetID(ctx context.Context, id uuid.UUID) (*entities.Session, error)
+	GetActiveByUser(ctx context.Context, userID uuid.UUID) ([]*entities.Session, error)
+	Touch(ctx context.Context, id uuid.UUID, lastSeenAt, expiresAt time.Time) error
+	SetOrganization(ctx context.Context, id, organizationID uuid.UUID) error
+	Revoke(ctx context.Context, id uuid.UUID) error
+}
+
+type sessionRepository struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) SessionRepository {
+	return &sessionRepository{db: db}
+}
+
+func (repository *sessionRepository) Create(ctx context.Context, session *entities.Session) error {
+	return repository.db.WithContext(ctx).Create(session).Error
+}
+
+func (repository *sessionRepository) GetID(ctx context.Context, id uuid.UUID) (*entities.Session, error) {
+	var session entities.Session
+	err := repository.db.WithContext(ctx).First(&session, "id = ?", id).Error
+	if err != nil {
+		if stdErrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrSessionNotFound
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (repository *sessionRepository) GetActiveByUser(ctx context.Context, userID uuid.UUID) ([]*entities.Session, error) {
+	var sessions []*entities.Session
+	err := repository.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_seen_at desc").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+func (repository *sessionRepository) Touch(ctx context.Context, id uuid.UUID, lastSeenAt, expiresAt time.Time) error {
+	return repository.db.WithContext(ctx).Model(&entities.Session{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"last_seen_at": lastSeenAt,
+			"expires_at":   expiresAt,
+		}).Error
+}
+
+func (repository *sessionRepository) SetOrganization(ctx context.Context, id, organizationID uuid.UUID) error {
+	return repository.db.WithContext(ctx).Model(&entities.Session{}).
+		Where("id = ?", id).
+		Update("organization_id", organizationID).Error
+}
+
+func (repository *sessionRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	return repository.db.WithContext(ctx).Model(&entities.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+}
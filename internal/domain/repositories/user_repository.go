@@ -5,6 +5,7 @@ import (
 	stdErrors "errors"
 	"gold_portal/internal/domain/entities"
 	"gold_portal/internal/errors"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -12,12 +13,31 @@ import (
 
 type UserRepository interface {
 	Create(ctx context.Context, user *entities.User) error
-	Get(ctx context.Context) ([]*entities.User, error)
+	// Создаёт пользователя сразу участником организации с ролью role
+	CreateInOrganization(ctx context.Context, user *entities.User, organizationID uuid.UUID, role entities.Role) error
+	// Участники организации; роль пользователя в ответе — роль в этой организации
+	Get(ctx context.Context, organizationID uuid.UUID) ([]*entities.User, error)
 	GetID(ctx context.Context, id uuid.UUID) (*entities.User, error)
+	// Как GetID, но только среди участников организации и с ролью в ней
+	GetInOrganization(ctx context.Context, organizationID, id uuid.UUID) (*entities.User, error)
+	// Сохраняет данные профиля; роль меняется через UpdateRole
 	Patch(ctx context.Context, user *entities.User) error
+	// Меняет роль пользователя в организации. Роль superuser действует во всех
+	// организациях, поэтому её выдача и снятие меняют и роль пользователя на платформе
+	UpdateRole(ctx context.Context, organizationID, id uuid.UUID, role entities.Role) error
+	// Удаляет пользователя вместе с его членствами в организациях
 	Delete(ctx context.Context, id uuid.UUID) error
+	// Сохраняет секрет TOTP и признак включённой двухфакторной аутентификации
+	UpdateTOTP(ctx context.Context, id uuid.UUID, secret string, enabled bool) error
+	SetPhoneVerified(ctx context.Context, id uuid.UUID, verified bool) error
+	// Сохраняет уже захэшированный пароль
+	UpdatePassword(ctx context.Context, id uuid.UUID, hashedPassword string) error
+	// Увеличивает версию токенов пользователя
+	IncrementTokenVersion(ctx context.Context, id uuid.UUID) error
 
 	FindByPhone(ctx context.Context, phone string) (*entities.User, error)
+	// Как FindByPhone, но только среди участников организации и с ролью в ней
+	FindByPhoneInOrganization(ctx context.Context, organizationID uuid.UUID, phone string) (*entities.User, error)
 }
 
 type userRepository struct {
@@ -31,10 +51,29 @@ func NewUserRepository(db *gorm.DB) UserRepository {
 func (repository *userRepository) Create(ctx context.Context, user *entities.User) error {
 	return repository.db.WithContext(ctx).Create(user).Error
 }
-func (repository *userRepository) Get(ctx context.Context) ([]*entities.User, error) {
+
+func (repository *userRepository) CreateInOrganization(ctx context.Context, user *entities.User, organizationID uuid.UUID, role entities.Role) error {
+	return repository.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		member := entities.OrganizationMember{
+			OrganizationID: organizationID,
+			UserID:         user.ID,
+			Role:           role,
+			CreatedAt:      user.CreatedAt,
+		}
+		return tx.Omit("Organization").Create(&member).Error
+	})
+}
+
+func (repository *userRepository) Get(ctx context.Context, organizationID uuid.UUID) ([]*entities.User, error) {
 	var users []*entities.User
-	err := repository.db.WithContext(ctx).Find(&users).Error
-	return users, err
+	err := repository.inOrganization(ctx, organizationID).Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+	return users, repository.applyOrganizationRoles(ctx, organizationID, users...)
 }
 
 func (repository *userRepository) GetID(ctx context.Context, id uuid.UUID) (*entities.User, error) {
@@ -49,12 +88,99 @@ func (repository *userRepository) GetID(ctx context.Context, id uuid.UUID) (*ent
 	return &user, nil
 }
 
+func (repository *userRepository) GetInOrganization(ctx context.Context, organizationID, id uuid.UUID) (*entities.User, error) {
+	var user entities.User
+	err := repository.inOrganization(ctx, organizationID).First(&user, "id = ?", id).Error
+	if err != nil {
+		if stdErrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrUserNotFound
+		}
+		return nil, err
+	}
+	return &user, repository.applyOrganizationRoles(ctx, organizationID, &user)
+}
+
 func (repository *userRepository) Patch(ctx context.Context, user *entities.User) error {
-	return repository.db.WithContext(ctx).Updates(user).Error
+	// Select записывает и нулевые значения, иначе is_active = false не сохранился бы.
+	// Роль не сохраняется: у загруженного в организации пользователя это роль в организации
+	return repository.db.WithContext(ctx).
+		Select("first_name", "last_name", "middle_name", "phone", "photo", "is_active", "updated_at").
+		Updates(user).Error
+}
+
+func (repository *userRepository) UpdateRole(ctx context.Context, organizationID, id uuid.UUID, role entities.Role) error {
+	return repository.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var member entities.OrganizationMember
+		if err := tx.First(&member, "organization_id = ? AND user_id = ?", organizationID, id).Error; err != nil {
+			if stdErrors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.ErrNotOrganizationMember
+			}
+			return err
+		}
+		if err := tx.Model(&entities.OrganizationMember{}).
+			Where("organization_id = ? AND user_id = ?", organizationID, id).
+			UpdateColumn("role", role).Error; err != nil {
+			return err
+		}
+
+		var platformRole entities.Role
+		switch {
+		case role == entities.RoleSuperUser:
+			platformRole = entities.RoleSuperUser
+		case member.Role == entities.RoleSuperUser:
+			platformRole = entities.RoleUser
+		default:
+			return nil
+		}
+		// Роль уже проверена обработчиком; UpdateColumn не вызывает хуки User
+		return tx.Model(&entities.User{}).
+			Where("id = ?", id).
+			UpdateColumn("role", platformRole).Error
+	})
 }
 
 func (repository *userRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	return repository.db.WithContext(ctx).Delete(&entities.User{}, "id = ?", id).Error
+	return repository.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", id).Delete(&entities.OrganizationMember{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", id).Delete(&entities.GroupMember{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&entities.User{}, "id = ?", id).Error
+	})
+}
+
+func (repository *userRepository) UpdateTOTP(ctx context.Context, id uuid.UUID, secret string, enabled bool) error {
+	// UpdateColumns записывает и нулевые значения при отключении 2FA и не вызывает хуки пароля
+	return repository.db.WithContext(ctx).Model(&entities.User{}).
+		Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{
+			"totp_secret":  secret,
+			"totp_enabled": enabled,
+		}).Error
+}
+
+func (repository *userRepository) SetPhoneVerified(ctx context.Context, id uuid.UUID, verified bool) error {
+	return repository.db.WithContext(ctx).Model(&entities.User{}).
+		Where("id = ?", id).
+		UpdateColumn("phone_verified", verified).Error
+}
+
+func (repository *userRepository) UpdatePassword(ctx context.Context, id uuid.UUID, hashedPassword string) error {
+	return repository.db.WithContext(ctx).Model(&entities.User{}).
+		Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{
+			"password":   hashedPassword,
+			"updated_at": time.Now(),
+		}).Error
+}
+
+func (repository *userRepository) IncrementTokenVersion(ctx context.Context, id uuid.UUID) error {
+	// Инкремент в SQL: одновременные запросы не потеряют увеличение версии
+	return repository.db.WithContext(ctx).Model(&entities.User{}).
+		Where("id = ?", id).
+		UpdateColumn("token_version", gorm.Expr("token_version + 1")).Error
 }
 
 func (repository *userRepository) FindByPhone(ctx context.Context, phone string) (*entities.User, error) {
@@ -68,3 +194,51 @@ func (repository *userRepository) FindByPhone(ctx context.Context, phone string)
 	}
 	return &user, nil
 }
+
+func (repository *userRepository) FindByPhoneInOrganization(ctx context.Context, organizationID uuid.UUID, phone string) (*entities.User, error) {
+	var user entities.User
+	err := repository.inOrganization(ctx, organizationID).First(&user, "phone = ?", phone).Error
+	if err != nil {
+		if stdErrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrUserNotFound
+		}
+		return nil, err
+	}
+	return &user, repository.applyOrganizationRoles(ctx, organizationID, &user)
+}
+
+// inOrganization ограничивает запрос участниками организации
+func (repository *userRepository) inOrganization(ctx context.Context, organizationID uuid.UUID) *gorm.DB {
+	db := repository.db.WithContext(ctx)
+	return db.Where("id IN (?)", db.Model(&entities.OrganizationMember{}).
+		Select("user_id").
+		Where("organization_id = ?", organizationID))
+}
+
+// applyOrganizationRoles заменяет роль пользователей на платформе их ролью в организации
+func (repository *userRepository) applyOrganizationRoles(ctx context.Context, organizationID uuid.UUID, users ...*entities.User) error {
+	if len(users) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, 0, len(users))
+	for _, user := range users {
+		ids = append(ids, user.ID)
+	}
+
+	var members []entities.OrganizationMember
+	if err := repository.db.WithContext(ctx).
+		Where("organization_id = ? AND user_id IN ?", organizationID, ids).
+		Find(&members).Error; err != nil {
+		return err
+	}
+	roles := make(map[uuid.UUID]entities.Role, len(members))
+	for _, member := range members {
+		roles[member.UserID] = member.Role
+	}
+	for _, user := range users {
+		if role, ok := roles[user.ID]; ok {
+			user.Role = role
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"context"
+	stdErrors "errors"
+	"gold_portal/internal/domain/entities"
+	"gold_portal/internal/errors"
+	"time"
+
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type WebAuthnCredentialRepository interface {
+	Create(ctx context.Context, credential *entities.WebAuthnCredential) error
+	GetByUser(ctx context.Context, userID uuid.UUID) ([]*entities.WebAuthnCredential, error)
+	GetByCredentialID(ctx context.Context, credentialID []byte) (*entities.WebAuthnCredential, error)
+	// Сохраняет обновлённый после входа счётчик подписей и время использования
+	UpdateAfterLogin(ctx context.Context, id uuid.UUID, credential webauthn.Credential) error
+	Delete(ctx context.Context, userID, id uuid.UUID) error
+}
+
+type webAuthnCredentialRepository struct {
+	db *gorm.DB
+}
+
+func NewWebAuthnCredentialRepository(db *gorm.DB) WebAuthnCredentialRepository {
+	return &webAuthnCredentialRepository{db: db}
+}
+
+func (repository *webAuthnCredentialRepository) Create(ctx context.Context, credential *entities.WebAuthnCredential) error {
+	return repository.db.WithContext(ctx).Create(credential).Error
+}
+
+func (repository *webAuthnCredentialRepository) GetByUser(ctx context.Context, userID uuid.UUID) ([]*entities.WebAuthnCredential, error) {
+	var credentials []*entities.WebAuthnCredential
+	err := repository.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at").
+		Find(&credentials).Error
+	return credentials, err
+}
+
+func (repository *webAuthnCredentialRepository) GetByCredentialID(ctx context.Context, credentialID []byte) (*entities.WebAuthnCredential, error) {
+	var credential entities.WebAuthnCredential
+	err := repository.db.WithContext(ctx).First(&credential, "credential_id = ?", credentialID).Error
+	if err != nil {
+		if stdErrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrWebAuthnCredentialNotFound
+		}
+		return nil, err
+	}
+	return &credential, nil
+}
+
+func (repository *webAuthnCredentialRepository) UpdateAfterLogin(ctx context.Context, id uuid.UUID, credential webauthn.Credential) error {
+	now := time.Now()
+	return repository.db.WithContext(ctx).Model(&entities.WebAuthnCredential{ID: id}).
+		Select("credential", "last_used_at").
+		Updates(&entities.WebAuthnCredential{
+			Credential: credential,
+			LastUsedAt: &now,
+		}).Error
+}
+
+func (repository *webAuthnCredentialRepository) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	result := repository.db.WithContext(ctx).Delete(&entities.WebAuthnCredential{}, "id = ? AND user_id = ?", id, userID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.ErrWebAuthnCredentialNotFound
+	}
+	return nil
+}
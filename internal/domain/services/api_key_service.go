@@ -0,0 +1,233 @@
+package services
+
+import (
+	"context"
+	stdErrors "errors"
+	"fmt"
+	"gold_portal/config"
+	"gold_portal/internal/domain/dto"
+	"gold_portal/internal/domain/entities"
+	"gold_portal/internal/domain/repositories"
+	"gold_portal/internal/errors"
+	"gold_portal/internal/pkg/crypto"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	AuditActionAPIKeyCreated = "API_KEY_CREATED"
+	AuditActionAPIKeyRevoked = "API_KEY_REVOKED"
+)
+
+const (
+	// Длина идентификатора после gpk_: 6 случайных байт в base64url
+	apiKeyIDLength = 8
+	// Время последнего использования записываем не чаще раза в минуту, а не на каждый запрос
+	apiKeyTouchInterval = time.Minute
+)
+
+type APIKeyService interface {
+	// Создаёт ключ в организации organizationID; открытое значение есть только в ответе
+	Create(ctx context.Context, userID, organizationID uuid.UUID, request dto.APIKeyCreateRequestDTO) (*dto.APIKeyCreateResponseDTO, error)
+	GetByUser(ctx context.Context, userID uuid.UUID) ([]*dto.APIKeyResponseDTO, error)
+	Revoke(ctx context.Context, userID, keyID uuid.UUID, clientIP, userAgent string) error
+	// Проверяет предъявленный ключ и возвращает его вместе с владельцем и его ролью
+	// в организации ключа
+	Authenticate(ctx context.Context, rawKey, clientIP string) (*entities.APIKey, *dto.UserResponseDTO, error)
+}
+
+type apiKeyService struct {
+	apiKeyRepository repositories.APIKeyRepository
+	userRepository   repositories.UserRepository
+	organizations    OrganizationService
+	auditService     AuditService
+	config           *config.Config
+}
+
+func NewAPIKeyService(apiKeyRepository repositories.APIKeyRepository, userRepository repositories.UserRepository, organizations OrganizationService, auditService AuditService, config *config.Config) APIKeyService {
+	return &apiKeyService{
+		apiKeyRepository: apiKeyRepository,
+		userRepository:   userRepository,
+		organizations:    organizations,
+		auditService:     auditService,
+		config:           config,
+	}
+}
+
+// IsAPIKey отличает API-ключ от JWT по префиксу
+func IsAPIKey(value string) bool {
+	return strings.HasPrefix(value, entities.APIKeyPrefix)
+}
+
+func (s *apiKeyService) Create(ctx context.Context, userID, organizationID uuid.UUID, request dto.APIKeyCreateRequestDTO) (*dto.APIKeyCreateResponseDTO, error) {
+	scopes, err := normalizeAPIKeyScopes(request.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepository.GetID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(s.config.APIKey.DefaultLifetime)
+	if request.ExpiresAt != nil {
+		expiresAt = *request.ExpiresAt
+	}
+	if !expiresAt.After(now) || expiresAt.After(now.Add(s.config.APIKey.MaxLifetime)) {
+		return nil, errors.ErrAPIKeyInvalidExpiry
+	}
+
+	active, err := s.apiKeyRepository.GetActiveByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(active) >= s.config.APIKey.MaxPerUser {
+		return nil, errors.ErrAPIKeyLimitReached
+	}
+
+	identifier, err := crypto.GenerateRandomString(6)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка генерации API-ключа: %w", err)
+	}
+	secret, err := crypto.GenerateRandomString(32)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка генерации API-ключа: %w", err)
+	}
+	prefix := entities.APIKeyPrefix + identifier
+	rawKey := prefix + "_" + secret
+
+	key := &entities.APIKey{
+		ID:             uuid.New(),
+		UserID:         userID,
+		OrganizationID: organizationID,
+		Name:           request.Name,
+		Prefix:         prefix,
+		KeyHash:        crypto.HashToken(rawKey),
+		Scopes:         scopes,
+		TokenVersion:   user.TokenVersion,
+		ExpiresAt:      &expiresAt,
+		CreatedAt:      now,
+	}
+	if err := s.apiKeyRepository.Create(ctx, key); err != nil {
+		return nil, fmt.Errorf("ошибка при создании API-ключа: %w", err)
+	}
+
+	if err := s.auditService.ForOrganization(organizationID).Log(userID, key.ID, AuditActionAPIKeyCreated, "APIKey", http.StatusCreated,
+		request.ClientIP, request.UserAgent, fmt.Sprintf("Создан API-ключ %s (%s)", key.Prefix, key.Name)); err != nil {
+		return nil, err
+	}
+
+	response := &dto.APIKeyCreateResponseDTO{Key: rawKey}
+	response.FromModel(key)
+	return response, nil
+}
+
+func (s *apiKeyService) GetByUser(ctx context.Context, userID uuid.UUID) ([]*dto.APIKeyResponseDTO, error) {
+	keys, err := s.apiKeyRepository.GetActiveByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]*dto.APIKeyResponseDTO, 0, len(keys))
+	for _, key := range keys {
+		var keyResponse dto.APIKeyResponseDTO
+		keyResponse.FromModel(key)
+		response = append(response, &keyResponse)
+	}
+	return response, nil
+}
+
+func (s *apiKeyService) Revoke(ctx context.Context, userID, keyID uuid.UUID, clientIP, userAgent string) error {
+	key, err := s.apiKeyRepository.GetID(ctx, keyID)
+	if err != nil {
+		return err
+	}
+	// Чужой или уже отозванный ключ для пользователя неотличим от несуществующего
+	if key.UserID != userID || key.RevokedAt != nil {
+		return errors.ErrAPIKeyNotFound
+	}
+
+	if err := s.apiKeyRepository.Revoke(ctx, key.ID); err != nil {
+		return err
+	}
+	// Ключ выпущен для организации, поэтому и отзыв виден в её журнале
+	return s.auditService.ForOrganization(key.OrganizationID).Log(userID, key.ID, AuditActionAPIKeyRevoked, "APIKey", http.StatusOK,
+		clientIP, userAgent, fmt.Sprintf("Отозван API-ключ %s (%s)", key.Prefix, key.Name))
+}
+
+func (s *apiKeyService) Authenticate(ctx context.Context, rawKey, clientIP string) (*entities.APIKey, *dto.UserResponseDTO, error) {
+	// Ключ имеет вид gpk_<идентификатор>_<секрет>, идентификатор фиксированной длины
+	prefixLength := len(entities.APIKeyPrefix) + apiKeyIDLength
+	if !IsAPIKey(rawKey) || len(rawKey) <= prefixLength+1 || rawKey[prefixLength] != '_' {
+		return nil, nil, errors.ErrAPIKeyInvalid
+	}
+
+	key, err := s.apiKeyRepository.FindByPrefix(ctx, rawKey[:prefixLength])
+	if err != nil {
+		if stdErrors.Is(err, errors.ErrAPIKeyNotFound) {
+			return nil, nil, errors.ErrAPIKeyInvalid
+		}
+		return nil, nil, err
+	}
+	if !crypto.CheckToken(key.KeyHash, rawKey) || !key.IsActive() {
+		return nil, nil, errors.ErrAPIKeyInvalid
+	}
+
+	user, err := s.userRepository.GetID(ctx, key.UserID)
+	if err != nil {
+		return nil, nil, errors.ErrAPIKeyInvalid
+	}
+	if !user.IsActive {
+		return nil, nil, errors.ErrAccountBlocked
+	}
+	// Ключ, выданный до выхода со всех устройств или сброса пароля, не действует,
+	// даже если его отзыв не записался
+	if key.TokenVersion != user.TokenVersion {
+		return nil, nil, errors.ErrAPIKeyInvalid
+	}
+	// Ключи, созданные до появления организаций, действуют в организации по умолчанию
+	organizationID, role, err := s.organizations.Resolve(ctx, user, key.OrganizationID)
+	if err != nil {
+		return nil, nil, errors.ErrAPIKeyInvalid
+	}
+
+	now := time.Now()
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval || key.LastUsedIP != clientIP {
+		// Учёт использования не должен отклонять запрос
+		if err := s.apiKeyRepository.Touch(ctx, key.ID, now, clientIP); err == nil {
+			key.LastUsedAt = &now
+			key.LastUsedIP = clientIP
+		}
+	}
+
+	var userResponse dto.UserResponseDTO
+	userResponse.FromModel(user)
+	userResponse.Role = role
+	userResponse.OrganizationID = &organizationID
+	return key, &userResponse, nil
+}
+
+// normalizeAPIKeyScopes проверяет scope по списку известных и убирает повторы
+func normalizeAPIKeyScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, errors.ErrAPIKeyInvalidScope
+	}
+	result := make([]string, 0, len(scopes))
+	seen := make(map[string]bool, len(scopes))
+	for _, scope := range scopes {
+		if !entities.IsAPIKeyScope(scope) {
+			return nil, fmt.Errorf("%w: %s", errors.ErrAPIKeyInvalidScope, scope)
+		}
+		if seen[scope] {
+			continue
+		}
+		seen[scope] = true
+		result = append(result, scope)
+	}
+	return result, nil
+}
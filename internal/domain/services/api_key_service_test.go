@@ -0,0 +1,329 @@
+package services
+
+import (
+	"context"
+	stdErrors "errors"
+	"gold_portal/config"
+	"gold_portal/internal/domain/dto"
+	"gold_portal/internal/domain/entities"
+	"gold_portal/internal/domain/repositories"
+	"gold_portal/internal/errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// fakeAPIKeyRepository хранит ключи в памяти
+type fakeAPIKeyRepository struct {
+	repositories.APIKeyRepository
+	keys map[uuid.UUID]*entities.APIKey
+}
+
+func (r *fakeAPIKeyRepository) Create(_ context.Context, key *entities.APIKey) error {
+	r.keys[key.ID] = key
+	return nil
+}
+
+func (r *fakeAPIKeyRepository) GetID(_ context.Context, id uuid.UUID) (*entities.APIKey, error) {
+	key, ok := r.keys[id]
+	if !ok {
+		return nil, errors.ErrAPIKeyNotFound
+	}
+	return key, nil
+}
+
+func (r *fakeAPIKeyRepository) FindByPrefix(_ context.Context, prefix string) (*entities.APIKey, error) {
+	for _, key := range r.keys {
+		if key.Prefix == prefix {
+			return key, nil
+		}
+	}
+	return nil, errors.ErrAPIKeyNotFound
+}
+
+func (r *fakeAPIKeyRepository) GetActiveByUser(_ context.Context, userID uuid.UUID) ([]*entities.APIKey, error) {
+	var keys []*entities.APIKey
+	for _, key := range r.keys {
+		if key.UserID == userID && key.IsActive() {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (r *fakeAPIKeyRepository) Touch(_ context.Context, id uuid.UUID, usedAt time.Time, clientIP string) error {
+	if key, ok := r.keys[id]; ok {
+		key.LastUsedAt = &usedAt
+		key.LastUsedIP = clientIP
+	}
+	return nil
+}
+
+func (r *fakeAPIKeyRepository) Revoke(_ context.Context, id uuid.UUID) error {
+	if key, ok := r.keys[id]; ok && key.RevokedAt == nil {
+		now := time.Now()
+		key.RevokedAt = &now
+	}
+	return nil
+}
+
+func (r *fakeAPIKeyRepository) RevokeByUser(ctx context.Context, userID uuid.UUID) error {
+	for _, key := range r.keys {
+		if key.UserID == userID {
+			if err := r.Revoke(ctx, key.ID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func newTestAPIKeyService(users ...*entities.User) (*apiKeyService, *fakeAPIKeyRepository, *fakeAuditService) {
+	repository := &fakeAPIKeyRepository{keys: make(map[uuid.UUID]*entities.APIKey)}
+	userRepository := newFakeUserRepository(users...)
+	audit := &fakeAuditService{}
+	service := &apiKeyService{
+		apiKeyRepository: repository,
+		userRepository:   userRepository,
+		organizations:    newTestOrganizationService(userRepository, nil),
+		auditService:     audit,
+		config: &config.Config{APIKey: config.APIKeyConfig{
+			DefaultLifetime: 24 * time.Hour,
+			MaxLifetime:     30 * 24 * time.Hour,
+			MaxPerUser:      2,
+		}},
+	}
+	return service, repository, audit
+}
+
+func TestAPIKeyCreate(t *testing.T) {
+	ctx := context.Background()
+	tooLate := time.Now().Add(31 * 24 * time.Hour)
+	past := time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		name       string
+		request    dto.APIKeyCreateRequestDTO
+		wantScopes []string
+		wantErr    error
+	}{
+		{
+			name:       "duplicate scopes are dropped",
+			request:    dto.APIKeyCreateRequestDTO{Name: "ci", Scopes: []string{entities.APIKeyScopeUsersRead, entities.APIKeyScopeUsersRead}},
+			wantScopes: []string{entities.APIKeyScopeUsersRead},
+		},
+		{name: "unknown scope", request: dto.APIKeyCreateRequestDTO{Name: "ci", Scopes: []string{"users:delete"}}, wantErr: errors.ErrAPIKeyInvalidScope},
+		{name: "no scopes", request: dto.APIKeyCreateRequestDTO{Name: "ci"}, wantErr: errors.ErrAPIKeyInvalidScope},
+		{
+			name:    "expiry beyond max lifetime",
+			request: dto.APIKeyCreateRequestDTO{Name: "ci", Scopes: []string{entities.APIKeyScopeUsersRead}, ExpiresAt: &tooLate},
+			wantErr: errors.ErrAPIKeyInvalidExpiry,
+		},
+		{
+			name:    "expiry in the past",
+			request: dto.APIKeyCreateRequestDTO{Name: "ci", Scopes: []string{entities.APIKeyScopeUsersRead}, ExpiresAt: &past},
+			wantErr: errors.ErrAPIKeyInvalidExpiry,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			owner := &entities.User{ID: uuid.New(), Role: entities.RoleManager, IsActive: true}
+			service, repository, audit := newTestAPIKeyService(owner)
+			response, err := service.Create(ctx, owner.ID, testOrganizationID, tt.request)
+			if !stdErrors.Is(err, tt.wantErr) {
+				t.Fatalf("Create error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if len(repository.keys) != 0 || len(audit.entries) != 0 {
+					t.Error("rejected key is stored")
+				}
+				return
+			}
+
+			if !reflect.DeepEqual(response.Scopes, tt.wantScopes) {
+				t.Errorf("scopes = %v, want %v", response.Scopes, tt.wantScopes)
+			}
+			// Хранится только хэш, открытое значение есть лишь в ответе
+			key := repository.keys[response.ID]
+			if !strings.HasPrefix(response.Key, key.Prefix+"_") || key.KeyHash == response.Key {
+				t.Errorf("stored key %+v does not match issued %q", key, response.Key)
+			}
+			if len(audit.entries) != 1 {
+				t.Errorf("audit entries = %v, want one", audit.entries)
+			}
+		})
+	}
+}
+
+func TestAPIKeyCreateLimit(t *testing.T) {
+	ctx := context.Background()
+	owner := &entities.User{ID: uuid.New(), Role: entities.RoleManager, IsActive: true}
+	service, _, _ := newTestAPIKeyService(owner)
+	userID := owner.ID
+	request := dto.APIKeyCreateRequestDTO{Name: "ci", Scopes: []string{entities.APIKeyScopeUsersRead}}
+
+	first, err := service.Create(ctx, userID, testOrganizationID, request)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := service.Create(ctx, userID, testOrganizationID, request); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := service.Create(ctx, userID, testOrganizationID, request); !stdErrors.Is(err, errors.ErrAPIKeyLimitReached) {
+		t.Fatalf("Create over limit error = %v, want %v", err, errors.ErrAPIKeyLimitReached)
+	}
+
+	// Отозванный ключ освобождает место
+	if err := service.Revoke(ctx, userID, first.ID, "", ""); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	if _, err := service.Create(ctx, userID, testOrganizationID, request); err != nil {
+		t.Errorf("Create after revoke: %v", err)
+	}
+}
+
+func TestAPIKeyAuthenticate(t *testing.T) {
+	ctx := context.Background()
+	owner := &entities.User{ID: uuid.New(), Role: entities.RoleManager, IsActive: true}
+	request := dto.APIKeyCreateRequestDTO{Name: "ci", Scopes: []string{entities.APIKeyScopeUsersRead}}
+
+	tests := []struct {
+		name    string
+		rawKey  func(issued string) string
+		change  func(key *entities.APIKey, owner *entities.User)
+		wantErr error
+	}{
+		{name: "valid key"},
+		{name: "wrong secret", rawKey: func(issued string) string { return issued[:len(issued)-1] + "x" }, wantErr: errors.ErrAPIKeyInvalid},
+		{name: "unknown prefix", rawKey: func(issued string) string { return "gpk_AAAAAAAA_" + issued[13:] }, wantErr: errors.ErrAPIKeyInvalid},
+		{name: "malformed key", rawKey: func(string) string { return "gpk_short" }, wantErr: errors.ErrAPIKeyInvalid},
+		{
+			name:    "revoked key",
+			change:  func(key *entities.APIKey, _ *entities.User) { key.RevokedAt = &time.Time{} },
+			wantErr: errors.ErrAPIKeyInvalid,
+		},
+		{
+			name: "expired key",
+			change: func(key *entities.APIKey, _ *entities.User) {
+				expired := time.Now().Add(-time.Second)
+				key.ExpiresAt = &expired
+			},
+			wantErr: errors.ErrAPIKeyInvalid,
+		},
+		{
+			// Отзыв ключа при выходе со всех устройств мог не записаться
+			name:    "issued before logout everywhere",
+			change:  func(_ *entities.APIKey, owner *entities.User) { owner.TokenVersion++ },
+			wantErr: errors.ErrAPIKeyInvalid,
+		},
+		{
+			name:    "blocked owner",
+			change:  func(_ *entities.APIKey, owner *entities.User) { owner.IsActive = false },
+			wantErr: errors.ErrAccountBlocked,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			owner := *owner
+			service, repository, _ := newTestAPIKeyService(&owner)
+			issued, err := service.Create(ctx, owner.ID, testOrganizationID, request)
+			if err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			rawKey := issued.Key
+			if tt.rawKey != nil {
+				rawKey = tt.rawKey(issued.Key)
+			}
+			if tt.change != nil {
+				tt.change(repository.keys[issued.ID], &owner)
+			}
+
+			key, user, err := service.Authenticate(ctx, rawKey, "10.0.0.1")
+			if !stdErrors.Is(err, tt.wantErr) {
+				t.Fatalf("Authenticate error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if key.ID != issued.ID || user.ID != owner.ID {
+				t.Errorf("Authenticate = key %s user %s, want key %s user %s", key.ID, user.ID, issued.ID, owner.ID)
+			}
+			if !key.HasScope(entities.APIKeyScopeUsersRead) || key.HasScope(entities.APIKeyScopeUsersWrite) {
+				t.Errorf("key scopes = %v, want only %s", key.Scopes, entities.APIKeyScopeUsersRead)
+			}
+			if key.LastUsedAt == nil || key.LastUsedIP != "10.0.0.1" {
+				t.Error("key usage is not recorded")
+			}
+		})
+	}
+}
+
+func TestAPIKeyRevoke(t *testing.T) {
+	ctx := context.Background()
+	owner := &entities.User{ID: uuid.New(), Role: entities.RoleManager, IsActive: true}
+	service, repository, audit := newTestAPIKeyService(owner)
+	ownerID := owner.ID
+	issued, err := service.Create(ctx, ownerID, testOrganizationID, dto.APIKeyCreateRequestDTO{Name: "ci", Scopes: []string{entities.APIKeyScopeUsersRead}})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	// Чужой ключ неотличим от несуществующего
+	if err := service.Revoke(ctx, uuid.New(), issued.ID, "", ""); !stdErrors.Is(err, errors.ErrAPIKeyNotFound) {
+		t.Fatalf("Revoke by another user error = %v, want %v", err, errors.ErrAPIKeyNotFound)
+	}
+	if repository.keys[issued.ID].RevokedAt != nil {
+		t.Fatal("key is revoked by another user")
+	}
+
+	audit.organizationID = uuid.Nil
+	if err := service.Revoke(ctx, ownerID, issued.ID, "", ""); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	if repository.keys[issued.ID].RevokedAt == nil {
+		t.Error("key is not revoked")
+	}
+	if audit.organizationID != testOrganizationID {
+		t.Errorf("revocation logged in organization %s, want %s", audit.organizationID, testOrganizationID)
+	}
+	if err := service.Revoke(ctx, ownerID, issued.ID, "", ""); !stdErrors.Is(err, errors.ErrAPIKeyNotFound) {
+		t.Errorf("second Revoke error = %v, want %v", err, errors.ErrAPIKeyNotFound)
+	}
+	if len(audit.entries) != 2 || !strings.HasPrefix(audit.entries[1], AuditActionAPIKeyRevoked) {
+		t.Errorf("audit entries = %q, want created and revoked", audit.entries)
+	}
+}
+
+func TestLogoutEverywhereRevokesAPIKeys(t *testing.T) {
+	ctx := context.Background()
+	owner := &entities.User{ID: uuid.New(), Role: entities.RoleManager, IsActive: true}
+	another := &entities.User{ID: uuid.New(), Role: entities.RoleManager, IsActive: true}
+	service, repository, _ := newTestAPIKeyService(owner, another)
+	sessions, _, _ := newTestSessionService(t)
+	sessions.userRepository = service.userRepository
+	sessions.apiKeyRepository = repository
+
+	issued, err := service.Create(ctx, owner.ID, testOrganizationID, dto.APIKeyCreateRequestDTO{Name: "ci", Scopes: []string{entities.APIKeyScopeUsersRead}})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	other, err := service.Create(ctx, another.ID, testOrganizationID, dto.APIKeyCreateRequestDTO{Name: "ci", Scopes: []string{entities.APIKeyScopeUsersRead}})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if err := sessions.LogoutEverywhere(ctx, owner.ID); err != nil {
+		t.Fatalf("LogoutEverywhere: %v", err)
+	}
+	if _, _, err := service.Authenticate(ctx, issued.Key, "10.0.0.1"); !stdErrors.Is(err, errors.ErrAPIKeyInvalid) {
+		t.Errorf("Authenticate after logout everywhere error = %v, want %v", err, errors.ErrAPIKeyInvalid)
+	}
+	if repository.keys[other.ID].RevokedAt != nil {
+		t.Error("key of another user is revoked")
+	}
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"gold_portal/internal/domain/entities"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,13 +11,21 @@ import (
 
 type AuditService interface {
 	Log(userID, entityID uuid.UUID, action, entity string, status int, clientIP, userAgent, data string) error
-	GetAll() ([]entities.AuditLog, error)
-	GetByUserID(userID uuid.UUID) ([]entities.AuditLog, error)
-	GetByEntity(entity string) ([]entities.AuditLog, error)
+	// Как Log, но запоминает API-ключ, от имени которого выполнен запрос
+	LogAPIKey(apiKeyID, userID, entityID uuid.UUID, action, entity string, status int, clientIP, userAgent, data string) error
+	// Возвращает журнал, который отмечает записи организацией, где выполнено действие
+	ForOrganization(organizationID uuid.UUID) AuditService
+	// Записи организации. Записи без организации (вход, действия на платформе) касаются
+	// всех организаций пользователя, поэтому добавляются только при includePlatform —
+	// для superuser платформы
+	GetAll(organizationID uuid.UUID, includePlatform bool) ([]entities.AuditLog, error)
+	GetByUserID(organizationID uuid.UUID, includePlatform bool, userID uuid.UUID) ([]entities.AuditLog, error)
+	GetByEntity(organizationID uuid.UUID, includePlatform bool, entity string) ([]entities.AuditLog, error)
 }
 
 type auditService struct {
-	db *gorm.DB
+	db             *gorm.DB
+	organizationID *uuid.UUID
 }
 
 func NewAuditService(db *gorm.DB) AuditService {
@@ -24,40 +33,79 @@ func NewAuditService(db *gorm.DB) AuditService {
 }
 
 func (s *auditService) Log(userID, entityID uuid.UUID, action, entity string, status int, clientIP, userAgent, data string) error {
+	return s.create(nil, userID, entityID, action, entity, status, clientIP, userAgent, data)
+}
+
+func (s *auditService) LogAPIKey(apiKeyID, userID, entityID uuid.UUID, action, entity string, status int, clientIP, userAgent, data string) error {
+	return s.create(&apiKeyID, userID, entityID, action, entity, status, clientIP, userAgent, data)
+}
+
+func (s *auditService) ForOrganization(organizationID uuid.UUID) AuditService {
+	return &auditService{db: s.db, organizationID: &organizationID}
+}
+
+func (s *auditService) create(apiKeyID *uuid.UUID, userID, entityID uuid.UUID, action, entity string, status int, clientIP, userAgent, data string) error {
 	log := entities.AuditLog{
-		UserID:    userID,
-		EntityID:  entityID,
-		Action:    action,
-		Entity:    entity,
-		Status:    status,
-		ClientIP:  clientIP,
-		UserAgent: userAgent,
-		Data:      data,
-		CreatedAt: time.Now(),
+		APIKeyID:       apiKeyID,
+		OrganizationID: s.organizationID,
+		UserID:         userID,
+		EntityID:       entityID,
+		Action:         action,
+		Entity:         entity,
+		Status:         status,
+		ClientIP:       clientIP,
+		UserAgent:      userAgent,
+		Data:           data,
+		CreatedAt:      time.Now(),
 	}
 	return s.db.Create(&log).Error
 }
 
-func (s *auditService) GetAll() ([]entities.AuditLog, error) {
+func (s *auditService) GetAll(organizationID uuid.UUID, includePlatform bool) ([]entities.AuditLog, error) {
 	var logs []entities.AuditLog
-	if err := s.db.Order("created_at desc").Find(&logs).Error; err != nil {
+	if err := s.inOrganization(organizationID, includePlatform).Order("created_at desc").Find(&logs).Error; err != nil {
 		return nil, err
 	}
 	return logs, nil
 }
 
-func (s *auditService) GetByUserID(userID uuid.UUID) ([]entities.AuditLog, error) {
+func (s *auditService) GetByUserID(organizationID uuid.UUID, includePlatform bool, userID uuid.UUID) ([]entities.AuditLog, error) {
 	var logs []entities.AuditLog
-	if err := s.db.Where("user_id = ?", userID).Order("created_at desc").Find(&logs).Error; err != nil {
+	if err := s.inOrganization(organizationID, includePlatform).Where("user_id = ?", userID).Order("created_at desc").Find(&logs).Error; err != nil {
 		return nil, err
 	}
 	return logs, nil
 }
 
-func (s *auditService) GetByEntity(entity string) ([]entities.AuditLog, error) {
+func (s *auditService) GetByEntity(organizationID uuid.UUID, includePlatform bool, entity string) ([]entities.AuditLog, error) {
 	var logs []entities.AuditLog
-	if err := s.db.Where("entity = ?", entity).Order("created_at desc").Find(&logs).Error; err != nil {
+	if err := s.inOrganization(organizationID, includePlatform).Where("entity = ?", entity).Order("created_at desc").Find(&logs).Error; err != nil {
 		return nil, err
 	}
 	return logs, nil
 }
+
+// inOrganization ограничивает записи организацией. Записи без организации (сделанные
+// вне запроса к ней или до появления организаций) относятся к организациям участников
+// и видны только при includePlatform: иначе админ одной организации видел бы вход
+// и действия её участников в других организациях
+func (s *auditService) inOrganization(organizationID uuid.UUID, includePlatform bool) *gorm.DB {
+	if !includePlatform {
+		return s.db.Where("organization_id = ?", organizationID)
+	}
+	members := s.db.Model(&entities.OrganizationMember{}).
+		Select("user_id").
+		Where("organization_id = ?", organizationID)
+	return s.db.Where("organization_id = ? OR (organization_id IS NULL AND (user_id IN (?) OR entity_id IN (?)))",
+		organizationID, members, members)
+}
+
+// maskPhone скрывает середину номера: журнал видят администраторы, а для поиска
+// пользователя достаточно entity_id
+func maskPhone(phone string) string {
+	digits := []rune(phone)
+	if len(digits) <= 6 {
+		return strings.Repeat("*", len(digits))
+	}
+	return string(digits[:4]) + strings.Repeat("*", len(digits)-6) + string(digits[len(digits)-2:])
+}
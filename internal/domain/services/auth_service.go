@@ -2,47 +2,97 @@ package services
 
 import (
 	"context"
+	stdErrors "errors"
 	"fmt"
 	"gold_portal/config"
 	"gold_portal/internal/domain/entities"
 	"gold_portal/internal/domain/repositories"
 	"gold_portal/internal/errors"
+	"gold_portal/internal/pkg/crypto"
+	"gold_portal/internal/pkg/jwt"
 	"mime/multipart"
 	"time"
 
 	"gold_portal/internal/domain/dto"
 
-	jwt "github.com/golang-jwt/jwt/v4"
+	jwtv4 "github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
 )
 
 type AuthService interface {
+	// Самостоятельная регистрация в организации по умолчанию
 	UserRegister(ctx context.Context, request dto.UserRequestDTO, photoFile *multipart.FileHeader) (*dto.UserResponseDTO, error)
-	Register(ctx context.Context, request dto.UserDashboardDTO, photoFile *multipart.FileHeader) (*dto.UserResponseDTO, error)
+	// Регистрирует пользователя участником организации с ролью из запроса
+	Register(ctx context.Context, organizationID uuid.UUID, request dto.UserDashboardDTO, photoFile *multipart.FileHeader) (*dto.UserResponseDTO, error)
+	// Проверяет пароль; при включённой 2FA возвращает mfa_token вместо токенов.
+	// При входе через приложение OAuth (ClientID) токены не выдаются: их выпускает CreateClientSession
 	Login(ctx context.Context, request dto.LoginRequestDTO) (*dto.LoginResponseDTO, error)
-	Logout(ctx context.Context, tokenString string) error
-	UserMe(ctx context.Context, id uuid.UUID) (*dto.UserResponseDTO, error)
-	VerifyToken(tokenString string) (*jwt.Token, error)
-	GetUserFromToken(ctx context.Context, token *jwt.Token) (*dto.UserResponseDTO, error)
-	GenerateRefreshToken(user *entities.User) (string, time.Time, error)
+	// Второй шаг входа: проверяет код 2FA и выдаёт токены
+	VerifyMFA(ctx context.Context, request dto.MFAVerifyRequestDTO) (*dto.LoginResponseDTO, error)
+	// Выпускает сессию и токены приложению OAuth при обмене кода авторизации
+	CreateClientSession(ctx context.Context, userID uuid.UUID, request dto.LoginRequestDTO, authMethods []string) (*dto.LoginResponseDTO, error)
+	// Вход ключом доступа (passkey) без пароля; считается двухфакторным
+	LoginWebAuthn(ctx context.Context, request dto.WebAuthnLoginFinishRequestDTO) (*dto.LoginResponseDTO, error)
+	// Отправляет код входа по SMS; не сообщает, зарегистрирован ли номер
+	SendLoginOTP(ctx context.Context, phone string) error
+	// Вход по коду из SMS без пароля; при включённой 2FA возвращает mfa_token
+	LoginOTP(ctx context.Context, request dto.OTPLoginRequestDTO) (*dto.LoginResponseDTO, error)
+	// Отправляет код подтверждения на номер пользователя
+	SendPhoneVerificationCode(ctx context.Context, userID uuid.UUID) error
+	// Отмечает номер пользователя подтверждённым
+	VerifyPhone(ctx context.Context, userID uuid.UUID, code string) error
+	// Отправляет код сброса пароля; не сообщает, зарегистрирован ли номер
+	ForgotPassword(ctx context.Context, phone string) error
+	// Меняет пароль по коду из SMS и завершает все сессии пользователя
+	ResetPassword(ctx context.Context, request dto.PasswordResetRequestDTO) error
+	// Отзывает access и refresh токены сессии; любой из них может быть пустым
+	Logout(ctx context.Context, accessToken, refreshToken string) error
+	// Отзывает access токен (черный список) или refresh токен (семейство и сессию)
+	RevokeToken(ctx context.Context, tokenString string) error
+	// Профиль пользователя с ролью и группами в организации organizationID
+	UserMe(ctx context.Context, id, organizationID uuid.UUID) (*dto.UserResponseDTO, error)
+	// Делает организацию активной в сессии и выдаёт токены с ролью в ней
+	SwitchOrganization(ctx context.Context, userID, sessionID, organizationID uuid.UUID) (*dto.TokenResponseDTO, error)
+	VerifyToken(tokenString string) (*jwtv4.Token, error)
+	GetUserFromToken(ctx context.Context, token *jwtv4.Token) (*dto.UserResponseDTO, error)
+	// Возвращает сервисного клиента, если токен выдан по client_credentials
+	GetClientFromToken(token *jwtv4.Token) (*dto.ServiceClientDTO, bool)
 	RefreshToken(ctx context.Context, refreshToken string) (*dto.TokenResponseDTO, error)
 	GetAccessTokenExpiry() time.Duration
 	GetRefreshTokenExpiry() time.Duration
 }
 
 type authService struct {
-	userRepository repositories.UserRepository
-	tokenService   TokenService
-	fileService    FileService
-	config         *config.Config
+	userRepository   repositories.UserRepository
+	tokenService     TokenService
+	sessionService   SessionService
+	twoFactorService TwoFactorService
+	webAuthnService  WebAuthnService
+	otpService       OTPService
+	lockoutService   LockoutService
+	passwordPolicy   PasswordPolicyService
+	organizations    OrganizationService
+	groupRepository  repositories.GroupRepository
+	fileService      FileService
+	jwtService       jwt.JWTService
+	config           *config.Config
 }
 
-func NewAuthService(userRepository repositories.UserRepository, tokenService TokenService, fileService FileService, config *config.Config) AuthService {
+func NewAuthService(userRepository repositories.UserRepository, tokenService TokenService, sessionService SessionService, twoFactorService TwoFactorService, webAuthnService WebAuthnService, otpService OTPService, lockoutService LockoutService, passwordPolicy PasswordPolicyService, organizations OrganizationService, groupRepository repositories.GroupRepository, fileService FileService, jwtService jwt.JWTService, config *config.Config) AuthService {
 	return &authService{
-		userRepository: userRepository,
-		tokenService:   tokenService,
-		fileService:    fileService,
-		config:         config,
+		userRepository:   userRepository,
+		tokenService:     tokenService,
+		sessionService:   sessionService,
+		twoFactorService: twoFactorService,
+		webAuthnService:  webAuthnService,
+		otpService:       otpService,
+		lockoutService:   lockoutService,
+		passwordPolicy:   passwordPolicy,
+		organizations:    organizations,
+		groupRepository:  groupRepository,
+		fileService:      fileService,
+		jwtService:       jwtService,
+		config:           config,
 	}
 }
 
@@ -54,56 +104,89 @@ func (s *authService) GetRefreshTokenExpiry() time.Duration {
 	return s.config.JWT.RefreshExpiry
 }
 
-func (s *authService) generateJWTToken(user *entities.User) (accessToken string, refreshToken string, expiresAt time.Time, err error) {
+func (s *authService) generateJWTToken(ctx context.Context, user *entities.User, session *entities.Session) (accessToken string, refreshToken string, expiresAt time.Time, err error) {
+	// Токен несёт роль пользователя в активной организации сессии
+	organizationID, role, err := s.organizations.Resolve(ctx, user, session.OrganizationID)
+	if err != nil {
+		return "", "", time.Time{}, err
+	}
+	// Группы для сервисов, которые принимают токен: на момент выдачи, обновляются при refresh.
+	// Права групп сам сервис проверяет по базе на каждом запросе
+	groups, err := s.groupRepository.GetByUser(ctx, organizationID, user.ID)
+	if err != nil {
+		return "", "", time.Time{}, err
+	}
+	groupNames := make([]string, 0, len(groups))
+	for _, group := range groups {
+		groupNames = append(groupNames, group.Name)
+	}
+
 	accessExpiry := time.Now().Add(s.config.JWT.Expiry)
 	refreshExpiry := time.Now().Add(s.config.JWT.RefreshExpiry)
+	refreshJTI := uuid.New().String()
 
 	accessClaims := jwt.MapClaims{
-		"user_id": user.ID.String(),
-		"role":    user.Role,
-		"exp":     accessExpiry.Unix(),
-		"type":    "access",
-		"jti":     uuid.New().String(),
-		"iat":     time.Now().Unix(),
+		"user_id":   user.ID.String(),
+		"role":      role,
+		"org":       organizationID.String(),
+		"groups":    groupNames,
+		"exp":       accessExpiry.Unix(),
+		"type":      "access",
+		"jti":       uuid.New().String(),
+		"sid":       session.ID.String(),
+		"iat":       time.Now().Unix(),
+		"auth_time": session.CreatedAt.Unix(),
+		"amr":       session.AuthMethods,
+		"ver":       user.TokenVersion,
 	}
 
 	refreshClaims := jwt.MapClaims{
-		"user_id": user.ID.String(),
-		"role":    user.Role,
-		"exp":     refreshExpiry.Unix(),
-		"type":    "refresh",
-		"jti":     uuid.New().String(),
-		"iat":     time.Now().Unix(),
-	}
-
-	at := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims)
-	rt := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims)
-
-	accessToken, err = at.SignedString([]byte(s.config.JWT.Secret))
+		"user_id":   user.ID.String(),
+		"role":      role,
+		"org":       organizationID.String(),
+		"exp":       refreshExpiry.Unix(),
+		"type":      "refresh",
+		"jti":       refreshJTI,
+		"sid":       session.ID.String(),
+		"fid":       session.RefreshFamilyID,
+		"iat":       time.Now().Unix(),
+		"auth_time": session.CreatedAt.Unix(),
+		"amr":       session.AuthMethods,
+		"ver":       user.TokenVersion,
+	}
+
+	// Токены, выданные приложению OAuth, несут его client_id и scope
+	if session.ClientID != "" {
+		accessClaims["client_id"] = session.ClientID
+		accessClaims["scope"] = session.Scope
+		refreshClaims["client_id"] = session.ClientID
+		refreshClaims["scope"] = session.Scope
+	}
+
+	accessToken, err = s.jwtService.CreateToken(accessClaims)
 	if err != nil {
 		return "", "", time.Time{}, fmt.Errorf("ошибка подписи access токена: %w", err)
 	}
 
-	refreshToken, err = rt.SignedString([]byte(s.config.JWT.Secret))
+	refreshToken, err = s.jwtService.CreateToken(refreshClaims)
 	if err != nil {
 		return "", "", time.Time{}, fmt.Errorf("ошибка подписи refresh токена: %w", err)
 	}
 
+	// Новый refresh токен становится единственным действующим в семействе
+	if err := s.tokenService.StoreRefreshToken(ctx, session.RefreshFamilyID, refreshJTI, refreshExpiry); err != nil {
+		return "", "", time.Time{}, err
+	}
+
 	return accessToken, refreshToken, accessExpiry, nil
 }
 
-func (s *authService) VerifyToken(tokenString string) (*jwt.Token, error) {
+func (s *authService) VerifyToken(tokenString string) (*jwtv4.Token, error) {
 	if tokenString == "" {
 		return nil, errors.ErrInvalidToken
 	}
 
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(s.config.JWT.Secret), nil
-	})
-
+	token, err := s.jwtService.ParseToken(tokenString)
 	if err != nil {
 		return nil, fmt.Errorf("invalid token: %w", err)
 	}
@@ -112,7 +195,7 @@ func (s *authService) VerifyToken(tokenString string) (*jwt.Token, error) {
 		return nil, errors.ErrInvalidToken
 	}
 
-	if claims, ok := token.Claims.(jwt.MapClaims); ok {
+	if claims, ok := token.Claims.(jwtv4.MapClaims); ok {
 		if claims["type"] != "access" {
 			return nil, errors.ErrInvalidToken
 		}
@@ -121,34 +204,12 @@ func (s *authService) VerifyToken(tokenString string) (*jwt.Token, error) {
 	return token, nil
 }
 
-func (s *authService) GenerateRefreshToken(user *entities.User) (string, time.Time, error) {
-	if s.config.JWT.Secret == "" {
-		return "", time.Time{}, errors.ErrTokenConfig
-	}
-	expiresAt := time.Now().Add(s.config.JWT.RefreshExpiry)
-
-	claims := jwt.MapClaims{
-		"user_id": user.ID.String(),
-		"exp":     expiresAt.Unix(),
-		"type":    "refresh",
-		"jti":     uuid.New().String(),
-	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(s.config.JWT.Secret))
-	if err != nil {
-		return "", time.Time{}, err
-	}
-
-	return tokenString, expiresAt, nil
-}
-
-func (s *authService) GetUserFromToken(ctx context.Context, token *jwt.Token) (*dto.UserResponseDTO, error) {
+func (s *authService) GetUserFromToken(ctx context.Context, token *jwtv4.Token) (*dto.UserResponseDTO, error) {
 	if token == nil || !token.Valid {
 		return nil, errors.ErrInvalidToken
 	}
 
-	claims, ok := token.Claims.(jwt.MapClaims)
+	claims, ok := token.Claims.(jwtv4.MapClaims)
 	if !ok {
 		return nil, errors.ErrEXP
 	}
@@ -158,6 +219,16 @@ func (s *authService) GetUserFromToken(ctx context.Context, token *jwt.Token) (*
 		return nil, errors.ErrJTI
 	}
 
+	if sessionID, ok := claims["sid"].(string); ok {
+		revoked, err := s.sessionService.IsRevoked(ctx, sessionID)
+		if err != nil {
+			return nil, err
+		}
+		if revoked {
+			return nil, errors.ErrSessionRevoked
+		}
+	}
+
 	id, err := uuid.Parse(userID)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка при парсинге ID пользователя: %w", err)
@@ -167,19 +238,82 @@ func (s *authService) GetUserFromToken(ctx context.Context, token *jwt.Token) (*
 	if err != nil {
 		return nil, fmt.Errorf("failed to find user: %w", err)
 	}
+	if !tokenVersionCurrent(claims, user) {
+		return nil, errors.ErrTokenRevoked
+	}
+	if !user.IsActive {
+		return nil, errors.ErrAccountBlocked
+	}
+
+	// Роль берётся из членства в организации токена: исключённый из организации
+	// пользователь теряет доступ к ней сразу. Токены без claim org выданы до появления
+	// организаций и относятся к организации по умолчанию
+	organizationID, role, err := s.organizations.Resolve(ctx, user, claimOrganization(claims))
+	if err != nil {
+		return nil, err
+	}
 
 	var userResp dto.UserResponseDTO
 	userResp.FromModel(user)
+	userResp.Role = role
+	userResp.OrganizationID = &organizationID
 	return &userResp, nil
 }
 
+// tokenVersionCurrent проверяет, что токен выдан после последнего выхода со всех устройств.
+// Токены без claim ver выданы до появления версий и соответствуют версии 0
+func tokenVersionCurrent(claims jwtv4.MapClaims, user *entities.User) bool {
+	version, _ := claims["ver"].(float64)
+	return int(version) == user.TokenVersion
+}
+
+func (s *authService) GetClientFromToken(token *jwtv4.Token) (*dto.ServiceClientDTO, bool) {
+	if token == nil || !token.Valid {
+		return nil, false
+	}
+
+	claims, ok := token.Claims.(jwtv4.MapClaims)
+	if !ok {
+		return nil, false
+	}
+
+	// Токены пользователей всегда содержат user_id, даже выданные через OAuth клиента
+	if _, isUser := claims["user_id"]; isUser {
+		return nil, false
+	}
+
+	clientID, _ := claims["client_id"].(string)
+	subject, _ := claims["sub"].(string)
+	id, err := uuid.Parse(subject)
+	if clientID == "" || err != nil {
+		return nil, false
+	}
+
+	role, _ := claims["role"].(string)
+	scope, _ := claims["scope"].(string)
+	return &dto.ServiceClientDTO{
+		ID:       id,
+		ClientID: clientID,
+		Role:     entities.Role(role),
+		Scope:    scope,
+	}, true
+}
+
 func (s *authService) UserRegister(ctx context.Context, request dto.UserRequestDTO, photoFile *multipart.FileHeader) (*dto.UserResponseDTO, error) {
 	existingUser, err := s.userRepository.FindByPhone(ctx, request.Phone)
 	if err == nil && existingUser != nil {
 		return nil, errors.ErrUserPhoneExists
 	}
 
-	user := request.ToModelUser(request.Password)
+	if err := s.passwordPolicy.Validate(ctx, request.Password, request.Phone, uuid.Nil); err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := crypto.HashPassword(request.Password)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка хэширования пароля: %w", err)
+	}
+	user := request.ToModelUser(hashedPassword)
 	user.ID = uuid.New()
 	user.CreatedAt = time.Now()
 	user.UpdatedAt = user.CreatedAt
@@ -192,9 +326,16 @@ func (s *authService) UserRegister(ctx context.Context, request dto.UserRequestD
 		user.Photo = filePath
 	}
 
-	if err := s.userRepository.Create(ctx, user); err != nil {
+	organization, err := s.organizations.GetDefault(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.userRepository.CreateInOrganization(ctx, user, organization.ID, entities.RoleUser); err != nil {
 		return nil, fmt.Errorf("ошибка при создании пользователя: %w", err)
 	}
+	if err := s.passwordPolicy.Remember(ctx, user.ID, user.Password); err != nil {
+		return nil, err
+	}
 
 	var userResponse dto.UserResponseDTO
 	userResponse.FromModelUser(user)
@@ -202,16 +343,29 @@ func (s *authService) UserRegister(ctx context.Context, request dto.UserRequestD
 	return &userResponse, nil
 }
 
-func (s *authService) Register(ctx context.Context, req dto.UserDashboardDTO, photoFile *multipart.FileHeader) (*dto.UserResponseDTO, error) {
+func (s *authService) Register(ctx context.Context, organizationID uuid.UUID, req dto.UserDashboardDTO, photoFile *multipart.FileHeader) (*dto.UserResponseDTO, error) {
 	existingUser, err := s.userRepository.FindByPhone(ctx, req.Phone)
 	if err == nil && existingUser != nil {
 		return nil, errors.ErrUserPhoneExists
 	}
 
-	user := req.ToModel(req.Password)
+	if err := s.passwordPolicy.Validate(ctx, req.Password, req.Phone, uuid.Nil); err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := crypto.HashPassword(req.Password)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка хэширования пароля: %w", err)
+	}
+	user := req.ToModel(hashedPassword)
 	user.ID = uuid.New()
 	user.CreatedAt = time.Now()
 	user.UpdatedAt = user.CreatedAt
+	// Роль из запроса — роль в организации; на платформе отдельно хранится только superuser
+	role := user.Role
+	if role != entities.RoleSuperUser {
+		user.Role = entities.RoleUser
+	}
 
 	if photoFile != nil {
 		filePath, err := s.saveUserPhoto(ctx, photoFile)
@@ -221,66 +375,318 @@ func (s *authService) Register(ctx context.Context, req dto.UserDashboardDTO, ph
 		user.Photo = filePath
 	}
 
-	if err := s.userRepository.Create(ctx, user); err != nil {
+	if err := s.userRepository.CreateInOrganization(ctx, user, organizationID, role); err != nil {
 		return nil, fmt.Errorf("ошибка при создании пользователя: %w", err)
 	}
+	if err := s.passwordPolicy.Remember(ctx, user.ID, user.Password); err != nil {
+		return nil, err
+	}
 
 	var userResponse dto.UserResponseDTO
 	userResponse.FromModel(user)
+	userResponse.Role = role
 
 	return &userResponse, nil
 }
 
 func (s *authService) Login(ctx context.Context, request dto.LoginRequestDTO) (*dto.LoginResponseDTO, error) {
+	if err := s.lockoutService.Check(ctx, request.Phone, request.ClientIP); err != nil {
+		return nil, err
+	}
+
 	user, err := s.userRepository.FindByPhone(ctx, request.Phone)
 	if err != nil {
-		return nil, errors.ErrInvalidCredentials
+		// Незарегистрированные номера тоже считаются, иначе подбор выдал бы, есть ли аккаунт
+		return nil, s.loginFailed(ctx, request)
 	}
 
 	if !user.IsActive {
 		return nil, errors.ErrAccountBlocked
 	}
 	if err := user.CheckPassword(request.Password); err != nil {
+		return nil, s.loginFailed(ctx, request)
+	}
+	if err := s.lockoutService.RegisterSuccess(ctx, request.Phone); err != nil {
+		return nil, err
+	}
+	s.upgradePasswordHash(ctx, user, request.Password)
+
+	return s.completeLogin(ctx, user, request, AuthMethodPassword)
+}
+
+// upgradePasswordHash пересчитывает хэш, созданный устаревшим алгоритмом или с прежними
+// параметрами: открытый пароль есть только в момент входа. Ошибка не мешает входу —
+// попробуем при следующем
+func (s *authService) upgradePasswordHash(ctx context.Context, user *entities.User, password string) {
+	if !crypto.PasswordNeedsRehash(user.Password) {
+		return
+	}
+	hashedPassword, err := crypto.HashPassword(password)
+	if err != nil {
+		return
+	}
+	if err := s.userRepository.UpdatePassword(ctx, user.ID, hashedPassword); err != nil {
+		return
+	}
+	user.Password = hashedPassword
+}
+
+func (s *authService) loginFailed(ctx context.Context, request dto.LoginRequestDTO) error {
+	if err := s.lockoutService.RegisterFailure(ctx, request.Phone, request.ClientIP, request.UserAgent); err != nil {
+		return err
+	}
+	return errors.ErrInvalidCredentials
+}
+
+func (s *authService) VerifyMFA(ctx context.Context, request dto.MFAVerifyRequestDTO) (*dto.LoginResponseDTO, error) {
+	challenge, err := s.twoFactorService.VerifyChallenge(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepository.GetID(ctx, challenge.UserID)
+	if err != nil {
+		return nil, errors.ErrInvalidCredentials
+	}
+	// Аккаунт могли заблокировать, пока пользователь вводил код
+	if !user.IsActive {
+		return nil, errors.ErrAccountBlocked
+	}
+
+	login := dto.LoginRequestDTO{
+		Phone:     user.Phone,
+		UserAgent: challenge.UserAgent,
+		ClientIP:  challenge.ClientIP,
+		ClientID:  challenge.ClientID,
+		Scope:     challenge.Scope,
+	}
+	return s.createSession(ctx, user, login, append(challenge.AuthMethods, AuthMethodOTP))
+}
+
+func (s *authService) SendLoginOTP(ctx context.Context, phone string) error {
+	user, err := s.userRepository.FindByPhone(ctx, phone)
+	if err != nil || !user.IsActive {
+		// Ограничение частоты действует и для незарегистрированных номеров,
+		// иначе по ответу можно было бы определить, есть ли аккаунт
+		return s.otpService.Throttle(ctx, phone, OTPPurposeLogin)
+	}
+	return s.otpService.Send(ctx, user.Phone, OTPPurposeLogin)
+}
+
+func (s *authService) LoginOTP(ctx context.Context, request dto.OTPLoginRequestDTO) (*dto.LoginResponseDTO, error) {
+	if err := s.otpService.Verify(ctx, request.Phone, OTPPurposeLogin, request.Code); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepository.FindByPhone(ctx, request.Phone)
+	if err != nil {
 		return nil, errors.ErrInvalidCredentials
 	}
-	accessToken, refreshToken, _, err := s.generateJWTToken(user)
+	if !user.IsActive {
+		return nil, errors.ErrAccountBlocked
+	}
+
+	// Код из SMS доказывает владение номером
+	if !user.PhoneVerified {
+		if err := s.userRepository.SetPhoneVerified(ctx, user.ID, true); err != nil {
+			return nil, err
+		}
+		user.PhoneVerified = true
+	}
+
+	login := dto.LoginRequestDTO{
+		Phone:     user.Phone,
+		UserAgent: request.UserAgent,
+		ClientIP:  request.ClientIP,
+	}
+	return s.completeLogin(ctx, user, login, AuthMethodSMS)
+}
+
+func (s *authService) SendPhoneVerificationCode(ctx context.Context, userID uuid.UUID) error {
+	user, err := s.userRepository.GetID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return s.otpService.Send(ctx, user.Phone, OTPPurposePhoneVerification)
+}
+
+func (s *authService) VerifyPhone(ctx context.Context, userID uuid.UUID, code string) error {
+	user, err := s.userRepository.GetID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.otpService.Verify(ctx, user.Phone, OTPPurposePhoneVerification, code); err != nil {
+		return err
+	}
+	return s.userRepository.SetPhoneVerified(ctx, user.ID, true)
+}
+
+func (s *authService) ForgotPassword(ctx context.Context, phone string) error {
+	user, err := s.userRepository.FindByPhone(ctx, phone)
+	if err != nil || !user.IsActive {
+		return s.otpService.Throttle(ctx, phone, OTPPurposePasswordReset)
+	}
+	return s.otpService.Send(ctx, user.Phone, OTPPurposePasswordReset)
+}
+
+func (s *authService) ResetPassword(ctx context.Context, request dto.PasswordResetRequestDTO) error {
+	if err := s.otpService.Verify(ctx, request.Phone, OTPPurposePasswordReset, request.Code); err != nil {
+		return err
+	}
+
+	user, err := s.userRepository.FindByPhone(ctx, request.Phone)
+	if err != nil {
+		return errors.ErrInvalidOTP
+	}
+	if !user.IsActive {
+		return errors.ErrAccountBlocked
+	}
+	if err := s.passwordPolicy.Validate(ctx, request.NewPassword, user.Phone, user.ID); err != nil {
+		return err
+	}
+
+	hashedPassword, err := crypto.HashPassword(request.NewPassword)
+	if err != nil {
+		return err
+	}
+	if err := s.userRepository.UpdatePassword(ctx, user.ID, hashedPassword); err != nil {
+		return fmt.Errorf("ошибка при смене пароля: %w", err)
+	}
+	if err := s.passwordPolicy.Remember(ctx, user.ID, hashedPassword); err != nil {
+		return err
+	}
+	if !user.PhoneVerified {
+		if err := s.userRepository.SetPhoneVerified(ctx, user.ID, true); err != nil {
+			return err
+		}
+	}
+
+	// Пароль мог быть скомпрометирован: все выданные токены перестают действовать
+	return s.sessionService.LogoutEverywhere(ctx, user.ID)
+}
+
+// completeLogin завершает вход после первого фактора: при включённой 2FA
+// возвращает mfa_token, иначе сразу выдаёт токены
+func (s *authService) completeLogin(ctx context.Context, user *entities.User, request dto.LoginRequestDTO, authMethod string) (*dto.LoginResponseDTO, error) {
+	if user.TOTPEnabled {
+		mfaToken, err := s.twoFactorService.CreateChallenge(ctx, user.ID, request, authMethod)
+		if err != nil {
+			return nil, err
+		}
+		return &dto.LoginResponseDTO{
+			Message:     "Two-factor authentication required",
+			MFARequired: true,
+			MFAToken:    mfaToken,
+			UserID:      user.ID,
+		}, nil
+	}
+
+	return s.createSession(ctx, user, request, []string{authMethod})
+}
+
+func (s *authService) LoginWebAuthn(ctx context.Context, request dto.WebAuthnLoginFinishRequestDTO) (*dto.LoginResponseDTO, error) {
+	userID, err := s.webAuthnService.FinishLogin(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepository.GetID(ctx, userID)
+	if err != nil {
+		return nil, errors.ErrInvalidCredentials
+	}
+	if !user.IsActive {
+		return nil, errors.ErrAccountBlocked
+	}
+
+	login := dto.LoginRequestDTO{
+		Phone:     user.Phone,
+		UserAgent: request.UserAgent,
+		ClientIP:  request.ClientIP,
+	}
+	return s.createSession(ctx, user, login, []string{AuthMethodPasskey})
+}
+
+// createSession регистрирует сессию устройства и выдаёт для неё пару токенов. Вход через
+// приложение OAuth только подтверждает пользователя: сессия появится при обмене кода
+func (s *authService) createSession(ctx context.Context, user *entities.User, request dto.LoginRequestDTO, authMethods []string) (*dto.LoginResponseDTO, error) {
+	if request.ClientID != "" {
+		return &dto.LoginResponseDTO{
+			Message:     "Success authorization",
+			UserID:      user.ID,
+			AuthMethods: authMethods,
+		}, nil
+	}
+	return s.issueSession(ctx, user, request, authMethods)
+}
+
+func (s *authService) CreateClientSession(ctx context.Context, userID uuid.UUID, request dto.LoginRequestDTO, authMethods []string) (*dto.LoginResponseDTO, error) {
+	user, err := s.userRepository.GetID(ctx, userID)
+	if err != nil {
+		return nil, errors.ErrInvalidCredentials
+	}
+	// Аккаунт могли заблокировать, пока приложение обменивало код
+	if !user.IsActive {
+		return nil, errors.ErrAccountBlocked
+	}
+	return s.issueSession(ctx, user, request, authMethods)
+}
+
+func (s *authService) issueSession(ctx context.Context, user *entities.User, request dto.LoginRequestDTO, authMethods []string) (*dto.LoginResponseDTO, error) {
+	// Сессия начинается в организации по умолчанию
+	organizationID, _, err := s.organizations.Resolve(ctx, user, uuid.Nil)
+	if err != nil {
+		return nil, err
+	}
+	session, err := s.sessionService.Create(ctx, user.ID, organizationID, request.UserAgent, request.ClientIP, request.ClientID, request.Scope, authMethods)
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, refreshToken, _, err := s.generateJWTToken(ctx, user, session)
 	if err != nil {
 		return nil, fmt.Errorf("token generation error: %w", err)
 	}
-	var userResponse dto.UserResponseDTO
-	userResponse.FromModel(user)
 
 	tokenResponse := &dto.LoginResponseDTO{
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
 		Message:      "Success authorization",
+		UserID:       user.ID,
+		SessionID:    session.ID,
 	}
 	return tokenResponse, nil
 }
 
-func (s *authService) Logout(ctx context.Context, tokenString string) error {
-	if tokenString == "" {
+func (s *authService) Logout(ctx context.Context, accessToken, refreshToken string) error {
+	if accessToken == "" && refreshToken == "" {
 		return errors.ErrInvalidToken
 	}
 
-	// Парсим токен для получения времени истечения
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	// Выход завершает всю сессию устройства, поэтому отзываем оба токена
+	for _, tokenString := range []string{accessToken, refreshToken} {
+		if tokenString == "" {
+			continue
 		}
-		return []byte(s.config.JWT.Secret), nil
-	})
+		if err := s.RevokeToken(ctx, tokenString); err != nil && !stdErrors.Is(err, errors.ErrInvalidToken) {
+			return err
+		}
+	}
 
-	if err != nil {
-		return fmt.Errorf("invalid token: %w", err)
+	return nil
+}
+
+func (s *authService) RevokeToken(ctx context.Context, tokenString string) error {
+	if tokenString == "" {
+		return errors.ErrInvalidToken
 	}
 
-	if !token.Valid {
+	// Парсим токен для получения времени истечения
+	token, err := s.jwtService.ParseToken(tokenString)
+	if err != nil || !token.Valid {
 		return errors.ErrInvalidToken
 	}
 
-	// Получаем время истечения токена
-	claims, ok := token.Claims.(jwt.MapClaims)
+	claims, ok := token.Claims.(jwtv4.MapClaims)
 	if !ok {
 		return errors.ErrInvalidToken
 	}
@@ -289,45 +695,126 @@ func (s *authService) Logout(ctx context.Context, tokenString string) error {
 	if !ok {
 		return errors.ErrInvalidToken
 	}
-
 	expiryTime := time.Unix(int64(exp), 0)
 
-	// Добавляем токен в черный список
-	err = s.tokenService.BlacklistToken(ctx, tokenString, expiryTime)
+	switch claims["type"] {
+	case "access":
+		// Добавляем токен в черный список
+		if err := s.tokenService.BlacklistToken(ctx, tokenString, expiryTime); err != nil {
+			return fmt.Errorf("failed to blacklist token: %w", err)
+		}
+	case "refresh":
+		// Отзыв refresh токена завершает сессию вместе с выданными в ней access токенами
+		familyID, _ := claims["fid"].(string)
+		if err := s.tokenService.RevokeRefreshFamily(ctx, familyID); err != nil {
+			return err
+		}
+		userID, _ := claims["user_id"].(string)
+		sessionID, _ := claims["sid"].(string)
+		if err := s.revokeSession(ctx, userID, sessionID); err != nil {
+			return err
+		}
+	default:
+		return errors.ErrInvalidToken
+	}
+
+	return nil
+}
+
+// revokeSession отзывает сессию по идентификаторам из claims токена
+func (s *authService) revokeSession(ctx context.Context, userID, sessionID string) error {
+	uid, err := uuid.Parse(userID)
 	if err != nil {
-		return fmt.Errorf("failed to blacklist token: %w", err)
+		return nil
+	}
+	sid, err := uuid.Parse(sessionID)
+	if err != nil {
+		return nil
 	}
 
+	err = s.sessionService.Revoke(ctx, uid, sid)
+	if err != nil && !stdErrors.Is(err, errors.ErrSessionNotFound) {
+		return err
+	}
 	return nil
 }
 
-func (s *authService) UserMe(ctx context.Context, id uuid.UUID) (*dto.UserResponseDTO, error) {
+func (s *authService) UserMe(ctx context.Context, id, organizationID uuid.UUID) (*dto.UserResponseDTO, error) {
 	user, err := s.userRepository.GetID(ctx, id)
 	if err != nil {
 		return nil, errors.ErrUserNotFound
 	}
+	organizationID, role, err := s.organizations.Resolve(ctx, user, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := s.groupRepository.GetByUser(ctx, organizationID, user.ID)
+	if err != nil {
+		return nil, err
+	}
 
 	var userResp dto.UserResponseDTO
 	userResp.FromModel(user)
+	userResp.Role = role
+	userResp.OrganizationID = &organizationID
+	userResp.Groups = make([]dto.GroupSummaryDTO, 0, len(groups))
+	for _, group := range groups {
+		var summary dto.GroupSummaryDTO
+		summary.FromModel(group)
+		userResp.Groups = append(userResp.Groups, summary)
+	}
 	return &userResp, nil
 }
 
+func (s *authService) SwitchOrganization(ctx context.Context, userID, sessionID, organizationID uuid.UUID) (*dto.TokenResponseDTO, error) {
+	user, err := s.userRepository.GetID(ctx, userID)
+	if err != nil {
+		return nil, errors.ErrUserNotFound
+	}
+	if !user.IsActive {
+		return nil, errors.ErrAccountBlocked
+	}
+	// Сессию не переключаем в организацию, где у пользователя нет доступа
+	organizationID, role, err := s.organizations.Resolve(ctx, user, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := s.sessionService.SwitchOrganization(ctx, userID, sessionID, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	// Новый refresh токен вытесняет прежний в семействе сессии
+	accessToken, refreshToken, expiresAt, err := s.generateJWTToken(ctx, user, session)
+	if err != nil {
+		return nil, fmt.Errorf("token generation error: %w", err)
+	}
+
+	var userResp dto.UserResponseDTO
+	userResp.FromModel(user)
+	userResp.Role = role
+	userResp.OrganizationID = &organizationID
+
+	return &dto.TokenResponseDTO{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		User:         userResp,
+		ExpiresAt:    expiresAt,
+		Message:      "Organization switched successfully",
+	}, nil
+}
+
 func (s *authService) RefreshToken(ctx context.Context, refreshToken string) (*dto.TokenResponseDTO, error) {
 	if refreshToken == "" {
 		return nil, errors.ErrInvalidToken
 	}
 
-	token, err := jwt.Parse(refreshToken, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(s.config.JWT.Secret), nil
-	})
+	token, err := s.jwtService.ParseToken(refreshToken)
 	if err != nil || !token.Valid {
 		return nil, errors.ErrInvalidToken
 	}
 
-	claims, ok := token.Claims.(jwt.MapClaims)
+	claims, ok := token.Claims.(jwtv4.MapClaims)
 	if !ok {
 		return nil, errors.ErrInvalidToken
 	}
@@ -345,28 +832,105 @@ func (s *authService) RefreshToken(ctx context.Context, refreshToken string) (*d
 		return nil, fmt.Errorf("ошибка при парсинге ID пользователя: %w", err)
 	}
 
+	sessionIDStr, _ := claims["sid"].(string)
+	sessionID, err := uuid.Parse(sessionIDStr)
+	if err != nil {
+		return nil, errors.ErrInvalidToken
+	}
+
+	familyID, _ := claims["fid"].(string)
+	jti, _ := claims["jti"].(string)
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return nil, errors.ErrInvalidToken
+	}
+
+	// Каждый refresh токен одноразовый: повторное предъявление отзывает семейство
+	if err := s.tokenService.RotateRefreshToken(ctx, familyID, jti, time.Unix(int64(exp), 0)); err != nil {
+		if stdErrors.Is(err, errors.ErrRefreshTokenReused) {
+			_ = s.sessionService.Revoke(ctx, userID, sessionID)
+		}
+		return nil, err
+	}
+
+	// Удалённый или заблокированный пользователь не получает новых токенов
 	user, err := s.userRepository.GetID(ctx, userID)
 	if err != nil {
+		if stdErrors.Is(err, errors.ErrUserNotFound) {
+			return nil, errors.ErrInvalidToken
+		}
 		return nil, fmt.Errorf("failed to find user: %w", err)
 	}
+	if !user.IsActive {
+		return nil, errors.ErrAccountBlocked
+	}
+	if !tokenVersionCurrent(claims, user) {
+		return nil, errors.ErrTokenRevoked
+	}
+
+	// Исключённый из организации пользователь не продлевает сессию в ней
+	organizationID, role, err := s.organizations.Resolve(ctx, user, claimOrganization(claims))
+	if err != nil {
+		return nil, err
+	}
 
-	accessToken, newRefreshToken, expiresAt, err := s.generateJWTToken(user)
+	if err := s.sessionService.Touch(ctx, sessionID); err != nil {
+		return nil, fmt.Errorf("ошибка при обновлении сессии: %w", err)
+	}
+
+	clientID, _ := claims["client_id"].(string)
+	scope, _ := claims["scope"].(string)
+	authTime, _ := claims["auth_time"].(float64)
+	session := &entities.Session{
+		ID:              sessionID,
+		OrganizationID:  organizationID,
+		RefreshFamilyID: familyID,
+		ClientID:        clientID,
+		Scope:           scope,
+		AuthMethods:     claimStrings(claims["amr"]),
+		CreatedAt:       time.Unix(int64(authTime), 0),
+	}
+	accessToken, newRefreshToken, expiresAt, err := s.generateJWTToken(ctx, user, session)
 	if err != nil {
 		return nil, fmt.Errorf("token generation error: %w", err)
 	}
-	_ = newRefreshToken
 
 	var userResp dto.UserResponseDTO
 	userResp.FromModel(user)
+	userResp.Role = role
+	userResp.OrganizationID = &organizationID
 
 	return &dto.TokenResponseDTO{
-		AccessToken: accessToken,
-		User:        userResp,
-		ExpiresAt:   expiresAt,
-		Message:     "Token refreshed successfully",
+		AccessToken:  accessToken,
+		RefreshToken: newRefreshToken,
+		User:         userResp,
+		ExpiresAt:    expiresAt,
+		Message:      "Token refreshed successfully",
 	}, nil
 }
 
+// claimOrganization возвращает организацию из claim org или uuid.Nil, если claim нет
+func claimOrganization(claims jwtv4.MapClaims) uuid.UUID {
+	value, _ := claims["org"].(string)
+	organizationID, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil
+	}
+	return organizationID
+}
+
+// claimStrings приводит массив из claims JWT к []string
+func claimStrings(value interface{}) []string {
+	items, _ := value.([]interface{})
+	values := make([]string, 0, len(items))
+	for _, item := range items {
+		if str, ok := item.(string); ok {
+			values = append(values, str)
+		}
+	}
+	return values
+}
+
 // saveUserPhoto сохраняет фото пользователя в MinIO
 func (s *authService) saveUserPhoto(ctx context.Context, photoFile *multipart.FileHeader) (string, error) {
 	if photoFile == nil {
@@ -0,0 +1,493 @@
+package services
+
+import (
+	"context"
+	stdErrors "errors"
+	"gold_portal/internal/domain/dto"
+	"gold_portal/internal/domain/entities"
+	"gold_portal/internal/errors"
+	"gold_portal/internal/pkg/crypto"
+	"gold_portal/internal/pkg/jwt"
+	"strings"
+	"testing"
+	"time"
+
+	jwtv4 "github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// newTestAuthService собирает authService поверх сессий в памяти и выдаёт пару токенов новой сессии
+func newTestAuthService(t *testing.T) (*authService, *fakeSessionRepository, func() (*entities.Session, string, string)) {
+	t.Helper()
+	sessions, repository, cache := newTestSessionService(t)
+	jwtService := newTestJWTService(t)
+	user := &entities.User{ID: uuid.New(), Phone: testPhone, Role: entities.RoleUser, IsActive: true}
+	users := newFakeUserRepository(user)
+	sessions.userRepository = users
+	service := &authService{
+		userRepository:  users,
+		tokenService:    NewTokenService(cache, jwtService),
+		passwordPolicy:  NewPasswordPolicyService(users, &fakePasswordHistoryRepository{}, sessions.config),
+		sessionService:  sessions,
+		organizations:   newTestOrganizationService(users, sessions),
+		groupRepository: newFakeGroupRepository(),
+		jwtService:      jwtService,
+		config:          sessions.config,
+	}
+
+	login := func() (*entities.Session, string, string) {
+		t.Helper()
+		session, err := sessions.Create(context.Background(), user.ID, testOrganizationID, "phone", "10.0.0.1", "", "", nil)
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		access, refresh, _, err := service.generateJWTToken(context.Background(), user, session)
+		if err != nil {
+			t.Fatalf("generateJWTToken: %v", err)
+		}
+		return session, access, refresh
+	}
+	return service, repository, login
+}
+
+func TestRevokeToken(t *testing.T) {
+	ctx := context.Background()
+	service, repository, login := newTestAuthService(t)
+
+	t.Run("access token is blacklisted", func(t *testing.T) {
+		session, access, _ := login()
+		if err := service.RevokeToken(ctx, access); err != nil {
+			t.Fatalf("RevokeToken: %v", err)
+		}
+		if blacklisted, _ := service.tokenService.IsTokenBlacklisted(ctx, access); !blacklisted {
+			t.Error("access token is not blacklisted")
+		}
+		// Отзыв access токена не завершает сессию
+		if repository.sessions[session.ID].RevokedAt != nil {
+			t.Error("session is revoked together with access token")
+		}
+	})
+
+	t.Run("refresh token ends the session", func(t *testing.T) {
+		session, _, refresh := login()
+		if err := service.RevokeToken(ctx, refresh); err != nil {
+			t.Fatalf("RevokeToken: %v", err)
+		}
+		if current, _ := service.tokenService.IsRefreshTokenCurrent(ctx, session.RefreshFamilyID, "any"); current {
+			t.Error("refresh family is still current")
+		}
+		if _, err := service.RefreshToken(ctx, refresh); !stdErrors.Is(err, errors.ErrInvalidToken) {
+			t.Errorf("RefreshToken after revoke error = %v, want %v", err, errors.ErrInvalidToken)
+		}
+		if repository.sessions[session.ID].RevokedAt == nil {
+			t.Error("session is not revoked")
+		}
+		if revoked, _ := service.tokenService.IsSessionRevoked(ctx, session.ID.String()); !revoked {
+			t.Error("access tokens of the session are not revoked")
+		}
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		for _, token := range []string{"", "not-a-jwt"} {
+			if err := service.RevokeToken(ctx, token); !stdErrors.Is(err, errors.ErrInvalidToken) {
+				t.Errorf("RevokeToken(%q) error = %v, want %v", token, err, errors.ErrInvalidToken)
+			}
+		}
+	})
+}
+
+func TestLogout(t *testing.T) {
+	ctx := context.Background()
+	service, repository, login := newTestAuthService(t)
+
+	session, access, refresh := login()
+	other, _, otherRefresh := login()
+	if err := service.Logout(ctx, access, refresh); err != nil {
+		t.Fatalf("Logout: %v", err)
+	}
+
+	if blacklisted, _ := service.tokenService.IsTokenBlacklisted(ctx, access); !blacklisted {
+		t.Error("access token is not blacklisted")
+	}
+	if repository.sessions[session.ID].RevokedAt == nil {
+		t.Error("session is not revoked")
+	}
+	// Остальные устройства пользователя остаются в системе
+	if repository.sessions[other.ID].RevokedAt != nil {
+		t.Error("another session is revoked")
+	}
+	if _, err := service.RefreshToken(ctx, otherRefresh); err != nil {
+		t.Errorf("RefreshToken of another session: %v", err)
+	}
+
+	// Повторный выход с уже отозванными токенами не считается ошибкой
+	if err := service.Logout(ctx, access, refresh); err != nil {
+		t.Errorf("second Logout: %v", err)
+	}
+	if err := service.Logout(ctx, "", ""); !stdErrors.Is(err, errors.ErrInvalidToken) {
+		t.Errorf("Logout without tokens error = %v, want %v", err, errors.ErrInvalidToken)
+	}
+}
+
+func TestGenerateJWTTokenCarriesOAuthClient(t *testing.T) {
+	ctx := context.Background()
+	service, _, _ := newTestAuthService(t)
+	user, err := service.userRepository.FindByPhone(ctx, testPhone)
+	if err != nil {
+		t.Fatalf("FindByPhone: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		clientID string
+		scope    string
+	}{
+		{name: "first-party session"},
+		{name: "oauth session", clientID: "spa", scope: "openid profile"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session, err := service.sessionService.Create(ctx, user.ID, testOrganizationID, "phone", "10.0.0.1", tt.clientID, tt.scope, nil)
+			if err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			access, refresh, _, err := service.generateJWTToken(ctx, user, session)
+			if err != nil {
+				t.Fatalf("generateJWTToken: %v", err)
+			}
+
+			for _, tokenString := range []string{access, refresh} {
+				token, err := service.jwtService.ParseToken(tokenString)
+				if err != nil {
+					t.Fatalf("ParseToken: %v", err)
+				}
+				claims := token.Claims.(jwtv4.MapClaims)
+				clientID, _ := claims["client_id"].(string)
+				scope, _ := claims["scope"].(string)
+				if clientID != tt.clientID || scope != tt.scope {
+					t.Errorf("%s token client_id = %q, scope = %q, want %q, %q", claims["type"], clientID, scope, tt.clientID, tt.scope)
+				}
+				if claims["org"] != testOrganizationID.String() {
+					t.Errorf("%s token org = %v, want %s", claims["type"], claims["org"], testOrganizationID)
+				}
+			}
+		})
+	}
+}
+
+func TestForgotPassword(t *testing.T) {
+	ctx := context.Background()
+	service, _, _ := newTestAuthService(t)
+	otp, sender, _ := newTestOTPService(t)
+	service.otpService = otp
+	const unknownPhone = "+996555000000"
+
+	if err := service.ForgotPassword(ctx, testPhone); err != nil {
+		t.Fatalf("ForgotPassword: %v", err)
+	}
+	if sender.codes[testPhone] == "" {
+		t.Error("reset code is not sent to a registered phone")
+	}
+
+	// Для незарегистрированного номера код не отправляется, но ответ тот же
+	if err := service.ForgotPassword(ctx, unknownPhone); err != nil {
+		t.Fatalf("ForgotPassword for unknown phone: %v", err)
+	}
+	if _, sent := sender.codes[unknownPhone]; sent {
+		t.Error("reset code is sent to an unknown phone")
+	}
+
+	// Повторный запрос ограничен одинаково для обоих номеров
+	for _, phone := range []string{testPhone, unknownPhone} {
+		if err := service.ForgotPassword(ctx, phone); !stdErrors.Is(err, errors.ErrOTPTooManyRequests) {
+			t.Errorf("repeated ForgotPassword(%s) error = %v, want %v", phone, err, errors.ErrOTPTooManyRequests)
+		}
+	}
+}
+
+func TestResetPassword(t *testing.T) {
+	ctx := context.Background()
+	const newPassword = "NewPassword123"
+
+	tests := []struct {
+		name    string
+		code    string
+		blocked bool
+		wantErr error
+	}{
+		{name: "valid code"},
+		{name: "wrong code", code: "000000x", wantErr: errors.ErrInvalidOTP},
+		{name: "blocked user", blocked: true, wantErr: errors.ErrAccountBlocked},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, repository, login := newTestAuthService(t)
+			otp, sender, _ := newTestOTPService(t)
+			service.otpService = otp
+			user, _ := service.userRepository.FindByPhone(ctx, testPhone)
+			user.Password = "old-hash"
+			user.IsActive = !tt.blocked
+			session, _, refresh := login()
+
+			if err := otp.Send(ctx, testPhone, OTPPurposePasswordReset); err != nil {
+				t.Fatalf("Send: %v", err)
+			}
+			code := tt.code
+			if code == "" {
+				code = sender.codes[testPhone]
+			}
+
+			err := service.ResetPassword(ctx, dto.PasswordResetRequestDTO{
+				Phone:           testPhone,
+				Code:            code,
+				NewPassword:     newPassword,
+				ConfirmPassword: newPassword,
+			})
+			if !stdErrors.Is(err, tt.wantErr) {
+				t.Fatalf("ResetPassword error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if user.Password != "old-hash" {
+					t.Error("password changed after a failed reset")
+				}
+				return
+			}
+
+			if err := crypto.CheckPassword(user.Password, newPassword); err != nil {
+				t.Errorf("new password does not match the stored hash: %v", err)
+			}
+			// Владение номером подтверждено кодом из SMS
+			if !user.PhoneVerified {
+				t.Error("phone is not marked as verified")
+			}
+			if repository.sessions[session.ID].RevokedAt == nil {
+				t.Error("session is not revoked after reset")
+			}
+			if _, err := service.RefreshToken(ctx, refresh); !stdErrors.Is(err, errors.ErrInvalidToken) {
+				t.Errorf("RefreshToken after reset error = %v, want %v", err, errors.ErrInvalidToken)
+			}
+			// Код сброса одноразовый
+			if err := otp.Verify(ctx, testPhone, OTPPurposePasswordReset, code); !stdErrors.Is(err, errors.ErrInvalidOTP) {
+				t.Errorf("reused reset code error = %v, want %v", err, errors.ErrInvalidOTP)
+			}
+		})
+	}
+}
+
+func TestLogoutEverywhere(t *testing.T) {
+	ctx := context.Background()
+	service, repository, login := newTestAuthService(t)
+	user, _ := service.userRepository.FindByPhone(ctx, testPhone)
+
+	phone, phoneAccess, phoneRefresh := login()
+	laptop, _, laptopRefresh := login()
+	// Токен без сессии отзывается только сменой версии
+	sessionless, err := service.jwtService.CreateToken(jwt.MapClaims{
+		"user_id": user.ID.String(),
+		"type":    "access",
+		"ver":     user.TokenVersion,
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		t.Fatalf("CreateToken: %v", err)
+	}
+
+	if err := service.sessionService.LogoutEverywhere(ctx, user.ID); err != nil {
+		t.Fatalf("LogoutEverywhere: %v", err)
+	}
+	if user.TokenVersion != 1 {
+		t.Errorf("token version = %d, want 1", user.TokenVersion)
+	}
+	for _, session := range []*entities.Session{phone, laptop} {
+		if repository.sessions[session.ID].RevokedAt == nil {
+			t.Errorf("session %s is not revoked", session.UserAgent)
+		}
+	}
+	for _, refresh := range []string{phoneRefresh, laptopRefresh} {
+		if _, err := service.RefreshToken(ctx, refresh); err == nil {
+			t.Error("refresh token issued before logout is accepted")
+		}
+	}
+	for name, access := range map[string]string{"session": phoneAccess, "sessionless": sessionless} {
+		token, err := service.VerifyToken(access)
+		if err != nil {
+			t.Fatalf("VerifyToken(%s): %v", name, err)
+		}
+		if _, err := service.GetUserFromToken(ctx, token); err == nil {
+			t.Errorf("%s access token issued before logout is accepted", name)
+		}
+	}
+
+	// Новый вход выдаёт токены с новой версией
+	_, access, refresh := login()
+	token, err := service.VerifyToken(access)
+	if err != nil {
+		t.Fatalf("VerifyToken: %v", err)
+	}
+	if _, err := service.GetUserFromToken(ctx, token); err != nil {
+		t.Errorf("GetUserFromToken after new login: %v", err)
+	}
+	if _, err := service.RefreshToken(ctx, refresh); err != nil {
+		t.Errorf("RefreshToken after new login: %v", err)
+	}
+}
+
+func TestTokenVersionCurrent(t *testing.T) {
+	tests := []struct {
+		name        string
+		claims      jwtv4.MapClaims
+		userVersion int
+		want        bool
+	}{
+		{name: "same version", claims: jwtv4.MapClaims{"ver": float64(2)}, userVersion: 2, want: true},
+		{name: "older version", claims: jwtv4.MapClaims{"ver": float64(1)}, userVersion: 2},
+		// Токены, выданные до появления версий
+		{name: "no version claim", claims: jwtv4.MapClaims{}, want: true},
+		{name: "no version claim after logout", claims: jwtv4.MapClaims{}, userVersion: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := &entities.User{TokenVersion: tt.userVersion}
+			if got := tokenVersionCurrent(tt.claims, user); got != tt.want {
+				t.Errorf("tokenVersionCurrent = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeactivatedUserTokens(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		change  func(users *fakeUserRepository, user *entities.User)
+		wantErr error
+	}{
+		{
+			name:    "blocked user",
+			change:  func(_ *fakeUserRepository, user *entities.User) { user.IsActive = false },
+			wantErr: errors.ErrAccountBlocked,
+		},
+		{
+			name:    "deleted user",
+			change:  func(users *fakeUserRepository, user *entities.User) { delete(users.users, user.ID) },
+			wantErr: errors.ErrInvalidToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _, login := newTestAuthService(t)
+			users := service.userRepository.(*fakeUserRepository)
+			user, _ := users.FindByPhone(ctx, testPhone)
+			_, access, refresh := login()
+
+			// Блокировка без выхода со всех устройств: токены ещё не отозваны
+			tt.change(users, user)
+
+			if _, err := service.RefreshToken(ctx, refresh); !stdErrors.Is(err, tt.wantErr) {
+				t.Errorf("RefreshToken error = %v, want %v", err, tt.wantErr)
+			}
+			token, err := service.VerifyToken(access)
+			if err != nil {
+				t.Fatalf("VerifyToken: %v", err)
+			}
+			if _, err := service.GetUserFromToken(ctx, token); err == nil {
+				t.Error("access token of a deactivated user is accepted")
+			}
+		})
+	}
+}
+
+func TestUpgradePasswordHash(t *testing.T) {
+	ctx := context.Background()
+	const password = "correct horse"
+
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("bcrypt: %v", err)
+	}
+	currentHash, err := crypto.HashPassword(password)
+	if err != nil {
+		t.Fatalf("HashPassword: %v", err)
+	}
+	outdated := testPasswordHasherConfig
+	outdated.Argon2Iterations++
+	outdatedHasher, err := crypto.NewPasswordHasher(outdated)
+	if err != nil {
+		t.Fatalf("NewPasswordHasher: %v", err)
+	}
+	outdatedHash, err := outdatedHasher.Hash(password)
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		hash       string
+		wantRehash bool
+	}{
+		{name: "bcrypt hash is upgraded", hash: string(bcryptHash), wantRehash: true},
+		{name: "argon2id with old parameters is upgraded", hash: outdatedHash, wantRehash: true},
+		{name: "current hash is kept", hash: currentHash},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := &entities.User{ID: uuid.New(), Password: tt.hash}
+			repository := newFakeUserRepository(&entities.User{ID: user.ID, Password: tt.hash})
+			service := &authService{userRepository: repository}
+
+			service.upgradePasswordHash(ctx, user, password)
+
+			stored := repository.users[user.ID].Password
+			if (stored != tt.hash) != tt.wantRehash {
+				t.Fatalf("stored hash changed = %v, want %v", stored != tt.hash, tt.wantRehash)
+			}
+			if stored != user.Password {
+				t.Fatal("user in memory must carry the stored hash")
+			}
+			if tt.wantRehash && !strings.HasPrefix(stored, "$"+crypto.AlgorithmArgon2id+"$") {
+				t.Fatalf("rehashed value %q is not argon2id", stored)
+			}
+			if err := crypto.CheckPassword(stored, password); err != nil {
+				t.Fatalf("password does not match the stored hash: %v", err)
+			}
+		})
+	}
+}
+
+func TestCreateClientSession(t *testing.T) {
+	ctx := context.Background()
+	service, repository, _ := newTestAuthService(t)
+	user, err := service.userRepository.FindByPhone(ctx, testPhone)
+	if err != nil {
+		t.Fatalf("FindByPhone: %v", err)
+	}
+	request := dto.LoginRequestDTO{UserAgent: "spa", ClientIP: "10.0.0.1", ClientID: "spa", Scope: "profile"}
+
+	response, err := service.CreateClientSession(ctx, user.ID, request, []string{AuthMethodPassword})
+	if err != nil {
+		t.Fatalf("CreateClientSession: %v", err)
+	}
+	session := repository.sessions[response.SessionID]
+	if session == nil || session.ClientID != "spa" || session.Scope != "profile" {
+		t.Fatalf("session %+v, want session of client spa with scope profile", session)
+	}
+
+	// Аккаунт заблокировали между входом и обменом кода
+	user.IsActive = false
+	if _, err := service.CreateClientSession(ctx, user.ID, request, nil); !stdErrors.Is(err, errors.ErrAccountBlocked) {
+		t.Errorf("CreateClientSession for blocked user error = %v, want %v", err, errors.ErrAccountBlocked)
+	}
+	if _, err := service.CreateClientSession(ctx, uuid.New(), request, nil); !stdErrors.Is(err, errors.ErrInvalidCredentials) {
+		t.Errorf("CreateClientSession for unknown user error = %v, want %v", err, errors.ErrInvalidCredentials)
+	}
+	if len(repository.sessions) != 1 {
+		t.Errorf("sessions = %d, want 1", len(repository.sessions))
+	}
+}
@@ -0,0 +1,343 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"gold_portal/internal/domain/dto"
+	"gold_portal/internal/domain/entities"
+	"gold_portal/internal/domain/repositories"
+	"gold_portal/internal/errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	AuditActionGroupCreated       = "GROUP_CREATED"
+	AuditActionGroupUpdated       = "GROUP_UPDATED"
+	AuditActionGroupDeleted       = "GROUP_DELETED"
+	AuditActionGroupMemberAdded   = "GROUP_MEMBER_ADDED"
+	AuditActionGroupMemberRemoved = "GROUP_MEMBER_REMOVED"
+)
+
+// GroupService группы организации. Действовать над группой может только тот, у кого
+// самого есть все её права, а роли группы он может назначить
+type GroupService interface {
+	GetAll(ctx context.Context, organizationID uuid.UUID) ([]*dto.GroupResponseDTO, error)
+	Get(ctx context.Context, organizationID, id uuid.UUID) (*dto.GroupResponseDTO, error)
+	Create(ctx context.Context, actorID, organizationID uuid.UUID, request dto.GroupRequestDTO) (*dto.GroupResponseDTO, error)
+	Update(ctx context.Context, actorID, organizationID, id uuid.UUID, request dto.GroupUpdateDTO) (*dto.GroupResponseDTO, error)
+	Delete(ctx context.Context, actorID, organizationID, id uuid.UUID, clientIP, userAgent string) error
+	// Добавляет в группу участника организации
+	AddMember(ctx context.Context, actorID, organizationID, id uuid.UUID, request dto.GroupMemberRequestDTO) error
+	RemoveMember(ctx context.Context, actorID, organizationID, id, userID uuid.UUID, clientIP, userAgent string) error
+}
+
+type groupService struct {
+	groupRepository      repositories.GroupRepository
+	permissionRepository repositories.PermissionRepository
+	userRepository       repositories.UserRepository
+	organizations        OrganizationService
+	roleService          RoleService
+	permissionService    PermissionService
+	auditService         AuditService
+}
+
+func NewGroupService(groupRepository repositories.GroupRepository, permissionRepository repositories.PermissionRepository, userRepository repositories.UserRepository, organizations OrganizationService, roleService RoleService, permissionService PermissionService, auditService AuditService) GroupService {
+	return &groupService{
+		groupRepository:      groupRepository,
+		permissionRepository: permissionRepository,
+		userRepository:       userRepository,
+		organizations:        organizations,
+		roleService:          roleService,
+		permissionService:    permissionService,
+		auditService:         auditService,
+	}
+}
+
+func (s *groupService) GetAll(ctx context.Context, organizationID uuid.UUID) ([]*dto.GroupResponseDTO, error) {
+	groups, err := s.groupRepository.GetAll(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]*dto.GroupResponseDTO, 0, len(groups))
+	for _, group := range groups {
+		groupResponse, err := s.toResponse(ctx, group)
+		if err != nil {
+			return nil, err
+		}
+		response = append(response, groupResponse)
+	}
+	return response, nil
+}
+
+func (s *groupService) Get(ctx context.Context, organizationID, id uuid.UUID) (*dto.GroupResponseDTO, error) {
+	group, err := s.groupRepository.GetID(ctx, organizationID, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.toResponse(ctx, group)
+}
+
+func (s *groupService) Create(ctx context.Context, actorID, organizationID uuid.UUID, request dto.GroupRequestDTO) (*dto.GroupResponseDTO, error) {
+	if _, err := s.groupRepository.GetByName(ctx, organizationID, request.Name); err == nil {
+		return nil, errors.ErrGroupExists
+	}
+	roles, permissions, err := s.checkGrant(ctx, actorID, organizationID, request.Roles, request.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	group := &entities.Group{
+		ID:             uuid.New(),
+		OrganizationID: organizationID,
+		Name:           request.Name,
+		Description:    request.Description,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := s.groupRepository.Create(ctx, group, roles, permissions); err != nil {
+		return nil, fmt.Errorf("ошибка при создании группы: %w", err)
+	}
+
+	if err := s.auditService.ForOrganization(organizationID).Log(actorID, group.ID, AuditActionGroupCreated, "Group",
+		http.StatusCreated, request.ClientIP, request.UserAgent,
+		fmt.Sprintf("Создана группа %s, роли: %s, права: %s", group.Name, joinRoles(roles), strings.Join(permissions, ", "))); err != nil {
+		return nil, err
+	}
+
+	var response dto.GroupResponseDTO
+	response.FromModel(group, roles, permissions, []uuid.UUID{})
+	return &response, nil
+}
+
+func (s *groupService) Update(ctx context.Context, actorID, organizationID, id uuid.UUID, request dto.GroupUpdateDTO) (*dto.GroupResponseDTO, error) {
+	group, err := s.groupRepository.GetID(ctx, organizationID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkGroup(ctx, actorID, group); err != nil {
+		return nil, err
+	}
+
+	var changes []string
+	if request.Name != nil && *request.Name != group.Name {
+		if _, err := s.groupRepository.GetByName(ctx, organizationID, *request.Name); err == nil {
+			return nil, errors.ErrGroupExists
+		}
+		changes = append(changes, fmt.Sprintf("переименована в %s", *request.Name))
+		group.Name = *request.Name
+	}
+	if request.Description != nil {
+		group.Description = *request.Description
+	}
+
+	// nil оставляет роли или права группы как есть
+	var roles []entities.Role
+	var permissions []string
+	if request.Roles != nil || request.Permissions != nil {
+		var requestedRoles []entities.Role
+		if request.Roles != nil {
+			requestedRoles = *request.Roles
+		}
+		var requestedPermissions []string
+		if request.Permissions != nil {
+			requestedPermissions = *request.Permissions
+		}
+		roles, permissions, err = s.checkGrant(ctx, actorID, organizationID, requestedRoles, requestedPermissions)
+		if err != nil {
+			return nil, err
+		}
+		if request.Roles == nil {
+			roles = nil
+		} else {
+			changes = append(changes, fmt.Sprintf("роли: %s", joinRoles(roles)))
+		}
+		if request.Permissions == nil {
+			permissions = nil
+		} else {
+			changes = append(changes, fmt.Sprintf("права: %s", strings.Join(permissions, ", ")))
+		}
+	}
+
+	group.UpdatedAt = time.Now()
+	if err := s.groupRepository.Update(ctx, group, roles, permissions); err != nil {
+		return nil, fmt.Errorf("ошибка при изменении группы: %w", err)
+	}
+
+	if err := s.auditService.ForOrganization(organizationID).Log(actorID, group.ID, AuditActionGroupUpdated, "Group",
+		http.StatusOK, request.ClientIP, request.UserAgent,
+		fmt.Sprintf("Изменена группа %s: %s", group.Name, strings.Join(changes, "; "))); err != nil {
+		return nil, err
+	}
+	return s.toResponse(ctx, group)
+}
+
+func (s *groupService) Delete(ctx context.Context, actorID, organizationID, id uuid.UUID, clientIP, userAgent string) error {
+	group, err := s.groupRepository.GetID(ctx, organizationID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.checkGroup(ctx, actorID, group); err != nil {
+		return err
+	}
+
+	if err := s.groupRepository.Delete(ctx, group); err != nil {
+		return fmt.Errorf("ошибка при удалении группы: %w", err)
+	}
+
+	return s.auditService.ForOrganization(organizationID).Log(actorID, group.ID, AuditActionGroupDeleted, "Group",
+		http.StatusOK, clientIP, userAgent, fmt.Sprintf("Удалена группа %s", group.Name))
+}
+
+func (s *groupService) AddMember(ctx context.Context, actorID, organizationID, id uuid.UUID, request dto.GroupMemberRequestDTO) error {
+	group, err := s.groupRepository.GetID(ctx, organizationID, id)
+	if err != nil {
+		return err
+	}
+	// Иначе можно было бы вступить в группу с правами шире собственных
+	if err := s.checkGroup(ctx, actorID, group); err != nil {
+		return err
+	}
+	member, err := s.organizations.IsMember(ctx, organizationID, request.UserID)
+	if err != nil {
+		return err
+	}
+	if !member {
+		return errors.ErrNotOrganizationMember
+	}
+
+	if err := s.groupRepository.AddMember(ctx, group.ID, request.UserID); err != nil {
+		return fmt.Errorf("ошибка при добавлении в группу: %w", err)
+	}
+
+	return s.auditService.ForOrganization(organizationID).Log(actorID, request.UserID, AuditActionGroupMemberAdded, "User",
+		http.StatusOK, request.ClientIP, request.UserAgent, fmt.Sprintf("Пользователь добавлен в группу %s", group.Name))
+}
+
+func (s *groupService) RemoveMember(ctx context.Context, actorID, organizationID, id, userID uuid.UUID, clientIP, userAgent string) error {
+	group, err := s.groupRepository.GetID(ctx, organizationID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.checkGroup(ctx, actorID, group); err != nil {
+		return err
+	}
+
+	if err := s.groupRepository.RemoveMember(ctx, group.ID, userID); err != nil {
+		return fmt.Errorf("ошибка при исключении из группы: %w", err)
+	}
+
+	return s.auditService.ForOrganization(organizationID).Log(actorID, userID, AuditActionGroupMemberRemoved, "User",
+		http.StatusOK, clientIP, userAgent, fmt.Sprintf("Пользователь исключён из группы %s", group.Name))
+}
+
+func (s *groupService) toResponse(ctx context.Context, group *entities.Group) (*dto.GroupResponseDTO, error) {
+	roles, err := s.groupRepository.GetRoles(ctx, group.ID)
+	if err != nil {
+		return nil, err
+	}
+	permissions, err := s.groupRepository.GetPermissions(ctx, group.ID)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.groupRepository.GetMembers(ctx, group.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var response dto.GroupResponseDTO
+	response.FromModel(group, roles, permissions, members)
+	return &response, nil
+}
+
+// checkGroup проверяет, что actor может выдать все текущие роли и права группы
+func (s *groupService) checkGroup(ctx context.Context, actorID uuid.UUID, group *entities.Group) error {
+	roles, err := s.groupRepository.GetRoles(ctx, group.ID)
+	if err != nil {
+		return err
+	}
+	permissions, err := s.groupRepository.GetPermissions(ctx, group.ID)
+	if err != nil {
+		return err
+	}
+	_, _, err = s.checkGrant(ctx, actorID, group.OrganizationID, roles, permissions)
+	return err
+}
+
+// checkGrant проверяет, что роли существуют и ранг каждой не выше ранга роли actor
+// в организации, а права известны и есть у самого actor с учётом его групп.
+// Возвращает роли и права без повторов
+func (s *groupService) checkGrant(ctx context.Context, actorID, organizationID uuid.UUID, roles []entities.Role, permissions []string) ([]entities.Role, []string, error) {
+	actor, err := s.userRepository.GetID(ctx, actorID)
+	if err != nil {
+		return nil, nil, err
+	}
+	_, actorRole, err := s.organizations.Resolve(ctx, actor, organizationID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resultRoles := make([]entities.Role, 0, len(roles))
+	for _, role := range roles {
+		if containsRole(resultRoles, role) {
+			continue
+		}
+		if err := s.roleService.CheckAssignable(ctx, actorRole, role); err != nil {
+			return nil, nil, err
+		}
+		resultRoles = append(resultRoles, role)
+	}
+
+	known, err := s.permissionRepository.GetAll(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	actorPermissions, err := s.permissionService.GetUserPermissions(ctx, actorRole, organizationID, actorID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resultPermissions := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		if containsString(resultPermissions, permission) {
+			continue
+		}
+		isKnown := false
+		for _, candidate := range known {
+			if candidate.Name == permission {
+				isKnown = true
+				break
+			}
+		}
+		if !isKnown {
+			return nil, nil, fmt.Errorf("%w: %s", errors.ErrUnknownPermission, permission)
+		}
+		if !containsString(actorPermissions, permission) {
+			return nil, nil, errors.ErrForbidden
+		}
+		resultPermissions = append(resultPermissions, permission)
+	}
+	return resultRoles, resultPermissions, nil
+}
+
+func containsRole(roles []entities.Role, role entities.Role) bool {
+	for _, candidate := range roles {
+		if candidate == role {
+			return true
+		}
+	}
+	return false
+}
+
+func joinRoles(roles []entities.Role) string {
+	names := make([]string, 0, len(roles))
+	for _, role := range roles {
+		names = append(names, string(role))
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	stdErrors "errors"
+	"gold_portal/internal/domain/entities"
+	"gold_portal/internal/errors"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// fakeOrganizationService считает платформенную роль пользователя его ролью в любой организации
+type fakeOrganizationService struct {
+	OrganizationService
+}
+
+func (s *fakeOrganizationService) Resolve(_ context.Context, user *entities.User, organizationID uuid.UUID) (uuid.UUID, entities.Role, error) {
+	return organizationID, user.Role, nil
+}
+
+func TestGroupServiceCheckGrant(t *testing.T) {
+	ctx := context.Background()
+	organizationID := uuid.New()
+	actor := &entities.User{ID: uuid.New(), Role: "editor"}
+
+	groups := newFakeGroupRepository()
+	// Право audit:read у actor есть только через группу
+	groups.grant(organizationID, actor.ID, "reviewers", nil, []string{entities.PermissionAuditRead})
+
+	cache, _ := newTestCache(t)
+	permissions := newTestPermissionRepository()
+	service := &groupService{
+		groupRepository:      groups,
+		permissionRepository: permissions,
+		userRepository:       newFakeUserRepository(actor),
+		organizations:        &fakeOrganizationService{},
+		roleService: &fakeRoleService{ranks: map[entities.Role]int{
+			entities.RoleUser:  100,
+			"auditor":          150,
+			"editor":           200,
+			entities.RoleAdmin: 300,
+		}},
+		permissionService: NewPermissionService(permissions, groups, cache),
+	}
+
+	tests := []struct {
+		name            string
+		roles           []entities.Role
+		permissions     []string
+		wantRoles       []entities.Role
+		wantPermissions []string
+		wantErr         error
+	}{
+		{
+			name:            "own role and permissions",
+			roles:           []entities.Role{"editor", "auditor"},
+			permissions:     []string{entities.PermissionUsersRead},
+			wantRoles:       []entities.Role{"editor", "auditor"},
+			wantPermissions: []string{entities.PermissionUsersRead},
+		},
+		{
+			name:            "permission held through a group",
+			permissions:     []string{entities.PermissionAuditRead},
+			wantRoles:       []entities.Role{},
+			wantPermissions: []string{entities.PermissionAuditRead},
+		},
+		{
+			name:            "duplicates are dropped",
+			roles:           []entities.Role{"auditor", "auditor"},
+			permissions:     []string{entities.PermissionUsersRead, entities.PermissionUsersRead},
+			wantRoles:       []entities.Role{"auditor"},
+			wantPermissions: []string{entities.PermissionUsersRead},
+		},
+		{
+			name:    "role ranked above the actor",
+			roles:   []entities.Role{entities.RoleAdmin},
+			wantErr: errors.ErrRoleRankTooHigh,
+		},
+		{
+			name:    "unknown role",
+			roles:   []entities.Role{"ghost"},
+			wantErr: errors.ErrInvalidUserRole,
+		},
+		{
+			name:        "permission the actor does not hold",
+			permissions: []string{entities.PermissionUsersDelete},
+			wantErr:     errors.ErrForbidden,
+		},
+		{
+			name:        "unknown permission",
+			permissions: []string{"reports:export"},
+			wantErr:     errors.ErrUnknownPermission,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			roles, granted, err := service.checkGrant(ctx, actor.ID, organizationID, tt.roles, tt.permissions)
+			if !stdErrors.Is(err, tt.wantErr) {
+				t.Fatalf("checkGrant: got %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if !reflect.DeepEqual(roles, tt.wantRoles) {
+				t.Fatalf("roles %v, want %v", roles, tt.wantRoles)
+			}
+			if !reflect.DeepEqual(granted, tt.wantPermissions) {
+				t.Fatalf("permissions %v, want %v", granted, tt.wantPermissions)
+			}
+		})
+	}
+}
+
+func TestGroupServiceCheckGrantIsPerOrganization(t *testing.T) {
+	ctx := context.Background()
+	organizationID, otherOrganizationID := uuid.New(), uuid.New()
+	actor := &entities.User{ID: uuid.New(), Role: entities.RoleUser}
+
+	groups := newFakeGroupRepository()
+	groups.grant(otherOrganizationID, actor.ID, "reviewers", nil, []string{entities.PermissionAuditRead})
+
+	cache, _ := newTestCache(t)
+	permissions := newTestPermissionRepository()
+	service := &groupService{
+		groupRepository:      groups,
+		permissionRepository: permissions,
+		userRepository:       newFakeUserRepository(actor),
+		organizations:        &fakeOrganizationService{},
+		roleService:          &fakeRoleService{},
+		permissionService:    NewPermissionService(permissions, groups, cache),
+	}
+
+	// Право из группы другой организации нельзя раздавать в этой
+	if _, _, err := service.checkGrant(ctx, actor.ID, organizationID, nil, []string{entities.PermissionAuditRead}); !stdErrors.Is(err, errors.ErrForbidden) {
+		t.Fatalf("checkGrant: got %v, want ErrForbidden", err)
+	}
+	if _, _, err := service.checkGrant(ctx, actor.ID, otherOrganizationID, nil, []string{entities.PermissionAuditRead}); err != nil {
+		t.Fatalf("checkGrant in granting organization: %v", err)
+	}
+}
@@ -0,0 +1,390 @@
+package services
+
+import (
+	"context"
+	"gold_portal/config"
+	"gold_portal/internal/domain/entities"
+	"gold_portal/internal/domain/repositories"
+	"gold_portal/internal/errors"
+	"gold_portal/internal/infrastructure/cache"
+	"gold_portal/internal/pkg/crypto"
+	"gold_portal/internal/pkg/jwt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+)
+
+// testPasswordHasherConfig argon2id с минимальными параметрами: тестам не нужна стойкость хэша
+var testPasswordHasherConfig = crypto.PasswordHasherConfig{
+	Algorithm:         crypto.AlgorithmArgon2id,
+	Argon2Memory:      1024,
+	Argon2Iterations:  1,
+	Argon2Parallelism: 1,
+	Argon2SaltLength:  16,
+	Argon2KeyLength:   32,
+}
+
+// testOrganizationID организация, в которой действуют тестовые пользователи
+var testOrganizationID = uuid.MustParse("00000000-0000-0000-0000-0000000000a1")
+
+func TestMain(m *testing.M) {
+	hasher, err := crypto.NewPasswordHasher(testPasswordHasherConfig)
+	if err != nil {
+		panic(err)
+	}
+	crypto.SetPasswordHasher(hasher)
+	os.Exit(m.Run())
+}
+
+// newTestCache поднимает miniredis и возвращает кэш поверх него вместе с сервером,
+// чтобы тест мог сдвигать время через FastForward
+func newTestCache(t *testing.T) (Cache, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	redisCache, err := cache.NewRedisCache(&config.Config{Redis: config.RedisConfig{
+		Driver:          "redis",
+		Host:            server.Host(),
+		Port:            server.Port(),
+		ConnectAttempts: 1,
+	}})
+	if err != nil {
+		t.Fatalf("NewRedisCache: %v", err)
+	}
+	t.Cleanup(func() { _ = redisCache.Close() })
+	return redisCache, server
+}
+
+// newTestJWTService подписывает токены ключом EdDSA, который живёт только в памяти
+func newTestJWTService(t *testing.T) jwt.JWTService {
+	t.Helper()
+	keyring, err := jwt.NewKeyring(jwt.KeyringConfig{
+		Algorithm:        jwt.AlgorithmEdDSA,
+		RotationInterval: time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("NewKeyring: %v", err)
+	}
+	return jwt.NewJWTService(keyring)
+}
+
+// fakeUserRepository хранит пользователей и их членства в организациях в памяти;
+// остальные методы интерфейса не нужны тестам
+type fakeUserRepository struct {
+	repositories.UserRepository
+	users   map[uuid.UUID]*entities.User
+	members []*entities.OrganizationMember
+}
+
+// newFakeUserRepository делает пользователей участниками testOrganizationID с их ролью
+func newFakeUserRepository(users ...*entities.User) *fakeUserRepository {
+	repository := &fakeUserRepository{users: make(map[uuid.UUID]*entities.User, len(users))}
+	for _, user := range users {
+		repository.users[user.ID] = user
+		repository.members = append(repository.members, &entities.OrganizationMember{
+			OrganizationID: testOrganizationID,
+			UserID:         user.ID,
+			Role:           user.Role,
+		})
+	}
+	return repository
+}
+
+func (r *fakeUserRepository) member(organizationID, userID uuid.UUID) *entities.OrganizationMember {
+	for _, member := range r.members {
+		if member.OrganizationID == organizationID && member.UserID == userID {
+			return member
+		}
+	}
+	return nil
+}
+
+func (r *fakeUserRepository) GetInOrganization(_ context.Context, organizationID, id uuid.UUID) (*entities.User, error) {
+	user, ok := r.users[id]
+	member := r.member(organizationID, id)
+	if !ok || member == nil {
+		return nil, errors.ErrUserNotFound
+	}
+	user.Role = member.Role
+	return user, nil
+}
+
+func (r *fakeUserRepository) UpdateRole(_ context.Context, organizationID, id uuid.UUID, role entities.Role) error {
+	member := r.member(organizationID, id)
+	if member == nil {
+		return errors.ErrNotOrganizationMember
+	}
+	switch {
+	case role == entities.RoleSuperUser:
+		r.users[id].Role = entities.RoleSuperUser
+	case member.Role == entities.RoleSuperUser:
+		r.users[id].Role = entities.RoleUser
+	}
+	member.Role = role
+	return nil
+}
+
+func (r *fakeUserRepository) GetID(_ context.Context, id uuid.UUID) (*entities.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, errors.ErrUserNotFound
+	}
+	return user, nil
+}
+
+func (r *fakeUserRepository) FindByPhone(_ context.Context, phone string) (*entities.User, error) {
+	for _, user := range r.users {
+		if user.Phone == phone {
+			return user, nil
+		}
+	}
+	return nil, errors.ErrUserNotFound
+}
+
+func (r *fakeUserRepository) UpdatePassword(_ context.Context, id uuid.UUID, hashedPassword string) error {
+	user, ok := r.users[id]
+	if !ok {
+		return errors.ErrUserNotFound
+	}
+	user.Password = hashedPassword
+	return nil
+}
+
+func (r *fakeUserRepository) SetPhoneVerified(_ context.Context, id uuid.UUID, verified bool) error {
+	user, ok := r.users[id]
+	if !ok {
+		return errors.ErrUserNotFound
+	}
+	user.PhoneVerified = verified
+	return nil
+}
+
+func (r *fakeUserRepository) IncrementTokenVersion(_ context.Context, id uuid.UUID) error {
+	user, ok := r.users[id]
+	if !ok {
+		return errors.ErrUserNotFound
+	}
+	user.TokenVersion++
+	return nil
+}
+
+func (r *fakeUserRepository) Patch(_ context.Context, user *entities.User) error {
+	if _, ok := r.users[user.ID]; !ok {
+		return errors.ErrUserNotFound
+	}
+	r.users[user.ID] = user
+	return nil
+}
+
+func (r *fakeUserRepository) Delete(_ context.Context, id uuid.UUID) error {
+	delete(r.users, id)
+	members := r.members[:0]
+	for _, member := range r.members {
+		if member.UserID != id {
+			members = append(members, member)
+		}
+	}
+	r.members = members
+	return nil
+}
+
+// fakeOrganizationRepository хранит организации в памяти, а членства — в fakeUserRepository.
+// Организация testOrganizationID уже создана и считается организацией по умолчанию
+type fakeOrganizationRepository struct {
+	repositories.OrganizationRepository
+	users         *fakeUserRepository
+	organizations map[uuid.UUID]*entities.Organization
+}
+
+func newFakeOrganizationRepository(users *fakeUserRepository) *fakeOrganizationRepository {
+	return &fakeOrganizationRepository{
+		users: users,
+		organizations: map[uuid.UUID]*entities.Organization{
+			testOrganizationID: {ID: testOrganizationID, Slug: entities.DefaultOrganizationSlug, Name: "По умолчанию"},
+		},
+	}
+}
+
+// newTestOrganizationService разрешает организации по членствам из users
+func newTestOrganizationService(users *fakeUserRepository, sessionService SessionService) OrganizationService {
+	return NewOrganizationService(newFakeOrganizationRepository(users), users, sessionService, &fakeAuditService{})
+}
+
+func (r *fakeOrganizationRepository) Create(_ context.Context, organization *entities.Organization) error {
+	if organization.ID == uuid.Nil {
+		organization.ID = uuid.New()
+	}
+	r.organizations[organization.ID] = organization
+	return nil
+}
+
+func (r *fakeOrganizationRepository) GetID(_ context.Context, id uuid.UUID) (*entities.Organization, error) {
+	organization, ok := r.organizations[id]
+	if !ok {
+		return nil, errors.ErrOrganizationNotFound
+	}
+	return organization, nil
+}
+
+func (r *fakeOrganizationRepository) GetBySlug(_ context.Context, slug string) (*entities.Organization, error) {
+	for _, organization := range r.organizations {
+		if organization.Slug == slug {
+			return organization, nil
+		}
+	}
+	return nil, errors.ErrOrganizationNotFound
+}
+
+func (r *fakeOrganizationRepository) GetMember(_ context.Context, organizationID, userID uuid.UUID) (*entities.OrganizationMember, error) {
+	member := r.users.member(organizationID, userID)
+	if member == nil {
+		return nil, errors.ErrNotOrganizationMember
+	}
+	return member, nil
+}
+
+func (r *fakeOrganizationRepository) GetMemberships(_ context.Context, userID uuid.UUID) ([]*entities.OrganizationMember, error) {
+	var members []*entities.OrganizationMember
+	for _, member := range r.users.members {
+		if member.UserID == userID {
+			member.Organization = *r.organizations[member.OrganizationID]
+			members = append(members, member)
+		}
+	}
+	return members, nil
+}
+
+func (r *fakeOrganizationRepository) SaveMember(_ context.Context, member *entities.OrganizationMember) error {
+	if previous := r.users.member(member.OrganizationID, member.UserID); previous != nil {
+		previous.Role = member.Role
+		return nil
+	}
+	r.users.members = append(r.users.members, member)
+	return nil
+}
+
+func (r *fakeOrganizationRepository) RemoveMember(_ context.Context, organizationID, userID uuid.UUID) error {
+	members := r.users.members[:0]
+	for _, member := range r.users.members {
+		if member.OrganizationID != organizationID || member.UserID != userID {
+			members = append(members, member)
+		}
+	}
+	r.users.members = members
+	return nil
+}
+
+// fakePasswordHistoryRepository хранит историю от старых паролей к новым
+type fakePasswordHistoryRepository struct {
+	repositories.PasswordHistoryRepository
+	entries []*entities.PasswordHistory
+}
+
+func (r *fakePasswordHistoryRepository) Add(_ context.Context, entry *entities.PasswordHistory, keep int) error {
+	r.entries = append(r.entries, entry)
+	if len(r.entries) > keep {
+		r.entries = r.entries[len(r.entries)-keep:]
+	}
+	return nil
+}
+
+func (r *fakePasswordHistoryRepository) GetRecent(_ context.Context, _ uuid.UUID, limit int) ([]*entities.PasswordHistory, error) {
+	recent := make([]*entities.PasswordHistory, 0, limit)
+	for i := len(r.entries) - 1; i >= 0 && len(recent) < limit; i-- {
+		recent = append(recent, r.entries[i])
+	}
+	return recent, nil
+}
+
+// fakeGroupRepository хранит выдачи через группы по паре организация и пользователь
+type fakeGroupRepository struct {
+	repositories.GroupRepository
+	groups      map[[2]uuid.UUID][]*entities.Group
+	roles       map[[2]uuid.UUID][]entities.Role
+	permissions map[[2]uuid.UUID][]string
+}
+
+func newFakeGroupRepository() *fakeGroupRepository {
+	return &fakeGroupRepository{
+		groups:      make(map[[2]uuid.UUID][]*entities.Group),
+		roles:       make(map[[2]uuid.UUID][]entities.Role),
+		permissions: make(map[[2]uuid.UUID][]string),
+	}
+}
+
+// grant добавляет пользователя в группу name, которая выдаёт roles и permissions
+func (r *fakeGroupRepository) grant(organizationID, userID uuid.UUID, name string, roles []entities.Role, permissions []string) {
+	key := [2]uuid.UUID{organizationID, userID}
+	r.groups[key] = append(r.groups[key], &entities.Group{ID: uuid.New(), OrganizationID: organizationID, Name: name})
+	r.roles[key] = append(r.roles[key], roles...)
+	r.permissions[key] = append(r.permissions[key], permissions...)
+}
+
+func (r *fakeGroupRepository) GetByUser(_ context.Context, organizationID, userID uuid.UUID) ([]*entities.Group, error) {
+	return r.groups[[2]uuid.UUID{organizationID, userID}], nil
+}
+
+func (r *fakeGroupRepository) GetUserGrants(_ context.Context, organizationID, userID uuid.UUID) ([]entities.Role, []string, error) {
+	key := [2]uuid.UUID{organizationID, userID}
+	return r.roles[key], r.permissions[key], nil
+}
+
+// fakePermissionRepository отдаёт права ролей из памяти и считает обращения к «базе»
+type fakePermissionRepository struct {
+	repositories.PermissionRepository
+	roles map[entities.Role][]string
+	reads int
+}
+
+func (r *fakePermissionRepository) GetAll(context.Context) ([]*entities.Permission, error) {
+	seen := make(map[string]bool)
+	var permissions []*entities.Permission
+	for _, granted := range r.roles {
+		for _, name := range granted {
+			if !seen[name] {
+				seen[name] = true
+				permissions = append(permissions, &entities.Permission{Name: name})
+			}
+		}
+	}
+	return permissions, nil
+}
+
+func (r *fakePermissionRepository) GetByRole(_ context.Context, role entities.Role) ([]string, error) {
+	r.reads++
+	return r.roles[role], nil
+}
+
+// fakeRoleService сравнивает ранги ролей так же, как roleService
+type fakeRoleService struct {
+	RoleService
+	ranks map[entities.Role]int
+}
+
+func (s *fakeRoleService) Exists(_ context.Context, role entities.Role) (bool, error) {
+	_, ok := s.ranks[role]
+	return ok, nil
+}
+
+func (s *fakeRoleService) CheckAssignable(_ context.Context, actor, role entities.Role) error {
+	rank, ok := s.ranks[role]
+	if !ok {
+		return errors.ErrInvalidUserRole
+	}
+	if rank > s.ranks[actor] {
+		return errors.ErrRoleRankTooHigh
+	}
+	return nil
+}
+
+// newTestRoleService знает встроенные роли с рангами по старшинству
+func newTestRoleService() *fakeRoleService {
+	return &fakeRoleService{ranks: map[entities.Role]int{
+		entities.RoleUser:      100,
+		entities.RoleManager:   200,
+		entities.RoleAdmin:     300,
+		entities.RoleSuperUser: 400,
+	}}
+}
@@ -0,0 +1,195 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"gold_portal/config"
+	"gold_portal/internal/domain/repositories"
+	"gold_portal/internal/errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	AuditActionLoginLockout = "LOGIN_LOCKOUT"
+	AuditActionLoginUnlock  = "LOGIN_UNLOCK"
+)
+
+// LockoutService защищает вход по паролю от подбора: считает неудачные попытки
+// по номеру и по IP, после нескольких ошибок заставляет ждать всё дольше,
+// а после порога временно блокирует вход
+type LockoutService interface {
+	// Возвращает *errors.LockoutError, если вход с этого номера или IP сейчас запрещён
+	Check(ctx context.Context, phone, clientIP string) error
+	// Учитывает неудачную попытку входа
+	RegisterFailure(ctx context.Context, phone, clientIP, userAgent string) error
+	// Сбрасывает неудачные попытки номера после успешного входа
+	RegisterSuccess(ctx context.Context, phone string) error
+	// Снимает блокировку с аккаунта участника организации; запись попадает в журнал этой организации
+	Unlock(ctx context.Context, actorID, organizationID, userID uuid.UUID, clientIP, userAgent string) error
+}
+
+type lockoutService struct {
+	userRepository repositories.UserRepository
+	auditService   AuditService
+	cache          Cache
+	config         *config.Config
+}
+
+func NewLockoutService(userRepository repositories.UserRepository, auditService AuditService, cache Cache, config *config.Config) LockoutService {
+	return &lockoutService{
+		userRepository: userRepository,
+		auditService:   auditService,
+		cache:          cache,
+		config:         config,
+	}
+}
+
+func (s *lockoutService) Check(ctx context.Context, phone, clientIP string) error {
+	if err := s.check(ctx, errors.LockoutScopeAccount, phone); err != nil {
+		return err
+	}
+	return s.check(ctx, errors.LockoutScopeIP, clientIP)
+}
+
+func (s *lockoutService) RegisterFailure(ctx context.Context, phone, clientIP, userAgent string) error {
+	cfg := s.config.Lockout
+	if err := s.registerFailure(ctx, errors.LockoutScopeAccount, phone, cfg.PhoneMaxAttempts, clientIP, userAgent); err != nil {
+		return err
+	}
+	return s.registerFailure(ctx, errors.LockoutScopeIP, clientIP, cfg.IPMaxAttempts, clientIP, userAgent)
+}
+
+func (s *lockoutService) RegisterSuccess(ctx context.Context, phone string) error {
+	return s.reset(ctx, errors.LockoutScopeAccount, phone)
+}
+
+func (s *lockoutService) Unlock(ctx context.Context, actorID, organizationID, userID uuid.UUID, clientIP, userAgent string) error {
+	user, err := s.userRepository.GetInOrganization(ctx, organizationID, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.reset(ctx, errors.LockoutScopeAccount, user.Phone); err != nil {
+		return err
+	}
+
+	return s.auditService.ForOrganization(organizationID).Log(actorID, userID, AuditActionLoginUnlock, "User", http.StatusOK,
+		clientIP, userAgent, "Администратор снял блокировку входа")
+}
+
+func (s *lockoutService) check(ctx context.Context, scope, subject string) error {
+	if subject == "" {
+		return nil
+	}
+	if retryAfter := s.remaining(ctx, lockoutKey(scope, subject)); retryAfter > 0 {
+		return &errors.LockoutError{Scope: scope, Locked: true, RetryAfter: retryAfter}
+	}
+	if retryAfter := s.remaining(ctx, loginDelayKey(scope, subject)); retryAfter > 0 {
+		return &errors.LockoutError{Scope: scope, RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+func (s *lockoutService) registerFailure(ctx context.Context, scope, subject string, maxAttempts int, clientIP, userAgent string) error {
+	if subject == "" {
+		return nil
+	}
+	cfg := s.config.Lockout
+
+	failuresKey := loginFailuresKey(scope, subject)
+	failures, err := s.cache.Incr(ctx, failuresKey)
+	if err != nil {
+		return err
+	}
+	if failures == 1 {
+		if err := s.cache.Expire(ctx, failuresKey, cfg.Window); err != nil {
+			return err
+		}
+	}
+
+	if maxAttempts > 0 && failures >= int64(maxAttempts) {
+		return s.lock(ctx, scope, subject, failures, clientIP, userAgent)
+	}
+
+	// Первые попытки без задержки, дальше задержка удваивается до MaxDelay
+	extra := failures - int64(cfg.FreeAttempts)
+	if extra <= 0 || cfg.BaseDelay <= 0 {
+		return nil
+	}
+	delay := cfg.MaxDelay
+	if extra < 32 && cfg.BaseDelay<<(extra-1) < cfg.MaxDelay {
+		delay = cfg.BaseDelay << (extra - 1)
+	}
+	return s.setUntil(ctx, loginDelayKey(scope, subject), delay)
+}
+
+func (s *lockoutService) lock(ctx context.Context, scope, subject string, failures int64, clientIP, userAgent string) error {
+	duration := s.config.Lockout.Duration
+	if err := s.setUntil(ctx, lockoutKey(scope, subject), duration); err != nil {
+		return err
+	}
+	// Счётчик начинается заново после окончания блокировки
+	if err := s.cache.Delete(ctx, loginFailuresKey(scope, subject)); err != nil {
+		return err
+	}
+	if err := s.cache.Delete(ctx, loginDelayKey(scope, subject)); err != nil {
+		return err
+	}
+
+	entityID := uuid.Nil
+	data := fmt.Sprintf("Вход с IP %s заблокирован на %s после %d неудачных попыток", subject, duration, failures)
+	if scope == errors.LockoutScopeAccount {
+		data = fmt.Sprintf("Вход с номера %s заблокирован на %s после %d неудачных попыток", maskPhone(subject), duration, failures)
+		if user, err := s.userRepository.FindByPhone(ctx, subject); err == nil {
+			entityID = user.ID
+		}
+	}
+	return s.auditService.Log(uuid.Nil, entityID, AuditActionLoginLockout, "Auth", http.StatusTooManyRequests,
+		clientIP, userAgent, data)
+}
+
+func (s *lockoutService) reset(ctx context.Context, scope, subject string) error {
+	for _, key := range []string{
+		loginFailuresKey(scope, subject),
+		loginDelayKey(scope, subject),
+		lockoutKey(scope, subject),
+	} {
+		if err := s.cache.Delete(ctx, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setUntil сохраняет время окончания ожидания: по нему считается Retry-After
+func (s *lockoutService) setUntil(ctx context.Context, key string, duration time.Duration) error {
+	until := time.Now().Add(duration).Unix()
+	return s.cache.Set(ctx, key, strconv.FormatInt(until, 10), duration)
+}
+
+func (s *lockoutService) remaining(ctx context.Context, key string) time.Duration {
+	value, err := s.cache.Get(ctx, key)
+	if err != nil {
+		return 0
+	}
+	until, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return time.Until(time.Unix(until, 0))
+}
+
+func loginFailuresKey(scope, subject string) string {
+	return fmt.Sprintf("login_failures:%s:%s", scope, subject)
+}
+
+func loginDelayKey(scope, subject string) string {
+	return fmt.Sprintf("login_delay:%s:%s", scope, subject)
+}
+
+func lockoutKey(scope, subject string) string {
+	return fmt.Sprintf("login_lockout:%s:%s", scope, subject)
+}
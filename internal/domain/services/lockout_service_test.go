@@ -0,0 +1,177 @@
+package services
+
+import (
+	"context"
+	stdErrors "errors"
+	"fmt"
+	"gold_portal/config"
+	"gold_portal/internal/domain/entities"
+	"gold_portal/internal/errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func newTestLockoutService(t *testing.T) (*lockoutService, *fakeAuditService) {
+	cache, _ := newTestCache(t)
+	audit := &fakeAuditService{}
+	service := &lockoutService{
+		userRepository: newFakeUserRepository(),
+		auditService:   audit,
+		cache:          cache,
+		config: &config.Config{Lockout: config.LockoutConfig{
+			PhoneMaxAttempts: 7,
+			IPMaxAttempts:    100,
+			FreeAttempts:     2,
+			BaseDelay:        time.Second,
+			MaxDelay:         5 * time.Second,
+			Window:           15 * time.Minute,
+			Duration:         15 * time.Minute,
+		}},
+	}
+	return service, audit
+}
+
+func TestLockoutBackoff(t *testing.T) {
+	ctx := context.Background()
+	service, audit := newTestLockoutService(t)
+
+	tests := []struct {
+		failures   int
+		wantDelay  time.Duration
+		wantLocked bool
+	}{
+		{failures: 1},
+		{failures: 2},
+		{failures: 3, wantDelay: time.Second},
+		{failures: 4, wantDelay: 2 * time.Second},
+		{failures: 5, wantDelay: 4 * time.Second},
+		// Задержка не превышает MaxDelay
+		{failures: 6, wantDelay: 5 * time.Second},
+		{failures: 7, wantDelay: 15 * time.Minute, wantLocked: true},
+	}
+
+	for _, tt := range tests {
+		if err := service.RegisterFailure(ctx, testPhone, "10.0.0.1", "test-agent"); err != nil {
+			t.Fatalf("failure %d: RegisterFailure: %v", tt.failures, err)
+		}
+
+		err := service.Check(ctx, testPhone, "10.0.0.1")
+		if tt.wantDelay == 0 {
+			if err != nil {
+				t.Fatalf("failure %d: got %v, want no delay", tt.failures, err)
+			}
+			continue
+		}
+
+		var lockoutErr *errors.LockoutError
+		if !stdErrors.As(err, &lockoutErr) {
+			t.Fatalf("failure %d: got %v, want LockoutError", tt.failures, err)
+		}
+		if lockoutErr.Scope != errors.LockoutScopeAccount || lockoutErr.Locked != tt.wantLocked {
+			t.Fatalf("failure %d: got scope %s locked %v, want account locked %v",
+				tt.failures, lockoutErr.Scope, lockoutErr.Locked, tt.wantLocked)
+		}
+		// Время окончания хранится с точностью до секунды
+		if lockoutErr.RetryAfter <= tt.wantDelay-time.Second || lockoutErr.RetryAfter > tt.wantDelay {
+			t.Fatalf("failure %d: retry after %s, want about %s", tt.failures, lockoutErr.RetryAfter, tt.wantDelay)
+		}
+	}
+
+	if len(audit.entries) != 1 || !strings.HasPrefix(audit.entries[0], AuditActionLoginLockout) {
+		t.Fatalf("audit entries %q, want one lockout", audit.entries)
+	}
+	if strings.Contains(audit.entries[0], testPhone) {
+		t.Fatalf("audit entry %q exposes the full phone number", audit.entries[0])
+	}
+}
+
+func TestLockoutSuccessResetsAccount(t *testing.T) {
+	ctx := context.Background()
+	service, _ := newTestLockoutService(t)
+
+	for i := 0; i < 3; i++ {
+		if err := service.RegisterFailure(ctx, testPhone, "10.0.0.1", "test-agent"); err != nil {
+			t.Fatalf("RegisterFailure: %v", err)
+		}
+	}
+	if err := service.Check(ctx, testPhone, "10.0.0.1"); err == nil {
+		t.Fatal("expected delay after failures")
+	}
+
+	// Успешный вход сбрасывает счётчик номера; задержку IP проверяем отдельно,
+	// поэтому дальше вход идёт с другого адреса
+	if err := service.RegisterSuccess(ctx, testPhone); err != nil {
+		t.Fatalf("RegisterSuccess: %v", err)
+	}
+	if err := service.Check(ctx, testPhone, "10.0.0.2"); err != nil {
+		t.Fatalf("Check after success: %v", err)
+	}
+	if err := service.RegisterFailure(ctx, testPhone, "10.0.0.2", "test-agent"); err != nil {
+		t.Fatalf("RegisterFailure: %v", err)
+	}
+	if err := service.Check(ctx, testPhone, "10.0.0.2"); err != nil {
+		t.Fatalf("Check after one new failure: %v", err)
+	}
+}
+
+func TestLockoutIPScope(t *testing.T) {
+	ctx := context.Background()
+	service, _ := newTestLockoutService(t)
+	service.config.Lockout.IPMaxAttempts = 3
+
+	// Подбор по разным номерам с одного IP блокирует IP, но не номера
+	for i := 0; i < 3; i++ {
+		phone := fmt.Sprintf("+99655500000%d", i)
+		if err := service.RegisterFailure(ctx, phone, "10.0.0.1", "test-agent"); err != nil {
+			t.Fatalf("RegisterFailure: %v", err)
+		}
+	}
+
+	var lockoutErr *errors.LockoutError
+	if err := service.Check(ctx, testPhone, "10.0.0.1"); !stdErrors.As(err, &lockoutErr) ||
+		lockoutErr.Scope != errors.LockoutScopeIP || !lockoutErr.Locked {
+		t.Fatalf("Check from attacking IP: got %v, want IP lockout", err)
+	}
+	if err := service.Check(ctx, testPhone, "10.0.0.2"); err != nil {
+		t.Fatalf("Check from another IP: %v", err)
+	}
+}
+
+func TestLockoutUnlock(t *testing.T) {
+	ctx := context.Background()
+	service, audit := newTestLockoutService(t)
+	user := &entities.User{ID: uuid.New(), Phone: testPhone, Role: entities.RoleUser, IsActive: true}
+	service.userRepository = newFakeUserRepository(user)
+	service.config.Lockout.PhoneMaxAttempts = 3
+
+	for i := 0; i < 3; i++ {
+		if err := service.RegisterFailure(ctx, testPhone, "10.0.0.1", "test-agent"); err != nil {
+			t.Fatalf("RegisterFailure: %v", err)
+		}
+	}
+	audit.entries = nil
+
+	// Пользователь чужой организации для администратора не существует
+	if err := service.Unlock(ctx, uuid.New(), uuid.New(), user.ID, "10.0.0.9", "test-agent"); !stdErrors.Is(err, errors.ErrUserNotFound) {
+		t.Fatalf("Unlock in another organization error = %v, want %v", err, errors.ErrUserNotFound)
+	}
+	if err := service.Check(ctx, testPhone, "10.0.0.2"); err == nil {
+		t.Fatal("refused Unlock removed the lockout")
+	}
+
+	if err := service.Unlock(ctx, uuid.New(), testOrganizationID, user.ID, "10.0.0.9", "test-agent"); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	if err := service.Check(ctx, testPhone, "10.0.0.2"); err != nil {
+		t.Fatalf("Check after Unlock: %v", err)
+	}
+	if len(audit.entries) != 1 || !strings.HasPrefix(audit.entries[0], AuditActionLoginUnlock) {
+		t.Fatalf("audit entries %q, want one unlock", audit.entries)
+	}
+	if audit.organizationID != testOrganizationID {
+		t.Errorf("unlock logged in organization %s, want %s", audit.organizationID, testOrganizationID)
+	}
+}
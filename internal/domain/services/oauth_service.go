@@ -0,0 +1,799 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	stdErrors "errors"
+	"fmt"
+	"gold_portal/config"
+	"gold_portal/internal/domain/dto"
+	"gold_portal/internal/domain/entities"
+	"gold_portal/internal/domain/repositories"
+	"gold_portal/internal/errors"
+	"gold_portal/internal/pkg/crypto"
+	"gold_portal/internal/pkg/jwt"
+	"net/url"
+	"strings"
+	"time"
+
+	jwtv4 "github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+)
+
+const (
+	authorizationCodeTTL = 5 * time.Minute
+
+	// Метод plain не поддерживается: перехваченный code_challenge равен code_verifier
+	codeChallengeMethodS256 = "S256"
+
+	grantTypeAuthorizationCode = "authorization_code"
+	grantTypeRefreshToken      = "refresh_token"
+	grantTypeClientCredentials = "client_credentials"
+)
+
+type OAuthService interface {
+	// Регистрирует клиента. Роль сервисного клиента не может быть выше роли actorRole
+	RegisterClient(ctx context.Context, actorRole entities.Role, request dto.OAuthClientRequestDTO) (*dto.OAuthClientResponseDTO, error)
+	GetClients(ctx context.Context) ([]*dto.OAuthClientResponseDTO, error)
+	DeleteClient(ctx context.Context, id uuid.UUID) error
+	// Проверяет запрос авторизации. Если клиент или redirect_uri не прошли
+	// проверку, клиент не возвращается и ошибку нельзя отправлять на redirect_uri
+	ValidateAuthorizeRequest(ctx context.Context, request dto.AuthorizeRequestDTO) (*entities.OAuthClient, error)
+	// Проверяет учётные данные через AuthService.Login и возвращает redirect_uri с кодом.
+	// Если у пользователя включена 2FA, вместо redirect_uri возвращается mfa_token
+	Authorize(ctx context.Context, request dto.AuthorizeRequestDTO, login dto.LoginRequestDTO) (redirectURI string, mfaToken string, err error)
+	// Завершает вход кодом 2FA и возвращает redirect_uri с кодом авторизации
+	AuthorizeMFA(ctx context.Context, request dto.AuthorizeRequestDTO, verify dto.MFAVerifyRequestDTO) (string, error)
+	// Возвращает redirect_uri с ошибкой авторизации
+	AuthorizeErrorRedirect(request dto.AuthorizeRequestDTO, err error) string
+	Token(ctx context.Context, request dto.TokenRequestDTO) (*dto.OAuthTokenResponseDTO, error)
+	// Отзывает токен, выданный клиенту; неизвестные и чужие токены игнорируются
+	Revoke(ctx context.Context, request dto.RevokeRequestDTO) error
+	// Интроспекция токена по запросу зарегистрированного конфиденциального клиента
+	Introspect(ctx context.Context, request dto.IntrospectRequestDTO) (*dto.IntrospectResponseDTO, error)
+	// Возвращает claims пользователя для /userinfo в пределах выданных scope
+	UserInfo(ctx context.Context, userID uuid.UUID, scope string) (*dto.UserInfoDTO, error)
+	OpenIDConfiguration() dto.OpenIDConfigurationDTO
+}
+
+type oauthService struct {
+	clientRepository repositories.OAuthClientRepository
+	userRepository   repositories.UserRepository
+	roleService      RoleService
+	authService      AuthService
+	sessionService   SessionService
+	tokenService     TokenService
+	jwtService       jwt.JWTService
+	cache            Cache
+	config           *config.Config
+}
+
+// authorizationCode данные, сохраняемые за выданным кодом авторизации
+type authorizationCode struct {
+	ClientID      string    `json:"client_id"`
+	RedirectURI   string    `json:"redirect_uri"`
+	Scope         string    `json:"scope"`
+	CodeChallenge string    `json:"code_challenge"`
+	Nonce         string    `json:"nonce"`
+	AuthTime      int64     `json:"auth_time"`
+	UserID        uuid.UUID `json:"user_id"`
+	// Данные входа для сессии, которая создаётся только при обмене кода
+	AuthMethods []string `json:"amr"`
+	UserAgent   string   `json:"user_agent"`
+	ClientIP    string   `json:"client_ip"`
+}
+
+func NewOAuthService(clientRepository repositories.OAuthClientRepository, userRepository repositories.UserRepository, roleService RoleService, authService AuthService, sessionService SessionService, tokenService TokenService, jwtService jwt.JWTService, cache Cache, config *config.Config) OAuthService {
+	return &oauthService{
+		clientRepository: clientRepository,
+		userRepository:   userRepository,
+		roleService:      roleService,
+		authService:      authService,
+		sessionService:   sessionService,
+		tokenService:     tokenService,
+		jwtService:       jwtService,
+		cache:            cache,
+		config:           config,
+	}
+}
+
+func (s *oauthService) RegisterClient(ctx context.Context, actorRole entities.Role, request dto.OAuthClientRequestDTO) (*dto.OAuthClientResponseDTO, error) {
+	for _, redirectURI := range request.RedirectURIs {
+		parsed, err := url.Parse(redirectURI)
+		if err != nil || parsed.Scheme == "" || parsed.Fragment != "" {
+			return nil, errors.NewOAuthError(errors.OAuthInvalidRequest, "invalid redirect_uri: "+redirectURI)
+		}
+	}
+
+	clientID, err := crypto.GenerateRandomString(16)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка генерации client_id: %w", err)
+	}
+
+	client := request.ToModel(clientID)
+	for _, grantType := range client.GrantTypes {
+		switch grantType {
+		case grantTypeAuthorizationCode, grantTypeRefreshToken, grantTypeClientCredentials:
+		default:
+			return nil, errors.NewOAuthError(errors.OAuthInvalidRequest, "unsupported grant_type: "+grantType)
+		}
+	}
+	if client.AllowsGrantType(grantTypeAuthorizationCode) && len(client.RedirectURIs) == 0 {
+		return nil, errors.NewOAuthError(errors.OAuthInvalidRequest, "redirect_uris are required for authorization_code")
+	}
+	// Сервисный клиент действует от своего имени, поэтому ему нужны секрет и роль
+	if client.AllowsGrantType(grantTypeClientCredentials) {
+		if !client.Confidential {
+			return nil, errors.NewOAuthError(errors.OAuthInvalidRequest, "client_credentials requires a confidential client")
+		}
+		exists, err := s.roleService.Exists(ctx, client.Role)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, errors.NewOAuthError(errors.OAuthInvalidRequest, "client_credentials requires a valid role")
+		}
+		// Иначе через клиента можно получить права роли выше собственной
+		if err := s.roleService.CheckAssignable(ctx, actorRole, client.Role); err != nil {
+			if stdErrors.Is(err, errors.ErrRoleRankTooHigh) {
+				return nil, errors.NewOAuthError(errors.OAuthAccessDenied, "client role is above your own")
+			}
+			return nil, err
+		}
+	}
+
+	var secret string
+	if client.Confidential {
+		secret, err = crypto.GenerateRandomString(32)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка генерации client_secret: %w", err)
+		}
+		client.SecretHash = crypto.HashToken(secret)
+	}
+
+	if err := s.clientRepository.Create(ctx, client); err != nil {
+		return nil, fmt.Errorf("ошибка при создании клиента: %w", err)
+	}
+
+	var response dto.OAuthClientResponseDTO
+	response.FromModel(client)
+	response.ClientSecret = secret
+	return &response, nil
+}
+
+func (s *oauthService) GetClients(ctx context.Context) ([]*dto.OAuthClientResponseDTO, error) {
+	clients, err := s.clientRepository.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]*dto.OAuthClientResponseDTO, 0, len(clients))
+	for _, client := range clients {
+		var clientResponse dto.OAuthClientResponseDTO
+		clientResponse.FromModel(client)
+		response = append(response, &clientResponse)
+	}
+	return response, nil
+}
+
+func (s *oauthService) DeleteClient(ctx context.Context, id uuid.UUID) error {
+	if id == uuid.Nil {
+		return errors.ErrInvalidUUID
+	}
+	return s.clientRepository.Delete(ctx, id)
+}
+
+func (s *oauthService) ValidateAuthorizeRequest(ctx context.Context, request dto.AuthorizeRequestDTO) (*entities.OAuthClient, error) {
+	if request.ClientID == "" {
+		return nil, errors.NewOAuthError(errors.OAuthInvalidRequest, "client_id is required")
+	}
+
+	client, err := s.clientRepository.FindByClientID(ctx, request.ClientID)
+	if err != nil {
+		if stdErrors.Is(err, errors.ErrOAuthClientNotFound) {
+			return nil, errors.NewOAuthError(errors.OAuthInvalidClient, "unknown client_id")
+		}
+		return nil, err
+	}
+
+	if !client.HasRedirectURI(request.RedirectURI) {
+		return nil, errors.NewOAuthError(errors.OAuthInvalidRequest, "redirect_uri is not registered for this client")
+	}
+
+	if !client.AllowsGrantType(grantTypeAuthorizationCode) {
+		return client, errors.NewOAuthError(errors.OAuthUnauthorizedClient, "client is not allowed to use authorization_code")
+	}
+
+	// Дальше ошибки можно сообщать приложению через redirect_uri
+	if request.ResponseType != "code" {
+		return client, errors.NewOAuthError(errors.OAuthUnsupportedResponseType, "only response_type=code is supported")
+	}
+
+	if !client.AllowsScopes(strings.Fields(request.Scope)) {
+		return client, errors.NewOAuthError(errors.OAuthInvalidScope, "requested scope is not allowed for this client")
+	}
+
+	if request.CodeChallenge == "" {
+		return client, errors.NewOAuthError(errors.OAuthInvalidRequest, "code_challenge is required")
+	}
+	if request.CodeChallengeMethod != codeChallengeMethodS256 {
+		return client, errors.NewOAuthError(errors.OAuthInvalidRequest, "code_challenge_method must be S256")
+	}
+
+	return client, nil
+}
+
+func (s *oauthService) Authorize(ctx context.Context, request dto.AuthorizeRequestDTO, login dto.LoginRequestDTO) (string, string, error) {
+	client, err := s.ValidateAuthorizeRequest(ctx, request)
+	if err != nil {
+		return "", "", err
+	}
+
+	login.ClientID = client.ClientID
+	login.Scope = s.grantedScope(client, request.Scope)
+
+	tokens, err := s.authService.Login(ctx, login)
+	if err != nil {
+		return "", "", err
+	}
+	if tokens.MFARequired {
+		return "", tokens.MFAToken, nil
+	}
+
+	redirectURI, err := s.issueAuthorizationCode(ctx, client, request, tokens, login.UserAgent, login.ClientIP)
+	return redirectURI, "", err
+}
+
+func (s *oauthService) AuthorizeMFA(ctx context.Context, request dto.AuthorizeRequestDTO, verify dto.MFAVerifyRequestDTO) (string, error) {
+	client, err := s.ValidateAuthorizeRequest(ctx, request)
+	if err != nil {
+		return "", err
+	}
+
+	verify.ClientID = client.ClientID
+	tokens, err := s.authService.VerifyMFA(ctx, verify)
+	if err != nil {
+		return "", err
+	}
+
+	return s.issueAuthorizationCode(ctx, client, request, tokens, verify.UserAgent, verify.ClientIP)
+}
+
+// issueAuthorizationCode сохраняет за одноразовым кодом подтверждённого пользователя и параметры
+// запроса и возвращает redirect_uri с этим кодом. Токенов в коде нет: их выпускает обмен кода
+func (s *oauthService) issueAuthorizationCode(ctx context.Context, client *entities.OAuthClient, request dto.AuthorizeRequestDTO, tokens *dto.LoginResponseDTO, userAgent, clientIP string) (string, error) {
+	code, err := crypto.GenerateRandomString(32)
+	if err != nil {
+		return "", fmt.Errorf("ошибка генерации кода авторизации: %w", err)
+	}
+
+	record, err := json.Marshal(authorizationCode{
+		ClientID:      client.ClientID,
+		RedirectURI:   request.RedirectURI,
+		Scope:         s.grantedScope(client, request.Scope),
+		CodeChallenge: request.CodeChallenge,
+		Nonce:         request.Nonce,
+		AuthTime:      time.Now().Unix(),
+		UserID:        tokens.UserID,
+		AuthMethods:   tokens.AuthMethods,
+		UserAgent:     userAgent,
+		ClientIP:      clientIP,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.cache.Set(ctx, authorizationCodeKey(code), string(record), authorizationCodeTTL); err != nil {
+		return "", fmt.Errorf("ошибка сохранения кода авторизации: %w", err)
+	}
+
+	return buildRedirectURI(request.RedirectURI, map[string]string{
+		"code":  code,
+		"state": request.State,
+	}), nil
+}
+
+func (s *oauthService) AuthorizeErrorRedirect(request dto.AuthorizeRequestDTO, err error) string {
+	oauthErr := &errors.OAuthError{}
+	if !stdErrors.As(err, &oauthErr) {
+		oauthErr = errors.NewOAuthError(errors.OAuthServerError, "")
+	}
+
+	return buildRedirectURI(request.RedirectURI, map[string]string{
+		"error":             oauthErr.Code,
+		"error_description": oauthErr.Description,
+		"state":             request.State,
+	})
+}
+
+func (s *oauthService) Token(ctx context.Context, request dto.TokenRequestDTO) (*dto.OAuthTokenResponseDTO, error) {
+	switch request.GrantType {
+	case grantTypeAuthorizationCode:
+		return s.exchangeAuthorizationCode(ctx, request)
+	case grantTypeRefreshToken:
+		return s.exchangeRefreshToken(ctx, request)
+	case grantTypeClientCredentials:
+		return s.exchangeClientCredentials(ctx, request)
+	case "":
+		return nil, errors.NewOAuthError(errors.OAuthInvalidRequest, "grant_type is required")
+	default:
+		return nil, errors.NewOAuthError(errors.OAuthUnsupportedGrantType, "")
+	}
+}
+
+func (s *oauthService) exchangeAuthorizationCode(ctx context.Context, request dto.TokenRequestDTO) (*dto.OAuthTokenResponseDTO, error) {
+	if request.Code == "" || request.ClientID == "" || request.RedirectURI == "" || request.CodeVerifier == "" {
+		return nil, errors.NewOAuthError(errors.OAuthInvalidRequest, "code, client_id, redirect_uri and code_verifier are required")
+	}
+
+	if _, err := s.authenticateClient(ctx, request, grantTypeAuthorizationCode); err != nil {
+		return nil, err
+	}
+
+	value, err := s.cache.Get(ctx, authorizationCodeKey(request.Code))
+	if err != nil {
+		// Код мог быть уже обменян: отзываем выданную по нему сессию (RFC 6749, раздел 4.1.2)
+		s.revokeCodeSession(ctx, request.Code)
+		return nil, errors.NewOAuthError(errors.OAuthInvalidGrant, "authorization code is invalid or expired")
+	}
+
+	var record authorizationCode
+	if err := json.Unmarshal([]byte(value), &record); err != nil {
+		return nil, err
+	}
+
+	// Код одноразовый: обменять его может только первый из одновременных запросов
+	ok, err := s.cache.SetNX(ctx, authorizationCodeUsedKey(request.Code), "", authorizationCodeTTL)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		s.revokeCodeSession(ctx, request.Code)
+		return nil, errors.NewOAuthError(errors.OAuthInvalidGrant, "authorization code has already been used")
+	}
+	_ = s.cache.Delete(ctx, authorizationCodeKey(request.Code))
+
+	if record.ClientID != request.ClientID {
+		return nil, errors.NewOAuthError(errors.OAuthInvalidGrant, "authorization code was issued to another client")
+	}
+	if record.RedirectURI != request.RedirectURI {
+		return nil, errors.NewOAuthError(errors.OAuthInvalidGrant, "redirect_uri does not match")
+	}
+	if !verifyCodeChallenge(record.CodeChallenge, request.CodeVerifier) {
+		return nil, errors.NewOAuthError(errors.OAuthInvalidGrant, "code_verifier does not match code_challenge")
+	}
+
+	// Сессия и токены появляются только после всех проверок кода
+	tokens, err := s.authService.CreateClientSession(ctx, record.UserID, dto.LoginRequestDTO{
+		UserAgent: record.UserAgent,
+		ClientIP:  record.ClientIP,
+		ClientID:  record.ClientID,
+		Scope:     record.Scope,
+	}, record.AuthMethods)
+	if err != nil {
+		if stdErrors.Is(err, errors.ErrAccountBlocked) || stdErrors.Is(err, errors.ErrInvalidCredentials) {
+			return nil, errors.NewOAuthError(errors.OAuthInvalidGrant, "user is not allowed to sign in")
+		}
+		return nil, err
+	}
+	// Сессия нужна, чтобы отозвать её при повторном предъявлении кода
+	_ = s.cache.Set(ctx, authorizationCodeUsedKey(request.Code), record.UserID.String()+":"+tokens.SessionID.String(), authorizationCodeTTL)
+
+	response := &dto.OAuthTokenResponseDTO{
+		AccessToken:  tokens.AccessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(s.config.JWT.Expiry.Seconds()),
+		RefreshToken: tokens.RefreshToken,
+		Scope:        record.Scope,
+	}
+
+	if hasScope(record.Scope, "openid") {
+		response.IDToken, err = s.createIDToken(ctx, record.UserID, record.ClientID, record.Scope, record.Nonce, record.AuthTime)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return response, nil
+}
+
+func (s *oauthService) exchangeRefreshToken(ctx context.Context, request dto.TokenRequestDTO) (*dto.OAuthTokenResponseDTO, error) {
+	if request.RefreshToken == "" || request.ClientID == "" {
+		return nil, errors.NewOAuthError(errors.OAuthInvalidRequest, "refresh_token and client_id are required")
+	}
+
+	if _, err := s.authenticateClient(ctx, request, grantTypeRefreshToken); err != nil {
+		return nil, err
+	}
+
+	// Refresh токен можно обменять только тем клиентом, которому он выдан
+	token, err := s.jwtService.ParseToken(request.RefreshToken)
+	if err != nil || !token.Valid {
+		return nil, errors.NewOAuthError(errors.OAuthInvalidGrant, "refresh token is invalid or expired")
+	}
+	claims, ok := token.Claims.(jwtv4.MapClaims)
+	if !ok || claims["client_id"] != request.ClientID {
+		return nil, errors.NewOAuthError(errors.OAuthInvalidGrant, "refresh token was issued to another client")
+	}
+
+	tokens, err := s.authService.RefreshToken(ctx, request.RefreshToken)
+	if err != nil {
+		return nil, errors.NewOAuthError(errors.OAuthInvalidGrant, err.Error())
+	}
+
+	scope, _ := claims["scope"].(string)
+	response := &dto.OAuthTokenResponseDTO{
+		AccessToken:  tokens.AccessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(time.Until(tokens.ExpiresAt).Seconds()),
+		RefreshToken: tokens.RefreshToken,
+		Scope:        scope,
+	}
+
+	// При обновлении id_token выдаётся заново без nonce (OIDC Core, раздел 12.2)
+	if hasScope(scope, "openid") {
+		authTime, _ := claims["auth_time"].(float64)
+		response.IDToken, err = s.createIDToken(ctx, tokens.User.ID, request.ClientID, scope, "", int64(authTime))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return response, nil
+}
+
+// exchangeClientCredentials выдаёт сервисному клиенту access токен от его собственного имени (RFC 6749, раздел 4.4).
+// Refresh токен не выдаётся: клиент просто запрашивает новый токен своим секретом
+func (s *oauthService) exchangeClientCredentials(ctx context.Context, request dto.TokenRequestDTO) (*dto.OAuthTokenResponseDTO, error) {
+	if request.ClientID == "" || request.ClientSecret == "" {
+		return nil, errors.NewOAuthError(errors.OAuthInvalidClient, "client authentication is required")
+	}
+
+	client, err := s.authenticateClient(ctx, request, grantTypeClientCredentials)
+	if err != nil {
+		return nil, err
+	}
+
+	if !client.AllowsScopes(strings.Fields(request.Scope)) {
+		return nil, errors.NewOAuthError(errors.OAuthInvalidScope, "requested scope is not allowed for this client")
+	}
+	scope := s.grantedScope(client, request.Scope)
+
+	jti, err := crypto.GenerateRandomString(16)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка генерации jti: %w", err)
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(s.config.JWT.ClientExpiry)
+	accessToken, err := s.jwtService.CreateToken(jwt.MapClaims{
+		"sub":       client.ID.String(),
+		"client_id": client.ClientID,
+		"role":      client.Role,
+		"scope":     scope,
+		"type":      "access",
+		"jti":       jti,
+		"iat":       now.Unix(),
+		"exp":       expiresAt.Unix(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка подписи токена клиента: %w", err)
+	}
+
+	return &dto.OAuthTokenResponseDTO{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(s.config.JWT.ClientExpiry.Seconds()),
+		Scope:       scope,
+	}, nil
+}
+
+// authenticateClient находит клиента, проверяет его секрет, если клиент конфиденциальный,
+// и что клиенту разрешён grant_type
+func (s *oauthService) authenticateClient(ctx context.Context, request dto.TokenRequestDTO, grantType string) (*entities.OAuthClient, error) {
+	client, err := s.findClient(ctx, request.ClientID, request.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	if !client.AllowsGrantType(grantType) {
+		return nil, errors.NewOAuthError(errors.OAuthUnauthorizedClient, "client is not allowed to use "+grantType)
+	}
+
+	return client, nil
+}
+
+// findClient находит клиента по client_id и проверяет секрет конфиденциального клиента
+func (s *oauthService) findClient(ctx context.Context, clientID, clientSecret string) (*entities.OAuthClient, error) {
+	client, err := s.clientRepository.FindByClientID(ctx, clientID)
+	if err != nil {
+		if stdErrors.Is(err, errors.ErrOAuthClientNotFound) {
+			return nil, errors.NewOAuthError(errors.OAuthInvalidClient, "client authentication failed")
+		}
+		return nil, err
+	}
+
+	if client.Confidential && !crypto.CheckToken(client.SecretHash, clientSecret) {
+		return nil, errors.NewOAuthError(errors.OAuthInvalidClient, "client authentication failed")
+	}
+
+	return client, nil
+}
+
+func (s *oauthService) Revoke(ctx context.Context, request dto.RevokeRequestDTO) error {
+	if request.ClientID == "" {
+		return errors.NewOAuthError(errors.OAuthInvalidClient, "client authentication is required")
+	}
+	client, err := s.findClient(ctx, request.ClientID, request.ClientSecret)
+	if err != nil {
+		return err
+	}
+
+	if request.Token == "" {
+		return errors.NewOAuthError(errors.OAuthInvalidRequest, "token is required")
+	}
+
+	// Недействительный токен считается отозванным (RFC 7009, раздел 2.2)
+	token, err := s.jwtService.ParseToken(request.Token)
+	if err != nil || !token.Valid {
+		return nil
+	}
+	claims, ok := token.Claims.(jwtv4.MapClaims)
+	if !ok {
+		return nil
+	}
+
+	// Клиент может отозвать только выданные ему токены
+	if claims["client_id"] != client.ClientID {
+		return nil
+	}
+
+	err = s.authService.RevokeToken(ctx, request.Token)
+	if err != nil && !stdErrors.Is(err, errors.ErrInvalidToken) {
+		return err
+	}
+	return nil
+}
+
+func (s *oauthService) Introspect(ctx context.Context, request dto.IntrospectRequestDTO) (*dto.IntrospectResponseDTO, error) {
+	// Интроспекция раскрывает содержимое токенов, поэтому доступна только клиентам с секретом
+	if request.ClientID == "" || request.ClientSecret == "" {
+		return nil, errors.NewOAuthError(errors.OAuthInvalidClient, "client authentication is required")
+	}
+	caller, err := s.findClient(ctx, request.ClientID, request.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+	if !caller.Confidential {
+		return nil, errors.NewOAuthError(errors.OAuthInvalidClient, "only confidential clients may introspect tokens")
+	}
+
+	if request.Token == "" {
+		return nil, errors.NewOAuthError(errors.OAuthInvalidRequest, "token is required")
+	}
+
+	inactive := &dto.IntrospectResponseDTO{Active: false}
+
+	// Просроченный, чужой, испорченный или отозванный токен — просто неактивен
+	info, err := s.tokenService.GetTokenInfo(ctx, request.Token)
+	if err != nil {
+		return inactive, nil
+	}
+
+	active, err := s.isTokenActive(ctx, info)
+	if err != nil {
+		return nil, err
+	}
+	if !active {
+		return inactive, nil
+	}
+
+	tokenType := "Bearer"
+	if info.Type == "refresh" {
+		tokenType = "refresh_token"
+	}
+
+	return &dto.IntrospectResponseDTO{
+		Active:       true,
+		Scope:        info.Scope,
+		ClientID:     info.ClientID,
+		TokenType:    tokenType,
+		Exp:          info.ExpiresAt.Unix(),
+		Iat:          info.IssuedAt.Unix(),
+		Sub:          info.Subject,
+		Iss:          s.config.JWT.Issuer,
+		Jti:          info.JTI,
+		Role:         info.Role,
+		SessionID:    info.SessionID,
+		Organization: info.OrganizationID,
+	}, nil
+}
+
+// isTokenActive проверяет состояние, которого нет в подписи: сессию, семейство refresh токенов,
+// активность пользователя или существование сервисного клиента
+func (s *oauthService) isTokenActive(ctx context.Context, info *jwt.TokenInfo) (bool, error) {
+	if info.SessionID != "" {
+		revoked, err := s.sessionService.IsRevoked(ctx, info.SessionID)
+		if err != nil || revoked {
+			return false, err
+		}
+	}
+
+	if info.Type == "refresh" {
+		current, err := s.tokenService.IsRefreshTokenCurrent(ctx, info.FamilyID, info.JTI)
+		if err != nil || !current {
+			return false, err
+		}
+	}
+
+	// Токен сервисного клиента действителен, пока клиент зарегистрирован
+	if info.UserID == "" {
+		client, err := s.clientRepository.FindByClientID(ctx, info.ClientID)
+		if err != nil {
+			if stdErrors.Is(err, errors.ErrOAuthClientNotFound) {
+				return false, nil
+			}
+			return false, err
+		}
+		return client.ID.String() == info.Subject, nil
+	}
+
+	userID, err := uuid.Parse(info.UserID)
+	if err != nil {
+		return false, nil
+	}
+	user, err := s.userRepository.GetID(ctx, userID)
+	if err != nil {
+		if stdErrors.Is(err, errors.ErrUserNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return user.IsActive && info.Version == user.TokenVersion, nil
+}
+
+func (s *oauthService) UserInfo(ctx context.Context, userID uuid.UUID, scope string) (*dto.UserInfoDTO, error) {
+	user, err := s.userRepository.GetID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var userResponse dto.UserResponseDTO
+	userResponse.FromModel(user)
+
+	var userInfo dto.UserInfoDTO
+	userInfo.FromUser(&userResponse, strings.Fields(scope))
+	return &userInfo, nil
+}
+
+func (s *oauthService) OpenIDConfiguration() dto.OpenIDConfigurationDTO {
+	issuer := s.config.JWT.Issuer
+	return dto.OpenIDConfigurationDTO{
+		Issuer:                            issuer,
+		AuthorizationEndpoint:             issuer + "/oauth/authorize",
+		TokenEndpoint:                     issuer + "/oauth/token",
+		IntrospectionEndpoint:             issuer + "/oauth/introspect",
+		RevocationEndpoint:                issuer + "/oauth/revoke",
+		UserinfoEndpoint:                  issuer + "/oauth/userinfo",
+		JwksURI:                           issuer + "/.well-known/jwks.json",
+		ScopesSupported:                   []string{"openid", "profile", "phone"},
+		ResponseTypesSupported:            []string{"code"},
+		GrantTypesSupported:               []string{grantTypeAuthorizationCode, grantTypeRefreshToken, grantTypeClientCredentials},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  []string{s.config.JWT.Algorithm},
+		TokenEndpointAuthMethodsSupported: []string{"none", "client_secret_basic", "client_secret_post"},
+		CodeChallengeMethodsSupported:     []string{codeChallengeMethodS256},
+		ClaimsSupported: []string{
+			"sub", "iss", "aud", "exp", "iat", "auth_time", "nonce",
+			"name", "given_name", "family_name", "middle_name", "picture", "updated_at",
+			"phone_number", "phone_number_verified",
+		},
+	}
+}
+
+// createIDToken выпускает id_token (OIDC Core, раздел 2) с claims профиля по выданным scope
+func (s *oauthService) createIDToken(ctx context.Context, userID uuid.UUID, clientID, scope, nonce string, authTime int64) (string, error) {
+	userInfo, err := s.UserInfo(ctx, userID, scope)
+	if err != nil {
+		return "", err
+	}
+
+	// Переносим claims профиля как есть, чтобы id_token и /userinfo совпадали
+	raw, err := json.Marshal(userInfo)
+	if err != nil {
+		return "", err
+	}
+	claims := jwt.MapClaims{}
+	if err := json.Unmarshal(raw, &claims); err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	claims["iss"] = s.config.JWT.Issuer
+	claims["aud"] = clientID
+	claims["iat"] = now.Unix()
+	claims["exp"] = now.Add(s.config.JWT.Expiry).Unix()
+	claims["auth_time"] = authTime
+	if nonce != "" {
+		claims["nonce"] = nonce
+	}
+
+	idToken, err := s.jwtService.CreateToken(claims)
+	if err != nil {
+		return "", fmt.Errorf("ошибка подписи id_token: %w", err)
+	}
+	return idToken, nil
+}
+
+// grantedScope возвращает запрошенные scope или все разрешённые клиенту, если scope не указан
+func (s *oauthService) grantedScope(client *entities.OAuthClient, requested string) string {
+	if strings.TrimSpace(requested) == "" {
+		return strings.Join(client.Scopes, " ")
+	}
+	return strings.Join(strings.Fields(requested), " ")
+}
+
+// verifyCodeChallenge проверяет code_verifier методом S256 по RFC 7636, раздел 4.6
+func verifyCodeChallenge(challenge, verifier string) bool {
+	sum := sha256.Sum256([]byte(verifier))
+	expected := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(challenge)) == 1
+}
+
+func hasScope(scope, want string) bool {
+	for _, granted := range strings.Fields(scope) {
+		if granted == want {
+			return true
+		}
+	}
+	return false
+}
+
+func buildRedirectURI(redirectURI string, params map[string]string) string {
+	parsed, err := url.Parse(redirectURI)
+	if err != nil {
+		return redirectURI
+	}
+
+	query := parsed.Query()
+	for key, value := range params {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	parsed.RawQuery = query.Encode()
+	return parsed.String()
+}
+
+func authorizationCodeKey(code string) string {
+	return fmt.Sprintf("oauth_code:%s", code)
+}
+
+// authorizationCodeUsedKey отметка обмена кода; после выпуска токенов хранит user_id:session_id
+func authorizationCodeUsedKey(code string) string {
+	return authorizationCodeKey(code) + ":used"
+}
+
+// revokeCodeSession отзывает сессию, выданную по уже обменянному коду
+func (s *oauthService) revokeCodeSession(ctx context.Context, code string) {
+	value, err := s.cache.Get(ctx, authorizationCodeUsedKey(code))
+	if err != nil {
+		return
+	}
+	userPart, sessionPart, found := strings.Cut(value, ":")
+	if !found {
+		return
+	}
+	userID, err := uuid.Parse(userPart)
+	if err != nil {
+		return
+	}
+	sessionID, err := uuid.Parse(sessionPart)
+	if err != nil {
+		return
+	}
+	_ = s.sessionService.Revoke(ctx, userID, sessionID)
+}
@@ -0,0 +1,927 @@
+package services
+
+import (
+	"context"
+	stdErrors "errors"
+	"gold_portal/config"
+	"gold_portal/internal/domain/dto"
+	"gold_portal/internal/domain/entities"
+	"gold_portal/internal/domain/repositories"
+	"gold_portal/internal/errors"
+	"gold_portal/internal/pkg/crypto"
+	"gold_portal/internal/pkg/jwt"
+	"net/url"
+	"testing"
+	"time"
+
+	jwtv4 "github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+)
+
+// Пример из RFC 7636, приложение B
+const (
+	testCodeVerifier  = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	testCodeChallenge = "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+)
+
+type fakeOAuthClientRepository struct {
+	repositories.OAuthClientRepository
+	client *entities.OAuthClient
+}
+
+func (r *fakeOAuthClientRepository) Create(_ context.Context, client *entities.OAuthClient) error {
+	client.ID = uuid.New()
+	r.client = client
+	return nil
+}
+
+func (r *fakeOAuthClientRepository) FindByClientID(_ context.Context, clientID string) (*entities.OAuthClient, error) {
+	if r.client == nil || r.client.ClientID != clientID {
+		return nil, errors.ErrOAuthClientNotFound
+	}
+	return r.client, nil
+}
+
+// fakeLoginAuth подтверждает вход пользователя userID без сессии, как при входе через
+// приложение OAuth, и выдаёт токены новой сессии на каждый вызов CreateClientSession
+type fakeLoginAuth struct {
+	AuthService
+	userID   uuid.UUID
+	sessions []uuid.UUID
+}
+
+func (s *fakeLoginAuth) Login(_ context.Context, _ dto.LoginRequestDTO) (*dto.LoginResponseDTO, error) {
+	return &dto.LoginResponseDTO{UserID: s.userID, AuthMethods: []string{AuthMethodPassword}}, nil
+}
+
+func (s *fakeLoginAuth) CreateClientSession(_ context.Context, userID uuid.UUID, _ dto.LoginRequestDTO, _ []string) (*dto.LoginResponseDTO, error) {
+	sessionID := uuid.New()
+	s.sessions = append(s.sessions, sessionID)
+	return &dto.LoginResponseDTO{
+		AccessToken:  "access-" + sessionID.String(),
+		RefreshToken: "refresh-" + sessionID.String(),
+		UserID:       userID,
+		SessionID:    sessionID,
+	}, nil
+}
+
+type fakeRevokingSessionService struct {
+	SessionService
+	revoked []uuid.UUID
+}
+
+func (s *fakeRevokingSessionService) Revoke(_ context.Context, _, sessionID uuid.UUID) error {
+	s.revoked = append(s.revoked, sessionID)
+	return nil
+}
+
+func newTestOAuthClient() *entities.OAuthClient {
+	return &entities.OAuthClient{
+		ClientID:     "spa",
+		RedirectURIs: []string{"https://app.example/callback"},
+		Scopes:       []string{"profile"},
+	}
+}
+
+func TestVerifyCodeChallenge(t *testing.T) {
+	tests := []struct {
+		name      string
+		challenge string
+		verifier  string
+		want      bool
+	}{
+		{name: "S256 matching verifier", challenge: testCodeChallenge, verifier: testCodeVerifier, want: true},
+		{name: "S256 wrong verifier", challenge: testCodeChallenge, verifier: testCodeVerifier + "x"},
+		{name: "S256 challenge sent as verifier", challenge: testCodeChallenge, verifier: testCodeChallenge},
+		// Метод plain не поддерживается: challenge, равный verifier, не проходит
+		{name: "plain challenge", challenge: testCodeVerifier, verifier: testCodeVerifier},
+		{name: "empty verifier", challenge: testCodeChallenge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := verifyCodeChallenge(tt.challenge, tt.verifier); got != tt.want {
+				t.Fatalf("verifyCodeChallenge = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateAuthorizeRequest(t *testing.T) {
+	ctx := context.Background()
+	client := newTestOAuthClient()
+	service := &oauthService{clientRepository: &fakeOAuthClientRepository{client: client}}
+
+	valid := dto.AuthorizeRequestDTO{
+		ResponseType:        "code",
+		ClientID:            client.ClientID,
+		RedirectURI:         client.RedirectURIs[0],
+		Scope:               "profile",
+		CodeChallenge:       testCodeChallenge,
+		CodeChallengeMethod: codeChallengeMethodS256,
+	}
+
+	tests := []struct {
+		name   string
+		modify func(request *dto.AuthorizeRequestDTO)
+		// Пустой код — запрос корректен
+		wantCode string
+		// Ошибку можно вернуть на redirect_uri только для проверенного клиента
+		wantClient bool
+	}{
+		{name: "valid request", modify: func(*dto.AuthorizeRequestDTO) {}, wantClient: true},
+		{name: "unknown client", modify: func(r *dto.AuthorizeRequestDTO) { r.ClientID = "other" }, wantCode: errors.OAuthInvalidClient},
+		{
+			name:     "unregistered redirect_uri",
+			modify:   func(r *dto.AuthorizeRequestDTO) { r.RedirectURI = "https://evil.example/callback" },
+			wantCode: errors.OAuthInvalidRequest,
+		},
+		{
+			name:       "implicit flow",
+			modify:     func(r *dto.AuthorizeRequestDTO) { r.ResponseType = "token" },
+			wantCode:   errors.OAuthUnsupportedResponseType,
+			wantClient: true,
+		},
+		{
+			name:       "scope not allowed for client",
+			modify:     func(r *dto.AuthorizeRequestDTO) { r.Scope = "profile admin" },
+			wantCode:   errors.OAuthInvalidScope,
+			wantClient: true,
+		},
+		{
+			name:       "missing code_challenge",
+			modify:     func(r *dto.AuthorizeRequestDTO) { r.CodeChallenge = "" },
+			wantCode:   errors.OAuthInvalidRequest,
+			wantClient: true,
+		},
+		{
+			name:       "unknown code_challenge_method",
+			modify:     func(r *dto.AuthorizeRequestDTO) { r.CodeChallengeMethod = "S512" },
+			wantCode:   errors.OAuthInvalidRequest,
+			wantClient: true,
+		},
+		{
+			name:       "plain code_challenge_method",
+			modify:     func(r *dto.AuthorizeRequestDTO) { r.CodeChallengeMethod = "plain" },
+			wantCode:   errors.OAuthInvalidRequest,
+			wantClient: true,
+		},
+		{
+			name:       "missing code_challenge_method",
+			modify:     func(r *dto.AuthorizeRequestDTO) { r.CodeChallengeMethod = "" },
+			wantCode:   errors.OAuthInvalidRequest,
+			wantClient: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := valid
+			tt.modify(&request)
+
+			got, err := service.ValidateAuthorizeRequest(ctx, request)
+			if tt.wantCode == "" {
+				if err != nil {
+					t.Fatalf("ValidateAuthorizeRequest: %v", err)
+				}
+			} else {
+				assertOAuthError(t, err, tt.wantCode)
+			}
+			if (got != nil) != tt.wantClient {
+				t.Fatalf("client returned = %v, want %v", got != nil, tt.wantClient)
+			}
+		})
+	}
+}
+
+func TestExchangeAuthorizationCode(t *testing.T) {
+	ctx := context.Background()
+	client := newTestOAuthClient()
+
+	// newService выдаёт код с S256-challenge из RFC 7636 и возвращает его
+	newService := func(t *testing.T) (*oauthService, *fakeLoginAuth, *fakeRevokingSessionService, string) {
+		cache, _ := newTestCache(t)
+		auth := &fakeLoginAuth{userID: uuid.New()}
+		sessions := &fakeRevokingSessionService{}
+		service := &oauthService{
+			clientRepository: &fakeOAuthClientRepository{client: client},
+			authService:      auth,
+			sessionService:   sessions,
+			cache:            cache,
+			config:           &config.Config{JWT: config.JWTConfig{Expiry: 15 * time.Minute}},
+		}
+
+		redirect, _, err := service.Authorize(ctx, dto.AuthorizeRequestDTO{
+			ResponseType:        "code",
+			ClientID:            client.ClientID,
+			RedirectURI:         client.RedirectURIs[0],
+			Scope:               "profile",
+			State:               "xyz",
+			CodeChallenge:       testCodeChallenge,
+			CodeChallengeMethod: codeChallengeMethodS256,
+		}, dto.LoginRequestDTO{Phone: "+996555123456", Password: "Password123"})
+		if err != nil {
+			t.Fatalf("Authorize: %v", err)
+		}
+		parsed, err := url.Parse(redirect)
+		if err != nil {
+			t.Fatalf("parse redirect: %v", err)
+		}
+		if state := parsed.Query().Get("state"); state != "xyz" {
+			t.Fatalf("redirect state %q, want xyz", state)
+		}
+		code := parsed.Query().Get("code")
+		if code == "" {
+			t.Fatalf("redirect %q has no code", redirect)
+		}
+		return service, auth, sessions, code
+	}
+
+	exchange := func(service *oauthService, clientID, code, verifier string) (*dto.OAuthTokenResponseDTO, error) {
+		return service.Token(ctx, dto.TokenRequestDTO{
+			GrantType:    "authorization_code",
+			Code:         code,
+			RedirectURI:  client.RedirectURIs[0],
+			ClientID:     clientID,
+			CodeVerifier: verifier,
+		})
+	}
+
+	t.Run("code is issued without a session", func(t *testing.T) {
+		_, auth, _, _ := newService(t)
+		if len(auth.sessions) != 0 {
+			t.Fatalf("sessions created at authorize: %d, want 0", len(auth.sessions))
+		}
+	})
+
+	t.Run("valid verifier mints tokens once", func(t *testing.T) {
+		service, auth, _, code := newService(t)
+		response, err := exchange(service, client.ClientID, code, testCodeVerifier)
+		if err != nil {
+			t.Fatalf("exchange: %v", err)
+		}
+		if len(auth.sessions) != 1 || response.AccessToken != "access-"+auth.sessions[0].String() || response.Scope != "profile" {
+			t.Fatalf("unexpected tokens %+v for sessions %v", response, auth.sessions)
+		}
+	})
+
+	t.Run("second exchange is rejected and revokes the session", func(t *testing.T) {
+		service, auth, sessions, code := newService(t)
+		if _, err := exchange(service, client.ClientID, code, testCodeVerifier); err != nil {
+			t.Fatalf("first exchange: %v", err)
+		}
+
+		_, err := exchange(service, client.ClientID, code, testCodeVerifier)
+		assertOAuthError(t, err, errors.OAuthInvalidGrant)
+		if len(auth.sessions) != 1 {
+			t.Fatalf("sessions created: %d, want 1", len(auth.sessions))
+		}
+		if len(sessions.revoked) != 1 || sessions.revoked[0] != auth.sessions[0] {
+			t.Fatalf("revoked sessions %v, want %v", sessions.revoked, auth.sessions)
+		}
+	})
+
+	t.Run("wrong verifier burns the code without a session", func(t *testing.T) {
+		service, auth, _, code := newService(t)
+		_, err := exchange(service, client.ClientID, code, testCodeChallenge)
+		assertOAuthError(t, err, errors.OAuthInvalidGrant)
+
+		_, err = exchange(service, client.ClientID, code, testCodeVerifier)
+		assertOAuthError(t, err, errors.OAuthInvalidGrant)
+		if len(auth.sessions) != 0 {
+			t.Fatalf("sessions created: %d, want 0", len(auth.sessions))
+		}
+	})
+
+	t.Run("unknown client", func(t *testing.T) {
+		service, _, _, code := newService(t)
+		_, err := exchange(service, "other", code, testCodeVerifier)
+		assertOAuthError(t, err, errors.OAuthInvalidClient)
+	})
+}
+
+func TestIDToken(t *testing.T) {
+	ctx := context.Background()
+	client := &entities.OAuthClient{
+		ClientID:     "spa",
+		RedirectURIs: []string{"https://app.example/callback"},
+		Scopes:       []string{"openid", "profile", "phone"},
+	}
+	user := &entities.User{
+		ID:         uuid.New(),
+		FirstName:  "Айбек",
+		MiddleName: "Асанович",
+		LastName:   "Касымов",
+		Phone:      "+996555123456",
+	}
+	jwtService := newTestJWTService(t)
+
+	tests := []struct {
+		name  string
+		scope string
+		nonce string
+		// Ожидаемые claims профиля; nil — id_token не выдаётся
+		want map[string]interface{}
+		// Claims, которых не должно быть
+		absent []string
+	}{
+		{
+			name:  "profile scope",
+			scope: "openid profile",
+			nonce: "n-0S6_WzA2Mj",
+			want: map[string]interface{}{
+				"given_name":  "Айбек",
+				"family_name": "Касымов",
+				"name":        "Айбек Асанович Касымов",
+				"nonce":       "n-0S6_WzA2Mj",
+			},
+			absent: []string{"phone_number"},
+		},
+		{
+			name:   "phone scope without nonce",
+			scope:  "openid phone",
+			want:   map[string]interface{}{"phone_number": user.Phone, "phone_number_verified": false},
+			absent: []string{"given_name", "nonce"},
+		},
+		{name: "plain OAuth without openid", scope: "profile"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache, _ := newTestCache(t)
+			service := &oauthService{
+				clientRepository: &fakeOAuthClientRepository{client: client},
+				userRepository:   newFakeUserRepository(user),
+				authService:      &fakeLoginAuth{userID: user.ID},
+				jwtService:       jwtService,
+				cache:            cache,
+				config: &config.Config{JWT: config.JWTConfig{
+					Expiry: 15 * time.Minute,
+					Issuer: "https://id.example",
+				}},
+			}
+
+			redirect, _, err := service.Authorize(ctx, dto.AuthorizeRequestDTO{
+				ResponseType:        "code",
+				ClientID:            client.ClientID,
+				RedirectURI:         client.RedirectURIs[0],
+				Scope:               tt.scope,
+				Nonce:               tt.nonce,
+				CodeChallenge:       testCodeChallenge,
+				CodeChallengeMethod: codeChallengeMethodS256,
+			}, dto.LoginRequestDTO{Phone: user.Phone, Password: "Password123"})
+			if err != nil {
+				t.Fatalf("Authorize: %v", err)
+			}
+			parsed, err := url.Parse(redirect)
+			if err != nil {
+				t.Fatalf("parse redirect: %v", err)
+			}
+
+			response, err := service.Token(ctx, dto.TokenRequestDTO{
+				GrantType:    "authorization_code",
+				Code:         parsed.Query().Get("code"),
+				RedirectURI:  client.RedirectURIs[0],
+				ClientID:     client.ClientID,
+				CodeVerifier: testCodeVerifier,
+			})
+			if err != nil {
+				t.Fatalf("Token: %v", err)
+			}
+			if tt.want == nil {
+				if response.IDToken != "" {
+					t.Fatal("id_token issued without openid scope")
+				}
+				return
+			}
+
+			token, err := jwtService.ParseToken(response.IDToken)
+			if err != nil {
+				t.Fatalf("ParseToken id_token: %v", err)
+			}
+			claims := token.Claims.(jwtv4.MapClaims)
+			want := map[string]interface{}{
+				"iss": "https://id.example",
+				"aud": client.ClientID,
+				"sub": user.ID.String(),
+			}
+			for claim, value := range tt.want {
+				want[claim] = value
+			}
+			for claim, value := range want {
+				if claims[claim] != value {
+					t.Fatalf("claim %s = %v, want %v", claim, claims[claim], value)
+				}
+			}
+			for _, claim := range tt.absent {
+				if _, ok := claims[claim]; ok {
+					t.Fatalf("claim %s must not be issued for scope %q", claim, tt.scope)
+				}
+			}
+			if _, ok := claims["auth_time"].(float64); !ok {
+				t.Fatalf("auth_time claim missing: %v", claims)
+			}
+		})
+	}
+}
+
+func TestRegisterClient(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		request    dto.OAuthClientRequestDTO
+		wantCode   string
+		wantSecret bool
+	}{
+		{
+			name:    "public client with default grants",
+			request: dto.OAuthClientRequestDTO{Name: "SPA", RedirectURIs: []string{"https://app.example/callback"}},
+		},
+		{
+			name: "service client",
+			request: dto.OAuthClientRequestDTO{
+				Name:         "Billing",
+				GrantTypes:   []string{grantTypeClientCredentials},
+				Confidential: true,
+				Role:         entities.RoleManager,
+			},
+			wantSecret: true,
+		},
+		{
+			name:     "authorization_code without redirect_uris",
+			request:  dto.OAuthClientRequestDTO{Name: "SPA"},
+			wantCode: errors.OAuthInvalidRequest,
+		},
+		{
+			name:     "unsupported grant type",
+			request:  dto.OAuthClientRequestDTO{Name: "Legacy", GrantTypes: []string{"password"}},
+			wantCode: errors.OAuthInvalidRequest,
+		},
+		{
+			name: "public service client",
+			request: dto.OAuthClientRequestDTO{
+				Name:       "Billing",
+				GrantTypes: []string{grantTypeClientCredentials},
+				Role:       entities.RoleManager,
+			},
+			wantCode: errors.OAuthInvalidRequest,
+		},
+		{
+			name: "service client without role",
+			request: dto.OAuthClientRequestDTO{
+				Name:         "Billing",
+				GrantTypes:   []string{grantTypeClientCredentials},
+				Confidential: true,
+			},
+			wantCode: errors.OAuthInvalidRequest,
+		},
+		{
+			name: "service client above actor role",
+			request: dto.OAuthClientRequestDTO{
+				Name:         "Billing",
+				GrantTypes:   []string{grantTypeClientCredentials},
+				Confidential: true,
+				Role:         entities.RoleSuperUser,
+			},
+			wantCode: errors.OAuthAccessDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := &fakeOAuthClientRepository{}
+			service := &oauthService{clientRepository: repository, roleService: newTestRoleService()}
+
+			response, err := service.RegisterClient(ctx, entities.RoleAdmin, tt.request)
+			if tt.wantCode != "" {
+				assertOAuthError(t, err, tt.wantCode)
+				if repository.client != nil {
+					t.Fatal("rejected client was stored")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RegisterClient: %v", err)
+			}
+			if (response.ClientSecret != "") != tt.wantSecret {
+				t.Fatalf("client_secret returned = %v, want %v", response.ClientSecret != "", tt.wantSecret)
+			}
+			// Секрет показывается один раз и хранится только хэшем
+			if tt.wantSecret && (repository.client.SecretHash == response.ClientSecret ||
+				repository.client.SecretHash != crypto.HashToken(response.ClientSecret)) {
+				t.Fatal("client_secret must be stored as a hash")
+			}
+		})
+	}
+}
+
+func TestClientCredentials(t *testing.T) {
+	ctx := context.Background()
+	const secret = "service-secret"
+	client := &entities.OAuthClient{
+		ID:           uuid.New(),
+		ClientID:     "billing",
+		Scopes:       []string{"users:read", "audit:read"},
+		GrantTypes:   []string{grantTypeClientCredentials},
+		Confidential: true,
+		SecretHash:   crypto.HashToken(secret),
+		Role:         entities.RoleManager,
+	}
+	jwtService := newTestJWTService(t)
+	service := &oauthService{
+		clientRepository: &fakeOAuthClientRepository{client: client},
+		jwtService:       jwtService,
+		config:           &config.Config{JWT: config.JWTConfig{ClientExpiry: 15 * time.Minute}},
+	}
+
+	tests := []struct {
+		name     string
+		request  dto.TokenRequestDTO
+		wantCode string
+		// Ожидаемый scope выданного токена
+		wantScope string
+	}{
+		{
+			name:      "all client scopes by default",
+			request:   dto.TokenRequestDTO{ClientID: client.ClientID, ClientSecret: secret},
+			wantScope: "users:read audit:read",
+		},
+		{
+			name:      "narrowed scope",
+			request:   dto.TokenRequestDTO{ClientID: client.ClientID, ClientSecret: secret, Scope: "audit:read"},
+			wantScope: "audit:read",
+		},
+		{
+			name:     "wrong secret",
+			request:  dto.TokenRequestDTO{ClientID: client.ClientID, ClientSecret: "guess"},
+			wantCode: errors.OAuthInvalidClient,
+		},
+		{
+			name:     "missing secret",
+			request:  dto.TokenRequestDTO{ClientID: client.ClientID},
+			wantCode: errors.OAuthInvalidClient,
+		},
+		{
+			name:     "unknown client",
+			request:  dto.TokenRequestDTO{ClientID: "other", ClientSecret: secret},
+			wantCode: errors.OAuthInvalidClient,
+		},
+		{
+			name:     "scope beyond the client",
+			request:  dto.TokenRequestDTO{ClientID: client.ClientID, ClientSecret: secret, Scope: "users:delete"},
+			wantCode: errors.OAuthInvalidScope,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := tt.request
+			request.GrantType = grantTypeClientCredentials
+
+			response, err := service.Token(ctx, request)
+			if tt.wantCode != "" {
+				assertOAuthError(t, err, tt.wantCode)
+				return
+			}
+			if err != nil {
+				t.Fatalf("Token: %v", err)
+			}
+			if response.RefreshToken != "" {
+				t.Fatal("client_credentials must not issue a refresh token")
+			}
+			if response.Scope != tt.wantScope {
+				t.Fatalf("scope %q, want %q", response.Scope, tt.wantScope)
+			}
+
+			token, err := jwtService.ParseToken(response.AccessToken)
+			if err != nil {
+				t.Fatalf("ParseToken: %v", err)
+			}
+			// Токен принадлежит клиенту и не должен сойти за пользовательский
+			serviceClient, ok := (&authService{}).GetClientFromToken(token)
+			if !ok {
+				t.Fatal("access token is not recognized as a service client token")
+			}
+			if serviceClient.ID != client.ID || serviceClient.ClientID != client.ClientID ||
+				serviceClient.Role != client.Role || serviceClient.Scope != tt.wantScope {
+				t.Fatalf("service client %+v, want %s with role %s and scope %q", serviceClient, client.ClientID, client.Role, tt.wantScope)
+			}
+		})
+	}
+}
+
+func TestClientCredentialsRequiresGrant(t *testing.T) {
+	const secret = "app-secret"
+	client := &entities.OAuthClient{
+		ID:           uuid.New(),
+		ClientID:     "web",
+		RedirectURIs: []string{"https://app.example/callback"},
+		Confidential: true,
+		SecretHash:   crypto.HashToken(secret),
+	}
+	service := &oauthService{clientRepository: &fakeOAuthClientRepository{client: client}}
+
+	_, err := service.Token(context.Background(), dto.TokenRequestDTO{
+		GrantType:    grantTypeClientCredentials,
+		ClientID:     client.ClientID,
+		ClientSecret: secret,
+	})
+	assertOAuthError(t, err, errors.OAuthUnauthorizedClient)
+}
+
+func TestGetClientFromTokenIgnoresUserTokens(t *testing.T) {
+	jwtService := newTestJWTService(t)
+	// Токен пользователя, выданный через OAuth клиента, тоже содержит client_id
+	signed, err := jwtService.CreateToken(jwt.MapClaims{
+		"user_id":   uuid.New().String(),
+		"sub":       uuid.New().String(),
+		"client_id": "spa",
+		"role":      string(entities.RoleSuperUser),
+		"exp":       time.Now().Add(time.Minute).Unix(),
+	})
+	if err != nil {
+		t.Fatalf("CreateToken: %v", err)
+	}
+	token, err := jwtService.ParseToken(signed)
+	if err != nil {
+		t.Fatalf("ParseToken: %v", err)
+	}
+	if client, ok := (&authService{}).GetClientFromToken(token); ok {
+		t.Fatalf("user token treated as service client %+v", client)
+	}
+}
+
+func assertOAuthError(t *testing.T, err error, code string) {
+	t.Helper()
+	var oauthErr *errors.OAuthError
+	if !stdErrors.As(err, &oauthErr) || oauthErr.Code != code {
+		t.Fatalf("got error %v, want OAuth error %s", err, code)
+	}
+}
+
+func TestIntrospect(t *testing.T) {
+	ctx := context.Background()
+	const secret = "resource-server-secret"
+	caller := &entities.OAuthClient{
+		ID:           uuid.New(),
+		ClientID:     "resource-server",
+		GrantTypes:   []string{grantTypeClientCredentials},
+		Confidential: true,
+		SecretHash:   crypto.HashToken(secret),
+		Role:         entities.RoleManager,
+	}
+	active := &entities.User{ID: uuid.New(), Role: entities.RoleUser, IsActive: true}
+	blocked := &entities.User{ID: uuid.New(), Role: entities.RoleUser}
+
+	cache, _ := newTestCache(t)
+	jwtService := newTestJWTService(t)
+	tokenService := NewTokenService(cache, jwtService)
+	service := &oauthService{
+		clientRepository: &fakeOAuthClientRepository{client: caller},
+		userRepository:   newFakeUserRepository(active, blocked),
+		sessionService:   &sessionService{tokenService: tokenService},
+		tokenService:     tokenService,
+		jwtService:       jwtService,
+		config:           &config.Config{JWT: config.JWTConfig{Issuer: "https://id.example"}},
+	}
+
+	now := time.Now()
+	// sign выпускает токен с claims, как у authService, поверх переданных
+	sign := func(claims jwt.MapClaims) string {
+		t.Helper()
+		full := jwt.MapClaims{
+			"role": string(entities.RoleUser),
+			"type": "access",
+			"jti":  uuid.New().String(),
+			"iat":  now.Unix(),
+			"exp":  now.Add(time.Hour).Unix(),
+		}
+		for claim, value := range claims {
+			full[claim] = value
+		}
+		token, err := jwtService.CreateToken(full)
+		if err != nil {
+			t.Fatalf("CreateToken: %v", err)
+		}
+		return token
+	}
+
+	revokedSession := uuid.New().String()
+	if err := tokenService.RevokeSession(ctx, revokedSession, now.Add(time.Hour)); err != nil {
+		t.Fatalf("RevokeSession: %v", err)
+	}
+	if err := tokenService.StoreRefreshToken(ctx, "family", "refresh-current", now.Add(time.Hour)); err != nil {
+		t.Fatalf("StoreRefreshToken: %v", err)
+	}
+	blacklisted := sign(jwt.MapClaims{"user_id": active.ID.String(), "sid": uuid.New().String()})
+	if err := tokenService.BlacklistToken(ctx, blacklisted, now.Add(time.Hour)); err != nil {
+		t.Fatalf("BlacklistToken: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		want  *dto.IntrospectResponseDTO
+	}{
+		{
+			name:  "access token of active user",
+			token: sign(jwt.MapClaims{"user_id": active.ID.String(), "sid": "session-1", "client_id": "spa", "scope": "openid profile", "jti": "access-1"}),
+			want: &dto.IntrospectResponseDTO{
+				Active:    true,
+				Scope:     "openid profile",
+				ClientID:  "spa",
+				TokenType: "Bearer",
+				Sub:       active.ID.String(),
+				Iss:       "https://id.example",
+				Jti:       "access-1",
+				Role:      string(entities.RoleUser),
+				SessionID: "session-1",
+				Exp:       now.Add(time.Hour).Unix(),
+				Iat:       now.Unix(),
+			},
+		},
+		{
+			name:  "current refresh token",
+			token: sign(jwt.MapClaims{"user_id": active.ID.String(), "type": "refresh", "fid": "family", "jti": "refresh-current"}),
+			want: &dto.IntrospectResponseDTO{
+				Active:    true,
+				TokenType: "refresh_token",
+				Sub:       active.ID.String(),
+				Iss:       "https://id.example",
+				Jti:       "refresh-current",
+				Role:      string(entities.RoleUser),
+				Exp:       now.Add(time.Hour).Unix(),
+				Iat:       now.Unix(),
+			},
+		},
+		{
+			name:  "service client token",
+			token: sign(jwt.MapClaims{"sub": caller.ID.String(), "client_id": caller.ClientID, "role": string(caller.Role), "jti": "client-1"}),
+			want: &dto.IntrospectResponseDTO{
+				Active:    true,
+				ClientID:  caller.ClientID,
+				TokenType: "Bearer",
+				Sub:       caller.ID.String(),
+				Iss:       "https://id.example",
+				Jti:       "client-1",
+				Role:      string(caller.Role),
+				Exp:       now.Add(time.Hour).Unix(),
+				Iat:       now.Unix(),
+			},
+		},
+		{name: "replaced refresh token", token: sign(jwt.MapClaims{"user_id": active.ID.String(), "type": "refresh", "fid": "family", "jti": "refresh-old"})},
+		{name: "revoked session", token: sign(jwt.MapClaims{"user_id": active.ID.String(), "sid": revokedSession})},
+		{name: "blacklisted token", token: blacklisted},
+		{name: "deactivated user", token: sign(jwt.MapClaims{"user_id": blocked.ID.String()})},
+		{name: "deleted user", token: sign(jwt.MapClaims{"user_id": uuid.New().String()})},
+		{name: "token of deleted client", token: sign(jwt.MapClaims{"sub": uuid.New().String(), "client_id": "removed"})},
+		{name: "expired token", token: sign(jwt.MapClaims{"user_id": active.ID.String(), "exp": now.Add(-time.Minute).Unix()})},
+		{name: "garbage", token: "not-a-token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := service.Introspect(ctx, dto.IntrospectRequestDTO{
+				Token:        tt.token,
+				ClientID:     caller.ClientID,
+				ClientSecret: secret,
+			})
+			if err != nil {
+				t.Fatalf("Introspect: %v", err)
+			}
+			want := tt.want
+			if want == nil {
+				// Для неактивного токена ничего, кроме active=false, не раскрывается
+				want = &dto.IntrospectResponseDTO{}
+			}
+			if *got != *want {
+				t.Fatalf("Introspect = %+v, want %+v", *got, *want)
+			}
+		})
+	}
+}
+
+func TestIntrospectRequiresConfidentialClient(t *testing.T) {
+	ctx := context.Background()
+	const secret = "resource-server-secret"
+
+	tests := []struct {
+		name     string
+		client   *entities.OAuthClient
+		secret   string
+		token    string
+		wantCode string
+	}{
+		{
+			name:     "public client",
+			client:   &entities.OAuthClient{ClientID: "spa"},
+			secret:   "anything",
+			token:    "token",
+			wantCode: errors.OAuthInvalidClient,
+		},
+		{
+			name:     "wrong secret",
+			client:   &entities.OAuthClient{ClientID: "rs", Confidential: true, SecretHash: crypto.HashToken(secret)},
+			secret:   "guess",
+			token:    "token",
+			wantCode: errors.OAuthInvalidClient,
+		},
+		{
+			name:     "missing secret",
+			client:   &entities.OAuthClient{ClientID: "rs", Confidential: true, SecretHash: crypto.HashToken(secret)},
+			token:    "token",
+			wantCode: errors.OAuthInvalidClient,
+		},
+		{
+			name:     "missing token",
+			client:   &entities.OAuthClient{ClientID: "rs", Confidential: true, SecretHash: crypto.HashToken(secret)},
+			secret:   secret,
+			wantCode: errors.OAuthInvalidRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &oauthService{clientRepository: &fakeOAuthClientRepository{client: tt.client}}
+			_, err := service.Introspect(ctx, dto.IntrospectRequestDTO{
+				Token:        tt.token,
+				ClientID:     tt.client.ClientID,
+				ClientSecret: tt.secret,
+			})
+			assertOAuthError(t, err, tt.wantCode)
+		})
+	}
+}
+
+// fakeRevokingAuth запоминает токены, переданные на отзыв
+type fakeRevokingAuth struct {
+	AuthService
+	revoked []string
+}
+
+func (s *fakeRevokingAuth) RevokeToken(_ context.Context, tokenString string) error {
+	s.revoked = append(s.revoked, tokenString)
+	return nil
+}
+
+func TestRevoke(t *testing.T) {
+	ctx := context.Background()
+	client := newTestOAuthClient()
+	jwtService := newTestJWTService(t)
+	auth := &fakeRevokingAuth{}
+	service := &oauthService{
+		clientRepository: &fakeOAuthClientRepository{client: client},
+		authService:      auth,
+		jwtService:       jwtService,
+	}
+
+	sign := func(clientID string) string {
+		t.Helper()
+		claims := jwt.MapClaims{
+			"user_id": uuid.New().String(),
+			"type":    "access",
+			"exp":     time.Now().Add(time.Hour).Unix(),
+		}
+		if clientID != "" {
+			claims["client_id"] = clientID
+		}
+		token, err := jwtService.CreateToken(claims)
+		if err != nil {
+			t.Fatalf("CreateToken: %v", err)
+		}
+		return token
+	}
+	own := sign(client.ClientID)
+
+	tests := []struct {
+		name        string
+		request     dto.RevokeRequestDTO
+		wantErr     string
+		wantRevoked bool
+	}{
+		{name: "own token", request: dto.RevokeRequestDTO{Token: own, ClientID: client.ClientID}, wantRevoked: true},
+		// Чужие и недействительные токены молча игнорируются (RFC 7009, раздел 2.2)
+		{name: "token of another client", request: dto.RevokeRequestDTO{Token: sign("other"), ClientID: client.ClientID}},
+		{name: "token issued without client", request: dto.RevokeRequestDTO{Token: sign(""), ClientID: client.ClientID}},
+		{name: "invalid token", request: dto.RevokeRequestDTO{Token: "not-a-jwt", ClientID: client.ClientID}},
+		{name: "missing client", request: dto.RevokeRequestDTO{Token: own}, wantErr: errors.OAuthInvalidClient},
+		{name: "unknown client", request: dto.RevokeRequestDTO{Token: own, ClientID: "other"}, wantErr: errors.OAuthInvalidClient},
+		{name: "missing token", request: dto.RevokeRequestDTO{ClientID: client.ClientID}, wantErr: errors.OAuthInvalidRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth.revoked = nil
+			err := service.Revoke(ctx, tt.request)
+			if tt.wantErr != "" {
+				assertOAuthError(t, err, tt.wantErr)
+			} else if err != nil {
+				t.Fatalf("Revoke: %v", err)
+			}
+			if revoked := len(auth.revoked) == 1 && auth.revoked[0] == tt.request.Token; revoked != tt.wantRevoked {
+				t.Errorf("token revoked = %v, want %v", revoked, tt.wantRevoked)
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package services
+
+import (
+	"context"
+	stdErrors "errors"
+	"fmt"
+	"gold_portal/internal/domain/dto"
+	"gold_portal/internal/domain/entities"
+	"gold_portal/internal/domain/repositories"
+	"gold_portal/internal/errors"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	AuditActionOrganizationCreated       = "ORGANIZATION_CREATED"
+	AuditActionOrganizationMemberSaved   = "ORGANIZATION_MEMBER_SAVED"
+	AuditActionOrganizationMemberRemoved = "ORGANIZATION_MEMBER_REMOVED"
+)
+
+var organizationSlugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,62}$`)
+
+type OrganizationService interface {
+	GetAll(ctx context.Context) ([]*dto.OrganizationResponseDTO, error)
+	Create(ctx context.Context, actorID uuid.UUID, request dto.OrganizationRequestDTO) (*dto.OrganizationResponseDTO, error)
+	// Добавляет пользователя в организацию или меняет его роль в ней
+	SaveMember(ctx context.Context, actorID, organizationID uuid.UUID, request dto.OrganizationMemberRequestDTO) error
+	// Исключает пользователя из организации и выводит его со всех устройств
+	RemoveMember(ctx context.Context, actorID, organizationID, userID uuid.UUID, clientIP, userAgent string) error
+	// Организации пользователя с ролью в каждой; active — активная организация сессии
+	GetMemberships(ctx context.Context, userID, active uuid.UUID) ([]*dto.OrganizationMembershipDTO, error)
+	// Определяет организацию и роль пользователя в ней. uuid.Nil означает организацию по
+	// умолчанию: первую, в которую вступил пользователь. Суперпользователь действует с ролью
+	// superuser в любой организации, остальным нужно членство (иначе ErrNotOrganizationMember)
+	Resolve(ctx context.Context, user *entities.User, organizationID uuid.UUID) (uuid.UUID, entities.Role, error)
+	// Организация для самостоятельной регистрации
+	GetDefault(ctx context.Context) (*entities.Organization, error)
+	IsMember(ctx context.Context, organizationID, userID uuid.UUID) (bool, error)
+}
+
+type organizationService struct {
+	organizationRepository repositories.OrganizationRepository
+	userRepository         repositories.UserRepository
+	sessionService         SessionService
+	auditService           AuditService
+}
+
+func NewOrganizationService(organizationRepository repositories.OrganizationRepository, userRepository repositories.UserRepository, sessionService SessionService, auditService AuditService) OrganizationService {
+	return &organizationService{
+		organizationRepository: organizationRepository,
+		userRepository:         userRepository,
+		sessionService:         sessionService,
+		auditService:           auditService,
+	}
+}
+
+func (s *organizationService) GetAll(ctx context.Context) ([]*dto.OrganizationResponseDTO, error) {
+	organizations, err := s.organizationRepository.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]*dto.OrganizationResponseDTO, 0, len(organizations))
+	for _, organization := range organizations {
+		var organizationResponse dto.OrganizationResponseDTO
+		organizationResponse.FromModel(organization)
+		response = append(response, &organizationResponse)
+	}
+	return response, nil
+}
+
+func (s *organizationService) Create(ctx context.Context, actorID uuid.UUID, request dto.OrganizationRequestDTO) (*dto.OrganizationResponseDTO, error) {
+	if !organizationSlugPattern.MatchString(request.Slug) {
+		return nil, errors.ErrInvalidOrganizationSlug
+	}
+	if _, err := s.organizationRepository.GetBySlug(ctx, request.Slug); err == nil {
+		return nil, errors.ErrOrganizationExists
+	}
+
+	now := time.Now()
+	organization := &entities.Organization{
+		ID:        uuid.New(),
+		Slug:      request.Slug,
+		Name:      request.Name,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.organizationRepository.Create(ctx, organization); err != nil {
+		return nil, fmt.Errorf("ошибка при создании организации: %w", err)
+	}
+
+	if err := s.auditService.ForOrganization(organization.ID).Log(actorID, organization.ID, AuditActionOrganizationCreated, "Organization",
+		http.StatusCreated, request.ClientIP, request.UserAgent,
+		fmt.Sprintf("Создана организация %s (%s)", organization.Slug, organization.Name)); err != nil {
+		return nil, err
+	}
+
+	var response dto.OrganizationResponseDTO
+	response.FromModel(organization)
+	return &response, nil
+}
+
+func (s *organizationService) SaveMember(ctx context.Context, actorID, organizationID uuid.UUID, request dto.OrganizationMemberRequestDTO) error {
+	if _, err := s.organizationRepository.GetID(ctx, organizationID); err != nil {
+		return err
+	}
+	if _, err := s.userRepository.GetID(ctx, request.UserID); err != nil {
+		return err
+	}
+
+	previous, err := s.organizationRepository.GetMember(ctx, organizationID, request.UserID)
+	if err != nil && !stdErrors.Is(err, errors.ErrNotOrganizationMember) {
+		return err
+	}
+	var data string
+	if previous == nil {
+		member := &entities.OrganizationMember{
+			OrganizationID: organizationID,
+			UserID:         request.UserID,
+			Role:           request.Role,
+		}
+		if err := s.organizationRepository.SaveMember(ctx, member); err != nil {
+			return fmt.Errorf("ошибка при добавлении в организацию: %w", err)
+		}
+		data = fmt.Sprintf("Пользователь добавлен в организацию с ролью %s", request.Role)
+	} else {
+		if previous.Role == request.Role {
+			return nil
+		}
+		data = fmt.Sprintf("Роль в организации изменена с %s на %s, сессии отозваны", previous.Role, request.Role)
+	}
+	// UpdateRole переносит выдачу и снятие роли superuser на роль пользователя на платформе
+	if previous != nil || request.Role == entities.RoleSuperUser {
+		if err := s.userRepository.UpdateRole(ctx, organizationID, request.UserID, request.Role); err != nil {
+			return err
+		}
+	}
+	// Токены несут прежнюю роль
+	if previous != nil {
+		if err := s.sessionService.LogoutEverywhere(ctx, request.UserID); err != nil {
+			return err
+		}
+	}
+	return s.auditService.ForOrganization(organizationID).Log(actorID, request.UserID, AuditActionOrganizationMemberSaved, "User",
+		http.StatusOK, request.ClientIP, request.UserAgent, data)
+}
+
+func (s *organizationService) RemoveMember(ctx context.Context, actorID, organizationID, userID uuid.UUID, clientIP, userAgent string) error {
+	if _, err := s.organizationRepository.GetMember(ctx, organizationID, userID); err != nil {
+		return err
+	}
+	if err := s.organizationRepository.RemoveMember(ctx, organizationID, userID); err != nil {
+		return fmt.Errorf("ошибка при исключении из организации: %w", err)
+	}
+	// Токены с этой организацией в claim org больше не действуют
+	if err := s.sessionService.LogoutEverywhere(ctx, userID); err != nil {
+		return err
+	}
+
+	return s.auditService.ForOrganization(organizationID).Log(actorID, userID, AuditActionOrganizationMemberRemoved, "User",
+		http.StatusOK, clientIP, userAgent, "Пользователь исключён из организации, сессии отозваны")
+}
+
+func (s *organizationService) GetMemberships(ctx context.Context, userID, active uuid.UUID) ([]*dto.OrganizationMembershipDTO, error) {
+	members, err := s.organizationRepository.GetMemberships(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]*dto.OrganizationMembershipDTO, 0, len(members))
+	for _, member := range members {
+		membership := &dto.OrganizationMembershipDTO{
+			Role:   member.Role,
+			Active: member.OrganizationID == active,
+		}
+		membership.FromModel(&member.Organization)
+		response = append(response, membership)
+	}
+	return response, nil
+}
+
+func (s *organizationService) Resolve(ctx context.Context, user *entities.User, organizationID uuid.UUID) (uuid.UUID, entities.Role, error) {
+	if organizationID == uuid.Nil {
+		members, err := s.organizationRepository.GetMemberships(ctx, user.ID)
+		if err != nil {
+			return uuid.Nil, "", err
+		}
+		switch {
+		case len(members) > 0:
+			organizationID = members[0].OrganizationID
+		case user.Role == entities.RoleSuperUser:
+			organization, err := s.GetDefault(ctx)
+			if err != nil {
+				return uuid.Nil, "", err
+			}
+			organizationID = organization.ID
+		default:
+			return uuid.Nil, "", errors.ErrNotOrganizationMember
+		}
+	}
+
+	if user.Role == entities.RoleSuperUser {
+		if _, err := s.organizationRepository.GetID(ctx, organizationID); err != nil {
+			return uuid.Nil, "", err
+		}
+		return organizationID, entities.RoleSuperUser, nil
+	}
+
+	member, err := s.organizationRepository.GetMember(ctx, organizationID, user.ID)
+	if err != nil {
+		return uuid.Nil, "", err
+	}
+	return organizationID, member.Role, nil
+}
+
+func (s *organizationService) GetDefault(ctx context.Context) (*entities.Organization, error) {
+	return s.organizationRepository.GetBySlug(ctx, entities.DefaultOrganizationSlug)
+}
+
+func (s *organizationService) IsMember(ctx context.Context, organizationID, userID uuid.UUID) (bool, error) {
+	if _, err := s.organizationRepository.GetMember(ctx, organizationID, userID); err != nil {
+		if stdErrors.Is(err, errors.ErrNotOrganizationMember) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
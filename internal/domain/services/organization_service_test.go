@@ -0,0 +1,245 @@
+package services
+
+import (
+	"context"
+	stdErrors "errors"
+	"gold_portal/internal/domain/dto"
+	"gold_portal/internal/domain/entities"
+	"gold_portal/internal/errors"
+	"strings"
+	"testing"
+
+	jwtv4 "github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+)
+
+// newTestOrganizationFixture возвращает сервис организаций с ещё одной организацией otherID
+func newTestOrganizationFixture(t *testing.T, users ...*entities.User) (*organizationService, *fakeUserRepository, *fakeSessionRepository, uuid.UUID) {
+	t.Helper()
+	sessions, sessionRepository, _ := newTestSessionService(t)
+	userRepository := newFakeUserRepository(users...)
+	sessions.userRepository = userRepository
+	organizations := newFakeOrganizationRepository(userRepository)
+	otherID := uuid.New()
+	organizations.organizations[otherID] = &entities.Organization{ID: otherID, Slug: "acme", Name: "ACME"}
+
+	service := &organizationService{
+		organizationRepository: organizations,
+		userRepository:         userRepository,
+		sessionService:         sessions,
+		auditService:           &fakeAuditService{},
+	}
+	return service, userRepository, sessionRepository, otherID
+}
+
+func TestOrganizationResolve(t *testing.T) {
+	ctx := context.Background()
+	member := &entities.User{ID: uuid.New(), Role: entities.RoleManager}
+	superUser := &entities.User{ID: uuid.New(), Role: entities.RoleSuperUser}
+	outsider := &entities.User{ID: uuid.New(), Role: entities.RoleAdmin}
+	service, users, _, otherID := newTestOrganizationFixture(t, member, superUser, outsider)
+	// Суперпользователь не состоит ни в одной организации, а outsider состоит только в другой
+	users.members = users.members[:1]
+	users.members = append(users.members, &entities.OrganizationMember{OrganizationID: otherID, UserID: outsider.ID, Role: entities.RoleAdmin})
+
+	tests := []struct {
+		name           string
+		user           *entities.User
+		organizationID uuid.UUID
+		wantID         uuid.UUID
+		wantRole       entities.Role
+		wantErr        error
+	}{
+		{name: "member in own organization", user: member, organizationID: testOrganizationID, wantID: testOrganizationID, wantRole: entities.RoleManager},
+		{name: "member defaults to first organization", user: member, wantID: testOrganizationID, wantRole: entities.RoleManager},
+		{name: "member of another organization", user: member, organizationID: otherID, wantErr: errors.ErrNotOrganizationMember},
+		{name: "role comes from membership", user: outsider, wantID: otherID, wantRole: entities.RoleAdmin},
+		{name: "admin elsewhere is not admin here", user: outsider, organizationID: testOrganizationID, wantErr: errors.ErrNotOrganizationMember},
+		{name: "superuser in any organization", user: superUser, organizationID: otherID, wantID: otherID, wantRole: entities.RoleSuperUser},
+		{name: "superuser defaults to default organization", user: superUser, wantID: testOrganizationID, wantRole: entities.RoleSuperUser},
+		{name: "superuser in unknown organization", user: superUser, organizationID: uuid.New(), wantErr: errors.ErrOrganizationNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			organizationID, role, err := service.Resolve(ctx, tt.user, tt.organizationID)
+			if !stdErrors.Is(err, tt.wantErr) {
+				t.Fatalf("Resolve error = %v, want %v", err, tt.wantErr)
+			}
+			if organizationID != tt.wantID || role != tt.wantRole {
+				t.Errorf("Resolve = %s %s, want %s %s", organizationID, role, tt.wantID, tt.wantRole)
+			}
+		})
+	}
+}
+
+func TestOrganizationSaveMember(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		join       bool
+		role       entities.Role
+		wantRole   entities.Role
+		wantLogout bool
+	}{
+		{name: "join another organization", join: true, role: entities.RoleManager},
+		{name: "same role is a no-op", role: entities.RoleUser},
+		{name: "role change logs out", role: entities.RoleManager, wantLogout: true},
+		{name: "superuser grant changes platform role", role: entities.RoleSuperUser, wantRole: entities.RoleSuperUser, wantLogout: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := &entities.User{ID: uuid.New(), Role: entities.RoleUser, IsActive: true}
+			service, users, sessionRepository, otherID := newTestOrganizationFixture(t, user)
+			session, err := service.sessionService.Create(ctx, user.ID, testOrganizationID, "phone", "10.0.0.1", "", "", nil)
+			if err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			organizationID := testOrganizationID
+			if tt.join {
+				organizationID = otherID
+			}
+
+			err = service.SaveMember(ctx, uuid.New(), organizationID, dto.OrganizationMemberRequestDTO{UserID: user.ID, Role: tt.role})
+			if err != nil {
+				t.Fatalf("SaveMember: %v", err)
+			}
+
+			if member := users.member(organizationID, user.ID); member == nil || member.Role != tt.role {
+				t.Errorf("membership = %+v, want role %s", member, tt.role)
+			}
+			// Членство в прежней организации не меняется при вступлении в новую
+			if tt.join && users.member(testOrganizationID, user.ID).Role != entities.RoleUser {
+				t.Error("joining another organization changed the existing membership")
+			}
+			wantRole := tt.wantRole
+			if wantRole == "" {
+				wantRole = entities.RoleUser
+			}
+			if user.Role != wantRole {
+				t.Errorf("platform role = %s, want %s", user.Role, wantRole)
+			}
+			if revoked := sessionRepository.sessions[session.ID].RevokedAt != nil; revoked != tt.wantLogout {
+				t.Errorf("session revoked = %v, want %v", revoked, tt.wantLogout)
+			}
+		})
+	}
+}
+
+func TestOrganizationRemoveMember(t *testing.T) {
+	ctx := context.Background()
+	user := &entities.User{ID: uuid.New(), Role: entities.RoleManager, IsActive: true}
+	service, users, sessionRepository, otherID := newTestOrganizationFixture(t, user)
+	session, err := service.sessionService.Create(ctx, user.ID, testOrganizationID, "phone", "10.0.0.1", "", "", nil)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if err := service.RemoveMember(ctx, uuid.New(), otherID, user.ID, "", ""); !stdErrors.Is(err, errors.ErrNotOrganizationMember) {
+		t.Fatalf("RemoveMember from foreign organization error = %v, want %v", err, errors.ErrNotOrganizationMember)
+	}
+	if sessionRepository.sessions[session.ID].RevokedAt != nil {
+		t.Fatal("failed removal revoked the session")
+	}
+
+	if err := service.RemoveMember(ctx, uuid.New(), testOrganizationID, user.ID, "", ""); err != nil {
+		t.Fatalf("RemoveMember: %v", err)
+	}
+	if users.member(testOrganizationID, user.ID) != nil {
+		t.Error("membership is not removed")
+	}
+	// Токены с исключённой организацией больше не действуют
+	if user.TokenVersion != 1 || sessionRepository.sessions[session.ID].RevokedAt == nil {
+		t.Error("removed member is not logged out everywhere")
+	}
+}
+
+func TestUserServiceOrganizationIsolation(t *testing.T) {
+	ctx := context.Background()
+	actor := &entities.User{ID: uuid.New(), Role: entities.RoleAdmin, IsActive: true}
+	member := &entities.User{ID: uuid.New(), Phone: testPhone, Role: entities.RoleUser, IsActive: true}
+	stranger := &entities.User{ID: uuid.New(), Phone: "+996555000001", Role: entities.RoleUser, IsActive: true}
+	service, users, _, audit := newTestUserService(t, actor, member, stranger)
+	otherID := uuid.New()
+	organizations := service.organizationRepository.(*fakeOrganizationRepository)
+	organizations.organizations[otherID] = &entities.Organization{ID: otherID, Slug: "acme", Name: "ACME"}
+	// stranger состоит только в другой организации
+	users.members[2].OrganizationID = otherID
+	admin := entities.RoleAdmin
+
+	if _, err := service.UserID(ctx, testOrganizationID, stranger.ID); !stdErrors.Is(err, errors.ErrUserNotFound) {
+		t.Errorf("UserID of foreign user error = %v, want %v", err, errors.ErrUserNotFound)
+	}
+	if _, err := service.Patch(ctx, actor.ID, testOrganizationID, stranger.ID, dto.UserUpdateDTO{Role: &admin}, nil); !stdErrors.Is(err, errors.ErrUserNotFound) {
+		t.Errorf("Patch of foreign user error = %v, want %v", err, errors.ErrUserNotFound)
+	}
+	if err := service.Delete(ctx, actor.ID, testOrganizationID, stranger.ID); !stdErrors.Is(err, errors.ErrUserNotFound) {
+		t.Errorf("Delete of foreign user error = %v, want %v", err, errors.ErrUserNotFound)
+	}
+	if stranger.Role != entities.RoleUser || users.users[stranger.ID] == nil || stranger.TokenVersion != 0 {
+		t.Error("request in another organization changed the user")
+	}
+
+	// Роль меняется только в организации запроса, а журнал пишется в неё же
+	users.members = append(users.members, &entities.OrganizationMember{OrganizationID: otherID, UserID: member.ID, Role: entities.RoleUser})
+	if _, err := service.Patch(ctx, actor.ID, testOrganizationID, member.ID, dto.UserUpdateDTO{Role: &admin}, nil); err != nil {
+		t.Fatalf("Patch: %v", err)
+	}
+	if users.member(testOrganizationID, member.ID).Role != entities.RoleAdmin || users.member(otherID, member.ID).Role != entities.RoleUser {
+		t.Error("role is not changed in the request organization only")
+	}
+	if len(audit.entries) != 1 || !strings.HasPrefix(audit.entries[0], AuditActionSessionsRevoked) {
+		t.Errorf("audit entries = %q, want sessions revoked", audit.entries)
+	}
+
+	// Пользователь из нескольких организаций только исключается из текущей
+	if err := service.Delete(ctx, actor.ID, testOrganizationID, member.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if users.users[member.ID] == nil || users.member(testOrganizationID, member.ID) != nil || users.member(otherID, member.ID) == nil {
+		t.Error("member of several organizations is not just removed from the current one")
+	}
+}
+
+func TestSwitchOrganization(t *testing.T) {
+	ctx := context.Background()
+	service, repository, login := newTestAuthService(t)
+	users := service.userRepository.(*fakeUserRepository)
+	user, err := users.FindByPhone(ctx, testPhone)
+	if err != nil {
+		t.Fatalf("FindByPhone: %v", err)
+	}
+	otherID := uuid.New()
+	organizations := service.organizations.(*organizationService).organizationRepository.(*fakeOrganizationRepository)
+	organizations.organizations[otherID] = &entities.Organization{ID: otherID, Slug: "acme", Name: "ACME"}
+	session, _, _ := login()
+
+	if _, err := service.SwitchOrganization(ctx, user.ID, session.ID, otherID); !stdErrors.Is(err, errors.ErrNotOrganizationMember) {
+		t.Fatalf("SwitchOrganization to foreign organization error = %v, want %v", err, errors.ErrNotOrganizationMember)
+	}
+	if repository.sessions[session.ID].OrganizationID != testOrganizationID {
+		t.Fatal("session switched to a foreign organization")
+	}
+
+	users.members = append(users.members, &entities.OrganizationMember{OrganizationID: otherID, UserID: user.ID, Role: entities.RoleManager})
+	response, err := service.SwitchOrganization(ctx, user.ID, session.ID, otherID)
+	if err != nil {
+		t.Fatalf("SwitchOrganization: %v", err)
+	}
+	if repository.sessions[session.ID].OrganizationID != otherID {
+		t.Error("session organization is not switched")
+	}
+	if response.User.Role != entities.RoleManager || *response.User.OrganizationID != otherID {
+		t.Errorf("user = %s in %v, want %s in %s", response.User.Role, response.User.OrganizationID, entities.RoleManager, otherID)
+	}
+	token, err := service.jwtService.ParseToken(response.AccessToken)
+	if err != nil {
+		t.Fatalf("ParseToken: %v", err)
+	}
+	claims := token.Claims.(jwtv4.MapClaims)
+	if claims["org"] != otherID.String() || claims["role"] != string(entities.RoleManager) {
+		t.Errorf("access token org = %v role = %v, want %s %s", claims["org"], claims["role"], otherID, entities.RoleManager)
+	}
+}
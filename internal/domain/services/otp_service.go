@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"gold_portal/config"
+	"gold_portal/internal/errors"
+	"gold_portal/internal/infrastructure/sms"
+	"gold_portal/internal/pkg/crypto"
+	"strings"
+)
+
+const (
+	// Назначение кода: код для одного действия нельзя использовать для другого
+	OTPPurposeLogin             = "login"
+	OTPPurposePhoneVerification = "phone_verification"
+	OTPPurposePasswordReset     = "password_reset"
+
+	// Вход по коду из SMS (RFC 8176)
+	AuthMethodSMS = "sms"
+)
+
+type OTPService interface {
+	// Отправляет одноразовый код на номер
+	Send(ctx context.Context, phone, purpose string) error
+	// Отмечает отправку кода на номер без самой отправки. Возвращает ErrOTPTooManyRequests,
+	// если код уже отправлялся недавно; позволяет одинаково ограничивать и несуществующие номера
+	Throttle(ctx context.Context, phone, purpose string) error
+	// Проверяет код и гасит его; после исчерпания попыток код аннулируется
+	Verify(ctx context.Context, phone, purpose, code string) error
+}
+
+type otpService struct {
+	sender sms.SMSSender
+	cache  Cache
+	config *config.Config
+}
+
+func NewOTPService(sender sms.SMSSender, cache Cache, config *config.Config) OTPService {
+	return &otpService{
+		sender: sender,
+		cache:  cache,
+		config: config,
+	}
+}
+
+func (s *otpService) Send(ctx context.Context, phone, purpose string) error {
+	if err := s.Throttle(ctx, phone, purpose); err != nil {
+		return err
+	}
+
+	code, err := crypto.GenerateNumericCode(s.config.OTP.Length)
+	if err != nil {
+		return fmt.Errorf("ошибка генерации кода: %w", err)
+	}
+
+	key := otpKey(purpose, phone)
+	if err := s.cache.Set(ctx, key, s.hashCode(phone, purpose, code), s.config.OTP.Expiry); err != nil {
+		return fmt.Errorf("ошибка сохранения кода: %w", err)
+	}
+	// Новый код получает полный набор попыток
+	if err := s.cache.Delete(ctx, key+":attempts"); err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf("Код подтверждения: %s. Никому не сообщайте его", code)
+	if err := s.sender.Send(ctx, phone, message); err != nil {
+		return fmt.Errorf("ошибка отправки SMS: %w", err)
+	}
+	return nil
+}
+
+func (s *otpService) Throttle(ctx context.Context, phone, purpose string) error {
+	fresh, err := s.cache.SetNX(ctx, otpKey(purpose, phone)+":throttle", "sent", s.config.OTP.ResendInterval)
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		return errors.ErrOTPTooManyRequests
+	}
+	return nil
+}
+
+func (s *otpService) Verify(ctx context.Context, phone, purpose, code string) error {
+	key := otpKey(purpose, phone)
+	expected, err := s.cache.Get(ctx, key)
+	if err != nil {
+		return errors.ErrInvalidOTP
+	}
+
+	attempts, err := s.cache.Incr(ctx, key+":attempts")
+	if err != nil {
+		return err
+	}
+	if attempts == 1 {
+		_ = s.cache.Expire(ctx, key+":attempts", s.config.OTP.Expiry)
+	}
+	if attempts > int64(s.config.OTP.MaxAttempts) {
+		_ = s.cache.Delete(ctx, key)
+		return errors.ErrInvalidOTP
+	}
+
+	actual := s.hashCode(phone, purpose, strings.TrimSpace(code))
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(actual)) != 1 {
+		return errors.ErrInvalidOTP
+	}
+
+	// Код одноразовый
+	if err := s.cache.Delete(ctx, key); err != nil {
+		return err
+	}
+	return s.cache.Delete(ctx, key+":attempts")
+}
+
+// hashCode привязывает хэш к номеру и назначению, чтобы код нельзя было перенести
+func (s *otpService) hashCode(phone, purpose, code string) string {
+	return crypto.HashCode(s.config.OTP.HashKey, purpose+":"+phone+":"+code)
+}
+
+func otpKey(purpose, phone string) string {
+	return fmt.Sprintf("otp:%s:%s", purpose, phone)
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	stdErrors "errors"
+	"gold_portal/config"
+	"gold_portal/internal/errors"
+	"regexp"
+	"testing"
+	"time"
+)
+
+const testPhone = "+996555123456"
+
+// fakeSMSSender запоминает последний код, отправленный на каждый номер
+type fakeSMSSender struct {
+	codes map[string]string
+}
+
+var otpCodePattern = regexp.MustCompile(`\d{4,10}`)
+
+func (s *fakeSMSSender) Send(_ context.Context, phone, message string) error {
+	s.codes[phone] = otpCodePattern.FindString(message)
+	return nil
+}
+
+func newTestOTPService(t *testing.T) (*otpService, *fakeSMSSender, func(time.Duration)) {
+	cache, server := newTestCache(t)
+	sender := &fakeSMSSender{codes: make(map[string]string)}
+	service := &otpService{
+		sender: sender,
+		cache:  cache,
+		config: &config.Config{OTP: config.OTPConfig{
+			Length:         6,
+			Expiry:         5 * time.Minute,
+			MaxAttempts:    3,
+			ResendInterval: time.Minute,
+			HashKey:        "test-otp-key",
+		}},
+	}
+	return service, sender, server.FastForward
+}
+
+func TestOTPVerify(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		// Коды вводятся по очереди; "" заменяется отправленным кодом
+		attempts []string
+		phone    string
+		purpose  string
+		wantErr  []error
+	}{
+		{
+			name:     "sent code",
+			attempts: []string{""},
+			wantErr:  []error{nil},
+		},
+		{
+			name:     "code is single use",
+			attempts: []string{"", ""},
+			wantErr:  []error{nil, errors.ErrInvalidOTP},
+		},
+		{
+			name:     "code for another purpose",
+			attempts: []string{""},
+			purpose:  OTPPurposePhoneVerification,
+			wantErr:  []error{errors.ErrInvalidOTP},
+		},
+		{
+			name:     "code for another phone",
+			attempts: []string{""},
+			phone:    "+996555000000",
+			wantErr:  []error{errors.ErrInvalidOTP},
+		},
+		{
+			name:     "wrong code keeps remaining attempts",
+			attempts: []string{"wrong", "wrong", ""},
+			wantErr:  []error{errors.ErrInvalidOTP, errors.ErrInvalidOTP, nil},
+		},
+		{
+			name:     "attempts exhausted invalidate the code",
+			attempts: []string{"wrong", "wrong", "wrong", ""},
+			wantErr:  []error{errors.ErrInvalidOTP, errors.ErrInvalidOTP, errors.ErrInvalidOTP, errors.ErrInvalidOTP},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, sender, _ := newTestOTPService(t)
+			if err := service.Send(ctx, testPhone, OTPPurposeLogin); err != nil {
+				t.Fatalf("Send: %v", err)
+			}
+			phone, purpose := testPhone, OTPPurposeLogin
+			if tt.phone != "" {
+				phone = tt.phone
+			}
+			if tt.purpose != "" {
+				purpose = tt.purpose
+			}
+
+			for i, code := range tt.attempts {
+				if code == "" {
+					code = sender.codes[testPhone]
+				}
+				if err := service.Verify(ctx, phone, purpose, code); !stdErrors.Is(err, tt.wantErr[i]) {
+					t.Fatalf("attempt %d: got %v, want %v", i+1, err, tt.wantErr[i])
+				}
+			}
+		})
+	}
+}
+
+func TestOTPSendThrottle(t *testing.T) {
+	ctx := context.Background()
+	service, sender, advance := newTestOTPService(t)
+
+	if err := service.Send(ctx, testPhone, OTPPurposeLogin); err != nil {
+		t.Fatalf("first Send: %v", err)
+	}
+	first := sender.codes[testPhone]
+
+	if err := service.Send(ctx, testPhone, OTPPurposeLogin); !stdErrors.Is(err, errors.ErrOTPTooManyRequests) {
+		t.Fatalf("repeated Send: got %v, want ErrOTPTooManyRequests", err)
+	}
+	if sender.codes[testPhone] != first {
+		t.Fatal("throttled Send must not deliver a new code")
+	}
+	// Ограничение действует на пару номер и назначение
+	if err := service.Throttle(ctx, testPhone, OTPPurposePhoneVerification); err != nil {
+		t.Fatalf("Throttle for another purpose: %v", err)
+	}
+
+	advance(time.Minute)
+	if err := service.Send(ctx, testPhone, OTPPurposeLogin); err != nil {
+		t.Fatalf("Send after resend interval: %v", err)
+	}
+	if err := service.Verify(ctx, testPhone, OTPPurposeLogin, sender.codes[testPhone]); err != nil {
+		t.Fatalf("Verify new code: %v", err)
+	}
+}
+
+func TestOTPResendResetsAttempts(t *testing.T) {
+	ctx := context.Background()
+	service, sender, advance := newTestOTPService(t)
+
+	if err := service.Send(ctx, testPhone, OTPPurposeLogin); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := service.Verify(ctx, testPhone, OTPPurposeLogin, "wrong"); !stdErrors.Is(err, errors.ErrInvalidOTP) {
+			t.Fatalf("wrong attempt %d: got %v", i+1, err)
+		}
+	}
+
+	advance(time.Minute)
+	if err := service.Send(ctx, testPhone, OTPPurposeLogin); err != nil {
+		t.Fatalf("resend: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := service.Verify(ctx, testPhone, OTPPurposeLogin, "wrong"); !stdErrors.Is(err, errors.ErrInvalidOTP) {
+			t.Fatalf("wrong attempt after resend %d: got %v", i+1, err)
+		}
+	}
+	if err := service.Verify(ctx, testPhone, OTPPurposeLogin, sender.codes[testPhone]); err != nil {
+		t.Fatalf("Verify after resend: %v", err)
+	}
+}
@@ -0,0 +1,214 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"gold_portal/config"
+	"gold_portal/internal/domain/entities"
+	"gold_portal/internal/domain/repositories"
+	"gold_portal/internal/errors"
+	"gold_portal/internal/pkg/crypto"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+type PasswordPolicyService interface {
+	// Проверяет пароль по политике. Для нового пользователя userID = uuid.Nil: история не проверяется.
+	// Возвращает *errors.PasswordPolicyError со всеми найденными нарушениями
+	Validate(ctx context.Context, password, phone string, userID uuid.UUID) error
+	// Запоминает хэш установленного пароля, чтобы не дать вернуться к нему позже
+	Remember(ctx context.Context, userID uuid.UUID, hashedPassword string) error
+}
+
+type passwordPolicyService struct {
+	userRepository    repositories.UserRepository
+	historyRepository repositories.PasswordHistoryRepository
+	config            *config.Config
+}
+
+func NewPasswordPolicyService(userRepository repositories.UserRepository, historyRepository repositories.PasswordHistoryRepository, config *config.Config) PasswordPolicyService {
+	return &passwordPolicyService{
+		userRepository:    userRepository,
+		historyRepository: historyRepository,
+		config:            config,
+	}
+}
+
+func (s *passwordPolicyService) Validate(ctx context.Context, password, phone string, userID uuid.UUID) error {
+	policy := s.config.Password
+	var violations []errors.PasswordViolation
+	violate := func(code, message string) {
+		violations = append(violations, errors.PasswordViolation{Code: code, Message: message})
+	}
+
+	if utf8.RuneCountInString(password) < policy.MinLength {
+		violate(errors.PasswordTooShort, fmt.Sprintf("Пароль должен содержать не менее %d символов", policy.MinLength))
+	}
+	if policy.MaxLength > 0 && len(password) > policy.MaxLength {
+		violate(errors.PasswordTooLong, fmt.Sprintf("Пароль должен занимать не более %d байт", policy.MaxLength))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+	if policy.RequireUpper && !hasUpper {
+		violate(errors.PasswordMissingUpper, "Пароль должен содержать заглавную букву")
+	}
+	if policy.RequireLower && !hasLower {
+		violate(errors.PasswordMissingLower, "Пароль должен содержать строчную букву")
+	}
+	if policy.RequireDigit && !hasDigit {
+		violate(errors.PasswordMissingDigit, "Пароль должен содержать цифру")
+	}
+	if policy.RequireSymbol && !hasSymbol {
+		violate(errors.PasswordMissingSymbol, "Пароль должен содержать специальный символ")
+	}
+
+	if containsPhone(password, phone) {
+		violate(errors.PasswordContainsPhone, "Пароль не должен содержать номер телефона")
+	}
+
+	if userID != uuid.Nil {
+		reused, err := s.isReused(ctx, userID, password)
+		if err != nil {
+			return err
+		}
+		if reused {
+			violate(errors.PasswordReused, fmt.Sprintf("Пароль совпадает с одним из %d последних паролей", policy.HistorySize))
+		}
+	}
+
+	breached, err := s.isBreached(password)
+	if err != nil {
+		return err
+	}
+	if breached {
+		violate(errors.PasswordBreached, "Пароль найден в базе утёкших паролей")
+	}
+
+	if len(violations) > 0 {
+		return &errors.PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+func (s *passwordPolicyService) Remember(ctx context.Context, userID uuid.UUID, hashedPassword string) error {
+	if s.config.Password.HistorySize <= 0 {
+		return nil
+	}
+	entry := &entities.PasswordHistory{
+		ID:           uuid.New(),
+		UserID:       userID,
+		PasswordHash: hashedPassword,
+	}
+	if err := s.historyRepository.Add(ctx, entry, s.config.Password.HistorySize); err != nil {
+		return fmt.Errorf("ошибка сохранения истории паролей: %w", err)
+	}
+	return nil
+}
+
+// isReused сравнивает пароль с текущим и с последними паролями из истории.
+// Текущий проверяется отдельно: у пользователей, созданных до появления истории, её нет
+func (s *passwordPolicyService) isReused(ctx context.Context, userID uuid.UUID, password string) (bool, error) {
+	size := s.config.Password.HistorySize
+	if size <= 0 {
+		return false, nil
+	}
+
+	user, err := s.userRepository.GetID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	if crypto.CheckPassword(user.Password, password) == nil {
+		return true, nil
+	}
+
+	history, err := s.historyRepository.GetRecent(ctx, userID, size)
+	if err != nil {
+		return false, err
+	}
+	for _, entry := range history {
+		if crypto.CheckPassword(entry.PasswordHash, password) == nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// isBreached ищет пароль в каталоге утёкших паролей. Файл <PREFIX> содержит строки
+// <SUFFIX>:<COUNT>, где PREFIX и SUFFIX — начало и остаток SHA-1 пароля в верхнем регистре,
+// как в ответах range API Have I Been Pwned
+func (s *passwordPolicyService) isBreached(password string) (bool, error) {
+	dir := s.config.Password.BreachedDir
+	if dir == "" {
+		return false, nil
+	}
+
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	file, err := os.Open(filepath.Join(dir, prefix))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("ошибка чтения базы утёкших паролей: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		candidate, count, _ := strings.Cut(line, ":")
+		// Строки с нулевым счётчиком — заполнитель (padding) и утечкой не считаются
+		if strings.EqualFold(candidate, suffix) && strings.TrimSpace(count) != "0" {
+			return true, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return false, fmt.Errorf("ошибка чтения базы утёкших паролей: %w", err)
+	}
+	return false, nil
+}
+
+// containsPhone проверяет, есть ли в пароле номер телефона целиком или без кода страны.
+// Разделители между цифрами пароля не спасают: сравниваются только цифры
+func containsPhone(password, phone string) bool {
+	digits := onlyDigits(phone)
+	if len(digits) < 6 {
+		return false
+	}
+	// Последние девять цифр — номер абонента без кода страны
+	if len(digits) > 9 {
+		digits = digits[len(digits)-9:]
+	}
+	return strings.Contains(onlyDigits(password), digits)
+}
+
+func onlyDigits(value string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, value)
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	stdErrors "errors"
+	"gold_portal/config"
+	"gold_portal/internal/domain/entities"
+	"gold_portal/internal/errors"
+	"gold_portal/internal/pkg/crypto"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// writeBreachedPasswords создаёт каталог утёкших паролей в формате range API HIBP
+func writeBreachedPasswords(t *testing.T, counts map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for password, count := range counts {
+		sum := sha1.Sum([]byte(password))
+		hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+		file, err := os.OpenFile(filepath.Join(dir, hash[:5]), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			t.Fatalf("create breached list: %v", err)
+		}
+		if _, err := file.WriteString(hash[5:] + ":" + count + "\r\n"); err != nil {
+			t.Fatalf("write breached list: %v", err)
+		}
+		_ = file.Close()
+	}
+	return dir
+}
+
+func TestPasswordPolicyValidate(t *testing.T) {
+	ctx := context.Background()
+	breachedDir := writeBreachedPasswords(t, map[string]string{
+		"Breached#Pass1": "42",
+		// Нулевой счётчик — заполнитель, а не утечка
+		"Padding#Pass1": "0",
+	})
+
+	userID := uuid.New()
+	hash := func(password string) string {
+		hashed, err := crypto.HashPassword(password)
+		if err != nil {
+			t.Fatalf("HashPassword: %v", err)
+		}
+		return hashed
+	}
+	user := &entities.User{ID: userID, Phone: testPhone, Password: hash("Current#Pass1")}
+	// История от старых к новым; проверяются только последние HistorySize
+	history := &fakePasswordHistoryRepository{}
+	for _, password := range []string{"Ancient#Pass1", "Older#Pass1", "Recent#Pass1"} {
+		history.entries = append(history.entries, &entities.PasswordHistory{ID: uuid.New(), UserID: userID, PasswordHash: hash(password)})
+	}
+
+	service := NewPasswordPolicyService(newFakeUserRepository(user), history, &config.Config{Password: config.PasswordPolicyConfig{
+		MinLength:     10,
+		MaxLength:     72,
+		RequireUpper:  true,
+		RequireLower:  true,
+		RequireDigit:  true,
+		RequireSymbol: true,
+		HistorySize:   2,
+		BreachedDir:   breachedDir,
+	}})
+
+	tests := []struct {
+		name     string
+		password string
+		userID   uuid.UUID
+		want     []string
+	}{
+		{name: "strong password", password: "Fresh#Pass12"},
+		{name: "too short", password: "Ab#1", want: []string{errors.PasswordTooShort}},
+		{name: "too long", password: "Aa#1" + strings.Repeat("x", 69), want: []string{errors.PasswordTooLong}},
+		{
+			name:     "every class missing",
+			password: "          ",
+			want:     []string{errors.PasswordMissingUpper, errors.PasswordMissingLower, errors.PasswordMissingDigit},
+		},
+		{name: "no symbol", password: "FreshPass12", want: []string{errors.PasswordMissingSymbol}},
+		{name: "contains phone", password: "Ab#996555123456", want: []string{errors.PasswordContainsPhone}},
+		{name: "contains phone with separators", password: "Ab#555-123-456", want: []string{errors.PasswordContainsPhone}},
+		{name: "current password", password: "Current#Pass1", userID: userID, want: []string{errors.PasswordReused}},
+		{name: "recent password", password: "Recent#Pass1", userID: userID, want: []string{errors.PasswordReused}},
+		{name: "password beyond history size", password: "Ancient#Pass1", userID: userID},
+		{name: "new user skips history", password: "Current#Pass1"},
+		{name: "breached password", password: "Breached#Pass1", want: []string{errors.PasswordBreached}},
+		{name: "padding entry is not a breach", password: "Padding#Pass1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := service.Validate(ctx, tt.password, testPhone, tt.userID)
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("Validate: %v", err)
+				}
+				return
+			}
+
+			var policyErr *errors.PasswordPolicyError
+			if !stdErrors.As(err, &policyErr) {
+				t.Fatalf("got %v, want PasswordPolicyError", err)
+			}
+			var codes []string
+			for _, violation := range policyErr.Violations {
+				codes = append(codes, violation.Code)
+			}
+			if !reflect.DeepEqual(codes, tt.want) {
+				t.Fatalf("violations %v, want %v", codes, tt.want)
+			}
+		})
+	}
+}
+
+func TestPasswordPolicyRemember(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		historySize int
+		want        []string
+	}{
+		{name: "keeps last passwords", historySize: 2, want: []string{"second", "third"}},
+		{name: "disabled history stores nothing", historySize: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			history := &fakePasswordHistoryRepository{}
+			service := NewPasswordPolicyService(newFakeUserRepository(), history,
+				&config.Config{Password: config.PasswordPolicyConfig{HistorySize: tt.historySize}})
+
+			userID := uuid.New()
+			for _, hash := range []string{"first", "second", "third"} {
+				if err := service.Remember(ctx, userID, hash); err != nil {
+					t.Fatalf("Remember: %v", err)
+				}
+			}
+			var got []string
+			for _, entry := range history.entries {
+				got = append(got, entry.PasswordHash)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("history %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"gold_portal/internal/domain/dto"
+	"gold_portal/internal/domain/entities"
+	"gold_portal/internal/domain/repositories"
+	"gold_portal/internal/errors"
+	"gold_portal/internal/pkg/crypto"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+const AuditActionPasswordChange = "PASSWORD_CHANGE"
+
+type PasswordService interface {
+	// Меняет пароль пользователя после проверки текущего и завершает все его сессии,
+	// кроме текущей sessionID: на этом устройстве пользователь остаётся в системе
+	ChangePassword(ctx context.Context, userID, sessionID uuid.UUID, request dto.ChangePasswordDTO) error
+	// Устанавливает пароль участнику организации от имени администратора и выводит его со всех устройств
+	SetPassword(ctx context.Context, actorID, organizationID, userID uuid.UUID, request dto.ChangePasswordDashboardDTO) error
+}
+
+type passwordService struct {
+	userRepository repositories.UserRepository
+	sessionService SessionService
+	auditService   AuditService
+	passwordPolicy PasswordPolicyService
+	usersService   UsersService
+}
+
+func NewPasswordService(userRepository repositories.UserRepository, sessionService SessionService, auditService AuditService, passwordPolicy PasswordPolicyService, usersService UsersService) PasswordService {
+	return &passwordService{
+		userRepository: userRepository,
+		sessionService: sessionService,
+		auditService:   auditService,
+		passwordPolicy: passwordPolicy,
+		usersService:   usersService,
+	}
+}
+
+func (s *passwordService) ChangePassword(ctx context.Context, userID, sessionID uuid.UUID, request dto.ChangePasswordDTO) error {
+	user, err := s.userRepository.GetID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := user.CheckPassword(request.OldPassword); err != nil {
+		return errors.ErrInvalidPassword
+	}
+
+	if err := s.updatePassword(ctx, user, request.NewPassword); err != nil {
+		return err
+	}
+	// Пароль мог утечь: остальные устройства входят заново, текущее сохраняет свои токены
+	if err := s.sessionService.RevokeAll(ctx, userID, sessionID); err != nil {
+		return err
+	}
+
+	return s.auditService.Log(userID, userID, AuditActionPasswordChange, "User", http.StatusOK,
+		request.ClientIP, request.UserAgent, "Пользователь сменил пароль")
+}
+
+func (s *passwordService) SetPassword(ctx context.Context, actorID, organizationID, userID uuid.UUID, request dto.ChangePasswordDashboardDTO) error {
+	user, err := s.userRepository.GetInOrganization(ctx, organizationID, userID)
+	if err != nil {
+		return err
+	}
+	// Администратор не может сменить пароль пользователю, у которого больше прав в организации
+	if err := s.usersService.CheckTarget(ctx, actorID, organizationID, userID); err != nil {
+		return err
+	}
+
+	if err := s.updatePassword(ctx, user, request.NewPassword); err != nil {
+		return err
+	}
+	if err := s.sessionService.LogoutEverywhere(ctx, userID); err != nil {
+		return err
+	}
+
+	return s.auditService.ForOrganization(organizationID).Log(actorID, userID, AuditActionPasswordChange, "User", http.StatusOK,
+		request.ClientIP, request.UserAgent, fmt.Sprintf("Администратор сменил пароль пользователя %s", maskPhone(user.Phone)))
+}
+
+func (s *passwordService) updatePassword(ctx context.Context, user *entities.User, password string) error {
+	if err := s.passwordPolicy.Validate(ctx, password, user.Phone, user.ID); err != nil {
+		return err
+	}
+
+	hashedPassword, err := crypto.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	if err := s.userRepository.UpdatePassword(ctx, user.ID, hashedPassword); err != nil {
+		return fmt.Errorf("ошибка при смене пароля: %w", err)
+	}
+	return s.passwordPolicy.Remember(ctx, user.ID, hashedPassword)
+}
@@ -0,0 +1,184 @@
+package services
+
+import (
+	"context"
+	stdErrors "errors"
+	"gold_portal/config"
+	"gold_portal/internal/domain/dto"
+	"gold_portal/internal/domain/entities"
+	"gold_portal/internal/errors"
+	"gold_portal/internal/pkg/crypto"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// fakeAuditService запоминает записи журнала вместо записи в базу
+type fakeAuditService struct {
+	AuditService
+	entries []string
+	// Организация из последнего вызова ForOrganization
+	organizationID uuid.UUID
+}
+
+func (s *fakeAuditService) Log(_, _ uuid.UUID, action, _ string, _ int, _, _, data string) error {
+	s.entries = append(s.entries, action+": "+data)
+	return nil
+}
+
+func (s *fakeAuditService) ForOrganization(organizationID uuid.UUID) AuditService {
+	s.organizationID = organizationID
+	return s
+}
+
+// newTestPasswordService возвращает сервис поверх пользователей и сессий в памяти
+func newTestPasswordService(t *testing.T, users ...*entities.User) (*passwordService, *fakeSessionRepository, *fakeAuditService) {
+	t.Helper()
+	sessions, repository, cache := newTestSessionService(t)
+	userRepository := newFakeUserRepository(users...)
+	sessions.userRepository = userRepository
+	audit := &fakeAuditService{}
+	service := &passwordService{
+		userRepository: userRepository,
+		sessionService: sessions,
+		auditService:   audit,
+		passwordPolicy: NewPasswordPolicyService(userRepository, &fakePasswordHistoryRepository{}, &config.Config{}),
+		usersService: &userService{
+			usersRepository:   userRepository,
+			organizations:     newTestOrganizationService(userRepository, sessions),
+			permissionService: NewPermissionService(&fakePermissionRepository{roles: entities.DefaultRolePermissions}, newFakeGroupRepository(), cache),
+		},
+	}
+	return service, repository, audit
+}
+
+func newTestUserWithPassword(t *testing.T, role entities.Role, password string) *entities.User {
+	t.Helper()
+	hashedPassword, err := crypto.HashPassword(password)
+	if err != nil {
+		t.Fatalf("HashPassword: %v", err)
+	}
+	return &entities.User{ID: uuid.New(), Phone: testPhone, Role: role, Password: hashedPassword, IsActive: true}
+}
+
+func TestChangePassword(t *testing.T) {
+	ctx := context.Background()
+	const oldPassword, newPassword = "Password123", "NewPassword123"
+
+	tests := []struct {
+		name        string
+		oldPassword string
+		wantErr     error
+	}{
+		{name: "correct current password", oldPassword: oldPassword},
+		{name: "wrong current password", oldPassword: "wrong", wantErr: errors.ErrInvalidPassword},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := newTestUserWithPassword(t, entities.RoleUser, oldPassword)
+			service, repository, audit := newTestPasswordService(t, user)
+			current, err := service.sessionService.Create(ctx, user.ID, testOrganizationID, "phone", "10.0.0.1", "", "", nil)
+			if err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			other, err := service.sessionService.Create(ctx, user.ID, testOrganizationID, "laptop", "10.0.0.2", "", "", nil)
+			if err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+
+			err = service.ChangePassword(ctx, user.ID, current.ID, dto.ChangePasswordDTO{
+				OldPassword:     tt.oldPassword,
+				NewPassword:     newPassword,
+				ConfirmPassword: newPassword,
+			})
+			if !stdErrors.Is(err, tt.wantErr) {
+				t.Fatalf("ChangePassword error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if user.CheckPassword(oldPassword) != nil || repository.sessions[other.ID].RevokedAt != nil || len(audit.entries) != 0 {
+					t.Error("failed change has side effects")
+				}
+				return
+			}
+
+			if err := user.CheckPassword(newPassword); err != nil {
+				t.Errorf("new password does not match the stored hash: %v", err)
+			}
+			// Остальные устройства входят заново, текущее остаётся в системе
+			if repository.sessions[other.ID].RevokedAt == nil {
+				t.Error("other session is not revoked")
+			}
+			if repository.sessions[current.ID].RevokedAt != nil {
+				t.Error("current session is revoked")
+			}
+			if revoked, err := service.sessionService.IsRevoked(ctx, current.ID.String()); err != nil || revoked {
+				t.Errorf("current session tokens revoked = %v (%v), want kept", revoked, err)
+			}
+			if user.TokenVersion != 0 {
+				t.Errorf("token version = %d, want 0: current tokens must stay valid", user.TokenVersion)
+			}
+			if len(audit.entries) != 1 {
+				t.Errorf("audit entries = %v, want one", audit.entries)
+			}
+		})
+	}
+}
+
+func TestSetPassword(t *testing.T) {
+	ctx := context.Background()
+	const newPassword = "NewPassword123"
+
+	tests := []struct {
+		name       string
+		actorRole  entities.Role
+		targetRole entities.Role
+		wantErr    error
+	}{
+		{name: "admin sets user password", actorRole: entities.RoleAdmin, targetRole: entities.RoleUser},
+		{name: "admin sets admin password", actorRole: entities.RoleAdmin, targetRole: entities.RoleAdmin},
+		{name: "manager cannot set admin password", actorRole: entities.RoleManager, targetRole: entities.RoleAdmin, wantErr: errors.ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actor := &entities.User{ID: uuid.New(), Role: tt.actorRole, IsActive: true}
+			target := newTestUserWithPassword(t, tt.targetRole, "Password123")
+			service, repository, audit := newTestPasswordService(t, actor, target)
+			session, err := service.sessionService.Create(ctx, target.ID, testOrganizationID, "phone", "10.0.0.1", "", "", nil)
+			if err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+
+			err = service.SetPassword(ctx, actor.ID, testOrganizationID, target.ID, dto.ChangePasswordDashboardDTO{
+				NewPassword:     newPassword,
+				ConfirmPassword: newPassword,
+			})
+			if !stdErrors.Is(err, tt.wantErr) {
+				t.Fatalf("SetPassword error = %v, want %v", err, tt.wantErr)
+			}
+
+			changed := target.CheckPassword(newPassword) == nil
+			revoked := repository.sessions[session.ID].RevokedAt != nil
+			if tt.wantErr != nil {
+				if changed || revoked || len(audit.entries) != 0 {
+					t.Error("forbidden change has side effects")
+				}
+				return
+			}
+			if !changed {
+				t.Error("password is not changed")
+			}
+			// Пользователь должен войти заново на всех устройствах
+			if !revoked {
+				t.Error("session is not revoked")
+			}
+			if len(audit.entries) != 1 {
+				t.Errorf("audit entries = %v, want one", audit.entries)
+			} else if strings.Contains(audit.entries[0], testPhone) {
+				t.Errorf("audit entry %q exposes the full phone number", audit.entries[0])
+			}
+		})
+	}
+}
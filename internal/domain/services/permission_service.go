@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"gold_portal/internal/domain/entities"
+	"gold_portal/internal/domain/repositories"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Права роли читаются на каждом запросе, поэтому кешируются. После изменения прав
+// кеш сбрасывается через Invalidate; срок жизни — страховка от рассинхронизации
+const rolePermissionsCacheTTL = 5 * time.Minute
+
+type PermissionService interface {
+	// Возвращает права роли
+	GetRolePermissions(ctx context.Context, role entities.Role) ([]string, error)
+	HasPermission(ctx context.Context, role entities.Role, permission string) (bool, error)
+	// Роль пользователя в организации и роли, выданные ему через группы этой организации
+	GetUserRoles(ctx context.Context, role entities.Role, organizationID, userID uuid.UUID) ([]entities.Role, error)
+	// Действующие права пользователя: права его роли в организации, ролей его групп
+	// и права, выданные группам напрямую. Групповые выдачи не кешируются и действуют сразу
+	GetUserPermissions(ctx context.Context, role entities.Role, organizationID, userID uuid.UUID) ([]string, error)
+	// Проверяет, что у actorID есть все действующие права targetID в организации (как в
+	// GetUserPermissions): действовать над пользователем можно, только если его права не шире собственных
+	Covers(ctx context.Context, organizationID, actorID uuid.UUID, actor entities.Role, targetID uuid.UUID, target entities.Role) (bool, error)
+	// Сбрасывает кеш прав роли после их изменения
+	Invalidate(ctx context.Context, role entities.Role) error
+}
+
+type permissionService struct {
+	permissionRepository repositories.PermissionRepository
+	groupRepository      repositories.GroupRepository
+	cache                Cache
+}
+
+func NewPermissionService(permissionRepository repositories.PermissionRepository, groupRepository repositories.GroupRepository, cache Cache) PermissionService {
+	return &permissionService{
+		permissionRepository: permissionRepository,
+		groupRepository:      groupRepository,
+		cache:                cache,
+	}
+}
+
+func (s *permissionService) GetRolePermissions(ctx context.Context, role entities.Role) ([]string, error) {
+	key := rolePermissionsKey(role)
+	if value, err := s.cache.Get(ctx, key); err == nil {
+		var permissions []string
+		if err := json.Unmarshal([]byte(value), &permissions); err == nil {
+			return permissions, nil
+		}
+	}
+
+	permissions, err := s.permissionRepository.GetByRole(ctx, role)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения прав роли: %w", err)
+	}
+	if permissions == nil {
+		permissions = []string{}
+	}
+
+	// Недоступный кеш не мешает проверке прав: в следующий раз прочитаем из базы
+	if record, err := json.Marshal(permissions); err == nil {
+		_ = s.cache.Set(ctx, key, string(record), rolePermissionsCacheTTL)
+	}
+	return permissions, nil
+}
+
+func (s *permissionService) HasPermission(ctx context.Context, role entities.Role, permission string) (bool, error) {
+	permissions, err := s.GetRolePermissions(ctx, role)
+	if err != nil {
+		return false, err
+	}
+	for _, granted := range permissions {
+		if granted == permission {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *permissionService) GetUserRoles(ctx context.Context, role entities.Role, organizationID, userID uuid.UUID) ([]entities.Role, error) {
+	groupRoles, _, err := s.groupRepository.GetUserGrants(ctx, organizationID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения ролей групп: %w", err)
+	}
+
+	roles := []entities.Role{role}
+	for _, groupRole := range groupRoles {
+		if groupRole != role {
+			roles = append(roles, groupRole)
+		}
+	}
+	return roles, nil
+}
+
+func (s *permissionService) GetUserPermissions(ctx context.Context, role entities.Role, organizationID, userID uuid.UUID) ([]string, error) {
+	groupRoles, groupPermissions, err := s.groupRepository.GetUserGrants(ctx, organizationID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения прав групп: %w", err)
+	}
+
+	granted := make(map[string]bool)
+	for _, grantedRole := range append([]entities.Role{role}, groupRoles...) {
+		permissions, err := s.GetRolePermissions(ctx, grantedRole)
+		if err != nil {
+			return nil, err
+		}
+		for _, permission := range permissions {
+			granted[permission] = true
+		}
+	}
+	for _, permission := range groupPermissions {
+		granted[permission] = true
+	}
+
+	permissions := make([]string, 0, len(granted))
+	for permission := range granted {
+		permissions = append(permissions, permission)
+	}
+	sort.Strings(permissions)
+	return permissions, nil
+}
+
+func (s *permissionService) Covers(ctx context.Context, organizationID, actorID uuid.UUID, actor entities.Role, targetID uuid.UUID, target entities.Role) (bool, error) {
+	// Одинаковых ролей недостаточно: группы могут дать target права, которых нет у actor
+	actorPermissions, err := s.GetUserPermissions(ctx, actor, organizationID, actorID)
+	if err != nil {
+		return false, err
+	}
+	targetPermissions, err := s.GetUserPermissions(ctx, target, organizationID, targetID)
+	if err != nil {
+		return false, err
+	}
+
+	granted := make(map[string]bool, len(actorPermissions))
+	for _, permission := range actorPermissions {
+		granted[permission] = true
+	}
+	for _, permission := range targetPermissions {
+		if !granted[permission] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (s *permissionService) Invalidate(ctx context.Context, role entities.Role) error {
+	return s.cache.Delete(ctx, rolePermissionsKey(role))
+}
+
+func rolePermissionsKey(role entities.Role) string {
+	return fmt.Sprintf("role_permissions:%s", role)
+}
@@ -0,0 +1,244 @@
+package services
+
+import (
+	"context"
+	"gold_portal/internal/domain/entities"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func newTestPermissionRepository() *fakePermissionRepository {
+	return &fakePermissionRepository{roles: map[entities.Role][]string{
+		entities.RoleAdmin: {entities.PermissionUsersRead, entities.PermissionUsersUpdate, entities.PermissionUsersDelete, entities.PermissionAuditRead},
+		"auditor":          {entities.PermissionAuditRead},
+		"editor":           {entities.PermissionUsersRead, entities.PermissionUsersUpdate},
+		entities.RoleUser:  {},
+	}}
+}
+
+func TestPermissionServiceHasPermission(t *testing.T) {
+	ctx := context.Background()
+	cache, _ := newTestCache(t)
+	service := NewPermissionService(newTestPermissionRepository(), nil, cache)
+
+	tests := []struct {
+		role       entities.Role
+		permission string
+		want       bool
+	}{
+		{role: entities.RoleAdmin, permission: entities.PermissionAuditRead, want: true},
+		{role: "auditor", permission: entities.PermissionAuditRead, want: true},
+		{role: "auditor", permission: entities.PermissionUsersUpdate},
+		{role: entities.RoleUser, permission: entities.PermissionUsersRead},
+		{role: "unknown", permission: entities.PermissionUsersRead},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.role)+"/"+tt.permission, func(t *testing.T) {
+			got, err := service.HasPermission(ctx, tt.role, tt.permission)
+			if err != nil {
+				t.Fatalf("HasPermission: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("HasPermission = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPermissionServiceCovers(t *testing.T) {
+	ctx := context.Background()
+	organizationID := uuid.New()
+	plainID, auditorGroupID, grantedID := uuid.New(), uuid.New(), uuid.New()
+
+	groups := newFakeGroupRepository()
+	groups.grant(organizationID, auditorGroupID, "auditors", []entities.Role{"auditor"}, nil)
+	groups.grant(organizationID, grantedID, "moderators", nil, []string{entities.PermissionUsersDelete})
+
+	cache, _ := newTestCache(t)
+	service := NewPermissionService(newTestPermissionRepository(), groups, cache)
+
+	tests := []struct {
+		name     string
+		actorID  uuid.UUID
+		actor    entities.Role
+		targetID uuid.UUID
+		target   entities.Role
+		want     bool
+	}{
+		{name: "same role", actorID: plainID, actor: "editor", targetID: uuid.New(), target: "editor", want: true},
+		{name: "superset", actorID: plainID, actor: entities.RoleAdmin, targetID: uuid.New(), target: "editor", want: true},
+		{name: "role without permissions", actorID: plainID, actor: "auditor", targetID: uuid.New(), target: entities.RoleUser, want: true},
+		{name: "subset", actorID: plainID, actor: "editor", targetID: uuid.New(), target: entities.RoleAdmin},
+		// Права не сравниваются по количеству: у ролей разные наборы
+		{name: "disjoint roles", actorID: plainID, actor: "auditor", targetID: uuid.New(), target: "editor"},
+		{name: "disjoint roles reversed", actorID: plainID, actor: "editor", targetID: uuid.New(), target: "auditor"},
+		// Сравниваются действующие права: роль в организации вместе с выдачами групп
+		{name: "same role with broader group role", actorID: plainID, actor: "editor", targetID: auditorGroupID, target: "editor"},
+		{name: "same role with broader group permission", actorID: plainID, actor: "editor", targetID: grantedID, target: "editor"},
+		{name: "actor group role covers target", actorID: auditorGroupID, actor: "editor", targetID: uuid.New(), target: "auditor", want: true},
+		{name: "admin covers group permission", actorID: plainID, actor: entities.RoleAdmin, targetID: grantedID, target: "editor", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := service.Covers(ctx, organizationID, tt.actorID, tt.actor, tt.targetID, tt.target)
+			if err != nil {
+				t.Fatalf("Covers: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("Covers(%s, %s) = %v, want %v", tt.actor, tt.target, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPermissionServiceCachesRolePermissions(t *testing.T) {
+	ctx := context.Background()
+	cache, _ := newTestCache(t)
+	repository := newTestPermissionRepository()
+	service := NewPermissionService(repository, nil, cache)
+
+	for i := 0; i < 3; i++ {
+		if _, err := service.HasPermission(ctx, "auditor", entities.PermissionAuditRead); err != nil {
+			t.Fatalf("HasPermission: %v", err)
+		}
+	}
+	if repository.reads != 1 {
+		t.Fatalf("repository reads %d, want 1 with cache", repository.reads)
+	}
+}
+
+func TestPermissionServiceCacheInvalidate(t *testing.T) {
+	ctx := context.Background()
+	cache, _ := newTestCache(t)
+	repository := newTestPermissionRepository()
+	service := NewPermissionService(repository, nil, cache)
+
+	for i := 0; i < 3; i++ {
+		if _, err := service.HasPermission(ctx, "auditor", entities.PermissionAuditRead); err != nil {
+			t.Fatalf("HasPermission: %v", err)
+		}
+	}
+	if repository.reads != 1 {
+		t.Fatalf("repository reads %d, want 1 with cache", repository.reads)
+	}
+
+	// Изменённые права видны сразу после Invalidate
+	repository.roles["auditor"] = []string{entities.PermissionUsersRead}
+	if err := service.Invalidate(ctx, "auditor"); err != nil {
+		t.Fatalf("Invalidate: %v", err)
+	}
+	got, err := service.HasPermission(ctx, "auditor", entities.PermissionAuditRead)
+	if err != nil {
+		t.Fatalf("HasPermission: %v", err)
+	}
+	if got {
+		t.Fatal("revoked permission still granted after Invalidate")
+	}
+}
+
+func TestPermissionServiceGetUserPermissions(t *testing.T) {
+	ctx := context.Background()
+	organizationID, otherOrganizationID := uuid.New(), uuid.New()
+	userID := uuid.New()
+
+	groups := newFakeGroupRepository()
+	groups.grant(organizationID, userID, "reviewers", []entities.Role{"auditor", "editor"}, []string{entities.PermissionGroupsManage})
+	// Выдачи другой организации в этой не действуют
+	groups.grant(otherOrganizationID, userID, "reviewers", []entities.Role{entities.RoleAdmin}, []string{entities.PermissionRolesManage})
+
+	cache, _ := newTestCache(t)
+	service := NewPermissionService(newTestPermissionRepository(), groups, cache)
+
+	tests := []struct {
+		name           string
+		role           entities.Role
+		organizationID uuid.UUID
+		userID         uuid.UUID
+		wantRoles      []entities.Role
+		want           []string
+	}{
+		{
+			name:           "role, group roles and direct group permissions",
+			role:           entities.RoleUser,
+			organizationID: organizationID,
+			userID:         userID,
+			wantRoles:      []entities.Role{entities.RoleUser, "auditor", "editor"},
+			want:           []string{entities.PermissionAuditRead, entities.PermissionGroupsManage, entities.PermissionUsersRead, entities.PermissionUsersUpdate},
+		},
+		{
+			name:           "group role equal to own role is listed once",
+			role:           "editor",
+			organizationID: organizationID,
+			userID:         userID,
+			wantRoles:      []entities.Role{"editor", "auditor"},
+			want:           []string{entities.PermissionAuditRead, entities.PermissionGroupsManage, entities.PermissionUsersRead, entities.PermissionUsersUpdate},
+		},
+		{
+			name:           "grants of another organization",
+			role:           entities.RoleUser,
+			organizationID: otherOrganizationID,
+			userID:         userID,
+			wantRoles:      []entities.Role{entities.RoleUser, entities.RoleAdmin},
+			want: []string{entities.PermissionAuditRead, entities.PermissionRolesManage, entities.PermissionUsersDelete,
+				entities.PermissionUsersRead, entities.PermissionUsersUpdate},
+		},
+		{
+			name:           "user without groups",
+			role:           "auditor",
+			organizationID: organizationID,
+			userID:         uuid.New(),
+			wantRoles:      []entities.Role{"auditor"},
+			want:           []string{entities.PermissionAuditRead},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			roles, err := service.GetUserRoles(ctx, tt.role, tt.organizationID, tt.userID)
+			if err != nil {
+				t.Fatalf("GetUserRoles: %v", err)
+			}
+			if !reflect.DeepEqual(roles, tt.wantRoles) {
+				t.Fatalf("roles %v, want %v", roles, tt.wantRoles)
+			}
+
+			permissions, err := service.GetUserPermissions(ctx, tt.role, tt.organizationID, tt.userID)
+			if err != nil {
+				t.Fatalf("GetUserPermissions: %v", err)
+			}
+			if !reflect.DeepEqual(permissions, tt.want) {
+				t.Fatalf("permissions %v, want %v", permissions, tt.want)
+			}
+		})
+	}
+}
+
+func TestPermissionServiceGroupGrantsAreNotCached(t *testing.T) {
+	ctx := context.Background()
+	organizationID, userID := uuid.New(), uuid.New()
+	groups := newFakeGroupRepository()
+	cache, _ := newTestCache(t)
+	service := NewPermissionService(newTestPermissionRepository(), groups, cache)
+
+	before, err := service.GetUserPermissions(ctx, entities.RoleUser, organizationID, userID)
+	if err != nil {
+		t.Fatalf("GetUserPermissions: %v", err)
+	}
+	if len(before) != 0 {
+		t.Fatalf("permissions %v, want none", before)
+	}
+
+	// Добавление в группу действует сразу, без сброса кеша
+	groups.grant(organizationID, userID, "reviewers", nil, []string{entities.PermissionAuditRead})
+	after, err := service.GetUserPermissions(ctx, entities.RoleUser, organizationID, userID)
+	if err != nil {
+		t.Fatalf("GetUserPermissions: %v", err)
+	}
+	if !reflect.DeepEqual(after, []string{entities.PermissionAuditRead}) {
+		t.Fatalf("permissions %v, want [%s]", after, entities.PermissionAuditRead)
+	}
+}
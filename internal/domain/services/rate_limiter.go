@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+type RateLimitResult struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Когда окно сменится и счётчик начнёт убывать
+	ResetAfter time.Duration
+	// Сколько ждать до следующего разрешённого запроса; 0, если запрос разрешён
+	RetryAfter time.Duration
+}
+
+// RateLimiter ограничивает частоту запросов скользящим окном. Счётчики хранятся в кеше,
+// поэтому лимит общий для всех экземпляров сервиса
+type RateLimiter interface {
+	Allow(ctx context.Context, key string, limit int, window time.Duration) (*RateLimitResult, error)
+}
+
+type rateLimiter struct {
+	cache Cache
+	now   func() time.Time
+}
+
+func NewRateLimiter(cache Cache) RateLimiter {
+	return &rateLimiter{
+		cache: cache,
+		now:   time.Now,
+	}
+}
+
+// Allow считает запросы в текущем и предыдущем фиксированных окнах и оценивает число
+// запросов за последние window: предыдущее окно учитывается пропорционально тому,
+// какая его часть ещё попадает в скользящее окно. Счётчик увеличивается атомарно
+// через Incr, поэтому одновременные запросы не теряются
+func (l *rateLimiter) Allow(ctx context.Context, key string, limit int, window time.Duration) (*RateLimitResult, error) {
+	now := l.now()
+	windowStart := now.Truncate(window)
+	elapsed := now.Sub(windowStart)
+
+	currentKey := rateLimitKey(key, windowStart)
+	current, err := l.cache.Incr(ctx, currentKey)
+	if err != nil {
+		return nil, err
+	}
+	if current == 1 {
+		// Окно нужно и следующему окну как предыдущее
+		if err := l.cache.Expire(ctx, currentKey, 2*window); err != nil {
+			return nil, err
+		}
+	}
+
+	var previous int64
+	if value, err := l.cache.Get(ctx, rateLimitKey(key, windowStart.Add(-window))); err == nil {
+		previous, _ = strconv.ParseInt(value, 10, 64)
+	}
+
+	weight := 1 - float64(elapsed)/float64(window)
+	estimated := float64(previous)*weight + float64(current)
+
+	result := &RateLimitResult{
+		Allowed:    estimated <= float64(limit),
+		Limit:      limit,
+		Remaining:  int(math.Max(0, float64(limit)-math.Ceil(estimated))),
+		ResetAfter: window - elapsed,
+	}
+	if !result.Allowed {
+		result.RetryAfter = retryAfter(previous, current, limit, window, elapsed)
+	}
+	return result, nil
+}
+
+// retryAfter оценивает, через сколько доля предыдущего окна уменьшится настолько,
+// что следующий запрос уложится в лимит
+func retryAfter(previous, current int64, limit int, window, elapsed time.Duration) time.Duration {
+	// Следующий запрос тоже попадёт в текущее окно
+	free := float64(int64(limit) - current - 1)
+	if free < 0 || previous == 0 {
+		return window - elapsed
+	}
+	// previous * (1 - t/window) <= free
+	wait := time.Duration(float64(window)*(1-free/float64(previous))) - elapsed
+	if wait <= 0 {
+		return time.Second
+	}
+	return wait
+}
+
+func rateLimitKey(key string, windowStart time.Time) string {
+	return fmt.Sprintf("rate_limit:%s:%d", key, windowStart.Unix())
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterAllow(t *testing.T) {
+	ctx := context.Background()
+	const (
+		limit  = 4
+		window = time.Minute
+	)
+
+	// step сдвигает часы на advance и отправляет requests запросов
+	type step struct {
+		advance  time.Duration
+		requests int
+		// Сколько из них должно пройти
+		wantAllowed int
+	}
+
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name:  "limit within one window",
+			steps: []step{{requests: 6, wantAllowed: limit}},
+		},
+		{
+			name: "previous window counts in proportion to its overlap",
+			steps: []step{
+				{requests: limit, wantAllowed: limit},
+				// Через 45 секунд нового окна в скользящее попадает четверть прошлого: 1 запрос
+				{advance: window + 45*time.Second, requests: limit, wantAllowed: limit - 1},
+			},
+		},
+		{
+			name: "rejected requests also count",
+			steps: []step{
+				{requests: 2 * limit, wantAllowed: limit},
+				// Четверть от 8 запросов прошлого окна — 2
+				{advance: window + 45*time.Second, requests: limit, wantAllowed: limit - 2},
+			},
+		},
+		{
+			name: "window older than one window is forgotten",
+			steps: []step{
+				{requests: limit, wantAllowed: limit},
+				{advance: 2 * window, requests: limit + 1, wantAllowed: limit},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache, server := newTestCache(t)
+			now := time.Unix(1_700_000_000, 0).Truncate(window)
+			limiter := &rateLimiter{cache: cache, now: func() time.Time { return now }}
+
+			for i, step := range tt.steps {
+				now = now.Add(step.advance)
+				server.FastForward(step.advance)
+
+				allowed := 0
+				for j := 0; j < step.requests; j++ {
+					result, err := limiter.Allow(ctx, "client", limit, window)
+					if err != nil {
+						t.Fatalf("step %d: Allow: %v", i+1, err)
+					}
+					if result.Allowed {
+						allowed++
+						continue
+					}
+					if result.Remaining != 0 || result.RetryAfter <= 0 {
+						t.Fatalf("step %d: rejected with remaining %d, retry after %s", i+1, result.Remaining, result.RetryAfter)
+					}
+				}
+				if allowed != step.wantAllowed {
+					t.Fatalf("step %d: allowed %d, want %d", i+1, allowed, step.wantAllowed)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimiterKeysAreIndependent(t *testing.T) {
+	ctx := context.Background()
+	cache, _ := newTestCache(t)
+	now := time.Unix(1_700_000_000, 0).Truncate(time.Minute)
+	limiter := &rateLimiter{cache: cache, now: func() time.Time { return now }}
+
+	for _, key := range []string{"ip:10.0.0.1", "ip:10.0.0.2"} {
+		result, err := limiter.Allow(ctx, key, 1, time.Minute)
+		if err != nil {
+			t.Fatalf("Allow %s: %v", key, err)
+		}
+		if !result.Allowed {
+			t.Fatalf("first request for %s rejected", key)
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	const window = time.Minute
+
+	tests := []struct {
+		name     string
+		previous int64
+		current  int64
+		limit    int
+		elapsed  time.Duration
+		want     time.Duration
+	}{
+		{name: "no previous window waits for the next one", current: 5, limit: 4, elapsed: 20 * time.Second, want: 40 * time.Second},
+		{name: "current window alone is over the limit", previous: 10, current: 5, limit: 4, elapsed: 20 * time.Second, want: 40 * time.Second},
+		// 10 * (1 - t/60s) <= 2 при t = 48s
+		{name: "previous window share decays", previous: 10, current: 2, limit: 5, elapsed: 30 * time.Second, want: 18 * time.Second},
+		{name: "at least one second", previous: 10, current: 2, limit: 5, elapsed: 50 * time.Second, want: time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryAfter(tt.previous, tt.current, tt.limit, window, tt.elapsed); got != tt.want {
+				t.Fatalf("retryAfter = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
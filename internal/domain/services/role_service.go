@@ -0,0 +1,316 @@
+package services
+
+import (
+	"context"
+	stdErrors "errors"
+	"fmt"
+	"gold_portal/internal/domain/dto"
+	"gold_portal/internal/domain/entities"
+	"gold_portal/internal/domain/repositories"
+	"gold_portal/internal/errors"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	AuditActionRoleCreated = "ROLE_CREATED"
+	AuditActionRoleUpdated = "ROLE_UPDATED"
+	AuditActionRoleDeleted = "ROLE_DELETED"
+)
+
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{1,49}$`)
+
+type RoleService interface {
+	GetAll(ctx context.Context) ([]*dto.RoleResponseDTO, error)
+	GetPermissions(ctx context.Context) ([]*dto.PermissionResponseDTO, error)
+	Create(ctx context.Context, actorID uuid.UUID, request dto.RoleRequestDTO) (*dto.RoleResponseDTO, error)
+	Update(ctx context.Context, actorID uuid.UUID, name entities.Role, request dto.RoleUpdateDTO) (*dto.RoleResponseDTO, error)
+	Delete(ctx context.Context, actorID uuid.UUID, name entities.Role, clientIP, userAgent string) error
+	// Проверяет, что роль существует
+	Exists(ctx context.Context, role entities.Role) (bool, error)
+	// Проверяет, что роль actor может назначить роль role: роль существует и её ранг
+	// не выше ранга actor. Возвращает ErrInvalidUserRole или ErrRoleRankTooHigh
+	CheckAssignable(ctx context.Context, actor, role entities.Role) error
+}
+
+type roleService struct {
+	roleRepository       repositories.RoleRepository
+	permissionRepository repositories.PermissionRepository
+	userRepository       repositories.UserRepository
+	permissionService    PermissionService
+	auditService         AuditService
+}
+
+func NewRoleService(roleRepository repositories.RoleRepository, permissionRepository repositories.PermissionRepository, userRepository repositories.UserRepository, permissionService PermissionService, auditService AuditService) RoleService {
+	return &roleService{
+		roleRepository:       roleRepository,
+		permissionRepository: permissionRepository,
+		userRepository:       userRepository,
+		permissionService:    permissionService,
+		auditService:         auditService,
+	}
+}
+
+func (s *roleService) GetAll(ctx context.Context) ([]*dto.RoleResponseDTO, error) {
+	roles, err := s.roleRepository.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]*dto.RoleResponseDTO, 0, len(roles))
+	for _, role := range roles {
+		permissions, err := s.permissionRepository.GetByRole(ctx, role.Name)
+		if err != nil {
+			return nil, err
+		}
+		var roleResponse dto.RoleResponseDTO
+		roleResponse.FromModel(role, permissions)
+		response = append(response, &roleResponse)
+	}
+	return response, nil
+}
+
+func (s *roleService) GetPermissions(ctx context.Context) ([]*dto.PermissionResponseDTO, error) {
+	permissions, err := s.permissionRepository.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]*dto.PermissionResponseDTO, 0, len(permissions))
+	for _, permission := range permissions {
+		var permissionResponse dto.PermissionResponseDTO
+		permissionResponse.FromModel(permission)
+		response = append(response, &permissionResponse)
+	}
+	return response, nil
+}
+
+func (s *roleService) Create(ctx context.Context, actorID uuid.UUID, request dto.RoleRequestDTO) (*dto.RoleResponseDTO, error) {
+	if !roleNamePattern.MatchString(string(request.Name)) {
+		return nil, errors.ErrInvalidRoleName
+	}
+	if _, err := s.roleRepository.GetByName(ctx, request.Name); err == nil {
+		return nil, errors.ErrRoleExists
+	}
+
+	actor, err := s.userRepository.GetID(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	permissions, err := s.checkGrant(ctx, actor.Role, request.Rank, request.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	role := &entities.RoleDefinition{
+		ID:          uuid.New(),
+		Name:        request.Name,
+		Description: request.Description,
+		Rank:        request.Rank,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.roleRepository.Create(ctx, role, permissions); err != nil {
+		return nil, fmt.Errorf("ошибка при создании роли: %w", err)
+	}
+	// В кеше могло остаться пустое множество прав от запросов с ещё не существовавшей ролью
+	_ = s.permissionService.Invalidate(ctx, role.Name)
+
+	if err := s.auditService.Log(actorID, role.ID, AuditActionRoleCreated, "Role", http.StatusCreated,
+		request.ClientIP, request.UserAgent,
+		fmt.Sprintf("Создана роль %s, ранг %d, права: %s", role.Name, role.Rank, strings.Join(permissions, ", "))); err != nil {
+		return nil, err
+	}
+
+	var response dto.RoleResponseDTO
+	response.FromModel(role, permissions)
+	return &response, nil
+}
+
+func (s *roleService) Update(ctx context.Context, actorID uuid.UUID, name entities.Role, request dto.RoleUpdateDTO) (*dto.RoleResponseDTO, error) {
+	role, err := s.roleRepository.GetByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	actor, err := s.userRepository.GetID(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []string
+	if request.Name != nil && *request.Name != role.Name {
+		if role.BuiltIn {
+			return nil, errors.ErrRoleBuiltIn
+		}
+		if !roleNamePattern.MatchString(string(*request.Name)) {
+			return nil, errors.ErrInvalidRoleName
+		}
+		if _, err := s.roleRepository.GetByName(ctx, *request.Name); err == nil {
+			return nil, errors.ErrRoleExists
+		}
+		changes = append(changes, fmt.Sprintf("переименована в %s", *request.Name))
+		role.Name = *request.Name
+	}
+	if request.Description != nil {
+		role.Description = *request.Description
+	}
+	if request.Rank != nil && *request.Rank != role.Rank {
+		changes = append(changes, fmt.Sprintf("ранг %d -> %d", role.Rank, *request.Rank))
+		role.Rank = *request.Rank
+	}
+
+	// Права и ранг можно выдать не шире собственных
+	current, err := s.permissionRepository.GetByRole(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	requested := current
+	if request.Permissions != nil {
+		requested = *request.Permissions
+	}
+	permissions, err := s.checkGrant(ctx, actor.Role, role.Rank, requested)
+	if err != nil {
+		return nil, err
+	}
+	// Иначе никто больше не сможет управлять ролями
+	if name == entities.RoleSuperUser && !containsString(permissions, entities.PermissionRolesManage) {
+		return nil, errors.ErrSuperUserLockedOut
+	}
+
+	var replace []string
+	if request.Permissions != nil {
+		replace = permissions
+		changes = append(changes, fmt.Sprintf("права: %s", strings.Join(permissions, ", ")))
+	}
+
+	role.UpdatedAt = time.Now()
+	if err := s.roleRepository.Update(ctx, name, role, replace); err != nil {
+		return nil, fmt.Errorf("ошибка при изменении роли: %w", err)
+	}
+	_ = s.permissionService.Invalidate(ctx, name)
+	_ = s.permissionService.Invalidate(ctx, role.Name)
+
+	if err := s.auditService.Log(actorID, role.ID, AuditActionRoleUpdated, "Role", http.StatusOK,
+		request.ClientIP, request.UserAgent,
+		fmt.Sprintf("Изменена роль %s: %s", name, strings.Join(changes, "; "))); err != nil {
+		return nil, err
+	}
+
+	var response dto.RoleResponseDTO
+	response.FromModel(role, permissions)
+	return &response, nil
+}
+
+func (s *roleService) Delete(ctx context.Context, actorID uuid.UUID, name entities.Role, clientIP, userAgent string) error {
+	role, err := s.roleRepository.GetByName(ctx, name)
+	if err != nil {
+		return err
+	}
+	if role.BuiltIn {
+		return errors.ErrRoleBuiltIn
+	}
+	assigned, err := s.roleRepository.CountAssignments(ctx, name)
+	if err != nil {
+		return err
+	}
+	if assigned > 0 {
+		return errors.ErrRoleInUse
+	}
+
+	if err := s.roleRepository.Delete(ctx, role); err != nil {
+		return fmt.Errorf("ошибка при удалении роли: %w", err)
+	}
+	_ = s.permissionService.Invalidate(ctx, name)
+
+	return s.auditService.Log(actorID, role.ID, AuditActionRoleDeleted, "Role", http.StatusOK,
+		clientIP, userAgent, fmt.Sprintf("Удалена роль %s", role.Name))
+}
+
+func (s *roleService) Exists(ctx context.Context, role entities.Role) (bool, error) {
+	if _, err := s.roleRepository.GetByName(ctx, role); err != nil {
+		if stdErrors.Is(err, errors.ErrRoleNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *roleService) CheckAssignable(ctx context.Context, actor, role entities.Role) error {
+	target, err := s.roleRepository.GetByName(ctx, role)
+	if err != nil {
+		if stdErrors.Is(err, errors.ErrRoleNotFound) {
+			return errors.ErrInvalidUserRole
+		}
+		return err
+	}
+	actorRole, err := s.roleRepository.GetByName(ctx, actor)
+	if err != nil {
+		if stdErrors.Is(err, errors.ErrRoleNotFound) {
+			return errors.ErrRoleRankTooHigh
+		}
+		return err
+	}
+	if target.Rank > actorRole.Rank {
+		return errors.ErrRoleRankTooHigh
+	}
+	return nil
+}
+
+// checkGrant проверяет, что все права известны и есть у самого actor, а ранг не выше
+// ранга actor. Роли общие для всех организаций, поэтому actor — роль на платформе.
+// Возвращает права без повторов
+func (s *roleService) checkGrant(ctx context.Context, actor entities.Role, rank int, permissions []string) ([]string, error) {
+	actorRole, err := s.roleRepository.GetByName(ctx, actor)
+	if err != nil {
+		return nil, err
+	}
+	if rank > actorRole.Rank {
+		return nil, errors.ErrRoleRankTooHigh
+	}
+
+	known, err := s.permissionRepository.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	actorPermissions, err := s.permissionService.GetRolePermissions(ctx, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		if containsString(result, permission) {
+			continue
+		}
+		isKnown := false
+		for _, candidate := range known {
+			if candidate.Name == permission {
+				isKnown = true
+				break
+			}
+		}
+		if !isKnown {
+			return nil, fmt.Errorf("%w: %s", errors.ErrUnknownPermission, permission)
+		}
+		if !containsString(actorPermissions, permission) {
+			return nil, errors.ErrForbidden
+		}
+		result = append(result, permission)
+	}
+	return result, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,298 @@
+package services
+
+import (
+	"context"
+	stdErrors "errors"
+	"gold_portal/internal/domain/dto"
+	"gold_portal/internal/domain/entities"
+	"gold_portal/internal/domain/repositories"
+	"gold_portal/internal/errors"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// fakeRoleRepository хранит роли в памяти, а их права — в fakePermissionRepository
+type fakeRoleRepository struct {
+	repositories.RoleRepository
+	roles       map[entities.Role]*entities.RoleDefinition
+	permissions *fakePermissionRepository
+	assigned    map[entities.Role]int64
+}
+
+func (r *fakeRoleRepository) GetByName(_ context.Context, name entities.Role) (*entities.RoleDefinition, error) {
+	role, ok := r.roles[name]
+	if !ok {
+		return nil, errors.ErrRoleNotFound
+	}
+	stored := *role
+	return &stored, nil
+}
+
+func (r *fakeRoleRepository) Create(_ context.Context, role *entities.RoleDefinition, permissions []string) error {
+	r.roles[role.Name] = role
+	r.permissions.roles[role.Name] = permissions
+	return nil
+}
+
+func (r *fakeRoleRepository) Update(_ context.Context, previousName entities.Role, role *entities.RoleDefinition, permissions []string) error {
+	if permissions == nil {
+		permissions = r.permissions.roles[previousName]
+	}
+	delete(r.roles, previousName)
+	delete(r.permissions.roles, previousName)
+	r.roles[role.Name] = role
+	r.permissions.roles[role.Name] = permissions
+	return nil
+}
+
+func (r *fakeRoleRepository) Delete(_ context.Context, role *entities.RoleDefinition) error {
+	delete(r.roles, role.Name)
+	delete(r.permissions.roles, role.Name)
+	return nil
+}
+
+func (r *fakeRoleRepository) CountAssignments(_ context.Context, name entities.Role) (int64, error) {
+	return r.assigned[name], nil
+}
+
+// newTestRoleServiceWithRoles возвращает roleService со встроенными ролями и ролью support ранга 150
+func newTestRoleServiceWithRoles(t *testing.T, actors ...*entities.User) (*roleService, *fakeRoleRepository, *fakeAuditService) {
+	t.Helper()
+	cache, _ := newTestCache(t)
+	permissions := &fakePermissionRepository{roles: make(map[entities.Role][]string)}
+	roles := &fakeRoleRepository{
+		roles:       make(map[entities.Role]*entities.RoleDefinition),
+		permissions: permissions,
+		assigned:    make(map[entities.Role]int64),
+	}
+	for _, role := range entities.BuiltInRoles {
+		role := role
+		roles.roles[role.Name] = &role
+	}
+	for role, granted := range entities.DefaultRolePermissions {
+		permissions.roles[role] = granted
+	}
+	roles.roles["support"] = &entities.RoleDefinition{ID: uuid.New(), Name: "support", Rank: 150}
+	permissions.roles["support"] = []string{entities.PermissionUsersRead}
+
+	audit := &fakeAuditService{}
+	service := &roleService{
+		roleRepository:       roles,
+		permissionRepository: permissions,
+		userRepository:       newFakeUserRepository(actors...),
+		permissionService:    NewPermissionService(permissions, nil, cache),
+		auditService:         audit,
+	}
+	return service, roles, audit
+}
+
+func TestRoleServiceCreate(t *testing.T) {
+	ctx := context.Background()
+	admin := &entities.User{ID: uuid.New(), Role: entities.RoleAdmin}
+
+	tests := []struct {
+		name    string
+		request dto.RoleRequestDTO
+		wantErr error
+	}{
+		{
+			name:    "role within actor rights",
+			request: dto.RoleRequestDTO{Name: "auditor", Rank: 150, Permissions: []string{entities.PermissionAuditRead, entities.PermissionAuditRead}},
+		},
+		{name: "invalid name", request: dto.RoleRequestDTO{Name: "Auditor!"}, wantErr: errors.ErrInvalidRoleName},
+		{name: "existing role", request: dto.RoleRequestDTO{Name: "support"}, wantErr: errors.ErrRoleExists},
+		{name: "rank above actor", request: dto.RoleRequestDTO{Name: "root", Rank: 350}, wantErr: errors.ErrRoleRankTooHigh},
+		{
+			name:    "permission the actor does not have",
+			request: dto.RoleRequestDTO{Name: "integrator", Rank: 150, Permissions: []string{entities.PermissionRolesManage}},
+			wantErr: errors.ErrForbidden,
+		},
+		{
+			name:    "unknown permission",
+			request: dto.RoleRequestDTO{Name: "integrator", Rank: 150, Permissions: []string{"users:fly"}},
+			wantErr: errors.ErrUnknownPermission,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, roles, audit := newTestRoleServiceWithRoles(t, admin)
+			// Запрос прав ещё не созданной роли кеширует пустое множество
+			if _, err := service.permissionService.GetRolePermissions(ctx, tt.request.Name); err != nil {
+				t.Fatalf("GetRolePermissions: %v", err)
+			}
+
+			response, err := service.Create(ctx, admin.ID, tt.request)
+			if !stdErrors.Is(err, tt.wantErr) {
+				t.Fatalf("Create error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if tt.wantErr != errors.ErrRoleExists && roles.roles[tt.request.Name] != nil {
+					t.Error("rejected role is stored")
+				}
+				return
+			}
+
+			if want := []string{entities.PermissionAuditRead}; !reflect.DeepEqual(response.Permissions, want) {
+				t.Errorf("permissions = %v, want %v", response.Permissions, want)
+			}
+			// Кеш прав сброшен, новая роль сразу получает свои права
+			granted, err := service.permissionService.HasPermission(ctx, tt.request.Name, entities.PermissionAuditRead)
+			if err != nil || !granted {
+				t.Errorf("HasPermission after create = %v, %v, want true", granted, err)
+			}
+			if len(audit.entries) != 1 {
+				t.Errorf("audit entries = %v, want one", audit.entries)
+			}
+		})
+	}
+}
+
+func TestRoleServiceUpdate(t *testing.T) {
+	ctx := context.Background()
+	superUser := &entities.User{ID: uuid.New(), Role: entities.RoleSuperUser}
+	manager := &entities.User{ID: uuid.New(), Role: entities.RoleManager}
+	rename := func(name entities.Role) *entities.Role { return &name }
+	rank := func(rank int) *int { return &rank }
+	permissions := func(names ...string) *[]string { return &names }
+
+	tests := []struct {
+		name            string
+		actor           *entities.User
+		role            entities.Role
+		request         dto.RoleUpdateDTO
+		wantErr         error
+		wantName        entities.Role
+		wantPermissions []string
+	}{
+		{
+			name:            "rename and replace permissions",
+			actor:           superUser,
+			role:            "support",
+			request:         dto.RoleUpdateDTO{Name: rename("helpdesk"), Permissions: permissions(entities.PermissionUsersRead, entities.PermissionUsersUnlock)},
+			wantName:        "helpdesk",
+			wantPermissions: []string{entities.PermissionUsersRead, entities.PermissionUsersUnlock},
+		},
+		{
+			name:            "rank change keeps permissions",
+			actor:           superUser,
+			role:            "support",
+			request:         dto.RoleUpdateDTO{Rank: rank(120)},
+			wantName:        "support",
+			wantPermissions: []string{entities.PermissionUsersRead},
+		},
+		{name: "built-in role cannot be renamed", actor: superUser, role: entities.RoleAdmin, request: dto.RoleUpdateDTO{Name: rename("boss")}, wantErr: errors.ErrRoleBuiltIn},
+		{name: "rename onto existing role", actor: superUser, role: "support", request: dto.RoleUpdateDTO{Name: rename(entities.RoleManager)}, wantErr: errors.ErrRoleExists},
+		{name: "rank above actor", actor: manager, role: "support", request: dto.RoleUpdateDTO{Rank: rank(250)}, wantErr: errors.ErrRoleRankTooHigh},
+		{
+			name:    "permission the actor does not have",
+			actor:   manager,
+			role:    "support",
+			request: dto.RoleUpdateDTO{Permissions: permissions(entities.PermissionAuditRead)},
+			wantErr: errors.ErrForbidden,
+		},
+		{
+			name:    "superuser keeps role management",
+			actor:   superUser,
+			role:    entities.RoleSuperUser,
+			request: dto.RoleUpdateDTO{Permissions: permissions(entities.PermissionUsersRead)},
+			wantErr: errors.ErrSuperUserLockedOut,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, roles, _ := newTestRoleServiceWithRoles(t, superUser, manager)
+			before := roles.permissions.roles[tt.role]
+			// Права попадают в кеш до изменения
+			if _, err := service.permissionService.GetRolePermissions(ctx, tt.role); err != nil {
+				t.Fatalf("GetRolePermissions: %v", err)
+			}
+
+			response, err := service.Update(ctx, tt.actor.ID, tt.role, tt.request)
+			if !stdErrors.Is(err, tt.wantErr) {
+				t.Fatalf("Update error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if !reflect.DeepEqual(roles.permissions.roles[tt.role], before) {
+					t.Error("rejected update changed permissions")
+				}
+				return
+			}
+
+			got := append([]string(nil), response.Permissions...)
+			sort.Strings(got)
+			if response.Name != tt.wantName || !reflect.DeepEqual(got, tt.wantPermissions) {
+				t.Errorf("Update = %s %v, want %s %v", response.Name, got, tt.wantName, tt.wantPermissions)
+			}
+			cached, err := service.permissionService.GetRolePermissions(ctx, tt.wantName)
+			if err != nil {
+				t.Fatalf("GetRolePermissions: %v", err)
+			}
+			sort.Strings(cached)
+			if !reflect.DeepEqual(cached, tt.wantPermissions) {
+				t.Errorf("cached permissions = %v, want %v", cached, tt.wantPermissions)
+			}
+		})
+	}
+}
+
+func TestRoleServiceDelete(t *testing.T) {
+	ctx := context.Background()
+	actorID := uuid.New()
+
+	tests := []struct {
+		name     string
+		role     entities.Role
+		assigned int64
+		wantErr  error
+	}{
+		{name: "unused custom role", role: "support"},
+		{name: "role in use", role: "support", assigned: 1, wantErr: errors.ErrRoleInUse},
+		{name: "built-in role", role: entities.RoleManager, wantErr: errors.ErrRoleBuiltIn},
+		{name: "unknown role", role: "ghost", wantErr: errors.ErrRoleNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, roles, _ := newTestRoleServiceWithRoles(t)
+			roles.assigned[tt.role] = tt.assigned
+
+			err := service.Delete(ctx, actorID, tt.role, "", "")
+			if !stdErrors.Is(err, tt.wantErr) {
+				t.Fatalf("Delete error = %v, want %v", err, tt.wantErr)
+			}
+			if _, exists := roles.roles[tt.role]; exists != (tt.wantErr != nil && tt.wantErr != errors.ErrRoleNotFound) {
+				t.Errorf("role exists after Delete = %v", exists)
+			}
+		})
+	}
+}
+
+func TestRoleServiceCheckAssignable(t *testing.T) {
+	ctx := context.Background()
+	service, _, _ := newTestRoleServiceWithRoles(t)
+
+	tests := []struct {
+		actor   entities.Role
+		role    entities.Role
+		wantErr error
+	}{
+		{actor: entities.RoleAdmin, role: "support"},
+		{actor: entities.RoleAdmin, role: entities.RoleAdmin},
+		{actor: "support", role: entities.RoleManager, wantErr: errors.ErrRoleRankTooHigh},
+		{actor: entities.RoleAdmin, role: "ghost", wantErr: errors.ErrInvalidUserRole},
+		{actor: "ghost", role: entities.RoleUser, wantErr: errors.ErrRoleRankTooHigh},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.actor)+"/"+string(tt.role), func(t *testing.T) {
+			if err := service.CheckAssignable(ctx, tt.actor, tt.role); !stdErrors.Is(err, tt.wantErr) {
+				t.Errorf("CheckAssignable error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"gold_portal/config"
+	"gold_portal/internal/domain/dto"
+	"gold_portal/internal/domain/entities"
+	"gold_portal/internal/domain/repositories"
+	"gold_portal/internal/errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SessionService interface {
+	Create(ctx context.Context, userID, organizationID uuid.UUID, userAgent, clientIP, clientID, scope string, authMethods []string) (*entities.Session, error)
+	Touch(ctx context.Context, sessionID uuid.UUID) error
+	// Делает организацию активной в сессии пользователя
+	SwitchOrganization(ctx context.Context, userID, sessionID, organizationID uuid.UUID) (*entities.Session, error)
+	GetByUser(ctx context.Context, userID uuid.UUID) ([]*dto.SessionResponseDTO, error)
+	Revoke(ctx context.Context, userID, sessionID uuid.UUID) error
+	// Завершает все сессии пользователя, кроме except (uuid.Nil — завершить все)
+	RevokeAll(ctx context.Context, userID, except uuid.UUID) error
+	// Выход со всех устройств: завершает все сессии и делает недействительными все
+	// выданные пользователю токены, в том числе не привязанные к сессии, и API-ключи
+	LogoutEverywhere(ctx context.Context, userID uuid.UUID) error
+	IsRevoked(ctx context.Context, sessionID string) (bool, error)
+}
+
+type sessionService struct {
+	sessionRepository repositories.SessionRepository
+	userRepository    repositories.UserRepository
+	apiKeyRepository  repositories.APIKeyRepository
+	tokenService      TokenService
+	config            *config.Config
+}
+
+func NewSessionService(sessionRepository repositories.SessionRepository, userRepository repositories.UserRepository, apiKeyRepository repositories.APIKeyRepository, tokenService TokenService, config *config.Config) SessionService {
+	return &sessionService{
+		sessionRepository: sessionRepository,
+		userRepository:    userRepository,
+		apiKeyRepository:  apiKeyRepository,
+		tokenService:      tokenService,
+		config:            config,
+	}
+}
+
+func (s *sessionService) Create(ctx context.Context, userID, organizationID uuid.UUID, userAgent, clientIP, clientID, scope string, authMethods []string) (*entities.Session, error) {
+	now := time.Now()
+	session := &entities.Session{
+		ID:              uuid.New(),
+		UserID:          userID,
+		OrganizationID:  organizationID,
+		UserAgent:       userAgent,
+		ClientIP:        clientIP,
+		RefreshFamilyID: uuid.New().String(),
+		ClientID:        clientID,
+		Scope:           scope,
+		AuthMethods:     authMethods,
+		CreatedAt:       now,
+		LastSeenAt:      now,
+		ExpiresAt:       now.Add(s.config.JWT.RefreshExpiry),
+	}
+
+	if err := s.sessionRepository.Create(ctx, session); err != nil {
+		return nil, fmt.Errorf("ошибка при создании сессии: %w", err)
+	}
+
+	return session, nil
+}
+
+func (s *sessionService) Touch(ctx context.Context, sessionID uuid.UUID) error {
+	now := time.Now()
+	return s.sessionRepository.Touch(ctx, sessionID, now, now.Add(s.config.JWT.RefreshExpiry))
+}
+
+func (s *sessionService) SwitchOrganization(ctx context.Context, userID, sessionID, organizationID uuid.UUID) (*entities.Session, error) {
+	session, err := s.sessionRepository.GetID(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.UserID != userID || !session.IsActive() {
+		return nil, errors.ErrSessionNotFound
+	}
+
+	if err := s.sessionRepository.SetOrganization(ctx, session.ID, organizationID); err != nil {
+		return nil, fmt.Errorf("ошибка при смене организации: %w", err)
+	}
+	session.OrganizationID = organizationID
+	return session, nil
+}
+
+func (s *sessionService) GetByUser(ctx context.Context, userID uuid.UUID) ([]*dto.SessionResponseDTO, error) {
+	sessions, err := s.sessionRepository.GetActiveByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]*dto.SessionResponseDTO, 0, len(sessions))
+	for _, session := range sessions {
+		var sessionResponse dto.SessionResponseDTO
+		sessionResponse.FromModel(session)
+		response = append(response, &sessionResponse)
+	}
+	return response, nil
+}
+
+func (s *sessionService) Revoke(ctx context.Context, userID, sessionID uuid.UUID) error {
+	session, err := s.sessionRepository.GetID(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	// Чужая сессия для пользователя неотличима от несуществующей
+	if session.UserID != userID {
+		return errors.ErrSessionNotFound
+	}
+
+	return s.revoke(ctx, session)
+}
+
+func (s *sessionService) RevokeAll(ctx context.Context, userID, except uuid.UUID) error {
+	sessions, err := s.sessionRepository.GetActiveByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		if session.ID == except {
+			continue
+		}
+		if err := s.revoke(ctx, session); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *sessionService) LogoutEverywhere(ctx context.Context, userID uuid.UUID) error {
+	if _, err := s.userRepository.GetID(ctx, userID); err != nil {
+		return err
+	}
+	if err := s.userRepository.IncrementTokenVersion(ctx, userID); err != nil {
+		return fmt.Errorf("ошибка при отзыве токенов: %w", err)
+	}
+	// Сброс пароля и удаление полагаются на этот вызов: ключ скомпрометированной
+	// учётной записи не должен пережить его
+	if err := s.apiKeyRepository.RevokeByUser(ctx, userID); err != nil {
+		return fmt.Errorf("ошибка при отзыве API-ключей: %w", err)
+	}
+	return s.RevokeAll(ctx, userID, uuid.Nil)
+}
+
+func (s *sessionService) IsRevoked(ctx context.Context, sessionID string) (bool, error) {
+	return s.tokenService.IsSessionRevoked(ctx, sessionID)
+}
+
+// revoke завершает сессию: refresh токены перестают обмениваться,
+// а выданные access токены отклоняются до истечения их срока
+func (s *sessionService) revoke(ctx context.Context, session *entities.Session) error {
+	if err := s.sessionRepository.Revoke(ctx, session.ID); err != nil {
+		return fmt.Errorf("ошибка при отзыве сессии: %w", err)
+	}
+
+	if err := s.tokenService.RevokeRefreshFamily(ctx, session.RefreshFamilyID); err != nil {
+		return err
+	}
+
+	return s.tokenService.RevokeSession(ctx, session.ID.String(), time.Now().Add(s.config.JWT.Expiry))
+}
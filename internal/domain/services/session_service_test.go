@@ -0,0 +1,180 @@
+package services
+
+import (
+	"context"
+	stdErrors "errors"
+	"gold_portal/config"
+	"gold_portal/internal/domain/entities"
+	"gold_portal/internal/domain/repositories"
+	"gold_portal/internal/errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// fakeSessionRepository хранит сессии в памяти и отбирает активные так же, как запрос в базу
+type fakeSessionRepository struct {
+	repositories.SessionRepository
+	sessions map[uuid.UUID]*entities.Session
+}
+
+func newFakeSessionRepository() *fakeSessionRepository {
+	return &fakeSessionRepository{sessions: make(map[uuid.UUID]*entities.Session)}
+}
+
+func (r *fakeSessionRepository) Create(_ context.Context, session *entities.Session) error {
+	r.sessions[session.ID] = session
+	return nil
+}
+
+func (r *fakeSessionRepository) GetID(_ context.Context, id uuid.UUID) (*entities.Session, error) {
+	session, ok := r.sessions[id]
+	if !ok {
+		return nil, errors.ErrSessionNotFound
+	}
+	return session, nil
+}
+
+func (r *fakeSessionRepository) GetActiveByUser(_ context.Context, userID uuid.UUID) ([]*entities.Session, error) {
+	var sessions []*entities.Session
+	for _, session := range r.sessions {
+		if session.UserID == userID && session.RevokedAt == nil && session.ExpiresAt.After(time.Now()) {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions, nil
+}
+
+func (r *fakeSessionRepository) Revoke(_ context.Context, id uuid.UUID) error {
+	if session, ok := r.sessions[id]; ok && session.RevokedAt == nil {
+		now := time.Now()
+		session.RevokedAt = &now
+	}
+	return nil
+}
+
+func (r *fakeSessionRepository) Touch(_ context.Context, id uuid.UUID, lastSeenAt, expiresAt time.Time) error {
+	if session, ok := r.sessions[id]; ok {
+		session.LastSeenAt = lastSeenAt
+		session.ExpiresAt = expiresAt
+	}
+	return nil
+}
+
+func (r *fakeSessionRepository) SetOrganization(_ context.Context, id, organizationID uuid.UUID) error {
+	if session, ok := r.sessions[id]; ok {
+		session.OrganizationID = organizationID
+	}
+	return nil
+}
+
+func newTestSessionService(t *testing.T) (*sessionService, *fakeSessionRepository, Cache) {
+	cache, _ := newTestCache(t)
+	repository := newFakeSessionRepository()
+	service := &sessionService{
+		sessionRepository: repository,
+		apiKeyRepository:  &fakeAPIKeyRepository{keys: make(map[uuid.UUID]*entities.APIKey)},
+		tokenService:      NewTokenService(cache, nil),
+		config: &config.Config{JWT: config.JWTConfig{
+			Expiry:        15 * time.Minute,
+			RefreshExpiry: 24 * time.Hour,
+		}},
+	}
+	return service, repository, cache
+}
+
+func TestSessionServiceGetByUser(t *testing.T) {
+	ctx := context.Background()
+	service, repository, _ := newTestSessionService(t)
+	userID, otherUserID := uuid.New(), uuid.New()
+
+	active, err := service.Create(ctx, userID, testOrganizationID, "phone", "10.0.0.1", "", "", nil)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	revoked, err := service.Create(ctx, userID, testOrganizationID, "laptop", "10.0.0.2", "", "", nil)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := service.Revoke(ctx, userID, revoked.ID); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	expired, err := service.Create(ctx, userID, testOrganizationID, "tablet", "10.0.0.3", "", "", nil)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	repository.sessions[expired.ID].ExpiresAt = time.Now().Add(-time.Minute)
+	if _, err := service.Create(ctx, otherUserID, testOrganizationID, "phone", "10.0.0.4", "", "", nil); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	sessions, err := service.GetByUser(ctx, userID)
+	if err != nil {
+		t.Fatalf("GetByUser: %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].ID != active.ID {
+		t.Fatalf("sessions %+v, want only %s", sessions, active.ID)
+	}
+	if sessions[0].UserAgent != "phone" || sessions[0].ClientIP != "10.0.0.1" {
+		t.Fatalf("session device %q from %q, want phone from 10.0.0.1", sessions[0].UserAgent, sessions[0].ClientIP)
+	}
+}
+
+func TestSessionServiceRevoke(t *testing.T) {
+	ctx := context.Background()
+	ownerID := uuid.New()
+
+	tests := []struct {
+		name    string
+		userID  uuid.UUID
+		unknown bool
+		wantErr error
+	}{
+		{name: "own session", userID: ownerID},
+		// Чужая сессия не должна отзываться и не должна выдавать своё существование
+		{name: "session of another user", userID: uuid.New(), wantErr: errors.ErrSessionNotFound},
+		{name: "unknown session", userID: ownerID, unknown: true, wantErr: errors.ErrSessionNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, repository, cache := newTestSessionService(t)
+			session, err := service.Create(ctx, ownerID, testOrganizationID, "phone", "10.0.0.1", "", "", nil)
+			if err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			if err := service.tokenService.StoreRefreshToken(ctx, session.RefreshFamilyID, "jti", session.ExpiresAt); err != nil {
+				t.Fatalf("StoreRefreshToken: %v", err)
+			}
+
+			sessionID := session.ID
+			if tt.unknown {
+				sessionID = uuid.New()
+			}
+			if err := service.Revoke(ctx, tt.userID, sessionID); !stdErrors.Is(err, tt.wantErr) {
+				t.Fatalf("Revoke: got %v, want %v", err, tt.wantErr)
+			}
+
+			wantRevoked := tt.wantErr == nil
+			if got := repository.sessions[session.ID].RevokedAt != nil; got != wantRevoked {
+				t.Fatalf("stored session revoked = %v, want %v", got, wantRevoked)
+			}
+			revoked, err := service.IsRevoked(ctx, session.ID.String())
+			if err != nil {
+				t.Fatalf("IsRevoked: %v", err)
+			}
+			if revoked != wantRevoked {
+				t.Fatalf("access tokens revoked = %v, want %v", revoked, wantRevoked)
+			}
+			// Отзыв сессии гасит и её refresh токены
+			familyExists, err := cache.Exists(ctx, refreshFamilyKey(session.RefreshFamilyID))
+			if err != nil {
+				t.Fatalf("Exists: %v", err)
+			}
+			if familyExists == wantRevoked {
+				t.Fatalf("refresh family exists = %v, want %v", familyExists, !wantRevoked)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"fmt"
+	"gold_portal/internal/errors"
 	"gold_portal/internal/pkg/jwt"
 	"time"
 
@@ -18,6 +19,18 @@ type TokenService interface {
 	RemoveFromBlacklist(ctx context.Context, tokenString string) error
 	// Получает информацию о токене
 	GetTokenInfo(ctx context.Context, tokenString string) (*jwt.TokenInfo, error)
+	// Запоминает актуальный refresh токен (jti) семейства
+	StoreRefreshToken(ctx context.Context, familyID, jti string, expiry time.Time) error
+	// Погашает refresh токен; повторное использование отзывает всё семейство
+	RotateRefreshToken(ctx context.Context, familyID, jti string, expiry time.Time) error
+	// Проверяет, что refresh токен (jti) — актуальный в своём семействе
+	IsRefreshTokenCurrent(ctx context.Context, familyID, jti string) (bool, error)
+	// Отзывает семейство refresh токенов
+	RevokeRefreshFamily(ctx context.Context, familyID string) error
+	// Помечает сессию отозванной до истечения выданных в ней access токенов
+	RevokeSession(ctx context.Context, sessionID string, expiry time.Time) error
+	// Проверяет, отозвана ли сессия
+	IsSessionRevoked(ctx context.Context, sessionID string) (bool, error)
 }
 
 type tokenService struct {
@@ -30,6 +43,9 @@ type Cache interface {
 	Get(ctx context.Context, key string) (string, error)
 	Delete(ctx context.Context, key string) error
 	Exists(ctx context.Context, key string) (bool, error)
+	SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error)
+	Incr(ctx context.Context, key string) (int64, error)
+	Expire(ctx context.Context, key string, expiration time.Duration) error
 }
 
 func NewTokenService(cache Cache, jwtService jwt.JWTService) TokenService {
@@ -120,9 +136,14 @@ func (s *tokenService) GetTokenInfo(ctx context.Context, tokenString string) (*j
 		return nil, fmt.Errorf("invalid token claims")
 	}
 
-	// Приводим к правильному типу
-	userID, ok := claims["user_id"].(string)
-	if !ok {
+	// Приводим к правильному типу. Токены сервисных клиентов не содержат user_id,
+	// субъектом в них выступает sub
+	userID, _ := claims["user_id"].(string)
+	subject := userID
+	if subject == "" {
+		subject, _ = claims["sub"].(string)
+	}
+	if subject == "" {
 		return nil, fmt.Errorf("invalid user_id in token")
 	}
 
@@ -158,7 +179,154 @@ func (s *tokenService) GetTokenInfo(ctx context.Context, tokenString string) (*j
 		ExpiresAt: time.Unix(int64(exp), 0),
 		IssuedAt:  time.Unix(int64(iat), 0),
 		JTI:       jti,
+		Subject:   subject,
+	}
+	tokenInfo.ClientID, _ = claims["client_id"].(string)
+	tokenInfo.Scope, _ = claims["scope"].(string)
+	tokenInfo.SessionID, _ = claims["sid"].(string)
+	tokenInfo.FamilyID, _ = claims["fid"].(string)
+	tokenInfo.OrganizationID, _ = claims["org"].(string)
+	if version, ok := claims["ver"].(float64); ok {
+		tokenInfo.Version = int(version)
 	}
 
 	return tokenInfo, nil
 }
+
+func (s *tokenService) StoreRefreshToken(ctx context.Context, familyID, jti string, expiry time.Time) error {
+	if familyID == "" || jti == "" {
+		return fmt.Errorf("family id and jti are required")
+	}
+
+	// Семейство хранит jti последнего выданного refresh токена
+	ttl := time.Until(expiry)
+	if ttl <= 0 {
+		return errors.ErrInvalidToken
+	}
+
+	if err := s.cache.Set(ctx, refreshFamilyKey(familyID), jti, ttl); err != nil {
+		return fmt.Errorf("failed to store refresh token: %w", err)
+	}
+
+	return nil
+}
+
+func (s *tokenService) RotateRefreshToken(ctx context.Context, familyID, jti string, expiry time.Time) error {
+	if familyID == "" || jti == "" {
+		return errors.ErrInvalidToken
+	}
+
+	current, err := s.cache.Get(ctx, refreshFamilyKey(familyID))
+	if err != nil {
+		// Семейство отозвано или истекло
+		return errors.ErrInvalidToken
+	}
+
+	// Предъявлен уже заменённый токен — вероятна кража, отзываем всё семейство
+	if current != jti {
+		if err := s.RevokeRefreshFamily(ctx, familyID); err != nil {
+			return err
+		}
+		return errors.ErrRefreshTokenReused
+	}
+
+	// Помечаем токен использованным атомарно, чтобы два параллельных запроса
+	// не смогли обменять один и тот же токен
+	usedExpiry := time.Until(expiry)
+	if usedExpiry <= 0 {
+		return errors.ErrInvalidToken
+	}
+
+	ok, err := s.cache.SetNX(ctx, refreshUsedKey(jti), familyID, usedExpiry)
+	if err != nil {
+		return fmt.Errorf("failed to mark refresh token as used: %w", err)
+	}
+	if !ok {
+		if err := s.RevokeRefreshFamily(ctx, familyID); err != nil {
+			return err
+		}
+		return errors.ErrRefreshTokenReused
+	}
+
+	return nil
+}
+
+func (s *tokenService) IsRefreshTokenCurrent(ctx context.Context, familyID, jti string) (bool, error) {
+	if familyID == "" || jti == "" {
+		return false, nil
+	}
+
+	exists, err := s.cache.Exists(ctx, refreshFamilyKey(familyID))
+	if err != nil || !exists {
+		return false, err
+	}
+
+	current, err := s.cache.Get(ctx, refreshFamilyKey(familyID))
+	if err != nil {
+		return false, fmt.Errorf("failed to read refresh token family: %w", err)
+	}
+	if current != jti {
+		return false, nil
+	}
+
+	// Токен уже обменян, но новый ещё не сохранён
+	used, err := s.cache.Exists(ctx, refreshUsedKey(jti))
+	if err != nil {
+		return false, err
+	}
+	return !used, nil
+}
+
+func (s *tokenService) RevokeRefreshFamily(ctx context.Context, familyID string) error {
+	if familyID == "" {
+		return fmt.Errorf("family id is required")
+	}
+
+	if err := s.cache.Delete(ctx, refreshFamilyKey(familyID)); err != nil {
+		return fmt.Errorf("failed to revoke refresh token family: %w", err)
+	}
+
+	return nil
+}
+
+func (s *tokenService) RevokeSession(ctx context.Context, sessionID string, expiry time.Time) error {
+	if sessionID == "" {
+		return fmt.Errorf("session id is required")
+	}
+
+	revokeExpiry := time.Until(expiry)
+	if revokeExpiry <= 0 {
+		return nil
+	}
+
+	if err := s.cache.Set(ctx, sessionRevokedKey(sessionID), "revoked", revokeExpiry); err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	return nil
+}
+
+func (s *tokenService) IsSessionRevoked(ctx context.Context, sessionID string) (bool, error) {
+	if sessionID == "" {
+		return false, nil
+	}
+
+	exists, err := s.cache.Exists(ctx, sessionRevokedKey(sessionID))
+	if err != nil {
+		return false, fmt.Errorf("failed to check session status: %w", err)
+	}
+
+	return exists, nil
+}
+
+func refreshFamilyKey(familyID string) string {
+	return fmt.Sprintf("refresh_family:%s", familyID)
+}
+
+func refreshUsedKey(jti string) string {
+	return fmt.Sprintf("refresh_used:%s", jti)
+}
+
+func sessionRevokedKey(sessionID string) string {
+	return fmt.Sprintf("session_revoked:%s", sessionID)
+}
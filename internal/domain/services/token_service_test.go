@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	stdErrors "errors"
+	"gold_portal/internal/errors"
+	"testing"
+	"time"
+)
+
+func TestRotateRefreshToken(t *testing.T) {
+	ctx := context.Background()
+	expiry := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name string
+		// Выполняет шаги до проверяемого вызова RotateRefreshToken
+		setup   func(t *testing.T, service TokenService)
+		jti     string
+		wantErr error
+		// Семейство остаётся действующим после вызова
+		wantFamily bool
+	}{
+		{
+			name:       "current token rotates",
+			jti:        "jti-1",
+			wantFamily: true,
+		},
+		{
+			name: "replaced token revokes family",
+			setup: func(t *testing.T, service TokenService) {
+				if err := service.RotateRefreshToken(ctx, "family", "jti-1", expiry); err != nil {
+					t.Fatalf("first rotation: %v", err)
+				}
+				if err := service.StoreRefreshToken(ctx, "family", "jti-2", expiry); err != nil {
+					t.Fatalf("StoreRefreshToken: %v", err)
+				}
+			},
+			jti:     "jti-1",
+			wantErr: errors.ErrRefreshTokenReused,
+		},
+		{
+			name: "token used twice before replacement revokes family",
+			setup: func(t *testing.T, service TokenService) {
+				if err := service.RotateRefreshToken(ctx, "family", "jti-1", expiry); err != nil {
+					t.Fatalf("first rotation: %v", err)
+				}
+			},
+			jti:     "jti-1",
+			wantErr: errors.ErrRefreshTokenReused,
+		},
+		{
+			name: "revoked family is rejected",
+			setup: func(t *testing.T, service TokenService) {
+				if err := service.RevokeRefreshFamily(ctx, "family"); err != nil {
+					t.Fatalf("RevokeRefreshFamily: %v", err)
+				}
+			},
+			jti:     "jti-1",
+			wantErr: errors.ErrInvalidToken,
+		},
+		{
+			name:    "unknown token in live family revokes family",
+			jti:     "forged",
+			wantErr: errors.ErrRefreshTokenReused,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache, _ := newTestCache(t)
+			service := NewTokenService(cache, nil)
+			if err := service.StoreRefreshToken(ctx, "family", "jti-1", expiry); err != nil {
+				t.Fatalf("StoreRefreshToken: %v", err)
+			}
+			if tt.setup != nil {
+				tt.setup(t, service)
+			}
+
+			err := service.RotateRefreshToken(ctx, "family", tt.jti, expiry)
+			if !stdErrors.Is(err, tt.wantErr) {
+				t.Fatalf("RotateRefreshToken: got %v, want %v", err, tt.wantErr)
+			}
+
+			exists, err := cache.Exists(ctx, refreshFamilyKey("family"))
+			if err != nil {
+				t.Fatalf("Exists: %v", err)
+			}
+			if exists != tt.wantFamily {
+				t.Fatalf("family exists = %v, want %v", exists, tt.wantFamily)
+			}
+		})
+	}
+}
+
+func TestIsRefreshTokenCurrent(t *testing.T) {
+	ctx := context.Background()
+	expiry := time.Now().Add(time.Hour)
+	cache, _ := newTestCache(t)
+	service := NewTokenService(cache, nil)
+
+	if err := service.StoreRefreshToken(ctx, "family", "jti-1", expiry); err != nil {
+		t.Fatalf("StoreRefreshToken: %v", err)
+	}
+	if current, err := service.IsRefreshTokenCurrent(ctx, "family", "jti-1"); err != nil || !current {
+		t.Fatalf("fresh token: got %v, %v; want current", current, err)
+	}
+
+	// Обменянный токен перестаёт быть актуальным ещё до записи нового
+	if err := service.RotateRefreshToken(ctx, "family", "jti-1", expiry); err != nil {
+		t.Fatalf("RotateRefreshToken: %v", err)
+	}
+	if current, err := service.IsRefreshTokenCurrent(ctx, "family", "jti-1"); err != nil || current {
+		t.Fatalf("used token: got %v, %v; want not current", current, err)
+	}
+
+	if err := service.StoreRefreshToken(ctx, "family", "jti-2", expiry); err != nil {
+		t.Fatalf("StoreRefreshToken: %v", err)
+	}
+	if current, err := service.IsRefreshTokenCurrent(ctx, "family", "jti-2"); err != nil || !current {
+		t.Fatalf("replacement token: got %v, %v; want current", current, err)
+	}
+}
@@ -0,0 +1,383 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"gold_portal/config"
+	"gold_portal/internal/domain/dto"
+	"gold_portal/internal/domain/entities"
+	"gold_portal/internal/domain/repositories"
+	"gold_portal/internal/errors"
+	"gold_portal/internal/pkg/crypto"
+	"image/png"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+)
+
+const (
+	// Способы аутентификации для claim amr (RFC 8176)
+	AuthMethodPassword = "pwd"
+	AuthMethodOTP      = "otp"
+
+	recoveryCodeCount = 10
+	totpQRCodeSize    = 256
+	// Код TOTP действует 30 секунд плюс окно в один шаг в каждую сторону
+	totpReplayWindow = 90 * time.Second
+)
+
+type TwoFactorService interface {
+	// Создаёт новый секрет TOTP; 2FA включается только после Confirm
+	Enroll(ctx context.Context, userID uuid.UUID) (*dto.TwoFactorEnrollResponseDTO, error)
+	// Проверяет первый код, включает 2FA и выдаёт коды восстановления
+	Confirm(ctx context.Context, userID uuid.UUID, code string) (*dto.RecoveryCodesResponseDTO, error)
+	// Отключает 2FA по коду TOTP или коду восстановления
+	Disable(ctx context.Context, userID uuid.UUID, code string) error
+	// Выпускает новые коды восстановления взамен старых
+	RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) (*dto.RecoveryCodesResponseDTO, error)
+	// Создаёт одноразовый токен второго шага входа; authMethod — пройденный первый фактор
+	CreateChallenge(ctx context.Context, userID uuid.UUID, login dto.LoginRequestDTO, authMethod string) (string, error)
+	// Проверяет код по токену второго шага и возвращает данные начатого входа
+	VerifyChallenge(ctx context.Context, request dto.MFAVerifyRequestDTO) (*MFAChallenge, error)
+	// Обязательна ли 2FA для роли
+	IsRequired(role entities.Role) bool
+}
+
+type twoFactorService struct {
+	userRepository         repositories.UserRepository
+	recoveryCodeRepository repositories.RecoveryCodeRepository
+	organizationRepository repositories.OrganizationRepository
+	groupRepository        repositories.GroupRepository
+	cache                  Cache
+	config                 *config.Config
+}
+
+// MFAChallenge данные входа, ожидающего второй фактор
+type MFAChallenge struct {
+	UserID    uuid.UUID `json:"user_id"`
+	UserAgent string    `json:"user_agent"`
+	ClientIP  string    `json:"client_ip"`
+	ClientID  string    `json:"client_id"`
+	Scope     string    `json:"scope"`
+	// Пройденные до второго фактора способы аутентификации
+	AuthMethods []string `json:"amr"`
+}
+
+func NewTwoFactorService(userRepository repositories.UserRepository, recoveryCodeRepository repositories.RecoveryCodeRepository, organizationRepository repositories.OrganizationRepository, groupRepository repositories.GroupRepository, cache Cache, config *config.Config) TwoFactorService {
+	return &twoFactorService{
+		userRepository:         userRepository,
+		recoveryCodeRepository: recoveryCodeRepository,
+		organizationRepository: organizationRepository,
+		groupRepository:        groupRepository,
+		cache:                  cache,
+		config:                 config,
+	}
+}
+
+func (s *twoFactorService) Enroll(ctx context.Context, userID uuid.UUID) (*dto.TwoFactorEnrollResponseDTO, error) {
+	user, err := s.userRepository.GetID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, errors.ErrTwoFactorAlreadyEnabled
+	}
+
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      s.config.MFA.Issuer,
+		AccountName: user.Phone,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка генерации секрета TOTP: %w", err)
+	}
+
+	encrypted, err := crypto.Encrypt(s.config.MFA.EncryptionKey, key.Secret())
+	if err != nil {
+		return nil, fmt.Errorf("ошибка шифрования секрета TOTP: %w", err)
+	}
+	if err := s.userRepository.UpdateTOTP(ctx, userID, encrypted, false); err != nil {
+		return nil, fmt.Errorf("ошибка сохранения секрета TOTP: %w", err)
+	}
+
+	image, err := key.Image(totpQRCodeSize, totpQRCodeSize)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка генерации QR кода: %w", err)
+	}
+	var qrCode bytes.Buffer
+	if err := png.Encode(&qrCode, image); err != nil {
+		return nil, fmt.Errorf("ошибка генерации QR кода: %w", err)
+	}
+
+	return &dto.TwoFactorEnrollResponseDTO{
+		Secret: key.Secret(),
+		URI:    key.URL(),
+		QRCode: "data:image/png;base64," + base64.StdEncoding.EncodeToString(qrCode.Bytes()),
+	}, nil
+}
+
+func (s *twoFactorService) Confirm(ctx context.Context, userID uuid.UUID, code string) (*dto.RecoveryCodesResponseDTO, error) {
+	user, err := s.userRepository.GetID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, errors.ErrTwoFactorAlreadyEnabled
+	}
+	if user.TOTPSecret == "" {
+		return nil, errors.ErrTwoFactorNotEnrolled
+	}
+
+	ok, err := s.validateTOTP(ctx, user, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.ErrInvalidTwoFactorCode
+	}
+
+	if err := s.userRepository.UpdateTOTP(ctx, userID, user.TOTPSecret, true); err != nil {
+		return nil, fmt.Errorf("ошибка включения 2FA: %w", err)
+	}
+
+	return s.issueRecoveryCodes(ctx, userID)
+}
+
+func (s *twoFactorService) Disable(ctx context.Context, userID uuid.UUID, code string) error {
+	user, err := s.userRepository.GetID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !user.TOTPEnabled {
+		return errors.ErrTwoFactorNotEnabled
+	}
+	if s.IsRequired(user.Role) {
+		return errors.ErrTwoFactorRequired
+	}
+	// 2FA нельзя отключить, если она обязательна для роли хотя бы в одной организации,
+	// в том числе для роли, выданной через группу
+	members, err := s.organizationRepository.GetMemberships(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, member := range members {
+		if s.IsRequired(member.Role) {
+			return errors.ErrTwoFactorRequired
+		}
+	}
+	groupRoles, err := s.groupRepository.GetUserRoles(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, role := range groupRoles {
+		if s.IsRequired(role) {
+			return errors.ErrTwoFactorRequired
+		}
+	}
+
+	if _, err := s.verifyCode(ctx, user, code); err != nil {
+		return err
+	}
+
+	if err := s.userRepository.UpdateTOTP(ctx, userID, "", false); err != nil {
+		return fmt.Errorf("ошибка отключения 2FA: %w", err)
+	}
+	return s.recoveryCodeRepository.DeleteByUser(ctx, userID)
+}
+
+func (s *twoFactorService) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) (*dto.RecoveryCodesResponseDTO, error) {
+	user, err := s.userRepository.GetID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.TOTPEnabled {
+		return nil, errors.ErrTwoFactorNotEnabled
+	}
+
+	if _, err := s.verifyCode(ctx, user, code); err != nil {
+		return nil, err
+	}
+
+	return s.issueRecoveryCodes(ctx, userID)
+}
+
+func (s *twoFactorService) CreateChallenge(ctx context.Context, userID uuid.UUID, login dto.LoginRequestDTO, authMethod string) (string, error) {
+	token, err := crypto.GenerateRandomString(32)
+	if err != nil {
+		return "", fmt.Errorf("ошибка генерации mfa_token: %w", err)
+	}
+
+	record, err := json.Marshal(MFAChallenge{
+		UserID:      userID,
+		UserAgent:   login.UserAgent,
+		ClientIP:    login.ClientIP,
+		ClientID:    login.ClientID,
+		Scope:       login.Scope,
+		AuthMethods: []string{authMethod},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.cache.Set(ctx, mfaChallengeKey(token), string(record), s.config.MFA.ChallengeExpiry); err != nil {
+		return "", fmt.Errorf("ошибка сохранения mfa_token: %w", err)
+	}
+	return token, nil
+}
+
+func (s *twoFactorService) VerifyChallenge(ctx context.Context, request dto.MFAVerifyRequestDTO) (*MFAChallenge, error) {
+	key := mfaChallengeKey(request.MFAToken)
+	value, err := s.cache.Get(ctx, key)
+	if err != nil {
+		return nil, errors.ErrMFAChallengeInvalid
+	}
+
+	var challenge MFAChallenge
+	if err := json.Unmarshal([]byte(value), &challenge); err != nil {
+		return nil, err
+	}
+
+	// Вход через приложение OAuth нельзя завершить напрямую через API и наоборот
+	if challenge.ClientID != request.ClientID {
+		return nil, errors.ErrMFAChallengeInvalid
+	}
+
+	// Ограничиваем перебор кодов: после исчерпания попыток нужно заново ввести пароль
+	attempts, err := s.cache.Incr(ctx, key+":attempts")
+	if err != nil {
+		return nil, err
+	}
+	if attempts == 1 {
+		_ = s.cache.Expire(ctx, key+":attempts", s.config.MFA.ChallengeExpiry)
+	}
+	if attempts > int64(s.config.MFA.MaxAttempts) {
+		_ = s.cache.Delete(ctx, key)
+		return nil, errors.ErrMFAChallengeInvalid
+	}
+
+	user, err := s.userRepository.GetID(ctx, challenge.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.verifyCode(ctx, user, request.Code); err != nil {
+		return nil, err
+	}
+
+	// Токен второго шага одноразовый
+	if err := s.cache.Delete(ctx, key); err != nil {
+		return nil, err
+	}
+
+	return &challenge, nil
+}
+
+func (s *twoFactorService) IsRequired(role entities.Role) bool {
+	for _, required := range s.config.MFA.RequiredRoles {
+		if entities.Role(required) == role {
+			return true
+		}
+	}
+	return false
+}
+
+// HasSecondFactor проверяет, что вход подтверждён вторым фактором: кодом 2FA или ключом доступа
+func HasSecondFactor(authMethods []string) bool {
+	for _, method := range authMethods {
+		if method == AuthMethodOTP || method == AuthMethodPasskey {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyCode принимает код TOTP или одноразовый код восстановления
+func (s *twoFactorService) verifyCode(ctx context.Context, user *entities.User, code string) (string, error) {
+	ok, err := s.validateTOTP(ctx, user, code)
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		return AuthMethodOTP, nil
+	}
+
+	used, err := s.recoveryCodeRepository.Use(ctx, user.ID, crypto.HashToken(normalizeRecoveryCode(code)))
+	if err != nil {
+		return "", err
+	}
+	if used {
+		return AuthMethodOTP, nil
+	}
+
+	return "", errors.ErrInvalidTwoFactorCode
+}
+
+// validateTOTP проверяет код и не даёт использовать один код дважды
+func (s *twoFactorService) validateTOTP(ctx context.Context, user *entities.User, code string) (bool, error) {
+	secret, err := crypto.Decrypt(s.config.MFA.EncryptionKey, user.TOTPSecret)
+	if err != nil {
+		return false, fmt.Errorf("ошибка расшифровки секрета TOTP: %w", err)
+	}
+
+	code = strings.TrimSpace(code)
+	valid, err := totp.ValidateCustom(code, secret, time.Now(), totp.ValidateOpts{
+		Period:    30,
+		Skew:      1,
+		Digits:    otp.DigitsSix,
+		Algorithm: otp.AlgorithmSHA1,
+	})
+	if err != nil || !valid {
+		return false, nil
+	}
+
+	// Ключ строится по очищенному коду, иначе код с пробелом прошёл бы повторно
+	fresh, err := s.cache.SetNX(ctx, fmt.Sprintf("totp_used:%s:%s", user.ID, code), "used", totpReplayWindow)
+	if err != nil {
+		return false, err
+	}
+	return fresh, nil
+}
+
+func (s *twoFactorService) issueRecoveryCodes(ctx context.Context, userID uuid.UUID) (*dto.RecoveryCodesResponseDTO, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	records := make([]*entities.RecoveryCode, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+		records = append(records, &entities.RecoveryCode{
+			ID:       uuid.New(),
+			UserID:   userID,
+			CodeHash: crypto.HashToken(normalizeRecoveryCode(code)),
+		})
+	}
+
+	if err := s.recoveryCodeRepository.Replace(ctx, userID, records); err != nil {
+		return nil, fmt.Errorf("ошибка сохранения кодов восстановления: %w", err)
+	}
+	return &dto.RecoveryCodesResponseDTO{RecoveryCodes: codes}, nil
+}
+
+// generateRecoveryCode возвращает код вида xxxxx-xxxxx
+func generateRecoveryCode() (string, error) {
+	raw, err := crypto.GenerateRandomString(8)
+	if err != nil {
+		return "", fmt.Errorf("ошибка генерации кода восстановления: %w", err)
+	}
+	code := strings.ToLower(strings.NewReplacer("-", "x", "_", "y").Replace(raw))[:10]
+	return code[:5] + "-" + code[5:], nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+}
+
+func mfaChallengeKey(token string) string {
+	return fmt.Sprintf("mfa_challenge:%s", token)
+}
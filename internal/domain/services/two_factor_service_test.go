@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	stdErrors "errors"
+	"gold_portal/config"
+	"gold_portal/internal/domain/entities"
+	"gold_portal/internal/domain/repositories"
+	"gold_portal/internal/errors"
+	"gold_portal/internal/pkg/crypto"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pquerna/otp/totp"
+)
+
+const testTOTPSecret = "JBSWY3DPEHPK3PXP"
+
+// fakeRecoveryCodeRepository погашает коды так же, как база: каждый ровно один раз
+type fakeRecoveryCodeRepository struct {
+	repositories.RecoveryCodeRepository
+	unused map[string]bool
+}
+
+func (r *fakeRecoveryCodeRepository) Use(_ context.Context, _ uuid.UUID, codeHash string) (bool, error) {
+	if !r.unused[codeHash] {
+		return false, nil
+	}
+	delete(r.unused, codeHash)
+	return true, nil
+}
+
+func TestTwoFactorVerifyCode(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{MFA: config.MFAConfig{EncryptionKey: "test-mfa-key"}}
+
+	encrypted, err := crypto.Encrypt(cfg.MFA.EncryptionKey, testTOTPSecret)
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	current, err := totp.GenerateCode(testTOTPSecret, time.Now())
+	if err != nil {
+		t.Fatalf("GenerateCode: %v", err)
+	}
+	const recoveryCode = "abcde-12345"
+
+	tests := []struct {
+		name string
+		// Коды вводятся по очереди одним пользователем
+		codes   []string
+		wantErr []error
+	}{
+		{
+			name:    "current TOTP code",
+			codes:   []string{current},
+			wantErr: []error{nil},
+		},
+		{
+			name:    "TOTP code replayed",
+			codes:   []string{current, current},
+			wantErr: []error{nil, errors.ErrInvalidTwoFactorCode},
+		},
+		{
+			name:    "TOTP code replayed with spaces",
+			codes:   []string{current, " " + current + " "},
+			wantErr: []error{nil, errors.ErrInvalidTwoFactorCode},
+		},
+		{
+			name:    "wrong code",
+			codes:   []string{"000000x"},
+			wantErr: []error{errors.ErrInvalidTwoFactorCode},
+		},
+		{
+			name:    "recovery code works once",
+			codes:   []string{recoveryCode, recoveryCode},
+			wantErr: []error{nil, errors.ErrInvalidTwoFactorCode},
+		},
+		{
+			name:    "recovery code is normalized",
+			codes:   []string{" ABCDE12345 ", recoveryCode},
+			wantErr: []error{nil, errors.ErrInvalidTwoFactorCode},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache, _ := newTestCache(t)
+			user := &entities.User{ID: uuid.New(), TOTPSecret: encrypted, TOTPEnabled: true}
+			service := &twoFactorService{
+				userRepository: newFakeUserRepository(user),
+				recoveryCodeRepository: &fakeRecoveryCodeRepository{unused: map[string]bool{
+					crypto.HashToken(normalizeRecoveryCode(recoveryCode)): true,
+				}},
+				cache:  cache,
+				config: cfg,
+			}
+
+			for i, code := range tt.codes {
+				method, err := service.verifyCode(ctx, user, code)
+				if !stdErrors.Is(err, tt.wantErr[i]) {
+					t.Fatalf("attempt %d (%q): got %v, want %v", i+1, code, err, tt.wantErr[i])
+				}
+				if err == nil && method != AuthMethodOTP {
+					t.Fatalf("attempt %d: method %q, want %q", i+1, method, AuthMethodOTP)
+				}
+			}
+		})
+	}
+}
+
+func TestTwoFactorRejectsSecretEncryptedWithAnotherKey(t *testing.T) {
+	cache, _ := newTestCache(t)
+	encrypted, err := crypto.Encrypt("another-key", testTOTPSecret)
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	user := &entities.User{ID: uuid.New(), TOTPSecret: encrypted, TOTPEnabled: true}
+	service := &twoFactorService{
+		cache:  cache,
+		config: &config.Config{MFA: config.MFAConfig{EncryptionKey: "test-mfa-key"}},
+	}
+
+	code, err := totp.GenerateCode(testTOTPSecret, time.Now())
+	if err != nil {
+		t.Fatalf("GenerateCode: %v", err)
+	}
+	if _, err := service.validateTOTP(context.Background(), user, code); err == nil {
+		t.Fatal("expected decryption error for a secret encrypted with another key")
+	}
+}
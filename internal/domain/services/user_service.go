@@ -5,36 +5,60 @@ import (
 	"fmt"
 	"gold_portal/internal/errors"
 	"mime/multipart"
+	"net/http"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 
 	"gold_portal/internal/domain/dto"
+	"gold_portal/internal/domain/entities"
 	"gold_portal/internal/domain/repositories"
 )
 
+const AuditActionSessionsRevoked = "SESSIONS_REVOKED"
+
+// Запросы к пользователям ограничены организацией organizationID: пользователи других
+// организаций для них не существуют, а роль пользователя — его роль в этой организации
 type UsersService interface {
-	GetAll(ctx context.Context) ([]*dto.UserResponseDTO, error)
-	UserID(ctx context.Context, id uuid.UUID) (*dto.UserResponseDTO, error)
-	GetByPhone(ctx context.Context, phone string) (*dto.UserResponseDTO, error)
-	Patch(ctx context.Context, id uuid.UUID, request dto.UserUpdateDTO, photoFile *multipart.FileHeader) (*dto.UserResponseDTO, error)
-	Delete(ctx context.Context, id uuid.UUID) error
+	GetAll(ctx context.Context, organizationID uuid.UUID) ([]*dto.UserResponseDTO, error)
+	UserID(ctx context.Context, organizationID, id uuid.UUID) (*dto.UserResponseDTO, error)
+	GetByPhone(ctx context.Context, organizationID uuid.UUID, phone string) (*dto.UserResponseDTO, error)
+	// Изменяет пользователя от имени actorID. Смена роли и блокировка выводят пользователя со всех устройств.
+	// Пользователя с правами шире, чем у actorID, изменить нельзя (ErrForbidden)
+	Patch(ctx context.Context, actorID, organizationID, id uuid.UUID, request dto.UserUpdateDTO, photoFile *multipart.FileHeader) (*dto.UserPatchResponseDTO, error)
+	// Исключает пользователя из организации от имени actorID; пользователь без других организаций удаляется.
+	// Пользователя с правами шире, чем у actorID, удалить нельзя (ErrForbidden)
+	Delete(ctx context.Context, actorID, organizationID, id uuid.UUID) error
+	// Проверяет, что права пользователя id в организации не шире прав actorID (иначе ErrForbidden).
+	// Общая проверка для всех действий администратора над другим пользователем
+	CheckTarget(ctx context.Context, actorID, organizationID, id uuid.UUID) error
 }
 
 type userService struct {
-	usersRepository repositories.UserRepository
-	fileService     FileService
+	usersRepository        repositories.UserRepository
+	organizationRepository repositories.OrganizationRepository
+	organizations          OrganizationService
+	permissionService      PermissionService
+	sessionService         SessionService
+	auditService           AuditService
+	fileService            FileService
 }
 
-func NewUserService(usersRepository repositories.UserRepository, fileService FileService) UsersService {
+func NewUserService(usersRepository repositories.UserRepository, organizationRepository repositories.OrganizationRepository, organizations OrganizationService, permissionService PermissionService, sessionService SessionService, auditService AuditService, fileService FileService) UsersService {
 	return &userService{
-		usersRepository: usersRepository,
-		fileService:     fileService,
+		usersRepository:        usersRepository,
+		organizationRepository: organizationRepository,
+		organizations:          organizations,
+		permissionService:      permissionService,
+		sessionService:         sessionService,
+		auditService:           auditService,
+		fileService:            fileService,
 	}
 }
 
-func (s *userService) GetAll(ctx context.Context) ([]*dto.UserResponseDTO, error) {
-	users, err := s.usersRepository.Get(ctx)
+func (s *userService) GetAll(ctx context.Context, organizationID uuid.UUID) ([]*dto.UserResponseDTO, error) {
+	users, err := s.usersRepository.Get(ctx, organizationID)
 	if err != nil {
 		return nil, errors.ErrUserNotFound
 	}
@@ -47,8 +71,8 @@ func (s *userService) GetAll(ctx context.Context) ([]*dto.UserResponseDTO, error
 	return response, nil
 }
 
-func (s *userService) UserID(ctx context.Context, id uuid.UUID) (*dto.UserResponseDTO, error) {
-	user, err := s.usersRepository.GetID(ctx, id)
+func (s *userService) UserID(ctx context.Context, organizationID, id uuid.UUID) (*dto.UserResponseDTO, error) {
+	user, err := s.usersRepository.GetInOrganization(ctx, organizationID, id)
 	if err != nil {
 		return nil, errors.ErrUserNotFound
 	}
@@ -58,8 +82,8 @@ func (s *userService) UserID(ctx context.Context, id uuid.UUID) (*dto.UserRespon
 	return &response, nil
 }
 
-func (s *userService) GetByPhone(ctx context.Context, phone string) (*dto.UserResponseDTO, error) {
-	user, err := s.usersRepository.FindByPhone(ctx, phone)
+func (s *userService) GetByPhone(ctx context.Context, organizationID uuid.UUID, phone string) (*dto.UserResponseDTO, error) {
+	user, err := s.usersRepository.FindByPhoneInOrganization(ctx, organizationID, phone)
 	if err != nil {
 		return nil, errors.ErrUserNotFound
 	}
@@ -68,14 +92,20 @@ func (s *userService) GetByPhone(ctx context.Context, phone string) (*dto.UserRe
 	return &userResponse, nil
 }
 
-func (s *userService) Patch(ctx context.Context, id uuid.UUID, request dto.UserUpdateDTO, photoFile *multipart.FileHeader) (*dto.UserResponseDTO, error) {
+func (s *userService) Patch(ctx context.Context, actorID, organizationID, id uuid.UUID, request dto.UserUpdateDTO, photoFile *multipart.FileHeader) (*dto.UserPatchResponseDTO, error) {
 	if id == uuid.Nil {
 		return nil, errors.ErrInvalidUUID
 	}
-	user, err := s.usersRepository.GetID(ctx, id)
+	user, err := s.usersRepository.GetInOrganization(ctx, organizationID, id)
 	if err != nil {
 		return nil, errors.ErrUserNotFound
 	}
+	if err := s.CheckTarget(ctx, actorID, organizationID, id); err != nil {
+		return nil, err
+	}
+	previousPhone := user.Phone
+	previousRole := user.Role
+	wasActive := user.IsActive
 	request.ApplyToModel(user)
 
 	// Если есть фото, сохраняем его
@@ -91,18 +121,113 @@ func (s *userService) Patch(ctx context.Context, id uuid.UUID, request dto.UserU
 	if err := s.usersRepository.Patch(ctx, user); err != nil {
 		return nil, errors.ErrUpdateConflict
 	}
-	var response dto.UserResponseDTO
+	if user.Role != previousRole {
+		if err := s.usersRepository.UpdateRole(ctx, organizationID, user.ID, user.Role); err != nil {
+			return nil, errors.ErrUpdateConflict
+		}
+	}
+	// Новый номер нужно подтвердить заново
+	if user.Phone != previousPhone && user.PhoneVerified {
+		if err := s.usersRepository.SetPhoneVerified(ctx, user.ID, false); err != nil {
+			return nil, err
+		}
+		user.PhoneVerified = false
+	}
+
+	var response dto.UserPatchResponseDTO
 	response.FromModel(user)
+
+	// Токены несут прежнюю роль, а заблокированный пользователь не должен оставаться в системе
+	var reasons []string
+	if user.Role != previousRole {
+		reasons = append(reasons, fmt.Sprintf("роль изменена с %s на %s", previousRole, user.Role))
+	}
+	if wasActive && !user.IsActive {
+		reasons = append(reasons, "пользователь заблокирован")
+	}
+	if len(reasons) > 0 {
+		if err := s.sessionService.LogoutEverywhere(ctx, user.ID); err != nil {
+			return nil, err
+		}
+		response.SessionsRevoked = true
+
+		data := fmt.Sprintf("Все сессии и токены отозваны: %s", strings.Join(reasons, ", "))
+		if err := s.auditService.ForOrganization(organizationID).Log(actorID, user.ID, AuditActionSessionsRevoked, "User", http.StatusOK,
+			request.ClientIP, request.UserAgent, data); err != nil {
+			return nil, err
+		}
+	}
 	return &response, nil
 }
 
-func (s *userService) Delete(ctx context.Context, id uuid.UUID) error {
+func (s *userService) Delete(ctx context.Context, actorID, organizationID, id uuid.UUID) error {
 	if id == uuid.Nil {
 		return errors.ErrInvalidUUID
 	}
+	if _, err := s.usersRepository.GetInOrganization(ctx, organizationID, id); err != nil {
+		return err
+	}
+	if err := s.CheckTarget(ctx, actorID, organizationID, id); err != nil {
+		return err
+	}
+
+	memberships, err := s.organizationRepository.GetMemberships(ctx, id)
+	if err != nil {
+		return err
+	}
+	removeAccount := len(memberships) <= 1
+	if removeAccount {
+		// Учётная запись superuser действует во всех организациях: администратор одной из них
+		// не должен её удалять. Сначала роль superuser нужно снять
+		user, err := s.usersRepository.GetID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if user.Role == entities.RoleSuperUser {
+			return errors.ErrSuperUserDelete
+		}
+	}
+
+	// Удалённый пользователь не должен оставаться в системе с выданными ранее токенами
+	if err := s.sessionService.LogoutEverywhere(ctx, id); err != nil {
+		return err
+	}
+	if !removeAccount {
+		return s.organizationRepository.RemoveMember(ctx, organizationID, id)
+	}
 	return s.usersRepository.Delete(ctx, id)
 }
 
+// CheckTarget сравнивает роли после Resolve, а не только роли участников организации:
+// роль superuser действует во всех организациях
+func (s *userService) CheckTarget(ctx context.Context, actorID, organizationID, id uuid.UUID) error {
+	actor, err := s.usersRepository.GetID(ctx, actorID)
+	if err != nil {
+		return err
+	}
+	_, actorRole, err := s.organizations.Resolve(ctx, actor, organizationID)
+	if err != nil {
+		return err
+	}
+	target, err := s.usersRepository.GetID(ctx, id)
+	if err != nil {
+		return err
+	}
+	_, targetRole, err := s.organizations.Resolve(ctx, target, organizationID)
+	if err != nil {
+		return err
+	}
+
+	covers, err := s.permissionService.Covers(ctx, organizationID, actorID, actorRole, id, targetRole)
+	if err != nil {
+		return err
+	}
+	if !covers {
+		return errors.ErrForbidden
+	}
+	return nil
+}
+
 // saveUserPhoto сохраняет фото пользователя в MinIO
 func (s *userService) saveUserPhoto(ctx context.Context, photoFile *multipart.FileHeader) (string, error) {
 	if photoFile == nil {
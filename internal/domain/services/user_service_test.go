@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	stdErrors "errors"
+	"gold_portal/internal/domain/dto"
+	"gold_portal/internal/domain/entities"
+	"gold_portal/internal/errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// newTestUserService возвращает сервис поверх пользователей testOrganizationID и сессий в памяти
+func newTestUserService(t *testing.T, users ...*entities.User) (*userService, *fakeUserRepository, *fakeSessionRepository, *fakeAuditService) {
+	t.Helper()
+	sessions, sessionRepository, cache := newTestSessionService(t)
+	userRepository := newFakeUserRepository(users...)
+	sessions.userRepository = userRepository
+	organizations := newFakeOrganizationRepository(userRepository)
+	audit := &fakeAuditService{}
+	service := &userService{
+		usersRepository:        userRepository,
+		organizationRepository: organizations,
+		organizations:          NewOrganizationService(organizations, userRepository, sessions, audit),
+		permissionService:      NewPermissionService(&fakePermissionRepository{roles: entities.DefaultRolePermissions}, newFakeGroupRepository(), cache),
+		sessionService:         sessions,
+		auditService:           audit,
+	}
+	return service, userRepository, sessionRepository, audit
+}
+
+func TestPatchLogsOutOnRoleChangeAndDeactivation(t *testing.T) {
+	ctx := context.Background()
+	admin, inactive := entities.RoleAdmin, false
+	firstName, phone := "Азамат", "+996555000000"
+
+	tests := []struct {
+		name       string
+		request    dto.UserUpdateDTO
+		wantLogout bool
+	}{
+		{name: "role change", request: dto.UserUpdateDTO{Role: &admin}, wantLogout: true},
+		{name: "deactivation", request: dto.UserUpdateDTO{IsActive: &inactive}, wantLogout: true},
+		{name: "profile change", request: dto.UserUpdateDTO{FirstName: &firstName}},
+		{name: "phone change", request: dto.UserUpdateDTO{Phone: &phone}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actor := &entities.User{ID: uuid.New(), Role: entities.RoleAdmin, IsActive: true}
+			user := &entities.User{ID: uuid.New(), Phone: testPhone, Role: entities.RoleUser, IsActive: true, PhoneVerified: true}
+			service, _, repository, audit := newTestUserService(t, actor, user)
+			session, err := service.sessionService.Create(ctx, user.ID, testOrganizationID, "phone", "10.0.0.1", "", "", nil)
+			if err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+
+			response, err := service.Patch(ctx, actor.ID, testOrganizationID, user.ID, tt.request, nil)
+			if err != nil {
+				t.Fatalf("Patch: %v", err)
+			}
+
+			loggedOut := user.TokenVersion == 1 && repository.sessions[session.ID].RevokedAt != nil
+			if loggedOut != tt.wantLogout {
+				t.Errorf("logged out everywhere = %v, want %v (version %d)", loggedOut, tt.wantLogout, user.TokenVersion)
+			}
+			// Администратор видит в ответе и в журнале, что сессии отозваны
+			if response.SessionsRevoked != tt.wantLogout {
+				t.Errorf("sessions_revoked = %v, want %v", response.SessionsRevoked, tt.wantLogout)
+			}
+			if logged := len(audit.entries) == 1 && strings.HasPrefix(audit.entries[0], AuditActionSessionsRevoked); logged != tt.wantLogout {
+				t.Errorf("audit entries = %q, want sessions revoked entry: %v", audit.entries, tt.wantLogout)
+			}
+			// Новый номер нужно подтвердить заново
+			if wantVerified := tt.request.Phone == nil; user.PhoneVerified != wantVerified {
+				t.Errorf("phone verified = %v, want %v", user.PhoneVerified, wantVerified)
+			}
+		})
+	}
+}
+
+func TestDeleteLogsOutEverywhere(t *testing.T) {
+	ctx := context.Background()
+	actor := &entities.User{ID: uuid.New(), Role: entities.RoleAdmin, IsActive: true}
+	user := &entities.User{ID: uuid.New(), Phone: testPhone, Role: entities.RoleUser, IsActive: true}
+	service, users, repository, _ := newTestUserService(t, actor, user)
+	session, err := service.sessionService.Create(ctx, user.ID, testOrganizationID, "phone", "10.0.0.1", "", "", nil)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if err := service.Delete(ctx, actor.ID, testOrganizationID, user.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, ok := users.users[user.ID]; ok {
+		t.Error("user is not deleted")
+	}
+	if user.TokenVersion != 1 || repository.sessions[session.ID].RevokedAt == nil {
+		t.Error("deleted user is not logged out everywhere")
+	}
+}
+
+func TestUserServiceRefusesBroaderTargets(t *testing.T) {
+	ctx := context.Background()
+	firstName := "Азамат"
+
+	tests := []struct {
+		name       string
+		actorRole  entities.Role
+		targetRole entities.Role
+		wantErr    error
+	}{
+		{name: "admin changes user", actorRole: entities.RoleAdmin, targetRole: entities.RoleUser},
+		{name: "admin changes admin", actorRole: entities.RoleAdmin, targetRole: entities.RoleAdmin},
+		{name: "manager cannot change admin", actorRole: entities.RoleManager, targetRole: entities.RoleAdmin, wantErr: errors.ErrForbidden},
+		{name: "admin cannot change superuser", actorRole: entities.RoleAdmin, targetRole: entities.RoleSuperUser, wantErr: errors.ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actor := &entities.User{ID: uuid.New(), Role: tt.actorRole, IsActive: true}
+			target := &entities.User{ID: uuid.New(), Phone: testPhone, Role: tt.targetRole, IsActive: true}
+			service, users, _, _ := newTestUserService(t, actor, target)
+
+			// Та же проверка защищает сессии и блокировку входа в панели
+			if err := service.CheckTarget(ctx, actor.ID, testOrganizationID, target.ID); !stdErrors.Is(err, tt.wantErr) {
+				t.Fatalf("CheckTarget error = %v, want %v", err, tt.wantErr)
+			}
+
+			_, err := service.Patch(ctx, actor.ID, testOrganizationID, target.ID, dto.UserUpdateDTO{FirstName: &firstName}, nil)
+			if !stdErrors.Is(err, tt.wantErr) {
+				t.Fatalf("Patch error = %v, want %v", err, tt.wantErr)
+			}
+			if changed := target.FirstName == firstName; changed != (tt.wantErr == nil) {
+				t.Errorf("target changed = %v, want %v", changed, tt.wantErr == nil)
+			}
+
+			err = service.Delete(ctx, actor.ID, testOrganizationID, target.ID)
+			if !stdErrors.Is(err, tt.wantErr) {
+				t.Fatalf("Delete error = %v, want %v", err, tt.wantErr)
+			}
+			if _, exists := users.users[target.ID]; exists != (tt.wantErr != nil) {
+				t.Errorf("target exists after Delete = %v", exists)
+			}
+		})
+	}
+}
+
+func TestDeleteKeepsSuperUserAccount(t *testing.T) {
+	ctx := context.Background()
+	actor := &entities.User{ID: uuid.New(), Role: entities.RoleSuperUser, IsActive: true}
+	target := &entities.User{ID: uuid.New(), Phone: testPhone, Role: entities.RoleSuperUser, IsActive: true}
+	service, users, _, _ := newTestUserService(t, actor, target)
+
+	if err := service.Delete(ctx, actor.ID, testOrganizationID, target.ID); !stdErrors.Is(err, errors.ErrSuperUserDelete) {
+		t.Fatalf("Delete error = %v, want %v", err, errors.ErrSuperUserDelete)
+	}
+	if _, ok := users.users[target.ID]; !ok || target.TokenVersion != 0 {
+		t.Fatal("refused delete has side effects")
+	}
+
+	// Из одной из нескольких организаций superuser исключить можно: учётная запись остаётся
+	otherID := uuid.New()
+	service.organizationRepository.(*fakeOrganizationRepository).organizations[otherID] = &entities.Organization{ID: otherID, Slug: "acme"}
+	users.members = append(users.members, &entities.OrganizationMember{OrganizationID: otherID, UserID: target.ID, Role: entities.RoleSuperUser})
+	if err := service.Delete(ctx, actor.ID, testOrganizationID, target.ID); err != nil {
+		t.Fatalf("Delete from one of several organizations: %v", err)
+	}
+	if _, ok := users.users[target.ID]; !ok || users.member(testOrganizationID, target.ID) != nil {
+		t.Error("superuser is not just removed from the organization")
+	}
+}
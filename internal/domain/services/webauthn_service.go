@@ -0,0 +1,308 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"gold_portal/config"
+	"gold_portal/internal/domain/dto"
+	"gold_portal/internal/domain/entities"
+	"gold_portal/internal/domain/repositories"
+	"gold_portal/internal/errors"
+	"gold_portal/internal/pkg/crypto"
+	"strings"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/google/uuid"
+)
+
+// Вход ключом доступа: владение ключом и проверка пользователя на устройстве (RFC 8176)
+const AuthMethodPasskey = "hwk"
+
+type WebAuthnService interface {
+	// Возвращает параметры для navigator.credentials.create()
+	BeginRegistration(ctx context.Context, userID uuid.UUID) (*protocol.CredentialCreation, error)
+	// Проверяет ответ аутентификатора и сохраняет ключ доступа
+	FinishRegistration(ctx context.Context, userID uuid.UUID, request dto.WebAuthnRegisterFinishRequestDTO) (*dto.WebAuthnCredentialResponseDTO, error)
+	// Возвращает параметры для navigator.credentials.get() без указания пользователя
+	BeginLogin(ctx context.Context) (*dto.WebAuthnLoginBeginResponseDTO, error)
+	// Проверяет подпись ключа доступа и возвращает владельца ключа
+	FinishLogin(ctx context.Context, request dto.WebAuthnLoginFinishRequestDTO) (uuid.UUID, error)
+	GetCredentials(ctx context.Context, userID uuid.UUID) ([]*dto.WebAuthnCredentialResponseDTO, error)
+	DeleteCredential(ctx context.Context, userID, id uuid.UUID) error
+}
+
+type webAuthnService struct {
+	webAuthn             *webauthn.WebAuthn
+	userRepository       repositories.UserRepository
+	credentialRepository repositories.WebAuthnCredentialRepository
+	cache                Cache
+	config               *config.Config
+}
+
+// webAuthnUser связывает пользователя и его ключи с интерфейсом webauthn.User
+type webAuthnUser struct {
+	user        *entities.User
+	credentials []webauthn.Credential
+}
+
+func (u *webAuthnUser) WebAuthnID() []byte {
+	return u.user.ID[:]
+}
+
+func (u *webAuthnUser) WebAuthnName() string {
+	return u.user.Phone
+}
+
+func (u *webAuthnUser) WebAuthnDisplayName() string {
+	name := strings.TrimSpace(u.user.LastName + " " + u.user.FirstName)
+	if name == "" {
+		return u.user.Phone
+	}
+	return name
+}
+
+func (u *webAuthnUser) WebAuthnCredentials() []webauthn.Credential {
+	return u.credentials
+}
+
+func NewWebAuthnService(userRepository repositories.UserRepository, credentialRepository repositories.WebAuthnCredentialRepository, cache Cache, config *config.Config) (WebAuthnService, error) {
+	webAuthn, err := webauthn.New(&webauthn.Config{
+		RPID:          config.WebAuthn.RPID,
+		RPDisplayName: config.WebAuthn.RPDisplayName,
+		RPOrigins:     config.WebAuthn.RPOrigins,
+		// Ключ должен храниться на устройстве (passkey) и проверять пользователя:
+		// только так вход без пароля остаётся двухфакторным
+		AuthenticatorSelection: protocol.AuthenticatorSelection{
+			ResidentKey:        protocol.ResidentKeyRequirementRequired,
+			RequireResidentKey: protocol.ResidentKeyRequired(),
+			UserVerification:   protocol.VerificationRequired,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка настройки WebAuthn: %w", err)
+	}
+
+	return &webAuthnService{
+		webAuthn:             webAuthn,
+		userRepository:       userRepository,
+		credentialRepository: credentialRepository,
+		cache:                cache,
+		config:               config,
+	}, nil
+}
+
+func (s *webAuthnService) BeginRegistration(ctx context.Context, userID uuid.UUID) (*protocol.CredentialCreation, error) {
+	user, err := s.loadUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Не даём зарегистрировать один и тот же аутентификатор дважды
+	creation, session, err := s.webAuthn.BeginRegistration(user,
+		webauthn.WithExclusions(webauthn.Credentials(user.credentials).CredentialDescriptors()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка начала регистрации ключа: %w", err)
+	}
+
+	if err := s.saveSession(ctx, webAuthnRegistrationKey(userID), session); err != nil {
+		return nil, err
+	}
+	return creation, nil
+}
+
+func (s *webAuthnService) FinishRegistration(ctx context.Context, userID uuid.UUID, request dto.WebAuthnRegisterFinishRequestDTO) (*dto.WebAuthnCredentialResponseDTO, error) {
+	session, err := s.takeSession(ctx, webAuthnRegistrationKey(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.loadUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBytes(request.Credential)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrWebAuthnVerificationFailed, err)
+	}
+	credential, err := s.webAuthn.CreateCredential(user, *session, parsed)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errors.ErrWebAuthnVerificationFailed, err)
+	}
+
+	name := strings.TrimSpace(request.Name)
+	if name == "" {
+		name = "Ключ доступа"
+	}
+	record := &entities.WebAuthnCredential{
+		ID:           uuid.New(),
+		UserID:       userID,
+		CredentialID: credential.ID,
+		Name:         name,
+		Credential:   *credential,
+	}
+	if err := s.credentialRepository.Create(ctx, record); err != nil {
+		return nil, fmt.Errorf("ошибка сохранения ключа доступа: %w", err)
+	}
+
+	var response dto.WebAuthnCredentialResponseDTO
+	response.FromModel(record)
+	return &response, nil
+}
+
+func (s *webAuthnService) BeginLogin(ctx context.Context) (*dto.WebAuthnLoginBeginResponseDTO, error) {
+	assertion, session, err := s.webAuthn.BeginDiscoverableLogin(
+		webauthn.WithUserVerification(protocol.VerificationRequired),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка начала входа по ключу: %w", err)
+	}
+
+	challengeID, err := crypto.GenerateRandomString(32)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка генерации challenge_id: %w", err)
+	}
+	if err := s.saveSession(ctx, webAuthnLoginKey(challengeID), session); err != nil {
+		return nil, err
+	}
+
+	return &dto.WebAuthnLoginBeginResponseDTO{
+		ChallengeID: challengeID,
+		Options:     assertion,
+	}, nil
+}
+
+func (s *webAuthnService) FinishLogin(ctx context.Context, request dto.WebAuthnLoginFinishRequestDTO) (uuid.UUID, error) {
+	session, err := s.takeSession(ctx, webAuthnLoginKey(request.ChallengeID))
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(request.Credential)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%w: %v", errors.ErrWebAuthnVerificationFailed, err)
+	}
+
+	// Пользователь определяется по ключу, который выбрал аутентификатор
+	var stored *entities.WebAuthnCredential
+	findUser := func(rawID, userHandle []byte) (webauthn.User, error) {
+		record, err := s.credentialRepository.GetByCredentialID(ctx, rawID)
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(record.UserID[:], userHandle) {
+			return nil, errors.ErrWebAuthnCredentialNotFound
+		}
+		stored = record
+		return s.loadUser(ctx, record.UserID)
+	}
+
+	_, credential, err := s.webAuthn.ValidatePasskeyLogin(findUser, *session, parsed)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%w: %v", errors.ErrWebAuthnVerificationFailed, err)
+	}
+	// Счётчик подписей не вырос: возможно, ключ скопирован
+	if credential.Authenticator.CloneWarning {
+		return uuid.Nil, fmt.Errorf("%w: authenticator sign count mismatch", errors.ErrWebAuthnVerificationFailed)
+	}
+
+	if err := s.credentialRepository.UpdateAfterLogin(ctx, stored.ID, *credential); err != nil {
+		return uuid.Nil, err
+	}
+	return stored.UserID, nil
+}
+
+func (s *webAuthnService) GetCredentials(ctx context.Context, userID uuid.UUID) ([]*dto.WebAuthnCredentialResponseDTO, error) {
+	credentials, err := s.credentialRepository.GetByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]*dto.WebAuthnCredentialResponseDTO, 0, len(credentials))
+	for _, credential := range credentials {
+		var item dto.WebAuthnCredentialResponseDTO
+		item.FromModel(credential)
+		response = append(response, &item)
+	}
+	return response, nil
+}
+
+func (s *webAuthnService) DeleteCredential(ctx context.Context, userID, id uuid.UUID) error {
+	return s.credentialRepository.Delete(ctx, userID, id)
+}
+
+func (s *webAuthnService) loadUser(ctx context.Context, userID uuid.UUID) (*webAuthnUser, error) {
+	user, err := s.userRepository.GetID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := s.credentialRepository.GetByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	credentials := make([]webauthn.Credential, 0, len(records))
+	for _, record := range records {
+		credentials = append(credentials, record.Credential)
+	}
+
+	return &webAuthnUser{user: user, credentials: credentials}, nil
+}
+
+func (s *webAuthnService) saveSession(ctx context.Context, key string, session *webauthn.SessionData) error {
+	record, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	if err := s.cache.Set(ctx, key, string(record), s.config.WebAuthn.ChallengeExpiry); err != nil {
+		return fmt.Errorf("ошибка сохранения challenge WebAuthn: %w", err)
+	}
+	// Ключ регистрации общий для всех церемоний пользователя: отметка об использовании
+	// прошлого challenge не должна отклонять новый
+	if err := s.cache.Delete(ctx, webAuthnUsedKey(key)); err != nil {
+		return fmt.Errorf("ошибка сохранения challenge WebAuthn: %w", err)
+	}
+	return nil
+}
+
+// takeSession достаёт данные церемонии и сразу удаляет их: challenge одноразовый.
+// Get и Delete не атомарны, поэтому challenge забирает только тот запрос,
+// который первым поставил отметку об использовании
+func (s *webAuthnService) takeSession(ctx context.Context, key string) (*webauthn.SessionData, error) {
+	value, err := s.cache.Get(ctx, key)
+	if err != nil {
+		return nil, errors.ErrWebAuthnChallengeInvalid
+	}
+	first, err := s.cache.SetNX(ctx, webAuthnUsedKey(key), "1", s.config.WebAuthn.ChallengeExpiry)
+	if err != nil {
+		return nil, err
+	}
+	if !first {
+		return nil, errors.ErrWebAuthnChallengeInvalid
+	}
+	if err := s.cache.Delete(ctx, key); err != nil {
+		return nil, err
+	}
+
+	var session webauthn.SessionData
+	if err := json.Unmarshal([]byte(value), &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func webAuthnRegistrationKey(userID uuid.UUID) string {
+	return fmt.Sprintf("webauthn_registration:%s", userID)
+}
+
+func webAuthnLoginKey(challengeID string) string {
+	return fmt.Sprintf("webauthn_login:%s", challengeID)
+}
+
+func webAuthnUsedKey(key string) string {
+	return key + ":used"
+}
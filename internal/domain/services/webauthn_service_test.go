@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	stdErrors "errors"
+	"gold_portal/config"
+	"gold_portal/internal/domain/dto"
+	"gold_portal/internal/domain/entities"
+	"gold_portal/internal/domain/repositories"
+	"gold_portal/internal/errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type fakeWebAuthnCredentialRepository struct {
+	repositories.WebAuthnCredentialRepository
+}
+
+func (r *fakeWebAuthnCredentialRepository) GetByUser(_ context.Context, _ uuid.UUID) ([]*entities.WebAuthnCredential, error) {
+	return nil, nil
+}
+
+func newTestWebAuthnService(t *testing.T, users ...*entities.User) *webAuthnService {
+	t.Helper()
+	cache, _ := newTestCache(t)
+	service, err := NewWebAuthnService(newFakeUserRepository(users...), &fakeWebAuthnCredentialRepository{}, cache, &config.Config{
+		WebAuthn: config.WebAuthnConfig{
+			RPID:            "id.example",
+			RPDisplayName:   "Gold Portal",
+			RPOrigins:       []string{"https://id.example"},
+			ChallengeExpiry: time.Minute,
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewWebAuthnService: %v", err)
+	}
+	return service.(*webAuthnService)
+}
+
+func TestWebAuthnLoginChallengeIsSingleUse(t *testing.T) {
+	ctx := context.Background()
+	service := newTestWebAuthnService(t)
+
+	begin, err := service.BeginLogin(ctx)
+	if err != nil {
+		t.Fatalf("BeginLogin: %v", err)
+	}
+	if begin.ChallengeID == "" || begin.Options == nil {
+		t.Fatalf("BeginLogin = %+v, want challenge id and options", begin)
+	}
+
+	// Неудачная проверка ответа всё равно расходует challenge
+	request := dto.WebAuthnLoginFinishRequestDTO{ChallengeID: begin.ChallengeID, Credential: []byte(`{}`)}
+	if _, err := service.FinishLogin(ctx, request); !stdErrors.Is(err, errors.ErrWebAuthnVerificationFailed) {
+		t.Fatalf("FinishLogin error = %v, want %v", err, errors.ErrWebAuthnVerificationFailed)
+	}
+	if _, err := service.FinishLogin(ctx, request); !stdErrors.Is(err, errors.ErrWebAuthnChallengeInvalid) {
+		t.Errorf("second FinishLogin error = %v, want %v", err, errors.ErrWebAuthnChallengeInvalid)
+	}
+
+	unknown := dto.WebAuthnLoginFinishRequestDTO{ChallengeID: "unknown", Credential: []byte(`{}`)}
+	if _, err := service.FinishLogin(ctx, unknown); !stdErrors.Is(err, errors.ErrWebAuthnChallengeInvalid) {
+		t.Errorf("FinishLogin with unknown challenge error = %v, want %v", err, errors.ErrWebAuthnChallengeInvalid)
+	}
+}
+
+func TestWebAuthnRegistrationChallengeIsSingleUse(t *testing.T) {
+	ctx := context.Background()
+	user := &entities.User{ID: uuid.New(), Phone: "+996555123456", IsActive: true}
+	service := newTestWebAuthnService(t, user)
+
+	request := dto.WebAuthnRegisterFinishRequestDTO{Name: "phone", Credential: []byte(`{}`)}
+	if _, err := service.FinishRegistration(ctx, user.ID, request); !stdErrors.Is(err, errors.ErrWebAuthnChallengeInvalid) {
+		t.Fatalf("FinishRegistration without challenge error = %v, want %v", err, errors.ErrWebAuthnChallengeInvalid)
+	}
+
+	if _, err := service.BeginRegistration(ctx, user.ID); err != nil {
+		t.Fatalf("BeginRegistration: %v", err)
+	}
+	if _, err := service.FinishRegistration(ctx, user.ID, request); !stdErrors.Is(err, errors.ErrWebAuthnVerificationFailed) {
+		t.Fatalf("FinishRegistration error = %v, want %v", err, errors.ErrWebAuthnVerificationFailed)
+	}
+	if _, err := service.FinishRegistration(ctx, user.ID, request); !stdErrors.Is(err, errors.ErrWebAuthnChallengeInvalid) {
+		t.Errorf("second FinishRegistration error = %v, want %v", err, errors.ErrWebAuthnChallengeInvalid)
+	}
+
+	// Новая церемония регистрации снова принимает ответ
+	if _, err := service.BeginRegistration(ctx, user.ID); err != nil {
+		t.Fatalf("second BeginRegistration: %v", err)
+	}
+	if _, err := service.FinishRegistration(ctx, user.ID, request); !stdErrors.Is(err, errors.ErrWebAuthnVerificationFailed) {
+		t.Errorf("FinishRegistration after new ceremony error = %v, want %v", err, errors.ErrWebAuthnVerificationFailed)
+	}
+}
@@ -19,6 +19,8 @@ var (
 	ErrInvalidUserID      = errors.New("invalid user id")
 	ErrAccountBlocked     = errors.New("account blocked")
 	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrInvalidPassword    = errors.New("current password is incorrect")
+	ErrForbidden          = errors.New("insufficient privileges")
 )
 
 var (
@@ -26,4 +28,69 @@ var (
 	ErrEXP          = errors.New("expired token")
 	ErrJTI          = errors.New("invalid jti")
 	ErrTokenConfig  = errors.New("token config error")
+
+	ErrRefreshTokenReused = errors.New("refresh token reuse detected")
+)
+
+var (
+	ErrSessionNotFound = errors.New("session not found")
+	ErrSessionRevoked  = errors.New("session revoked")
+	// Токен выдан до выхода пользователя со всех устройств
+	ErrTokenRevoked = errors.New("token revoked")
+)
+
+var (
+	ErrOAuthClientNotFound = errors.New("oauth client not found")
+)
+
+var (
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication already enabled")
+	ErrTwoFactorNotEnabled     = errors.New("two-factor authentication not enabled")
+	ErrTwoFactorNotEnrolled    = errors.New("two-factor enrollment not started")
+	ErrTwoFactorRequired       = errors.New("two-factor authentication is required for this role")
+	ErrInvalidTwoFactorCode    = errors.New("invalid two-factor code")
+	ErrMFAChallengeInvalid     = errors.New("mfa challenge is invalid or expired")
+)
+
+var (
+	ErrInvalidOTP         = errors.New("invalid or expired one-time code")
+	ErrOTPTooManyRequests = errors.New("one-time code was sent recently, try again later")
+)
+
+var (
+	ErrWebAuthnChallengeInvalid   = errors.New("webauthn challenge is invalid or expired")
+	ErrWebAuthnCredentialNotFound = errors.New("webauthn credential not found")
+	ErrWebAuthnVerificationFailed = errors.New("webauthn verification failed")
+)
+
+var (
+	ErrAPIKeyNotFound      = errors.New("api key not found")
+	ErrAPIKeyInvalid       = errors.New("invalid, expired or revoked api key")
+	ErrAPIKeyInvalidScope  = errors.New("unknown api key scope")
+	ErrAPIKeyInvalidExpiry = errors.New("invalid api key expiry")
+	ErrAPIKeyLimitReached  = errors.New("api key limit reached")
+)
+
+var (
+	ErrRoleNotFound       = errors.New("role not found")
+	ErrRoleExists         = errors.New("role already exists")
+	ErrRoleInUse          = errors.New("role is assigned to users or clients")
+	ErrRoleBuiltIn        = errors.New("built-in role cannot be renamed or deleted")
+	ErrInvalidRoleName    = errors.New("invalid role name")
+	ErrUnknownPermission  = errors.New("unknown permission")
+	ErrRoleRankTooHigh    = errors.New("role rank is higher than actor's rank")
+	ErrSuperUserLockedOut = errors.New("superuser role must keep roles:manage permission")
+)
+
+var (
+	ErrOrganizationNotFound    = errors.New("organization not found")
+	ErrOrganizationExists      = errors.New("organization already exists")
+	ErrInvalidOrganizationSlug = errors.New("invalid organization slug")
+	ErrNotOrganizationMember   = errors.New("user is not a member of the organization")
+	ErrSuperUserDelete         = errors.New("superuser account cannot be deleted")
+)
+
+var (
+	ErrGroupNotFound = errors.New("group not found")
+	ErrGroupExists   = errors.New("group already exists")
 )
@@ -0,0 +1,31 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrLoginLocked = errors.New("too many failed login attempts")
+
+// Область, для которой временно запрещён вход
+const (
+	LockoutScopeAccount = "account"
+	LockoutScopeIP      = "ip"
+)
+
+// LockoutError вход временно запрещён: после серии неудачных попыток нужно выждать
+// задержку (Locked = false) или окончания блокировки (Locked = true)
+type LockoutError struct {
+	Scope      string
+	Locked     bool
+	RetryAfter time.Duration
+}
+
+func (e *LockoutError) Error() string {
+	return fmt.Sprintf("%s: %s, retry after %ds", ErrLoginLocked, e.Scope, int(e.RetryAfter.Seconds()))
+}
+
+func (e *LockoutError) Is(target error) bool {
+	return target == ErrLoginLocked
+}
@@ -0,0 +1,43 @@
+package errors
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrPasswordPolicy = errors.New("password does not meet the policy")
+
+// Коды нарушений политики паролей
+const (
+	PasswordTooShort      = "PASSWORD_TOO_SHORT"
+	PasswordTooLong       = "PASSWORD_TOO_LONG"
+	PasswordMissingUpper  = "PASSWORD_MISSING_UPPERCASE"
+	PasswordMissingLower  = "PASSWORD_MISSING_LOWERCASE"
+	PasswordMissingDigit  = "PASSWORD_MISSING_DIGIT"
+	PasswordMissingSymbol = "PASSWORD_MISSING_SYMBOL"
+	PasswordContainsPhone = "PASSWORD_CONTAINS_PHONE"
+	PasswordReused        = "PASSWORD_REUSED"
+	PasswordBreached      = "PASSWORD_BREACHED"
+)
+
+type PasswordViolation struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// PasswordPolicyError пароль не прошёл проверку; Violations перечисляет все нарушения сразу
+type PasswordPolicyError struct {
+	Violations []PasswordViolation
+}
+
+func (e *PasswordPolicyError) Error() string {
+	codes := make([]string, 0, len(e.Violations))
+	for _, violation := range e.Violations {
+		codes = append(codes, violation.Code)
+	}
+	return ErrPasswordPolicy.Error() + ": " + strings.Join(codes, ", ")
+}
+
+func (e *PasswordPolicyError) Is(target error) bool {
+	return target == ErrPasswordPolicy
+}
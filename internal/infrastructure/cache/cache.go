@@ -0,0 +1,43 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gold_portal/config"
+	"time"
+)
+
+var ErrKeyNotFound = errors.New("key not found")
+
+// RedisCache хранилище с поддержкой TTL. Обе реализации ведут себя как Redis:
+// нулевой срок жизни означает хранение без ограничения, Incr не меняет TTL ключа
+type RedisCache interface {
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
+	// Возвращает ErrKeyNotFound, если ключа нет или срок его жизни истёк
+	Get(ctx context.Context, key string) (string, error)
+	Delete(ctx context.Context, key string) error
+	Exists(ctx context.Context, key string) (bool, error)
+	SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error)
+	Incr(ctx context.Context, key string) (int64, error)
+	// Возвращает ErrKeyNotFound, если ключа нет
+	Expire(ctx context.Context, key string, expiration time.Duration) error
+	Close() error
+}
+
+// NewRedisCache создаёт хранилище по REDIS_DRIVER: redis или memory
+func NewRedisCache(cfg *config.Config) (RedisCache, error) {
+	switch cfg.Redis.Driver {
+	case "", "redis":
+		return newRedisCache(cfg)
+	case "memory":
+		return newMemoryCache(time.Now), nil
+	default:
+		return nil, fmt.Errorf("unknown cache driver %q", cfg.Redis.Driver)
+	}
+}
+
+// formatValue приводит значение к строке одинаково для обеих реализаций
+func formatValue(value interface{}) string {
+	return fmt.Sprintf("%v", value)
+}
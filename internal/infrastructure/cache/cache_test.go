@@ -0,0 +1,268 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"gold_portal/config"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+// cacheFactory создаёт пустое хранилище и функцию, сдвигающую его часы вперёд
+type cacheFactory func(t *testing.T) (RedisCache, func(time.Duration))
+
+func TestMemoryCache(t *testing.T) {
+	runConformance(t, func(t *testing.T) (RedisCache, func(time.Duration)) {
+		clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+		cache := newMemoryCache(clock.Now)
+		t.Cleanup(func() { _ = cache.Close() })
+		return cache, clock.Advance
+	})
+}
+
+func TestRedisCache(t *testing.T) {
+	runConformance(t, func(t *testing.T) (RedisCache, func(time.Duration)) {
+		server := miniredis.RunT(t)
+		cache, err := NewRedisCache(&config.Config{Redis: config.RedisConfig{
+			Driver:          "redis",
+			Host:            server.Host(),
+			Port:            server.Port(),
+			ConnectAttempts: 1,
+		}})
+		if err != nil {
+			t.Fatalf("NewRedisCache: %v", err)
+		}
+		t.Cleanup(func() { _ = cache.Close() })
+		return cache, server.FastForward
+	})
+}
+
+func TestRedisCacheRequiresPassword(t *testing.T) {
+	server := miniredis.RunT(t)
+	server.RequireAuth("secret")
+
+	cfg := &config.Config{Redis: config.RedisConfig{
+		Driver:          "redis",
+		Host:            server.Host(),
+		Port:            server.Port(),
+		ConnectAttempts: 1,
+	}}
+	if _, err := NewRedisCache(cfg); err == nil {
+		t.Fatal("expected startup ping to fail without password")
+	}
+
+	cfg.Redis.Password = "secret"
+	cache, err := NewRedisCache(cfg)
+	if err != nil {
+		t.Fatalf("NewRedisCache with password: %v", err)
+	}
+	_ = cache.Close()
+}
+
+func TestNewRedisCacheUnknownDriver(t *testing.T) {
+	if _, err := NewRedisCache(&config.Config{Redis: config.RedisConfig{Driver: "memcached"}}); err == nil {
+		t.Fatal("expected error for unknown driver")
+	}
+}
+
+func runConformance(t *testing.T, newCache cacheFactory) {
+	ctx := context.Background()
+
+	t.Run("GetMissing", func(t *testing.T) {
+		cache, _ := newCache(t)
+		if _, err := cache.Get(ctx, "missing"); !errors.Is(err, ErrKeyNotFound) {
+			t.Fatalf("Get missing: got %v, want ErrKeyNotFound", err)
+		}
+	})
+
+	t.Run("SetGet", func(t *testing.T) {
+		cache, _ := newCache(t)
+		if err := cache.Set(ctx, "string", "value", time.Minute); err != nil {
+			t.Fatalf("Set: %v", err)
+		}
+		if err := cache.Set(ctx, "int", 42, time.Minute); err != nil {
+			t.Fatalf("Set: %v", err)
+		}
+		assertValue(t, cache, "string", "value")
+		assertValue(t, cache, "int", "42")
+	})
+
+	t.Run("SetOverwrites", func(t *testing.T) {
+		cache, _ := newCache(t)
+		_ = cache.Set(ctx, "key", "first", time.Minute)
+		_ = cache.Set(ctx, "key", "second", time.Minute)
+		assertValue(t, cache, "key", "second")
+	})
+
+	t.Run("SetExpires", func(t *testing.T) {
+		cache, advance := newCache(t)
+		_ = cache.Set(ctx, "key", "value", time.Minute)
+
+		advance(30 * time.Second)
+		assertValue(t, cache, "key", "value")
+
+		advance(31 * time.Second)
+		assertMissing(t, cache, "key")
+	})
+
+	t.Run("SetWithoutExpiration", func(t *testing.T) {
+		cache, advance := newCache(t)
+		_ = cache.Set(ctx, "key", "value", 0)
+
+		advance(365 * 24 * time.Hour)
+		assertValue(t, cache, "key", "value")
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		cache, _ := newCache(t)
+		_ = cache.Set(ctx, "key", "value", time.Minute)
+		if err := cache.Delete(ctx, "key"); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		assertMissing(t, cache, "key")
+
+		if err := cache.Delete(ctx, "key"); err != nil {
+			t.Fatalf("Delete missing: %v", err)
+		}
+	})
+
+	t.Run("SetNX", func(t *testing.T) {
+		cache, advance := newCache(t)
+
+		ok, err := cache.SetNX(ctx, "key", "first", time.Minute)
+		if err != nil || !ok {
+			t.Fatalf("first SetNX: ok=%v err=%v", ok, err)
+		}
+		ok, err = cache.SetNX(ctx, "key", "second", time.Minute)
+		if err != nil || ok {
+			t.Fatalf("second SetNX: ok=%v err=%v", ok, err)
+		}
+		assertValue(t, cache, "key", "first")
+
+		// После истечения срока ключ можно занять снова
+		advance(2 * time.Minute)
+		ok, err = cache.SetNX(ctx, "key", "third", time.Minute)
+		if err != nil || !ok {
+			t.Fatalf("SetNX after expiry: ok=%v err=%v", ok, err)
+		}
+		assertValue(t, cache, "key", "third")
+	})
+
+	t.Run("Incr", func(t *testing.T) {
+		cache, _ := newCache(t)
+		for want := int64(1); want <= 3; want++ {
+			got, err := cache.Incr(ctx, "counter")
+			if err != nil {
+				t.Fatalf("Incr: %v", err)
+			}
+			if got != want {
+				t.Fatalf("Incr: got %d, want %d", got, want)
+			}
+		}
+		assertValue(t, cache, "counter", "3")
+	})
+
+	t.Run("IncrKeepsTTL", func(t *testing.T) {
+		cache, advance := newCache(t)
+		_, _ = cache.Incr(ctx, "counter")
+		if err := cache.Expire(ctx, "counter", time.Minute); err != nil {
+			t.Fatalf("Expire: %v", err)
+		}
+
+		advance(40 * time.Second)
+		if got, _ := cache.Incr(ctx, "counter"); got != 2 {
+			t.Fatalf("Incr: got %d, want 2", got)
+		}
+
+		// Incr не продлевает срок: счётчик исчезает через минуту после Expire
+		advance(21 * time.Second)
+		assertMissing(t, cache, "counter")
+		if got, _ := cache.Incr(ctx, "counter"); got != 1 {
+			t.Fatalf("Incr after expiry: got %d, want 1", got)
+		}
+	})
+
+	t.Run("IncrNotInteger", func(t *testing.T) {
+		cache, _ := newCache(t)
+		_ = cache.Set(ctx, "key", "value", time.Minute)
+		if _, err := cache.Incr(ctx, "key"); err == nil {
+			t.Fatal("Incr of non-integer value: expected error")
+		}
+	})
+
+	t.Run("IncrConcurrent", func(t *testing.T) {
+		cache, _ := newCache(t)
+		const workers = 50
+
+		var wg sync.WaitGroup
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := cache.Incr(ctx, "counter"); err != nil {
+					t.Errorf("Incr: %v", err)
+				}
+			}()
+		}
+		wg.Wait()
+		assertValue(t, cache, "counter", "50")
+	})
+
+	t.Run("ExpireMissing", func(t *testing.T) {
+		cache, _ := newCache(t)
+		if err := cache.Expire(ctx, "missing", time.Minute); !errors.Is(err, ErrKeyNotFound) {
+			t.Fatalf("Expire missing: got %v, want ErrKeyNotFound", err)
+		}
+	})
+
+	t.Run("Exists", func(t *testing.T) {
+		cache, advance := newCache(t)
+		_ = cache.Set(ctx, "key", "value", time.Minute)
+
+		if ok, err := cache.Exists(ctx, "key"); err != nil || !ok {
+			t.Fatalf("Exists: ok=%v err=%v", ok, err)
+		}
+		advance(2 * time.Minute)
+		if ok, err := cache.Exists(ctx, "key"); err != nil || ok {
+			t.Fatalf("Exists after expiry: ok=%v err=%v", ok, err)
+		}
+	})
+}
+
+func assertValue(t *testing.T, cache RedisCache, key, want string) {
+	t.Helper()
+	got, err := cache.Get(context.Background(), key)
+	if err != nil {
+		t.Fatalf("Get %q: %v", key, err)
+	}
+	if got != want {
+		t.Fatalf("Get %q: got %q, want %q", key, got, want)
+	}
+}
+
+func assertMissing(t *testing.T, cache RedisCache, key string) {
+	t.Helper()
+	if _, err := cache.Get(context.Background(), key); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("Get %q: got %v, want ErrKeyNotFound", key, err)
+	}
+}
+
+type fakeClock struct {
+	mutex sync.Mutex
+	now   time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = c.now.Add(d)
+}
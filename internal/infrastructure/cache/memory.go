@@ -0,0 +1,174 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// memoryCache хранит данные в памяти процесса: данные теряются при перезапуске
+// и не видны другим экземплярам сервиса. Подходит для разработки и тестов
+type memoryCache struct {
+	store map[string]cacheItem
+	mutex sync.RWMutex
+	now   func() time.Time
+	done  chan struct{}
+	once  sync.Once
+}
+
+type cacheItem struct {
+	value string
+	// Нулевое время — ключ хранится без ограничения срока
+	expiration time.Time
+}
+
+func (i cacheItem) expired(now time.Time) bool {
+	return !i.expiration.IsZero() && !now.Before(i.expiration)
+}
+
+func newMemoryCache(now func() time.Time) *memoryCache {
+	cache := &memoryCache{
+		store: make(map[string]cacheItem),
+		now:   now,
+		done:  make(chan struct{}),
+	}
+
+	// Запускаем очистку устаревших записей
+	go cache.cleanupExpired()
+
+	return cache
+}
+
+func (r *memoryCache) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.store[key] = cacheItem{
+		value:      formatValue(value),
+		expiration: r.expiresAt(expiration),
+	}
+	return nil
+}
+
+func (r *memoryCache) Get(ctx context.Context, key string) (string, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	item, exists := r.store[key]
+	if !exists || item.expired(r.now()) {
+		return "", ErrKeyNotFound
+	}
+	return item.value, nil
+}
+
+func (r *memoryCache) Delete(ctx context.Context, key string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.store, key)
+	return nil
+}
+
+func (r *memoryCache) Exists(ctx context.Context, key string) (bool, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	item, exists := r.store[key]
+	return exists && !item.expired(r.now()), nil
+}
+
+func (r *memoryCache) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if item, exists := r.store[key]; exists && !item.expired(r.now()) {
+		return false, nil
+	}
+
+	r.store[key] = cacheItem{
+		value:      formatValue(value),
+		expiration: r.expiresAt(expiration),
+	}
+	return true, nil
+}
+
+func (r *memoryCache) Incr(ctx context.Context, key string) (int64, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	item, exists := r.store[key]
+	if !exists || item.expired(r.now()) {
+		// Как и в Redis, новый ключ создаётся без срока жизни
+		item = cacheItem{value: "0"}
+	}
+
+	current, err := strconv.ParseInt(item.value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("value is not an integer")
+	}
+
+	current++
+	item.value = strconv.FormatInt(current, 10)
+	r.store[key] = item
+	return current, nil
+}
+
+func (r *memoryCache) Expire(ctx context.Context, key string, expiration time.Duration) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	item, exists := r.store[key]
+	if !exists || item.expired(r.now()) {
+		return ErrKeyNotFound
+	}
+
+	// Как и EXPIRE в Redis, неположительный срок удаляет ключ
+	if expiration <= 0 {
+		delete(r.store, key)
+		return nil
+	}
+	item.expiration = r.now().Add(expiration)
+	r.store[key] = item
+	return nil
+}
+
+func (r *memoryCache) Close() error {
+	r.once.Do(func() { close(r.done) })
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.store = make(map[string]cacheItem)
+	return nil
+}
+
+func (r *memoryCache) expiresAt(expiration time.Duration) time.Time {
+	if expiration <= 0 {
+		return time.Time{}
+	}
+	return r.now().Add(expiration)
+}
+
+func (r *memoryCache) cleanupExpired() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+		}
+
+		r.mutex.Lock()
+		now := r.now()
+		for key, item := range r.store {
+			if item.expired(now) {
+				delete(r.store, key)
+			}
+		}
+		r.mutex.Unlock()
+	}
+}
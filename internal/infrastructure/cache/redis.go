@@ -2,173 +2,112 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"gold_portal/config"
-	"sync"
+	"net"
 	"time"
-)
 
-// Простая in-memory реализация для демонстрации
-// В продакшене заменить на реальный Redis клиент
-type RedisCache interface {
-	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
-	Get(ctx context.Context, key string) (string, error)
-	Delete(ctx context.Context, key string) error
-	Exists(ctx context.Context, key string) (bool, error)
-	SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error)
-	Incr(ctx context.Context, key string) (int64, error)
-	Expire(ctx context.Context, key string, expiration time.Duration) error
-	Close() error
-}
+	"github.com/redis/go-redis/v9"
+)
 
 type redisCache struct {
-	store map[string]cacheItem
-	mutex sync.RWMutex
-}
-
-type cacheItem struct {
-	value      string
-	expiration time.Time
+	client *redis.Client
 }
 
-func NewRedisCache(cfg *config.Config) (RedisCache, error) {
+func newRedisCache(cfg *config.Config) (*redisCache, error) {
 	if cfg.Redis.Host == "" {
 		return nil, fmt.Errorf("redis host is required")
 	}
 
-	cache := &redisCache{
-		store: make(map[string]cacheItem),
+	// Пул соединений и повторы команд при сетевых ошибках берёт на себя клиент
+	client := redis.NewClient(&redis.Options{
+		Addr:         net.JoinHostPort(cfg.Redis.Host, cfg.Redis.Port),
+		Password:     cfg.Redis.Password,
+		DB:           cfg.Redis.DB,
+		PoolSize:     cfg.Redis.PoolSize,
+		MinIdleConns: cfg.Redis.MinIdleConns,
+		MaxRetries:   cfg.Redis.MaxRetries,
+		DialTimeout:  cfg.Redis.DialTimeout,
+		ReadTimeout:  cfg.Redis.ReadTimeout,
+		WriteTimeout: cfg.Redis.WriteTimeout,
+	})
+
+	if err := ping(client, cfg.Redis.ConnectAttempts); err != nil {
+		_ = client.Close()
+		return nil, err
 	}
+	return &redisCache{client: client}, nil
+}
 
-	// Запускаем очистку устаревших записей
-	go cache.cleanupExpired()
+// ping ждёт, пока Redis ответит: при совместном запуске контейнеров он может подняться позже сервиса
+func ping(client *redis.Client, attempts int) error {
+	if attempts < 1 {
+		attempts = 1
+	}
 
-	return cache, nil
+	var err error
+	delay := time.Second
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err = client.Ping(context.Background()).Err(); err == nil {
+			return nil
+		}
+		if attempt < attempts {
+			time.Sleep(delay)
+			delay *= 2
+		}
+	}
+	return fmt.Errorf("redis is not available after %d attempts: %w", attempts, err)
 }
 
 func (r *redisCache) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
-	r.mutex.Lock()
-	defer r.mutex.Unlock()
-
-	expTime := time.Now().Add(expiration)
-	r.store[key] = cacheItem{
-		value:      fmt.Sprintf("%v", value),
-		expiration: expTime,
-	}
-	return nil
+	return r.client.Set(ctx, key, formatValue(value), ttl(expiration)).Err()
 }
 
 func (r *redisCache) Get(ctx context.Context, key string) (string, error) {
-	r.mutex.RLock()
-	defer r.mutex.RUnlock()
-
-	item, exists := r.store[key]
-	if !exists {
-		return "", fmt.Errorf("key not found")
+	value, err := r.client.Get(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", ErrKeyNotFound
 	}
-
-	if time.Now().After(item.expiration) {
-		delete(r.store, key)
-		return "", fmt.Errorf("key expired")
-	}
-
-	return item.value, nil
+	return value, err
 }
 
 func (r *redisCache) Delete(ctx context.Context, key string) error {
-	r.mutex.Lock()
-	defer r.mutex.Unlock()
-
-	delete(r.store, key)
-	return nil
+	return r.client.Del(ctx, key).Err()
 }
 
 func (r *redisCache) Exists(ctx context.Context, key string) (bool, error) {
-	r.mutex.RLock()
-	defer r.mutex.RUnlock()
-
-	item, exists := r.store[key]
-	if !exists {
-		return false, nil
-	}
-
-	if time.Now().After(item.expiration) {
-		delete(r.store, key)
-		return false, nil
-	}
-
-	return true, nil
+	count, err := r.client.Exists(ctx, key).Result()
+	return count > 0, err
 }
 
 func (r *redisCache) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
-	r.mutex.Lock()
-	defer r.mutex.Unlock()
-
-	if _, exists := r.store[key]; exists {
-		return false, nil
-	}
-
-	expTime := time.Now().Add(expiration)
-	r.store[key] = cacheItem{
-		value:      fmt.Sprintf("%v", value),
-		expiration: expTime,
-	}
-	return true, nil
+	return r.client.SetNX(ctx, key, formatValue(value), ttl(expiration)).Result()
 }
 
 func (r *redisCache) Incr(ctx context.Context, key string) (int64, error) {
-	r.mutex.Lock()
-	defer r.mutex.Unlock()
-
-	item, exists := r.store[key]
-	var current int64 = 0
-	if exists {
-		fmt.Sscanf(item.value, "%d", &current)
-	}
-
-	current++
-	expTime := time.Now().Add(time.Hour * 24) // По умолчанию 24 часа
-	r.store[key] = cacheItem{
-		value:      fmt.Sprintf("%d", current),
-		expiration: expTime,
-	}
-	return current, nil
+	return r.client.Incr(ctx, key).Result()
 }
 
 func (r *redisCache) Expire(ctx context.Context, key string, expiration time.Duration) error {
-	r.mutex.Lock()
-	defer r.mutex.Unlock()
-
-	item, exists := r.store[key]
-	if !exists {
-		return fmt.Errorf("key not found")
+	ok, err := r.client.Expire(ctx, key, expiration).Result()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrKeyNotFound
 	}
-
-	item.expiration = time.Now().Add(expiration)
-	r.store[key] = item
 	return nil
 }
 
 func (r *redisCache) Close() error {
-	r.mutex.Lock()
-	defer r.mutex.Unlock()
-
-	r.store = make(map[string]cacheItem)
-	return nil
+	return r.client.Close()
 }
 
-func (r *redisCache) cleanupExpired() {
-	ticker := time.NewTicker(time.Minute)
-	defer ticker.Stop()
-
-	for range ticker.C {
-		r.mutex.Lock()
-		now := time.Now()
-		for key, item := range r.store {
-			if now.After(item.expiration) {
-				delete(r.store, key)
-			}
-		}
-		r.mutex.Unlock()
+// ttl: у go-redis отрицательный срок означает KEEPTTL, а нам нужно «без ограничения»
+func ttl(expiration time.Duration) time.Duration {
+	if expiration < 0 {
+		return 0
 	}
+	return expiration
 }
@@ -4,9 +4,12 @@ import (
 	"fmt"
 	"gold_portal/config"
 	"gold_portal/internal/domain/entities"
+	"gold_portal/internal/pkg/crypto"
 
+	"github.com/google/uuid"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 func InitDB(cfg *config.Config) (*gorm.DB, error) {
@@ -42,12 +45,36 @@ func InitDB(cfg *config.Config) (*gorm.DB, error) {
 	err = db.AutoMigrate(
 		&entities.User{},
 		&entities.AuditLog{},
+		&entities.Session{},
+		&entities.OAuthClient{},
+		&entities.RecoveryCode{},
+		&entities.WebAuthnCredential{},
+		&entities.PasswordHistory{},
+		&entities.APIKey{},
+		&entities.RoleDefinition{},
+		&entities.Permission{},
+		&entities.RolePermission{},
+		&entities.Organization{},
+		&entities.OrganizationMember{},
+		&entities.Group{},
+		&entities.GroupMember{},
+		&entities.GroupRole{},
+		&entities.GroupPermission{},
 	)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := seedRoles(db); err != nil {
+		return nil, fmt.Errorf("failed to seed roles: %v", err)
+	}
+	if err := seedPermissions(db); err != nil {
+		return nil, fmt.Errorf("failed to seed permissions: %v", err)
+	}
 	createDefaultAdmin(db)
+	if err := seedOrganizations(db); err != nil {
+		return nil, fmt.Errorf("failed to seed organizations: %v", err)
+	}
 	return db, nil
 }
 
@@ -58,8 +85,13 @@ func createDefaultAdmin(db *gorm.DB) {
 		return
 	}
 
+	hashedPassword, err := crypto.HashPassword("Password123")
+	if err != nil {
+		fmt.Printf("Ошибка создания администратора: %v\n", err)
+		return
+	}
 	adminUser := entities.User{
-		Password: "Password123",
+		Password: hashedPassword,
 		Phone:    "+996500500500",
 		Role:     entities.RoleSuperUser,
 		IsActive: true,
@@ -69,3 +101,81 @@ func createDefaultAdmin(db *gorm.DB) {
 		fmt.Printf("Ошибка создания администратора: %v\n", err)
 	}
 }
+
+// seedRoles создаёт встроенные роли. Пользователи с ролью, которой нет в базе,
+// переводятся в роль user, иначе они не смогли бы пройти проверку роли
+func seedRoles(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		roles := entities.BuiltInRoles
+		if err := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "name"}}, DoNothing: true}).
+			Create(&roles).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Model(&entities.User{}).
+			Where("role NOT IN (?)", tx.Model(&entities.RoleDefinition{}).Select("name")).
+			UpdateColumn("role", entities.RoleUser).Error
+	})
+}
+
+// seedOrganizations при первом запуске создаёт организацию по умолчанию и переносит в неё
+// всех пользователей с их ролями. После переноса роль пользователя на платформе — user,
+// кроме superuser
+func seedOrganizations(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&entities.Organization{}).Where("slug = ?", entities.DefaultOrganizationSlug).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+
+		organization := entities.Organization{
+			ID:   uuid.New(),
+			Slug: entities.DefaultOrganizationSlug,
+			Name: "Организация по умолчанию",
+		}
+		if err := tx.Create(&organization).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(`INSERT INTO organization_members (organization_id, user_id, role, created_at)
+			SELECT ?, id, role, NOW() FROM users WHERE deleted_at IS NULL`, organization.ID).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Model(&entities.User{}).
+			Where("role <> ?", entities.RoleSuperUser).
+			UpdateColumn("role", entities.RoleUser).Error
+	})
+}
+
+// seedPermissions создаёт недостающие права. Новое право сразу выдаётся встроенным
+// ролям из DefaultRolePermissions; права, изменённые через API, не перезаписываются
+func seedPermissions(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, permission := range entities.DefaultPermissions {
+			var count int64
+			if err := tx.Model(&entities.Permission{}).Where("name = ?", permission.Name).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				continue
+			}
+
+			if err := tx.Create(&permission).Error; err != nil {
+				return err
+			}
+			for role, names := range entities.DefaultRolePermissions {
+				for _, name := range names {
+					if name != permission.Name {
+						continue
+					}
+					grant := entities.RolePermission{Role: role, Permission: name}
+					if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&grant).Error; err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return nil
+	})
+}
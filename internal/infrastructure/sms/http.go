@@ -0,0 +1,66 @@
+package sms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// httpSender отправляет SMS через HTTP API провайдера: POST JSON {from, to, text}
+// с токеном в заголовке Authorization
+type httpSender struct {
+	url    string
+	token  string
+	from   string
+	client *http.Client
+}
+
+type httpSendRequest struct {
+	From string `json:"from,omitempty"`
+	To   string `json:"to"`
+	Text string `json:"text"`
+}
+
+func NewHTTPSender(url, token, from string, timeout time.Duration) (SMSSender, error) {
+	if url == "" {
+		return nil, fmt.Errorf("SMS_HTTP_URL is required for http SMS driver")
+	}
+	return &httpSender{
+		url:    url,
+		token:  token,
+		from:   from,
+		client: &http.Client{Timeout: timeout},
+	}, nil
+}
+
+func (s *httpSender) Send(ctx context.Context, phone, message string) error {
+	body, err := json.Marshal(httpSendRequest{From: s.from, To: phone, Text: message})
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if s.token != "" {
+		request.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return fmt.Errorf("SMS gateway request failed: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(response.Body, 512))
+		return fmt.Errorf("SMS gateway returned %d: %s", response.StatusCode, bytes.TrimSpace(detail))
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package sms
+
+import (
+	"context"
+	"fmt"
+	"gold_portal/config"
+	"os"
+)
+
+// SMSSender отправляет SMS через выбранный шлюз
+type SMSSender interface {
+	Send(ctx context.Context, phone, message string) error
+}
+
+// NewSMSSender создаёт отправщик по SMS_DRIVER: console и file для разработки и тестов, http для реального провайдера
+func NewSMSSender(cfg *config.Config) (SMSSender, error) {
+	switch cfg.SMS.Driver {
+	case "console":
+		return NewWriterSender(os.Stdout), nil
+	case "file":
+		return NewFileSender(cfg.SMS.FilePath)
+	case "http":
+		return NewHTTPSender(cfg.SMS.HTTPURL, cfg.SMS.HTTPToken, cfg.SMS.From, cfg.SMS.HTTPTimeout)
+	}
+	return nil, fmt.Errorf("unknown SMS driver %q", cfg.SMS.Driver)
+}
@@ -0,0 +1,40 @@
+package sms
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// writerSender пишет сообщения в поток вместо отправки: в консоль или файл
+type writerSender struct {
+	writer io.Writer
+	mutex  sync.Mutex
+}
+
+func NewWriterSender(writer io.Writer) SMSSender {
+	return &writerSender{writer: writer}
+}
+
+// NewFileSender дописывает сообщения в файл, например для чтения кодов в e2e тестах
+func NewFileSender(path string) (SMSSender, error) {
+	if path == "" {
+		return nil, fmt.Errorf("SMS_FILE_PATH is required for file SMS driver")
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open SMS file: %w", err)
+	}
+	return NewWriterSender(file), nil
+}
+
+func (s *writerSender) Send(ctx context.Context, phone, message string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, err := fmt.Fprintf(s.writer, "%s SMS to %s: %s\n", time.Now().Format(time.RFC3339), phone, message)
+	return err
+}
@@ -1,20 +1,28 @@
 package crypto
 
 import (
-	"errors"
-
-	"golang.org/x/crypto/bcrypt"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 )
 
-func HashPassword(password string) (string, error) {
-	if len(password) < 8 {
-		return "", errors.New("password must be at least 8 characters long")
-	}
+// HashToken хэширует случайный секрет с высокой энтропией (секреты клиентов, ключи).
+// Для паролей не подходит — используйте HashPassword
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
 
-	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	if err != nil {
-		return "", err
-	}
+// CheckToken сравнивает секрет с хэшем за постоянное время
+func CheckToken(hashedToken, token string) bool {
+	return subtle.ConstantTimeCompare([]byte(hashedToken), []byte(HashToken(token))) == 1
+}
 
-	return string(hashedBytes), nil
+// HashCode хэширует короткий код (например, SMS) с ключом приложения: без ключа
+// перебрать все варианты по утёкшему хэшу было бы тривиально
+func HashCode(secret, code string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(code))
+	return hex.EncodeToString(mac.Sum(nil))
 }
@@ -1,9 +1,218 @@
 package crypto
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
 )
 
+const (
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmBcrypt   = "bcrypt"
+)
+
+var (
+	ErrPasswordMismatch    = errors.New("password does not match")
+	ErrUnknownPasswordHash = errors.New("unknown password hash format")
+)
+
+// PasswordHasherConfig параметры хэширования новых паролей. Проверяются хэши
+// обоих алгоритмов независимо от настроек
+type PasswordHasherConfig struct {
+	Algorithm  string
+	BcryptCost int
+	// Память в КиБ, число проходов и потоков argon2id
+	Argon2Memory      uint32
+	Argon2Iterations  uint32
+	Argon2Parallelism uint8
+	Argon2SaltLength  uint32
+	Argon2KeyLength   uint32
+}
+
+// DefaultPasswordHasherConfig рекомендации OWASP для argon2id
+var DefaultPasswordHasherConfig = PasswordHasherConfig{
+	Algorithm:         AlgorithmArgon2id,
+	BcryptCost:        12,
+	Argon2Memory:      64 * 1024,
+	Argon2Iterations:  3,
+	Argon2Parallelism: 2,
+	Argon2SaltLength:  16,
+	Argon2KeyLength:   32,
+}
+
+type PasswordHasher interface {
+	// Возвращает хэш в формате PHC ($argon2id$...) или Modular Crypt для bcrypt ($2a$...)
+	Hash(password string) (string, error)
+	// Возвращает ErrPasswordMismatch, если пароль не подходит
+	Verify(hash, password string) error
+	// Хэш создан другим алгоритмом или с другими параметрами и его стоит пересчитать
+	NeedsRehash(hash string) bool
+}
+
+type passwordHasher struct {
+	config PasswordHasherConfig
+}
+
+func NewPasswordHasher(config PasswordHasherConfig) (PasswordHasher, error) {
+	switch config.Algorithm {
+	case AlgorithmArgon2id:
+		if config.Argon2Memory == 0 || config.Argon2Iterations == 0 || config.Argon2Parallelism == 0 ||
+			config.Argon2SaltLength < 8 || config.Argon2KeyLength < 16 {
+			return nil, fmt.Errorf("invalid argon2id parameters")
+		}
+	case AlgorithmBcrypt:
+		if config.BcryptCost < bcrypt.MinCost || config.BcryptCost > bcrypt.MaxCost {
+			return nil, fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported password hash algorithm %q", config.Algorithm)
+	}
+	return &passwordHasher{config: config}, nil
+}
+
+func (h *passwordHasher) Hash(password string) (string, error) {
+	if h.config.Algorithm == AlgorithmBcrypt {
+		hashedBytes, err := bcrypt.GenerateFromPassword([]byte(password), h.config.BcryptCost)
+		if err != nil {
+			return "", err
+		}
+		return string(hashedBytes), nil
+	}
+
+	salt := make([]byte, h.config.Argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	params := argon2Params{
+		memory:      h.config.Argon2Memory,
+		iterations:  h.config.Argon2Iterations,
+		parallelism: h.config.Argon2Parallelism,
+	}
+	key := argon2.IDKey([]byte(password), salt, params.iterations, params.memory, params.parallelism, h.config.Argon2KeyLength)
+	return params.encode(salt, key), nil
+}
+
+func (h *passwordHasher) Verify(hash, password string) error {
+	switch {
+	case isBcryptHash(hash):
+		if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err != nil {
+			if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+				return ErrPasswordMismatch
+			}
+			return err
+		}
+		return nil
+	case strings.HasPrefix(hash, "$"+AlgorithmArgon2id+"$"):
+		params, salt, key, err := decodeArgon2id(hash)
+		if err != nil {
+			return err
+		}
+		candidate := argon2.IDKey([]byte(password), salt, params.iterations, params.memory, params.parallelism, uint32(len(key)))
+		if subtle.ConstantTimeCompare(key, candidate) != 1 {
+			return ErrPasswordMismatch
+		}
+		return nil
+	default:
+		return ErrUnknownPasswordHash
+	}
+}
+
+func (h *passwordHasher) NeedsRehash(hash string) bool {
+	if h.config.Algorithm == AlgorithmBcrypt {
+		cost, err := bcrypt.Cost([]byte(hash))
+		return err != nil || cost != h.config.BcryptCost
+	}
+
+	params, salt, key, err := decodeArgon2id(hash)
+	if err != nil {
+		return true
+	}
+	return params.memory != h.config.Argon2Memory ||
+		params.iterations != h.config.Argon2Iterations ||
+		params.parallelism != h.config.Argon2Parallelism ||
+		uint32(len(salt)) != h.config.Argon2SaltLength ||
+		uint32(len(key)) != h.config.Argon2KeyLength
+}
+
+type argon2Params struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+}
+
+// encode записывает хэш в формате PHC: $argon2id$v=19$m=65536,t=3,p=2$<соль>$<ключ>
+func (p argon2Params) encode(salt, key []byte) string {
+	return fmt.Sprintf("$%s$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		AlgorithmArgon2id, argon2.Version, p.memory, p.iterations, p.parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key))
+}
+
+func decodeArgon2id(hash string) (argon2Params, []byte, []byte, error) {
+	var params argon2Params
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[0] != "" || parts[1] != AlgorithmArgon2id {
+		return params, nil, nil, ErrUnknownPasswordHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, ErrUnknownPasswordHash
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.iterations, &params.parallelism); err != nil {
+		return params, nil, nil, ErrUnknownPasswordHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, ErrUnknownPasswordHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, ErrUnknownPasswordHash
+	}
+	return params, salt, key, nil
+}
+
+func isBcryptHash(hash string) bool {
+	_, err := bcrypt.Cost([]byte(hash))
+	return err == nil
+}
+
+var (
+	defaultHasher      PasswordHasher = &passwordHasher{config: DefaultPasswordHasherConfig}
+	defaultHasherMutex sync.RWMutex
+)
+
+// SetPasswordHasher задаёт хэшер для HashPassword и CheckPassword. Вызывается при старте
+func SetPasswordHasher(hasher PasswordHasher) {
+	defaultHasherMutex.Lock()
+	defer defaultHasherMutex.Unlock()
+	defaultHasher = hasher
+}
+
+func passwordHasherInstance() PasswordHasher {
+	defaultHasherMutex.RLock()
+	defer defaultHasherMutex.RUnlock()
+	return defaultHasher
+}
+
+func HashPassword(password string) (string, error) {
+	return passwordHasherInstance().Hash(password)
+}
+
 func CheckPassword(hashedPassword, password string) error {
-	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+	return passwordHasherInstance().Verify(hashedPassword, password)
+}
+
+// PasswordNeedsRehash сообщает, что хэш устарел по текущим настройкам
+func PasswordNeedsRehash(hashedPassword string) bool {
+	return passwordHasherInstance().NeedsRehash(hashedPassword)
 }
@@ -0,0 +1,139 @@
+package crypto
+
+import (
+	"errors"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Минимальные параметры: тестам не нужна стойкость хэша
+var (
+	testArgon2Config = PasswordHasherConfig{
+		Algorithm:         AlgorithmArgon2id,
+		Argon2Memory:      1024,
+		Argon2Iterations:  1,
+		Argon2Parallelism: 1,
+		Argon2SaltLength:  16,
+		Argon2KeyLength:   32,
+	}
+	testBcryptConfig = PasswordHasherConfig{
+		Algorithm:  AlgorithmBcrypt,
+		BcryptCost: bcrypt.MinCost,
+	}
+)
+
+func newTestHasher(t *testing.T, config PasswordHasherConfig) PasswordHasher {
+	t.Helper()
+	hasher, err := NewPasswordHasher(config)
+	if err != nil {
+		t.Fatalf("NewPasswordHasher: %v", err)
+	}
+	return hasher
+}
+
+func TestPasswordHasherVerify(t *testing.T) {
+	argon2Hasher := newTestHasher(t, testArgon2Config)
+	bcryptHasher := newTestHasher(t, testBcryptConfig)
+
+	argon2Hash, err := argon2Hasher.Hash("correct horse")
+	if err != nil {
+		t.Fatalf("argon2id Hash: %v", err)
+	}
+	bcryptHash, err := bcryptHasher.Hash("correct horse")
+	if err != nil {
+		t.Fatalf("bcrypt Hash: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		hasher   PasswordHasher
+		hash     string
+		password string
+		wantErr  error
+	}{
+		{name: "argon2id", hasher: argon2Hasher, hash: argon2Hash, password: "correct horse"},
+		{name: "argon2id wrong password", hasher: argon2Hasher, hash: argon2Hash, password: "wrong horse", wantErr: ErrPasswordMismatch},
+		// Хэши обоих алгоритмов проверяются независимо от настроенного
+		{name: "bcrypt with argon2id hasher", hasher: argon2Hasher, hash: bcryptHash, password: "correct horse"},
+		{name: "argon2id with bcrypt hasher", hasher: bcryptHasher, hash: argon2Hash, password: "correct horse"},
+		{name: "bcrypt wrong password", hasher: bcryptHasher, hash: bcryptHash, password: "wrong horse", wantErr: ErrPasswordMismatch},
+		{name: "plaintext stored value", hasher: argon2Hasher, hash: "correct horse", password: "correct horse", wantErr: ErrUnknownPasswordHash},
+		{name: "corrupted argon2id hash", hasher: argon2Hasher, hash: "$argon2id$v=19$m=1024,t=1,p=1$bad", password: "correct horse", wantErr: ErrUnknownPasswordHash},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.hasher.Verify(tt.hash, tt.password); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify: got %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPasswordHasherNeedsRehash(t *testing.T) {
+	argon2Hasher := newTestHasher(t, testArgon2Config)
+	bcryptHasher := newTestHasher(t, testBcryptConfig)
+
+	stronger := testArgon2Config
+	stronger.Argon2Iterations = 2
+	strongerHasher := newTestHasher(t, stronger)
+
+	argon2Hash, err := argon2Hasher.Hash("password")
+	if err != nil {
+		t.Fatalf("argon2id Hash: %v", err)
+	}
+	bcryptHash, err := bcryptHasher.Hash("password")
+	if err != nil {
+		t.Fatalf("bcrypt Hash: %v", err)
+	}
+	costlierBcrypt, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost+1)
+	if err != nil {
+		t.Fatalf("bcrypt: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		hasher PasswordHasher
+		hash   string
+		want   bool
+	}{
+		{name: "argon2id with current parameters", hasher: argon2Hasher, hash: argon2Hash},
+		{name: "argon2id with old parameters", hasher: strongerHasher, hash: argon2Hash, want: true},
+		{name: "bcrypt when argon2id is configured", hasher: argon2Hasher, hash: bcryptHash, want: true},
+		{name: "bcrypt with current cost", hasher: bcryptHasher, hash: bcryptHash},
+		{name: "bcrypt with another cost", hasher: bcryptHasher, hash: string(costlierBcrypt), want: true},
+		{name: "argon2id when bcrypt is configured", hasher: bcryptHasher, hash: argon2Hash, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.hasher.NeedsRehash(tt.hash); got != tt.want {
+				t.Fatalf("NeedsRehash = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewPasswordHasherRejectsInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config PasswordHasherConfig
+	}{
+		{name: "unknown algorithm", config: PasswordHasherConfig{Algorithm: "md5"}},
+		{name: "bcrypt cost too low", config: PasswordHasherConfig{Algorithm: AlgorithmBcrypt, BcryptCost: 1}},
+		{name: "argon2id short salt", config: func() PasswordHasherConfig {
+			config := testArgon2Config
+			config.Argon2SaltLength = 4
+			return config
+		}()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewPasswordHasher(tt.config); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
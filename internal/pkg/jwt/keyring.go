@@ -0,0 +1,347 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+
+	rsaKeyBits      = 2048
+	keyFileExt      = ".pem"
+	createdAtHeader = "Created-At"
+	reloadInterval  = time.Minute
+)
+
+// KeyringConfig параметры набора ключей подписи
+type KeyringConfig struct {
+	// Алгоритм подписи новых ключей: RS256 или EdDSA
+	Algorithm string
+	// Каталог для хранения ключей; пустое значение — ключи живут только в памяти
+	Dir string
+	// Как долго ключ используется для подписи, прежде чем его сменит новый
+	RotationInterval time.Duration
+	// Как долго вытесненный ключ продолжает проверять подписи
+	Overlap time.Duration
+}
+
+// signingKey ключ подписи с идентификатором kid
+type signingKey struct {
+	ID        string
+	Algorithm string
+	Private   crypto.Signer
+	CreatedAt time.Time
+}
+
+func (k *signingKey) method() jwt.SigningMethod {
+	return jwt.GetSigningMethod(k.Algorithm)
+}
+
+// Keyring набор ключей подписи с плановой ротацией.
+// Подписывает всегда самый новый ключ, остальные только проверяют подписи
+// до окончания окна перекрытия. Если задан каталог, ключи сохраняются в нём
+// и периодически перечитываются, поэтому реплики с общим каталогом видят
+// ключи, созданные друг другом.
+type Keyring struct {
+	config KeyringConfig
+	keys   []*signingKey
+	mutex  sync.RWMutex
+}
+
+// NewKeyring загружает ключи из каталога и создаёт новый, если действующего нет
+func NewKeyring(cfg KeyringConfig) (*Keyring, error) {
+	if cfg.Algorithm != AlgorithmRS256 && cfg.Algorithm != AlgorithmEdDSA {
+		return nil, fmt.Errorf("unsupported signing algorithm: %s", cfg.Algorithm)
+	}
+	if cfg.RotationInterval <= 0 {
+		return nil, fmt.Errorf("key rotation interval must be positive")
+	}
+
+	if cfg.Dir != "" {
+		if err := os.MkdirAll(cfg.Dir, 0o700); err != nil {
+			return nil, fmt.Errorf("failed to create keys directory: %w", err)
+		}
+	}
+
+	k := &Keyring{config: cfg}
+	if err := k.reload(); err != nil {
+		return nil, err
+	}
+	if err := k.rotateIfDue(); err != nil {
+		return nil, err
+	}
+
+	// Запускаем плановую ротацию ключей
+	go k.rotationLoop()
+
+	return k, nil
+}
+
+// current возвращает ключ для подписи новых токенов
+func (k *Keyring) current() *signingKey {
+	k.mutex.RLock()
+	defer k.mutex.RUnlock()
+
+	if len(k.keys) == 0 {
+		return nil
+	}
+	return k.keys[len(k.keys)-1]
+}
+
+// lookup ищет ключ проверки по kid
+func (k *Keyring) lookup(kid string) *signingKey {
+	k.mutex.RLock()
+	defer k.mutex.RUnlock()
+
+	for _, key := range k.keys {
+		if key.ID == kid {
+			return key
+		}
+	}
+	return nil
+}
+
+// verificationKeys возвращает все ключи, которые ещё проверяют подписи
+func (k *Keyring) verificationKeys() []*signingKey {
+	k.mutex.RLock()
+	defer k.mutex.RUnlock()
+
+	keys := make([]*signingKey, len(k.keys))
+	copy(keys, k.keys)
+	return keys
+}
+
+func (k *Keyring) rotationLoop() {
+	ticker := time.NewTicker(reloadInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := k.reload(); err != nil {
+			log.Printf("Ошибка загрузки ключей подписи: %v", err)
+		}
+		if err := k.rotateIfDue(); err != nil {
+			log.Printf("Ошибка ротации ключа подписи: %v", err)
+		}
+	}
+}
+
+// rotateIfDue создаёт новый ключ, если текущий отработал свой интервал,
+// и убирает ключи, у которых закончилось окно перекрытия
+func (k *Keyring) rotateIfDue() error {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	now := time.Now()
+	if k.rotationDue(now) {
+		key, err := generateKey(k.config.Algorithm)
+		if err != nil {
+			return err
+		}
+		if err := k.persist(key); err != nil {
+			return err
+		}
+		k.keys = append(k.keys, key)
+		log.Printf("Создан новый ключ подписи JWT %s (%s)", key.ID, key.Algorithm)
+	}
+
+	k.prune(now)
+	return nil
+}
+
+// rotationDue проверяет, пора ли сменить ключ подписи: ключа нет,
+// он отработал свой интервал или сменился алгоритм в конфигурации
+func (k *Keyring) rotationDue(now time.Time) bool {
+	if len(k.keys) == 0 {
+		return true
+	}
+	current := k.keys[len(k.keys)-1]
+	return now.Sub(current.CreatedAt) >= k.config.RotationInterval || current.Algorithm != k.config.Algorithm
+}
+
+// prune удаляет ключи, вытесненные раньше, чем overlap назад
+func (k *Keyring) prune(now time.Time) {
+	active := k.keys[:0]
+	for i, key := range k.keys {
+		if i < len(k.keys)-1 {
+			supersededAt := k.keys[i+1].CreatedAt
+			if now.Sub(supersededAt) > k.config.Overlap {
+				k.remove(key)
+				continue
+			}
+		}
+		active = append(active, key)
+	}
+	k.keys = active
+}
+
+// reload перечитывает ключи из каталога. Файл, который не удалось прочитать,
+// пропускается: из-за одного повреждённого ключа реплика не должна терять остальные
+func (k *Keyring) reload() error {
+	if k.config.Dir == "" {
+		return nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(k.config.Dir, "*"+keyFileExt))
+	if err != nil {
+		return fmt.Errorf("failed to list keys: %w", err)
+	}
+
+	keys := make([]*signingKey, 0, len(files))
+	for _, file := range files {
+		key, err := loadKey(file)
+		if err != nil {
+			log.Printf("Пропущен ключ подписи: %v", err)
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+
+	k.mutex.Lock()
+	k.keys = keys
+	k.mutex.Unlock()
+	return nil
+}
+
+func (k *Keyring) persist(key *signingKey) error {
+	if k.config.Dir == "" {
+		return nil
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(key.Private)
+	if err != nil {
+		return fmt.Errorf("failed to marshal signing key: %w", err)
+	}
+
+	block := &pem.Block{
+		Type:    "PRIVATE KEY",
+		Headers: map[string]string{createdAtHeader: key.CreatedAt.UTC().Format(time.RFC3339)},
+		Bytes:   der,
+	}
+
+	// Ключ пишется во временный файл и переименовывается: соседняя реплика
+	// не должна прочитать недописанный PEM
+	file, err := os.CreateTemp(k.config.Dir, "."+key.ID+"-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to save signing key: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(pem.EncodeToMemory(block)); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to save signing key: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to save signing key: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to save signing key: %w", err)
+	}
+
+	path := filepath.Join(k.config.Dir, key.ID+keyFileExt)
+	if err := os.Rename(file.Name(), path); err != nil {
+		return fmt.Errorf("failed to save signing key: %w", err)
+	}
+	return nil
+}
+
+func (k *Keyring) remove(key *signingKey) {
+	if k.config.Dir == "" {
+		return
+	}
+	path := filepath.Join(k.config.Dir, key.ID+keyFileExt)
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		log.Printf("Ошибка удаления ключа подписи %s: %v", key.ID, err)
+	}
+}
+
+func generateKey(algorithm string) (*signingKey, error) {
+	var private crypto.Signer
+	switch algorithm {
+	case AlgorithmRS256:
+		rsaKey, err := rsa.GenerateKey(rand.Reader, rsaKeyBits)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate RSA key: %w", err)
+		}
+		private = rsaKey
+	case AlgorithmEdDSA:
+		_, edKey, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate Ed25519 key: %w", err)
+		}
+		private = edKey
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm: %s", algorithm)
+	}
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, fmt.Errorf("failed to generate key id: %w", err)
+	}
+
+	return &signingKey{
+		ID:        hex.EncodeToString(id),
+		Algorithm: algorithm,
+		Private:   private,
+		CreatedAt: time.Now().Truncate(time.Second),
+	}, nil
+}
+
+func loadKey(path string) (*signingKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("invalid PEM in %s", path)
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing key %s: %w", path, err)
+	}
+
+	key := &signingKey{
+		ID: strings.TrimSuffix(filepath.Base(path), keyFileExt),
+	}
+
+	switch private := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.Algorithm = AlgorithmRS256
+		key.Private = private
+	case ed25519.PrivateKey:
+		key.Algorithm = AlgorithmEdDSA
+		key.Private = private
+	default:
+		return nil, fmt.Errorf("unsupported key type in %s", path)
+	}
+
+	key.CreatedAt, err = time.Parse(time.RFC3339, block.Headers[createdAtHeader])
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s header in %s: %w", createdAtHeader, path, err)
+	}
+
+	return key, nil
+}
@@ -0,0 +1,242 @@
+package jwt
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func newTestKeyring(t *testing.T, algorithm, dir string) *Keyring {
+	t.Helper()
+	keyring, err := NewKeyring(KeyringConfig{
+		Algorithm:        algorithm,
+		Dir:              dir,
+		RotationInterval: time.Hour,
+		Overlap:          2 * time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("NewKeyring: %v", err)
+	}
+	return keyring
+}
+
+func signTestToken(t *testing.T, service JWTService) string {
+	t.Helper()
+	token, err := service.CreateToken(MapClaims{"sub": "user", "exp": time.Now().Add(time.Hour).Unix()})
+	if err != nil {
+		t.Fatalf("CreateToken: %v", err)
+	}
+	return token
+}
+
+// age сдвигает время создания ключей в прошлое, как будто они созданы ago назад
+func age(keyring *Keyring, ago ...time.Duration) {
+	keyring.mutex.Lock()
+	defer keyring.mutex.Unlock()
+	for i, shift := range ago {
+		keyring.keys[i].CreatedAt = time.Now().Add(-shift)
+	}
+}
+
+func TestKeyringRotation(t *testing.T) {
+	keyring := newTestKeyring(t, AlgorithmEdDSA, "")
+	service := NewJWTService(keyring)
+	first := keyring.current()
+	oldToken := signTestToken(t, service)
+
+	// Ключ отработал интервал: подписывает новый, старый только проверяет
+	age(keyring, 61*time.Minute)
+	if err := keyring.rotateIfDue(); err != nil {
+		t.Fatalf("rotateIfDue: %v", err)
+	}
+	second := keyring.current()
+	if second.ID == first.ID {
+		t.Fatal("signing key was not rotated")
+	}
+	newToken := signTestToken(t, service)
+	parsed, err := service.ParseToken(newToken)
+	if err != nil {
+		t.Fatalf("ParseToken new token: %v", err)
+	}
+	if kid := parsed.Header["kid"]; kid != second.ID {
+		t.Fatalf("new token kid %v, want %s", kid, second.ID)
+	}
+	if _, err := service.ParseToken(oldToken); err != nil {
+		t.Fatalf("token of superseded key within overlap: %v", err)
+	}
+	if keys := service.JWKS().Keys; len(keys) != 2 {
+		t.Fatalf("JWKS has %d keys, want 2 during overlap", len(keys))
+	}
+
+	// Окно перекрытия первого ключа закончилось
+	age(keyring, 5*time.Hour, 3*time.Hour)
+	if err := keyring.rotateIfDue(); err != nil {
+		t.Fatalf("rotateIfDue: %v", err)
+	}
+	if _, err := service.ParseToken(oldToken); err == nil {
+		t.Fatal("token of pruned key is still accepted")
+	}
+	if _, err := service.ParseToken(newToken); err != nil {
+		t.Fatalf("token of superseded key within overlap: %v", err)
+	}
+	for _, jwk := range service.JWKS().Keys {
+		if jwk.Kid == first.ID {
+			t.Fatal("pruned key is still published in JWKS")
+		}
+	}
+}
+
+func TestKeyringSharedDirectory(t *testing.T) {
+	dir := t.TempDir()
+	first := newTestKeyring(t, AlgorithmEdDSA, dir)
+	// Вторая реплика подхватывает действующий ключ и не создаёт свой
+	second := newTestKeyring(t, AlgorithmEdDSA, dir)
+
+	if first.current().ID != second.current().ID {
+		t.Fatalf("replicas sign with different keys %s and %s", first.current().ID, second.current().ID)
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*"+keyFileExt))
+	if err != nil {
+		t.Fatalf("Glob: %v", err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("keys directory has %d keys, want 1", len(files))
+	}
+	// Временные файлы записи переименованы или удалены
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("keys directory has %d files, want only the key", len(entries))
+	}
+
+	token := signTestToken(t, NewJWTService(first))
+	if _, err := NewJWTService(second).ParseToken(token); err != nil {
+		t.Fatalf("token of another replica: %v", err)
+	}
+
+	loaded, err := loadKey(files[0])
+	if err != nil {
+		t.Fatalf("loadKey: %v", err)
+	}
+	if !loaded.CreatedAt.Equal(first.current().CreatedAt) || loaded.Algorithm != AlgorithmEdDSA {
+		t.Fatalf("stored key %s created %s, want %s created %s",
+			loaded.Algorithm, loaded.CreatedAt, AlgorithmEdDSA, first.current().CreatedAt)
+	}
+}
+
+func TestKeyringAlgorithmChange(t *testing.T) {
+	dir := t.TempDir()
+	edKeyring := newTestKeyring(t, AlgorithmEdDSA, dir)
+	oldToken := signTestToken(t, NewJWTService(edKeyring))
+
+	// Смена алгоритма в конфигурации выпускает новый ключ сразу
+	rsaKeyring := newTestKeyring(t, AlgorithmRS256, dir)
+	if algorithm := rsaKeyring.current().Algorithm; algorithm != AlgorithmRS256 {
+		t.Fatalf("signing algorithm %s, want %s", algorithm, AlgorithmRS256)
+	}
+	service := NewJWTService(rsaKeyring)
+	if _, err := service.ParseToken(oldToken); err != nil {
+		t.Fatalf("token of previous algorithm: %v", err)
+	}
+
+	keys := map[string]JWK{}
+	for _, jwk := range service.JWKS().Keys {
+		keys[jwk.Alg] = jwk
+	}
+	if jwk := keys[AlgorithmEdDSA]; jwk.Kty != "OKP" || jwk.Crv != "Ed25519" || jwk.X == "" {
+		t.Fatalf("EdDSA JWK %+v", jwk)
+	}
+	if jwk := keys[AlgorithmRS256]; jwk.Kty != "RSA" || jwk.N == "" || jwk.E != "AQAB" || jwk.Use != "sig" {
+		t.Fatalf("RS256 JWK %+v", jwk)
+	}
+}
+
+func TestParseTokenRejectsForgedTokens(t *testing.T) {
+	keyring := newTestKeyring(t, AlgorithmEdDSA, "")
+	service := NewJWTService(keyring)
+	key := keyring.current()
+	claims := jwt.MapClaims{"sub": "user", "exp": time.Now().Add(time.Hour).Unix()}
+
+	hmacToken := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	hmacToken.Header["kid"] = key.ID
+	// Открытый ключ известен всем, поэтому HMAC на нём — классическая подделка
+	hmacSigned, err := hmacToken.SignedString([]byte(key.ID))
+	if err != nil {
+		t.Fatalf("SignedString: %v", err)
+	}
+
+	otherKey, err := generateKey(AlgorithmEdDSA)
+	if err != nil {
+		t.Fatalf("generateKey: %v", err)
+	}
+	unknownToken := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+	unknownToken.Header["kid"] = otherKey.ID
+	unknownSigned, err := unknownToken.SignedString(otherKey.Private)
+	if err != nil {
+		t.Fatalf("SignedString: %v", err)
+	}
+
+	// Подпись чужим ключом под kid действующего
+	spoofedToken := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+	spoofedToken.Header["kid"] = key.ID
+	spoofedSigned, err := spoofedToken.SignedString(otherKey.Private)
+	if err != nil {
+		t.Fatalf("SignedString: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "HMAC with known kid", token: hmacSigned},
+		{name: "unknown kid", token: unknownSigned},
+		{name: "known kid signed by another key", token: spoofedSigned},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := service.ParseToken(tt.token); err == nil {
+				t.Fatal("forged token accepted")
+			}
+		})
+	}
+}
+
+func TestLoadKeyRejectsInvalidFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "broken"+keyFileExt)
+	if err := os.WriteFile(path, []byte("not a key"), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if _, err := loadKey(path); err == nil {
+		t.Fatal("loadKey accepted a file without PEM")
+	}
+}
+
+func TestKeyringSkipsUnreadableKeys(t *testing.T) {
+	dir := t.TempDir()
+	keyring := newTestKeyring(t, AlgorithmEdDSA, dir)
+	current := keyring.current()
+
+	// Повреждённый файл не мешает перечитать остальные ключи
+	if err := os.WriteFile(filepath.Join(dir, "broken"+keyFileExt), []byte("not a key"), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if err := keyring.reload(); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if got := keyring.current(); got == nil || got.ID != current.ID {
+		t.Fatalf("current key after reload = %v, want %s", got, current.ID)
+	}
+
+	// Новая реплика тоже запускается с действующим ключом
+	replica := newTestKeyring(t, AlgorithmEdDSA, dir)
+	if replica.current().ID != current.ID {
+		t.Fatalf("replica signs with %s, want %s", replica.current().ID, current.ID)
+	}
+}
@@ -15,6 +15,8 @@ type JWTService interface {
 	ParseToken(tokenString string) (*jwt.Token, error)
 	// Валидирует токен
 	ValidateToken(token *jwt.Token) error
+	// Возвращает открытые ключи для проверки подписей
+	JWKS() JWKS
 }
 
 // MapClaims тип для claims токена
@@ -28,25 +30,41 @@ type TokenInfo struct {
 	ExpiresAt time.Time `json:"expires_at"`
 	IssuedAt  time.Time `json:"issued_at"`
 	JTI       string    `json:"jti"`
+	// Субъект токена: пользователь или сервисный клиент
+	Subject   string `json:"sub"`
+	ClientID  string `json:"client_id,omitempty"`
+	Scope     string `json:"scope,omitempty"`
+	SessionID string `json:"sid,omitempty"`
+	FamilyID  string `json:"fid,omitempty"`
+	// Активная организация пользователя
+	OrganizationID string `json:"org,omitempty"`
+	// Версия токенов пользователя на момент выдачи
+	Version int `json:"ver,omitempty"`
 }
 
 // jwtService реализация JWT сервиса
 type jwtService struct {
-	secretKey string
+	keyring *Keyring
 }
 
 // NewJWTService создает новый JWT сервис
-func NewJWTService(secretKey string) JWTService {
+func NewJWTService(keyring *Keyring) JWTService {
 	return &jwtService{
-		secretKey: secretKey,
+		keyring: keyring,
 	}
 }
 
-// CreateToken создает новый JWT токен
+// CreateToken создает новый JWT токен, подписанный текущим ключом
 func (s *jwtService) CreateToken(claims MapClaims) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims(claims))
+	key := s.keyring.current()
+	if key == nil {
+		return "", fmt.Errorf("no signing key available")
+	}
+
+	token := jwt.NewWithClaims(key.method(), jwt.MapClaims(claims))
+	token.Header["kid"] = key.ID
 
-	tokenString, err := token.SignedString([]byte(s.secretKey))
+	tokenString, err := token.SignedString(key.Private)
 	if err != nil {
 		return "", fmt.Errorf("failed to sign token: %w", err)
 	}
@@ -56,12 +74,18 @@ func (s *jwtService) CreateToken(claims MapClaims) (string, error) {
 
 // ParseToken парсит и валидирует JWT токен
 func (s *jwtService) ParseToken(tokenString string) (*jwt.Token, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		// Проверяем алгоритм подписи
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{AlgorithmRS256, AlgorithmEdDSA}))
+	token, err := parser.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		// Ищем ключ по kid и проверяем, что алгоритм совпадает с ключом
+		kid, _ := token.Header["kid"].(string)
+		key := s.keyring.lookup(kid)
+		if key == nil {
+			return nil, fmt.Errorf("unknown signing key: %s", kid)
+		}
+		if token.Method.Alg() != key.Algorithm {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return []byte(s.secretKey), nil
+		return key.Private.Public(), nil
 	})
 
 	if err != nil {
@@ -88,3 +112,14 @@ func (s *jwtService) ValidateToken(token *jwt.Token) error {
 
 	return nil
 }
+
+// JWKS возвращает открытые ключи, которые сейчас проверяют подписи
+func (s *jwtService) JWKS() JWKS {
+	keys := s.keyring.verificationKeys()
+
+	jwks := JWKS{Keys: make([]JWK, 0, len(keys))}
+	for _, key := range keys {
+		jwks.Keys = append(jwks.Keys, newJWK(key))
+	}
+	return jwks
+}